@@ -1,16 +1,35 @@
 package main
 
 import (
+	"bufio"
+	"context"
 	"fmt"
+	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/axon/pkg/api"
 	"github.com/axon/pkg/chat"
 	"github.com/axon/pkg/cli"
+	"github.com/axon/pkg/commitmsg"
+	"github.com/axon/pkg/execx"
+	"github.com/axon/pkg/fsctx"
+	"github.com/axon/pkg/git"
 	"github.com/axon/pkg/indexer"
+	"github.com/axon/pkg/jsonl"
+	"github.com/axon/pkg/lineedit"
 	"github.com/axon/pkg/llm"
 	"github.com/axon/pkg/logger"
 	"github.com/axon/pkg/project"
+	"github.com/axon/pkg/review"
 	"github.com/axon/pkg/server"
+	"github.com/axon/pkg/tui"
 )
 
 // ANSI color codes
@@ -24,19 +43,74 @@ const (
 )
 
 func main() {
+	// Commands run in the namespaces sandbox re-execute axon as a helper
+	execx.RunSandboxHelper()
+
 	// Parse debug flag from environment
 	cli.Debug = os.Getenv("AXON_DEBUG") == "1"
 
-	// Handle help flag
+	// Handle help flag and subcommands
+	var fixCommand string
+	var fixIterations int
+	var commit *commitArgs
+	var reviewOpts *reviewArgs
+	var mcpServe *mcpArgs
+	var serve *serveArgs
+	var jsonLines, plain bool
 	if len(os.Args) > 1 {
 		arg := os.Args[1]
-		if arg == "help" || arg == "--help" || arg == "-h" {
+		switch {
+		case arg == "help" || arg == "--help" || arg == "-h":
 			printUsage()
 			return
+		case arg == "fix":
+			var err error
+			fixCommand, fixIterations, err = parseFixArgs(os.Args[2:])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				fmt.Fprintf(os.Stderr, "Usage: axon fix [--max-iterations N] <command>\n")
+				os.Exit(1)
+			}
+		case arg == "commit":
+			var err error
+			if commit, err = parseCommitArgs(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				fmt.Fprintf(os.Stderr, "Usage: axon commit [--hook]\n")
+				os.Exit(1)
+			}
+		case arg == "review":
+			var err error
+			if reviewOpts, err = parseReviewArgs(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				fmt.Fprintf(os.Stderr, "Usage: axon review [<rev-range> | --staged] [--format text|json|sarif] [--fail-on error|warning|info]\n")
+				os.Exit(2)
+			}
+		case arg == "mcp":
+			var err error
+			if mcpServe, err = parseMCPArgs(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				fmt.Fprintf(os.Stderr, "Usage: axon mcp serve [--allow-writes]\n")
+				os.Exit(1)
+			}
+		case arg == "--jsonl" || arg == "--plain":
+			if len(os.Args) > 2 {
+				fmt.Fprintf(os.Stderr, "Error: unknown argument %s\n", os.Args[2])
+				fmt.Fprintf(os.Stderr, "Usage: axon %s\n", arg)
+				os.Exit(1)
+			}
+			jsonLines, plain = arg == "--jsonl", arg == "--plain"
+		case arg == "serve":
+			var err error
+			if serve, err = parseServeArgs(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				fmt.Fprintf(os.Stderr, "Usage: axon serve [--listen 127.0.0.1:PORT] [--max-sessions N]\n")
+				os.Exit(1)
+			}
+		default:
+			fmt.Fprintf(os.Stderr, "axon: interactive chat mode\n")
+			fmt.Fprintf(os.Stderr, "Run 'axon' or 'axon --help' for more information.\n")
+			os.Exit(1)
 		}
-		fmt.Fprintf(os.Stderr, "axon: interactive chat mode\n")
-		fmt.Fprintf(os.Stderr, "Run 'axon' or 'axon --help' for more information.\n")
-		os.Exit(1)
 	}
 
 	// Get current working directory
@@ -68,10 +142,37 @@ func main() {
 
 	cli.Debugf("Loaded config from project root: %s", projectRoot)
 
+	// Installing the hook needs no model, and the hook itself must never
+	// start a server or block the commit
+	if commit != nil && commit.hook {
+		installCommitHook(projectRoot)
+		return
+	}
+	if commit != nil && commit.messageFile != "" {
+		writeCommitMessage(projectRoot, cfg, commit.messageFile)
+		return
+	}
+	// The MCP server only runs tools; the client brings its own model
+	if mcpServe != nil {
+		runMCPServer(projectRoot, cfg, mcpServe)
+		return
+	}
+
+	// In JSON-lines mode stdout carries the protocol, so everything else
+	// printed goes to stderr
+	protocol := os.Stdout
+	if jsonLines {
+		os.Stdout = os.Stderr
+	}
+
 	// Check if server is already running
 	var srv *server.Server
 	if server.CheckRunning(cfg.LLM.BaseURL) {
 		fmt.Fprintf(os.Stderr, "%sLLM server is already running at %s%s\n", colorGreen+colorBold, cfg.LLM.BaseURL, colorReset)
+	} else if jsonLines {
+		// Selecting a model would read the protocol's input
+		fmt.Fprintf(os.Stderr, "Error: no LLM server is running at %s; start it before running axon --jsonl\n", cfg.LLM.BaseURL)
+		os.Exit(1)
 	} else if cfg.Server.AutoStart {
 		// Server is not running, ask user to select model
 		selectedModel, err := server.SelectModel()
@@ -106,29 +207,450 @@ func main() {
 		os.Exit(1)
 	}
 
+	if fixCommand != "" {
+		runFix(projectRoot, cfg, srv, fixCommand, fixIterations)
+		return
+	}
+	if commit != nil {
+		runCommit(projectRoot, cfg, srv)
+		return
+	}
+	if reviewOpts != nil {
+		runReview(projectRoot, cfg, srv, reviewOpts)
+		return
+	}
+	if serve != nil {
+		runServe(projectRoot, cfg, srv, serve)
+		return
+	}
+	if jsonLines {
+		runJSONLines(projectRoot, cfg, protocol)
+		return
+	}
+
 	// Start interactive chat mode
-	startInteractiveMode(projectRoot, cfg, srv)
+	startInteractiveMode(projectRoot, cfg, srv, plain)
+}
+
+// parseFixArgs parses the arguments of 'axon fix': an optional iteration
+// budget followed by the command, quoted or not
+func parseFixArgs(args []string) (string, int, error) {
+	iterations := 0
+	for len(args) > 0 {
+		switch args[0] {
+		case "-n", "--max-iterations":
+			if len(args) < 2 {
+				return "", 0, fmt.Errorf("%s requires a value", args[0])
+			}
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return "", 0, fmt.Errorf("invalid %s: %s", args[0], args[1])
+			}
+			iterations = n
+			args = args[2:]
+			continue
+		case "--":
+			args = args[1:]
+		}
+		break
+	}
+	if len(args) == 0 {
+		return "", 0, fmt.Errorf("a command to fix is required")
+	}
+	return strings.Join(args, " "), iterations, nil
+}
+
+// runFix runs the edit-verify loop on a failing command and exits non-zero if
+// it still fails
+func runFix(projectRoot string, cfg *project.Config, srv *server.Server, command string, iterations int) {
+	session := newSession(projectRoot, cfg)
+	err := session.Fix(command, iterations)
+	session.Close()
+
+	if srv != nil {
+		srv.Stop()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%sFix incomplete:%s %v\n", colorRed+colorBold, colorReset, err)
+		os.Exit(1)
+	}
+}
+
+// commitArgs are the options of 'axon commit'
+type commitArgs struct {
+	hook        bool   // Install the prepare-commit-msg hook
+	messageFile string // Write the message to this file instead of committing (used by the hook)
 }
 
-func startInteractiveMode(projectRoot string, cfg *project.Config, srv *server.Server) {
-	// Index the project
-	fmt.Printf("%s📚 Indexing project...%s\n", colorYellow, colorReset)
+// parseCommitArgs parses the arguments of 'axon commit'
+func parseCommitArgs(args []string) (*commitArgs, error) {
+	commit := &commitArgs{}
+	for len(args) > 0 {
+		switch args[0] {
+		case "--hook":
+			commit.hook = true
+			args = args[1:]
+		case "--message-file":
+			if len(args) < 2 {
+				return nil, fmt.Errorf("%s requires a value", args[0])
+			}
+			commit.messageFile = args[1]
+			// git passes the message source and commit after the file
+			args = nil
+		default:
+			return nil, fmt.Errorf("unknown argument %s", args[0])
+		}
+	}
+	return commit, nil
+}
+
+// mcpArgs are the options of 'axon mcp serve'
+type mcpArgs struct {
+	allowWrites bool // Offer the write tools too
+}
+
+// parseMCPArgs parses the arguments of 'axon mcp'
+func parseMCPArgs(args []string) (*mcpArgs, error) {
+	if len(args) == 0 || args[0] != "serve" {
+		return nil, fmt.Errorf("expected 'serve'")
+	}
+	opts := &mcpArgs{}
+	for _, arg := range args[1:] {
+		switch arg {
+		case "--allow-writes":
+			opts.allowWrites = true
+		default:
+			return nil, fmt.Errorf("unknown argument %s", arg)
+		}
+	}
+	return opts, nil
+}
+
+// runMCPServer indexes the project and serves its tools over stdio. Stdout
+// carries the protocol, so everything else the tools print goes to stderr.
+func runMCPServer(projectRoot string, cfg *project.Config, opts *mcpArgs) {
+	protocol := os.Stdout
+	os.Stdout = os.Stderr
+
 	projectIndex := indexer.NewIndex(projectRoot, cfg)
 	if err := projectIndex.IndexProject(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to index project: %v\n", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := chat.ServeMCP(ctx, projectRoot, cfg, projectIndex, opts.allowWrites, os.Stdin, protocol); err != nil && ctx.Err() == nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runCommit writes a commit message for the staged changes and commits them
+// after the user approves
+func runCommit(projectRoot string, cfg *project.Config, srv *server.Server) {
+	client := llm.NewClient(cfg.LLM.BaseURL, cfg.LLM.Model, cfg.LLM.Temperature)
+	err := chat.CommitStaged(client, projectRoot, bufio.NewScanner(os.Stdin))
+
+	if srv != nil {
+		srv.Stop()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%sCommit failed:%s %v\n", colorRed+colorBold, colorReset, err)
+		os.Exit(1)
+	}
+}
+
+// installCommitHook installs the prepare-commit-msg hook
+func installCommitHook(projectRoot string) {
+	path, err := chat.InstallCommitHook(projectRoot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%sError installing the hook:%s %v\n", colorRed+colorBold, colorReset, err)
+		os.Exit(1)
+	}
+	fmt.Printf("%sInstalled %s%s\n", colorGreen, path, colorReset)
+	fmt.Println("   'git commit' without -m now opens the editor with a suggested message.")
+}
+
+// writeCommitMessage fills in the message file git opens in the editor. It
+// runs from the hook, so it gives up quietly when no LLM server is running
+// and never fails the commit.
+func writeCommitMessage(projectRoot string, cfg *project.Config, messageFile string) {
+	if !server.CheckRunning(cfg.LLM.BaseURL) {
+		fmt.Fprintf(os.Stderr, "axon: LLM server not running at %s; no commit message suggested\n", cfg.LLM.BaseURL)
+		return
+	}
+	client := llm.NewClient(cfg.LLM.BaseURL, cfg.LLM.Model, cfg.LLM.Temperature)
+	msg, err := chat.GenerateCommitMessage(context.Background(), client, projectRoot)
+	if err == nil {
+		err = commitmsg.WriteMessageFile(messageFile, msg)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "axon: no commit message suggested: %v\n", err)
+	}
+}
+
+// reviewArgs are the options of 'axon review'
+type reviewArgs struct {
+	rev    string // Revision or range; empty reviews uncommitted changes
+	staged bool
+	format string
+	failOn string // Exit 1 if a finding is at least this severe
+}
+
+// parseReviewArgs parses the arguments of 'axon review'
+func parseReviewArgs(args []string) (*reviewArgs, error) {
+	opts := &reviewArgs{format: review.FormatText}
+	for len(args) > 0 {
+		arg := args[0]
+		name, value, hasValue := strings.Cut(arg, "=")
+		switch name {
+		case "--staged", "--cached":
+			opts.staged = true
+		case "--format", "--fail-on":
+			if !hasValue {
+				if len(args) < 2 {
+					return nil, fmt.Errorf("%s requires a value", name)
+				}
+				value = args[1]
+				args = args[1:]
+			}
+			if name == "--format" {
+				opts.format = value
+			} else {
+				opts.failOn = value
+			}
+		default:
+			if strings.HasPrefix(arg, "-") {
+				return nil, fmt.Errorf("unknown argument %s", arg)
+			}
+			if opts.rev != "" {
+				return nil, fmt.Errorf("only one revision range can be reviewed")
+			}
+			opts.rev = arg
+		}
+		args = args[1:]
+	}
+	switch opts.format {
+	case review.FormatText, review.FormatJSON, review.FormatSARIF:
+	default:
+		return nil, fmt.Errorf("unknown format %q", opts.format)
+	}
+	if opts.failOn != "" && !slices.Contains(review.Severities, opts.failOn) {
+		return nil, fmt.Errorf("invalid --fail-on %q", opts.failOn)
+	}
+	if opts.staged && opts.rev != "" {
+		return nil, fmt.Errorf("--staged cannot be combined with a revision range")
+	}
+	return opts, nil
+}
+
+// maxReviewDiff is the number of bytes of patch read for a review
+const maxReviewDiff = 4 << 20
+
+// runReview reviews a diff with the model and prints the findings. It exits
+// 1 when a finding reaches --fail-on and 2 when the review cannot run.
+func runReview(projectRoot string, cfg *project.Config, srv *server.Server, opts *reviewArgs) {
+	exit := func(code int) {
+		if srv != nil {
+			srv.Stop()
+		}
+		if code != 0 {
+			os.Exit(code)
+		}
+	}
+
+	repo, err := git.Open(projectRoot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%sError:%s %v\n", colorRed+colorBold, colorReset, err)
+		exit(2)
+	}
+	rev := opts.rev
+	if rev == "" && !opts.staged {
+		// Staged and unstaged changes together
+		rev = "HEAD"
+	}
+	diff, err := repo.Diff(git.DiffOptions{Staged: opts.staged, Rev: rev, Limit: maxReviewDiff})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%sError:%s %v\n", colorRed+colorBold, colorReset, err)
+		exit(2)
+	}
+	var files []git.FilePatch
+	for _, f := range git.SplitPatch(diff.Patch) {
+		if !fsctx.ShouldIgnore(f.Path, cfg) {
+			files = append(files, f)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	interactive := isTerminal(os.Stderr)
+	client := llm.NewClient(cfg.LLM.BaseURL, cfg.LLM.Model, cfg.LLM.Temperature)
+	result, err := review.Review(ctx, client, projectRoot, files, review.Options{
+		Progress: func(done, total int, h git.Hunk) {
+			if interactive {
+				fmt.Fprintf(os.Stderr, "\r\033[K%s🔎 Reviewing %d/%d %s:%d%s", colorYellow, done+1, total, h.Path, h.NewStart, colorReset)
+			}
+		},
+	})
+	if interactive {
+		fmt.Fprint(os.Stderr, "\r\033[K")
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%sReview stopped:%s %v\n", colorRed+colorBold, colorReset, err)
+		exit(2)
+	}
+
+	if err := review.Write(os.Stdout, result, opts.format, isTerminal(os.Stdout)); err != nil {
+		fmt.Fprintf(os.Stderr, "%sError:%s %v\n", colorRed+colorBold, colorReset, err)
+		exit(2)
+	}
+	if result.Hunks == 0 && len(result.Errors) > 0 {
+		exit(2)
+	}
+	if opts.failOn != "" {
+		for _, f := range result.Findings {
+			if review.AtLeast(f.Severity, opts.failOn) {
+				exit(1)
+			}
+		}
+	}
+	exit(0)
+}
+
+// isTerminal reports whether a file is a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// serveArgs are the options of 'axon serve'
+type serveArgs struct {
+	listen      string
+	maxSessions int
+}
+
+// parseServeArgs parses the arguments of 'axon serve'
+func parseServeArgs(args []string) (*serveArgs, error) {
+	opts := &serveArgs{listen: api.DefaultListen}
+	for len(args) > 0 {
+		if len(args) < 2 {
+			return nil, fmt.Errorf("unknown argument %s", args[0])
+		}
+		switch args[0] {
+		case "--listen":
+			opts.listen = args[1]
+		case "--max-sessions":
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid session count %q", args[1])
+			}
+			opts.maxSessions = n
+		default:
+			return nil, fmt.Errorf("unknown argument %s", args[0])
+		}
+		args = args[2:]
+	}
+	return opts, api.CheckListen(opts.listen)
+}
+
+// runServe serves the HTTP API until interrupted
+func runServe(projectRoot string, cfg *project.Config, srv *server.Server, opts *serveArgs) {
+	projectIndex := indexProject(projectRoot, cfg)
+	client := llm.NewClient(cfg.LLM.BaseURL, cfg.LLM.Model, cfg.LLM.Temperature)
+	apiServer := api.New(projectRoot, cfg, projectIndex, func() *chat.Session {
+		return chat.NewSession(client, projectRoot, cfg, cli.Debug, projectIndex)
+	}, api.Options{MaxSessions: opts.maxSessions})
+	defer apiServer.Close()
+
+	httpServer := &http.Server{Addr: opts.listen, Handler: apiServer.Handler()}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if httpServer.Shutdown(shutdownCtx) != nil {
+			httpServer.Close()
+		}
+	}()
+
+	fmt.Printf("%s🌐 Serving the axon API on http://%s%s\n", colorGreen+colorBold, opts.listen, colorReset)
+	err := httpServer.ListenAndServe()
+	if srv != nil {
+		srv.Stop()
+	}
+	if err != nil && err != http.ErrServerClosed {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runJSONLines drives a chat session with JSON lines on stdin and protocol
+// until stdin ends or axon is interrupted
+func runJSONLines(projectRoot string, cfg *project.Config, protocol *os.File) {
+	session := newSession(projectRoot, cfg)
+	defer session.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := jsonl.NewServer(session).Serve(ctx, os.Stdin, protocol); err != nil && ctx.Err() == nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// indexProject indexes the project, showing the progress on one line
+func indexProject(projectRoot string, cfg *project.Config) *indexer.Index {
+	projectIndex := indexer.NewIndex(projectRoot, cfg)
+	var indexed indexer.IndexProgress
+	projectIndex.SetProgressFunc(func(p indexer.IndexProgress) {
+		if p.Done {
+			indexed = p
+			return
+		}
+		// Rewrite the status line in place
+		fmt.Printf("\r\033[K%s📚 Indexing project... %s%s", colorYellow, p, colorReset)
+	})
+	fmt.Printf("%s📚 Indexing project...%s", colorYellow, colorReset)
+	if err := projectIndex.IndexProject(); err != nil {
+		fmt.Print("\r\033[K")
 		fmt.Fprintf(os.Stderr, "%sWarning: Failed to index project:%s %v\n", colorYellow+colorBold, colorReset, err)
 		fmt.Fprintf(os.Stderr, "   Continuing without full index...\n")
 	} else {
-		fmt.Printf("%s✅ Project indexed successfully%s\n", colorGreen, colorReset)
+		fmt.Printf("\r\033[K%s✅ Project indexed: %s%s\n", colorGreen, indexed, colorReset)
 	}
+	return projectIndex
+}
+
+// newSession indexes the project and creates a chat session
+func newSession(projectRoot string, cfg *project.Config) *chat.Session {
+	projectIndex := indexProject(projectRoot, cfg)
 
 	// Create LLM client
 	client := llm.NewClient(cfg.LLM.BaseURL, cfg.LLM.Model, cfg.LLM.Temperature)
 
-	// Create chat session with server reference for cleanup
-	session := chat.NewSession(client, projectRoot, cfg, cli.Debug, projectIndex)
+	return chat.NewSession(client, projectRoot, cfg, cli.Debug, projectIndex)
+}
 
-	// Start interactive chat
-	err := session.Start()
+// startInteractiveMode chats in the full-screen interface, or in the plain
+// REPL if asked to or if stdin or stdout is not a terminal
+func startInteractiveMode(projectRoot string, cfg *project.Config, srv *server.Server, plain bool) {
+	var err error
+	if plain || !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+		session := newSession(projectRoot, cfg)
+		err = session.Start()
+	} else {
+		// The interface indexes the project in the background
+		projectIndex := indexer.NewIndex(projectRoot, cfg)
+		client := llm.NewClient(cfg.LLM.BaseURL, cfg.LLM.Model, cfg.LLM.Temperature)
+		session := chat.NewSession(client, projectRoot, cfg, cli.Debug, projectIndex)
+		err = tui.Run(session, tui.Options{
+			Model:   cfg.LLM.Model,
+			Index:   projectIndex,
+			History: lineedit.OpenHistory(filepath.Join(projectRoot, lineedit.HistoryFile)),
+		})
+		session.Close()
+	}
 
 	// Stop server on chat exit
 	if srv != nil {
@@ -146,10 +668,32 @@ func printUsage() {
 
 USAGE:
     axon                    Start interactive chat mode
+    axon --plain            Chat in the plain line-by-line REPL instead of full screen
+    axon fix <command>      Run a failing command and let axon edit until it passes
+         [-n, --max-iterations N]  Edit/re-run rounds before giving up (default 5)
+    axon commit             Write a commit message for the staged changes and commit
+         [--hook]           Install it as a prepare-commit-msg hook instead
+    axon review             Review uncommitted changes with the model
+         [<rev-range> | --staged]  What to review, e.g. main..HEAD or @{upstream}..
+         [--format text|json|sarif]  Output format (default text)
+         [--fail-on error|warning|info]  Exit 1 if a finding is at least this severe
+    axon serve              Serve an HTTP API for editor plugins and web UIs
+         [--listen 127.0.0.1:PORT]  Loopback address to listen on (default 127.0.0.1:7420)
+         [--max-sessions N]  Chat sessions held at once (default 8)
+    axon mcp serve          Offer the project's tools to MCP clients over stdio
+         [--allow-writes]   Offer the file writing tools too
+    axon --jsonl            Chat over stdio with JSON lines, for editor extensions
     axon --help             Show this help message
 
 INTERACTIVE CHAT MODE:
     axon is an interactive chat-based code assistant. Simply run 'axon' to start.
+    In a terminal it runs full screen: enter sends, alt+enter adds a line, up/down
+    recall earlier messages, ctrl+t shows tool call details and pgup/pgdown scroll.
+    All commands work there too; esc stops a running /fix, and /commit approves the
+    message as written. 'axon --plain' keeps the line-by-line REPL.
+    The plain REPL keeps a history in .axon/history (up/down, ctrl+r searches),
+    completes commands and paths with tab, sends pastes as one message and takes
+    multiline messages between lines holding only """.
 
     You can:
     - Chat naturally with the AI assistant
@@ -162,6 +706,13 @@ COMMANDS IN CHAT:
     /file <path>            Display a file's contents
     /explain <path>         Explain code in a file
     /explain <path> <start:end>  Explain a specific line range
+    /find <question>        Semantic search over the project
+    /reindex                Rebuild the project index
+    /fix <command>          Fix a failing command, e.g. /fix go test ./...
+    /commit                 Write a commit message for the staged changes and commit
+    /checkpoints            List the checkpoints taken by /fix
+    /diff [checkpoint]      Show changes since a checkpoint
+    /rollback <checkpoint>  Restore the files to a checkpoint
     /exit, /quit, /q        Exit the chat
 
 EXAMPLES:
@@ -172,6 +723,14 @@ EXAMPLES:
     You: /explain app/Http/Middleware/CheckRole.php
     You: /file routes/web.php
     You: /explain internal/server/http.go 120:180
+    You: /fix go test ./pkg/indexer/...
+
+    axon fix go test ./pkg/indexer/...      # Fix without entering chat
+    axon fix -n 3 "php artisan test --filter=UserTest"
+    axon commit                             # Commit the staged changes
+    axon commit --hook                      # Suggest messages on plain 'git commit'
+    axon review @{upstream}.. --fail-on error  # Review before pushing
+    axon review --staged --format sarif > review.sarif
 
 CONFIGURATION:
     Configuration can be set via:
@@ -0,0 +1,377 @@
+// Package api serves axon over HTTP for editor plugins and web UIs. The
+// server holds the project index and a pool of chat sessions; answers stream
+// as server-sent events, and tool calls that need confirmation wait until a
+// client approves or rejects them.
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/axon/pkg/chat"
+	"github.com/axon/pkg/indexer"
+	"github.com/axon/pkg/project"
+)
+
+// Defaults used when Options leaves a limit unset
+const (
+	DefaultListen      = "127.0.0.1:7420"
+	DefaultMaxSessions = 8
+	DefaultIdleTimeout = 30 * time.Minute
+)
+
+// Options configure the server
+type Options struct {
+	MaxSessions int           // Sessions held at once
+	IdleTimeout time.Duration // Unused sessions are closed after this long
+}
+
+// Server is the HTTP API of one project
+type Server struct {
+	root       string
+	newSession func() *chat.Session
+	opts       Options
+
+	toolsMu sync.Mutex
+	tools   *chat.Session // Runs index queries
+
+	mu       sync.Mutex
+	sessions map[string]*session
+	done     chan struct{}
+}
+
+// New creates a server for a project. newSession creates the chat session
+// behind each API session; the sessions share projectIndex.
+func New(projectRoot string, cfg *project.Config, projectIndex *indexer.Index, newSession func() *chat.Session, opts Options) *Server {
+	if opts.MaxSessions <= 0 {
+		opts.MaxSessions = DefaultMaxSessions
+	}
+	if opts.IdleTimeout <= 0 {
+		opts.IdleTimeout = DefaultIdleTimeout
+	}
+	srv := &Server{
+		root:       projectRoot,
+		newSession: newSession,
+		opts:       opts,
+		tools:      chat.NewToolSession(projectRoot, cfg, projectIndex),
+		sessions:   make(map[string]*session),
+		done:       make(chan struct{}),
+	}
+	go srv.reapIdle()
+	return srv
+}
+
+// Handler returns the routes of the API
+func (srv *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/health", srv.handleHealth)
+	mux.HandleFunc("GET /v1/sessions", srv.handleListSessions)
+	mux.HandleFunc("POST /v1/sessions", srv.handleCreateSession)
+	mux.HandleFunc("GET /v1/sessions/{id}", srv.handleGetSession)
+	mux.HandleFunc("DELETE /v1/sessions/{id}", srv.handleDeleteSession)
+	mux.HandleFunc("GET /v1/sessions/{id}/messages", srv.handleHistory)
+	mux.HandleFunc("POST /v1/sessions/{id}/messages", srv.handleMessage)
+	mux.HandleFunc("POST /v1/sessions/{id}/approvals/{approval}", srv.handleApproval)
+	mux.HandleFunc("POST /v1/index/{tool}", srv.handleIndex)
+	return localOnly(mux)
+}
+
+// Close closes every session
+func (srv *Server) Close() {
+	srv.mu.Lock()
+	sessions := srv.sessions
+	srv.sessions = make(map[string]*session)
+	select {
+	case <-srv.done:
+	default:
+		close(srv.done)
+	}
+	srv.mu.Unlock()
+	for _, s := range sessions {
+		s.chat.Close()
+	}
+	srv.tools.Close()
+}
+
+// reapIdle closes sessions that have not been used for the idle timeout
+func (srv *Server) reapIdle() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-srv.done:
+			return
+		case <-ticker.C:
+			srv.closeIdle(time.Now().Add(-srv.opts.IdleTimeout))
+		}
+	}
+}
+
+// closeIdle closes the sessions unused since t
+func (srv *Server) closeIdle(t time.Time) {
+	srv.mu.Lock()
+	var idle []*session
+	for id, s := range srv.sessions {
+		if s.retire(t) {
+			idle = append(idle, s)
+			delete(srv.sessions, id)
+		}
+	}
+	srv.mu.Unlock()
+	for _, s := range idle {
+		s.chat.Close()
+	}
+}
+
+// session finds a session by the {id} of the request, answering 404 if
+// there is none
+func (srv *Server) session(w http.ResponseWriter, r *http.Request) *session {
+	srv.mu.Lock()
+	s := srv.sessions[r.PathValue("id")]
+	srv.mu.Unlock()
+	if s == nil {
+		writeError(w, http.StatusNotFound, "no session %q", r.PathValue("id"))
+	}
+	return s
+}
+
+func (srv *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "ok", "project": srv.root})
+}
+
+func (srv *Server) handleListSessions(w http.ResponseWriter, r *http.Request) {
+	srv.mu.Lock()
+	infos := make([]sessionInfo, 0, len(srv.sessions))
+	for _, s := range srv.sessions {
+		infos = append(infos, s.info())
+	}
+	srv.mu.Unlock()
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Created.Before(infos[j].Created) })
+	writeJSON(w, http.StatusOK, map[string]interface{}{"sessions": infos})
+}
+
+func (srv *Server) handleCreateSession(w http.ResponseWriter, r *http.Request) {
+	srv.closeIdle(time.Now().Add(-srv.opts.IdleTimeout))
+	if srv.full() {
+		writeError(w, http.StatusServiceUnavailable, "all %d sessions are in use; delete one first", srv.opts.MaxSessions)
+		return
+	}
+
+	// Starting a session may take a while (MCP servers), so the pool is
+	// checked again once it is ready
+	s := newSession(srv.newSession())
+	srv.mu.Lock()
+	full := len(srv.sessions) >= srv.opts.MaxSessions
+	if !full {
+		srv.sessions[s.id] = s
+	}
+	srv.mu.Unlock()
+	if full {
+		s.chat.Close()
+		writeError(w, http.StatusServiceUnavailable, "all %d sessions are in use; delete one first", srv.opts.MaxSessions)
+		return
+	}
+	writeJSON(w, http.StatusCreated, s.info())
+}
+
+// full reports whether the pool holds the maximum number of sessions
+func (srv *Server) full() bool {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	return len(srv.sessions) >= srv.opts.MaxSessions
+}
+
+func (srv *Server) handleGetSession(w http.ResponseWriter, r *http.Request) {
+	if s := srv.session(w, r); s != nil {
+		writeJSON(w, http.StatusOK, s.info())
+	}
+}
+
+func (srv *Server) handleDeleteSession(w http.ResponseWriter, r *http.Request) {
+	s := srv.session(w, r)
+	if s == nil {
+		return
+	}
+	if !s.retire(time.Now()) {
+		writeError(w, http.StatusConflict, "session is answering a message")
+		return
+	}
+	srv.mu.Lock()
+	delete(srv.sessions, s.id)
+	srv.mu.Unlock()
+	s.chat.Close()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (srv *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	s := srv.session(w, r)
+	if s == nil {
+		return
+	}
+	history, ok := s.history()
+	if !ok {
+		writeError(w, http.StatusConflict, "session is answering a message")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"messages": history})
+}
+
+// handleMessage answers a message, streaming the tokens, tool calls,
+// confirmations and the outcome as server-sent events
+func (srv *Server) handleMessage(w http.ResponseWriter, r *http.Request) {
+	s := srv.session(w, r)
+	if s == nil {
+		return
+	}
+	var body struct {
+		Content string `json:"content"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || strings.TrimSpace(body.Content) == "" {
+		writeError(w, http.StatusBadRequest, "expected {\"content\": \"...\"}")
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	if !s.begin() {
+		writeError(w, http.StatusConflict, "session is answering another message")
+		return
+	}
+	defer s.end()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	// The turn ends with a done or error event
+	s.chat.Send(r.Context(), body.Content, func(e chat.Event) {
+		data, _ := json.Marshal(e)
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+		flusher.Flush()
+	})
+}
+
+func (srv *Server) handleApproval(w http.ResponseWriter, r *http.Request) {
+	s := srv.session(w, r)
+	if s == nil {
+		return
+	}
+	var body struct {
+		Decision string `json:"decision"`
+	}
+	json.NewDecoder(r.Body).Decode(&body)
+	switch body.Decision {
+	case "yes", "no", "always":
+	default:
+		writeError(w, http.StatusBadRequest, "decision must be yes, no or always")
+		return
+	}
+	if !s.decide(r.PathValue("approval"), body.Decision) {
+		writeError(w, http.StatusNotFound, "no pending approval %q", r.PathValue("approval"))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleIndex runs one of the read-only tools with the JSON arguments of the
+// request and returns its result
+func (srv *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	tool := r.PathValue("tool")
+	if !chat.IsReadOnlyTool(tool) {
+		writeError(w, http.StatusNotFound, "unknown index query %q", tool)
+		return
+	}
+	args := map[string]interface{}{}
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid arguments: %v", err)
+		return
+	}
+
+	srv.toolsMu.Lock()
+	result, err := srv.tools.ExecuteTool(tool, args)
+	srv.toolsMu.Unlock()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(result))
+}
+
+// writeJSON writes v as the response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes {"error": "..."} as the response
+func writeError(w http.ResponseWriter, status int, format string, args ...interface{}) {
+	writeJSON(w, status, map[string]string{"error": fmt.Sprintf(format, args...)})
+}
+
+// localOnly refuses requests whose Host or Origin is not a loopback address,
+// so that web pages cannot drive the API through DNS rebinding or cross-site
+// requests
+func localOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isLoopbackHost(r.Host) {
+			writeError(w, http.StatusForbidden, "host %q is not a loopback address", r.Host)
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" {
+			u, err := url.Parse(origin)
+			if err != nil || !isLoopbackHost(u.Host) {
+				writeError(w, http.StatusForbidden, "origin %q is not allowed", origin)
+				return
+			}
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			if r.Method == http.MethodOptions {
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE")
+				w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// CheckListen verifies that a listen address is on a loopback interface
+func CheckListen(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid listen address %q: %w", addr, err)
+	}
+	if !isLoopbackHost(host) {
+		return fmt.Errorf("refusing to listen on %s: the API has no authentication, use a loopback address such as 127.0.0.1", host)
+	}
+	return nil
+}
+
+// isLoopbackHost reports whether a host, with or without a port, is
+// localhost or a loopback IP
+func isLoopbackHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
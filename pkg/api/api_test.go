@@ -0,0 +1,195 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/axon/pkg/chat"
+	"github.com/axon/pkg/indexer"
+	"github.com/axon/pkg/internal/testutil"
+	"github.com/axon/pkg/llm"
+	"github.com/axon/pkg/project"
+)
+
+// newTestServer starts the API for a temporary project
+func newTestServer(t *testing.T) (*httptest.Server, string) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644)
+	cfg, err := project.LoadConfig(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	projectIndex := indexer.NewIndex(root, cfg)
+	if err := projectIndex.IndexProject(); err != nil {
+		t.Fatal(err)
+	}
+
+	model := testutil.FakeLLM(t, "hello.txt", "hi\n", "Created ", "hello.txt.")
+	client := llm.NewClient(model.URL, "test", 0)
+	srv := New(root, cfg, projectIndex, func() *chat.Session {
+		return chat.NewSession(client, root, cfg, false, projectIndex)
+	}, Options{MaxSessions: 1})
+	t.Cleanup(srv.Close)
+
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+	return ts, root
+}
+
+func post(t *testing.T, url string, body interface{}) *http.Response {
+	data, _ := json.Marshal(body)
+	resp, err := http.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestMessageWithApproval(t *testing.T) {
+	ts, root := newTestServer(t)
+
+	resp := post(t, ts.URL+"/v1/sessions", nil)
+	var info sessionInfo
+	json.NewDecoder(resp.Body).Decode(&info)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated || info.ID == "" {
+		t.Fatalf("create session: %s", resp.Status)
+	}
+	full := post(t, ts.URL+"/v1/sessions", nil)
+	full.Body.Close()
+	if full.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected the pool to be full, got %s", full.Status)
+	}
+
+	resp = post(t, ts.URL+"/v1/sessions/"+info.ID+"/messages", map[string]string{"content": "Create hello.txt"})
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("expected an event stream, got %s", resp.Header.Get("Content-Type"))
+	}
+
+	var types []string
+	var answer string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var e chat.Event
+		if err := json.Unmarshal([]byte(data), &e); err != nil {
+			t.Fatalf("bad event %s: %v", data, err)
+		}
+		types = append(types, e.Type)
+		switch e.Type {
+		case chat.EventConfirmation:
+			// A second message is refused while the first waits
+			busy := post(t, ts.URL+"/v1/sessions/"+info.ID+"/messages", map[string]string{"content": "x"})
+			busy.Body.Close()
+			if busy.StatusCode != http.StatusConflict {
+				t.Errorf("expected a busy session, got %s", busy.Status)
+			}
+			approved := post(t, ts.URL+"/v1/sessions/"+info.ID+"/approvals/"+e.Confirmation.ID, map[string]string{"decision": "yes"})
+			approved.Body.Close()
+			if approved.StatusCode != http.StatusNoContent {
+				t.Errorf("approve: %s", approved.Status)
+			}
+		case chat.EventError:
+			t.Fatalf("unexpected error event: %s", e.Error)
+		case chat.EventDone:
+			answer = e.Text
+		}
+	}
+
+	want := "tool_call,confirmation,tool_result,token,token,usage,done"
+	if got := strings.Join(types, ","); got != want {
+		t.Errorf("events = %s, want %s", got, want)
+	}
+	if answer != "Created hello.txt." {
+		t.Errorf("answer = %q", answer)
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "hello.txt")); err != nil || string(data) != "hi\n" {
+		t.Errorf("file not created: %q, %v", data, err)
+	}
+
+	historyResp, err := http.Get(ts.URL + "/v1/sessions/" + info.ID + "/messages")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer historyResp.Body.Close()
+	var history struct {
+		Messages []llm.Message `json:"messages"`
+	}
+	json.NewDecoder(historyResp.Body).Decode(&history)
+	if len(history.Messages) != 2 || history.Messages[1].Content != "Created hello.txt." {
+		t.Errorf("unexpected history %+v", history.Messages)
+	}
+}
+
+func TestIndexQuery(t *testing.T) {
+	ts, _ := newTestServer(t)
+
+	resp := post(t, ts.URL+"/v1/index/get_file_symbols", map[string]string{"path": "main.go"})
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), `"main"`) {
+		t.Errorf("get_file_symbols: %s %s", resp.Status, body)
+	}
+
+	resp = post(t, ts.URL+"/v1/index/write_file", map[string]string{"path": "x", "content": "y"})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("write_file is not an index query, got %s", resp.Status)
+	}
+	resp = post(t, ts.URL+"/v1/index/read_file", map[string]string{"path": "../outside"})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected a path outside the project to fail, got %s", resp.Status)
+	}
+}
+
+func TestLocalOnly(t *testing.T) {
+	ts, _ := newTestServer(t)
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/v1/health", nil)
+	req.Host = "attacker.example:7420"
+	if resp, err := http.DefaultClient.Do(req); err != nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected a foreign Host to be refused, got %v, %v", resp.Status, err)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, ts.URL+"/v1/health", nil)
+	req.Header.Set("Origin", "https://attacker.example")
+	if resp, err := http.DefaultClient.Do(req); err != nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected a foreign Origin to be refused, got %v, %v", resp.Status, err)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, ts.URL+"/v1/health", nil)
+	req.Header.Set("Origin", "http://localhost:5173")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil || resp.StatusCode != http.StatusOK || resp.Header.Get("Access-Control-Allow-Origin") != "http://localhost:5173" {
+		t.Errorf("expected a local Origin to be allowed, got %v, %v", resp.Status, err)
+	}
+}
+
+func TestCheckListen(t *testing.T) {
+	for addr, ok := range map[string]bool{
+		"127.0.0.1:7420": true,
+		"localhost:7420": true,
+		"[::1]:7420":     true,
+		"0.0.0.0:7420":   false,
+		":7420":          false,
+		"10.0.0.5:7420":  false,
+		"127.0.0.1":      false,
+	} {
+		if err := CheckListen(addr); (err == nil) != ok {
+			t.Errorf("CheckListen(%q) = %v", addr, err)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/axon/pkg/chat"
+	"github.com/axon/pkg/llm"
+)
+
+// session is a chat session held for API clients
+type session struct {
+	id      string
+	chat    *chat.Session
+	created time.Time
+
+	mu       sync.Mutex
+	lastUsed time.Time
+	busy     bool // A message is being answered
+	closed   bool // Removed from the pool; no more turns
+	pending  map[string]*approval
+}
+
+// approval is a confirmation waiting for a client's decision
+type approval struct {
+	chat.Confirmation
+	answer chan string
+}
+
+// sessionInfo describes a session in responses
+type sessionInfo struct {
+	ID        string      `json:"id"`
+	Created   time.Time   `json:"created"`
+	LastUsed  time.Time   `json:"last_used"`
+	Busy      bool        `json:"busy"`
+	Messages  int         `json:"messages"`
+	Approvals []*approval `json:"approvals"`
+}
+
+func newSession(chatSession *chat.Session) *session {
+	now := time.Now()
+	s := &session{
+		id:       newID(),
+		chat:     chatSession,
+		created:  now,
+		lastUsed: now,
+		pending:  make(map[string]*approval),
+	}
+	chatSession.SetApprover(s)
+	return s
+}
+
+// begin marks the session busy for a turn; it fails if a turn is running
+func (s *session) begin() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.busy || s.closed {
+		return false
+	}
+	s.busy = true
+	s.lastUsed = time.Now()
+	return true
+}
+
+// end marks the turn finished
+func (s *session) end() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.busy = false
+	s.lastUsed = time.Now()
+}
+
+// Approve waits for a client to answer the confirmation, which the message
+// stream has announced. A client that leaves answers no.
+func (s *session) Approve(ctx context.Context, c chat.Confirmation) (string, error) {
+	a := &approval{Confirmation: c, answer: make(chan string, 1)}
+	s.mu.Lock()
+	s.pending[c.ID] = a
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.pending, c.ID)
+		s.mu.Unlock()
+	}()
+	select {
+	case answer := <-a.answer:
+		return answer, nil
+	case <-ctx.Done():
+		return chat.DecisionNo, nil
+	}
+}
+
+// decide answers a pending confirmation; it reports whether it was pending
+func (s *session) decide(id, decision string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.pending[id]
+	if a == nil {
+		return false
+	}
+	delete(s.pending, id)
+	a.answer <- decision
+	return true
+}
+
+// info describes the session
+func (s *session) info() sessionInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	info := sessionInfo{
+		ID:        s.id,
+		Created:   s.created,
+		LastUsed:  s.lastUsed,
+		Busy:      s.busy,
+		Approvals: make([]*approval, 0, len(s.pending)),
+	}
+	if !s.busy {
+		// The conversation only changes during a turn
+		info.Messages = len(s.chat.History())
+	}
+	for _, a := range s.pending {
+		info.Approvals = append(info.Approvals, a)
+	}
+	sort.Slice(info.Approvals, func(i, j int) bool {
+		a, _ := strconv.Atoi(info.Approvals[i].ID)
+		b, _ := strconv.Atoi(info.Approvals[j].ID)
+		return a < b
+	})
+	return info
+}
+
+// retire marks the session closed unless it is answering a message or has
+// been used since t; it reports whether it did
+func (s *session) retire(t time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.busy || s.lastUsed.After(t) {
+		return false
+	}
+	s.closed = true
+	return true
+}
+
+// history returns the conversation, or false while a message is answered
+func (s *session) history() ([]llm.Message, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.busy {
+		return nil, false
+	}
+	return s.chat.History(), true
+}
+
+// newID returns a random session ID
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
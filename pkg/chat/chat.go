@@ -3,14 +3,27 @@ package chat
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/axon/pkg/checkpoint"
 	"github.com/axon/pkg/fsctx"
+	"github.com/axon/pkg/goanalysis"
 	"github.com/axon/pkg/indexer"
+	"github.com/axon/pkg/interrupt"
+	"github.com/axon/pkg/lineedit"
 	"github.com/axon/pkg/llm"
+	"github.com/axon/pkg/lsp"
+	"github.com/axon/pkg/mcp"
+	"github.com/axon/pkg/policy"
+	"github.com/axon/pkg/procs"
 	"github.com/axon/pkg/project"
+	"github.com/axon/pkg/schema"
+	"github.com/axon/pkg/semantic"
 	"github.com/charmbracelet/glamour"
 )
 
@@ -33,50 +46,127 @@ type Session struct {
 	cfg         *project.Config
 	messages    []llm.Message
 	debug       bool
-	scanner     *bufio.Scanner // Scanner for user input (used for confirmations)
-	index       *indexer.Index // Project index
+	editor      *lineedit.Editor     // Reads the user's input
+	scanner     *bufio.Scanner       // Scanner for user input (used for confirmations)
+	index       *indexer.Index       // Project index
+	semantic    *semantic.Index      // Embedding-based search over the project index
+	golang      *goanalysis.Analyzer // Type-checked symbol resolution, nil outside Go projects
+	lsp         *lsp.Manager         // Language servers, started on first use
+	mapGen      uint64               // Index generation the repository map was built from
+	schema      []*schema.Database   // Database schema, rebuilt when the index changes
+	schemaGen   uint64               // Index generation the schema was built from
+	checkpoints *checkpoint.Store    // Working tree snapshots for /fix, opened on first use
+	policy      *policy.Policy       // Permission rules for tool calls
+	audit       *policy.Audit        // Log of every permission decision
+	procs       *procs.Manager       // Background processes, stopped when the session closes
+	mcp         *mcp.Manager         // MCP servers whose tools are offered next to the built-in ones
+	unattended  bool                 // Calls no rule decides run without asking; the MCP client confirms them
+
+	approver      Approver        // Decides on confirmations; the terminal prompt by default
+	handle        func(Event)     // Receives the events of the running turn
+	ctx           context.Context // Context of the running turn
+	confirmations int             // Confirmations asked so far, for their IDs
+	out           io.Writer       // Where chat commands print
+	frontend      bool            // RunCommand runs the current command
 }
 
 // NewSession creates a new chat session
 func NewSession(client *llm.Client, projectRoot string, cfg *project.Config, debug bool, projectIndex *indexer.Index) *Session {
+	editor := lineedit.New(os.Stdin, os.Stdout)
 	session := &Session{
 		client:      client,
 		projectRoot: projectRoot,
 		cfg:         cfg,
 		messages:    make([]llm.Message, 0),
 		debug:       debug,
-		scanner:     bufio.NewScanner(os.Stdin),
+		editor:      editor,
+		scanner:     editor.Scanner(),
 		index:       projectIndex,
+		policy:      newPolicy(cfg),
+		audit:       policy.OpenAudit(projectRoot),
+		procs:       procs.NewManager(),
+		out:         os.Stdout,
 	}
+	session.approver = terminalApprover{scanner: session.scanner}
+
+	// Embeddings may be served by a separate llama-server started with --embedding
+	embedder := llm.NewClient(cfg.Embeddings.BaseURL, cfg.Embeddings.Model, 0)
+	session.semantic = semantic.NewIndex(projectRoot, projectIndex, embedder, cfg.Embeddings.Model)
+
+	if goanalysis.IsGoProject(projectRoot) {
+		session.golang = goanalysis.New(projectRoot)
+	}
+	session.lsp = lsp.NewManager(projectRoot, cfg.LSP.Servers)
+	session.startMCP()
 
 	// Add system message
 	session.messages = append(session.messages, llm.Message{
 		Role:    "system",
-		Content: llm.GetSystemPrompt(),
+		Content: session.systemPrompt(),
 	})
 
 	return session
 }
 
+// systemPrompt builds the system message: the base prompt followed by a ranked
+// map of the repository, so the model does not start every conversation blind
+func (s *Session) systemPrompt() string {
+	prompt := llm.GetSystemPrompt()
+	if s.mcp != nil && len(s.mcp.Tools()) > 0 {
+		prompt += "\nTools named mcp_<server>_<tool> come from the user's MCP servers (such as ticketing systems or databases); use them for questions about that data."
+	}
+	if s.index == nil {
+		return prompt
+	}
+
+	s.mapGen = s.index.Generation()
+	repoMap := s.index.RepoMap(s.cfg.Context.RepoMapTokens)
+	if repoMap == "" {
+		return prompt
+	}
+	return prompt + "\n\nRepository map (key files and symbols, most referenced first; use tools to read details):\n" + repoMap
+}
+
+// refreshSystemPrompt rebuilds the system message if the index changed since it was built
+func (s *Session) refreshSystemPrompt() {
+	if s.index == nil || s.index.Generation() == s.mapGen {
+		return
+	}
+	if len(s.messages) > 0 && s.messages[0].Role == "system" {
+		s.messages[0].Content = s.systemPrompt()
+	}
+}
+
+// Close stops the language servers and background processes started by the
+// session
+func (s *Session) Close() {
+	if s.lsp != nil {
+		s.lsp.Close()
+	}
+	s.procs.StopAll()
+	s.mcp.Close()
+}
+
 // Start starts the interactive chat session
 func (s *Session) Start() error {
+	defer s.Close()
+
 	// Print welcome message
 	s.printWelcome()
 
+	s.editor.SetHistory(lineedit.OpenHistory(filepath.Join(s.projectRoot, lineedit.HistoryFile)))
+	s.editor.SetCompleter(s.complete)
 	for {
-		// Print prompt
-		fmt.Printf("\n%sYou:%s ", colorCyan+colorBold, colorReset)
-
-		// Read input
-		if !s.scanner.Scan() {
-			// EOF or error
-			if err := s.scanner.Err(); err != nil {
-				return fmt.Errorf("error reading input: %w", err)
-			}
+		fmt.Println()
+		line, err := s.editor.ReadLine(fmt.Sprintf("%sYou:%s ", colorCyan+colorBold, colorReset))
+		if errors.Is(err, io.EOF) || errors.Is(err, lineedit.ErrInterrupt) {
 			break
 		}
+		if err != nil {
+			return fmt.Errorf("error reading input: %w", err)
+		}
 
-		input := strings.TrimSpace(s.scanner.Text())
+		input := strings.TrimSpace(line)
 
 		// Handle empty input
 		if input == "" {
@@ -91,124 +181,46 @@ func (s *Session) Start() error {
 			// If command handler returns false, treat it as regular input
 		}
 
-		// Add user message to history
-		s.messages = append(s.messages, llm.Message{
-			Role:    "user",
-			Content: input,
+		_, err = s.renderTurn(func(ctx context.Context, handle func(Event)) (string, error) {
+			return s.Send(ctx, input, handle)
 		})
-
-		// Show thinking indicator
-		fmt.Printf("\n%sAXON is thinking...%s\n\n", colorYellow, colorReset)
-
-		// Call LLM with tools support and streaming
-		ctx := context.Background()
-		tools := llm.GetAvailableTools()
-
-		// Create tool executor
-		executeTool := func(name string, args map[string]interface{}) (string, error) {
-			return s.ExecuteTool(name, args)
-		}
-
-		// Store original message count
-		originalCount := len(s.messages)
-
-		// Buffer to accumulate markdown for real-time rendering
-		var markdownBuffer strings.Builder
-		var lastRenderedLines int
-		var lastRenderLength int
-		renderCounter := 0
-
-		// Initialize markdown renderer once
-		markdownRenderer, err := glamour.NewTermRenderer(
-			glamour.WithAutoStyle(),
-			glamour.WithWordWrap(80),
-		)
-		if err != nil {
-			// Fallback to basic rendering if glamour fails
-			markdownRenderer = nil
-		}
-
-		// Streaming callback - render markdown in real-time
-		firstToken := true
-		streamCallback := func(chunk string) error {
-			if firstToken {
-				fmt.Printf("\n%sAXON:%s\n", colorMagenta+colorBold, colorReset)
-				firstToken = false
-				lastRenderedLines = 0
-				lastRenderLength = 0
-			}
-
-			// Accumulate chunk
-			markdownBuffer.WriteString(chunk)
-			currentBuffer := markdownBuffer.String()
-			currentLength := len(currentBuffer)
-
-			// Render periodically or when structure changes (e.g., code block closes)
-			shouldRender := false
-			renderCounter++
-
-			// Render if:
-			// 1. Every 3 chunks (throttling for performance)
-			// 2. Code block opens or closes (```)
-			// 3. Significant length change (new paragraph/section)
-			// 4. Newline character (likely end of sentence/paragraph)
-			if renderCounter%3 == 0 {
-				shouldRender = true
-			} else if strings.Contains(chunk, "```") {
-				shouldRender = true
-			} else if strings.Contains(chunk, "\n") {
-				shouldRender = true
-			} else if currentLength-lastRenderLength > 50 {
-				shouldRender = true
-			}
-
-			// Try to render the accumulated markdown
-			if markdownRenderer != nil && shouldRender {
-				s.renderStreamingMarkdown(markdownRenderer, currentBuffer, &lastRenderedLines)
-				lastRenderLength = currentLength
-			} else if markdownRenderer == nil {
-				// Fallback: just print the chunk
-				fmt.Print(chunk)
-			}
-
-			return nil
-		}
-
-		fullResponse, err := s.client.ChatWithToolsStream(ctx, s.messages, tools, executeTool, streamCallback)
-		if err != nil {
+		switch {
+		case errors.Is(err, context.Canceled):
+			fmt.Printf("\n%sStopped.%s\n", colorYellow, colorReset)
+		case err != nil:
 			fmt.Printf("\n%sError:%s %v\n", colorRed+colorBold, colorReset, err)
-			// Remove the last user message on error
-			if len(s.messages) > originalCount {
-				s.messages = s.messages[:originalCount]
-			}
-			continue
-		}
-
-		// Final render to ensure everything is properly formatted
-		// Only re-render if we have a response but didn't render during streaming
-		// (e.g., if markdownRenderer was nil during streaming but is now available)
-		if fullResponse != "" && markdownRenderer != nil {
-			// Check if we rendered during streaming (lastRenderedLines > 0 means we did)
-			if lastRenderedLines == 0 {
-				// We didn't render during streaming, so render now
-				fmt.Printf("\n%sAXON:%s\n", colorMagenta+colorBold, colorReset)
-				s.printFormattedMarkdown(fullResponse)
-			} else {
-				// We already rendered during streaming, just ensure newline at end
-				fmt.Println()
-			}
-		} else if fullResponse != "" {
-			// Fallback: just add newline
-			fmt.Println()
-		} else {
-			// Add newline after streaming response
-			fmt.Println()
 		}
 	}
 
 	return nil
 }
 
+// streamResponse sends messages to the model with tools enabled, rendering
+// the answer as markdown while it streams, and returns the full answer
+func (s *Session) streamResponse(messages []llm.Message) (string, error) {
+	if s.frontend {
+		return s.turn(s.turnContext(), messages, s.handle)
+	}
+	return s.renderTurn(func(ctx context.Context, handle func(Event)) (string, error) {
+		return s.turn(ctx, messages, handle)
+	})
+}
+
+// renderTurn runs a turn with the terminal as its frontend. Ctrl+C stops the
+// turn, or the command a tool runs in it, rather than axon.
+func (s *Session) renderTurn(run func(ctx context.Context, handle func(Event)) (string, error)) (string, error) {
+	ctx, stop := interrupt.NotifyContext(context.Background())
+	defer stop()
+	fmt.Printf("\n%sAXON is thinking...%s\n\n", colorYellow, colorReset)
+	out := newTerminalOutput()
+	answer, err := run(ctx, out.handle)
+	if err != nil {
+		return "", err
+	}
+	out.finish(s, answer)
+	return answer, nil
+}
+
 // handleCommand processes special commands starting with /
 func (s *Session) handleCommand(input string) bool {
 	parts := strings.Fields(input)
@@ -221,29 +233,68 @@ func (s *Session) handleCommand(input string) bool {
 
 	switch cmd {
 	case "/exit", "/quit", "/q":
-		fmt.Printf("\n%sGoodbye!%s\n", colorBlue+colorBold, colorReset)
+		fmt.Fprintf(s.out, "\n%sGoodbye!%s\n", colorBlue+colorBold, colorReset)
+		s.Close()
 		// Exit will trigger deferred cleanup in main()
 		os.Exit(0)
 		return true
 	case "/clear", "/reset":
-		// Clear conversation history (keep system message)
-		s.messages = []llm.Message{
-			{Role: "system", Content: llm.GetSystemPrompt()},
-		}
-		fmt.Printf("\n%sConversation history cleared.%s\n", colorGreen, colorReset)
+		s.Reset()
+		fmt.Fprintf(s.out, "\n%sConversation history cleared.%s\n", colorGreen, colorReset)
 		return true
 	case "/help", "/h":
 		s.printHelp()
 		return true
+	case "/reindex":
+		s.reindex()
+		return true
+	case "/find":
+		if len(args) == 0 {
+			fmt.Fprintf(s.out, "\n%sUsage:%s /find <question>\n", colorRed+colorBold, colorReset)
+			return true
+		}
+		s.findSemantic(strings.Join(args, " "))
+		return true
+	case "/fix":
+		if len(args) == 0 {
+			fmt.Fprintf(s.out, "\n%sUsage:%s /fix <command>, e.g. /fix go test ./pkg/indexer/...\n", colorRed+colorBold, colorReset)
+			return true
+		}
+		if err := s.Fix(strings.TrimSpace(strings.TrimPrefix(input, cmd)), 0); err != nil {
+			fmt.Fprintf(s.out, "\n%sFix incomplete:%s %v\n", colorRed+colorBold, colorReset, err)
+		}
+		return true
+	case "/commit":
+		if err := s.Commit(); err != nil {
+			fmt.Fprintf(s.out, "\n%sCommit failed:%s %v\n", colorRed+colorBold, colorReset, err)
+		}
+		return true
+	case "/checkpoints":
+		s.listCheckpoints()
+		return true
+	case "/diff":
+		id := ""
+		if len(args) > 0 {
+			id = args[0]
+		}
+		s.showCheckpointDiff(id)
+		return true
+	case "/rollback":
+		if len(args) == 0 {
+			fmt.Fprintf(s.out, "\n%sUsage:%s /rollback <checkpoint>  (see /checkpoints)\n", colorRed+colorBold, colorReset)
+			return true
+		}
+		s.rollbackCheckpoint(args[0])
+		return true
 	case "/file":
 		if len(args) == 0 {
-			fmt.Printf("\n%sUsage:%s /file <path>\n", colorRed+colorBold, colorReset)
+			fmt.Fprintf(s.out, "\n%sUsage:%s /file <path>\n", colorRed+colorBold, colorReset)
 			return true
 		}
 		filePath := args[0]
 		content, truncated, err := fsctx.ReadFile(s.projectRoot, filePath)
 		if err != nil {
-			fmt.Printf("\n%sError reading file:%s %v\n", colorRed+colorBold, colorReset, err)
+			fmt.Fprintf(s.out, "\n%sError reading file:%s %v\n", colorRed+colorBold, colorReset, err)
 			return true
 		}
 		lang := getLanguageFromExt(fsctx.GetFileExtension(filePath))
@@ -251,12 +302,12 @@ func (s *Session) handleCommand(input string) bool {
 		if truncated {
 			fileNote = " (truncated to 200KB)"
 		}
-		fmt.Printf("\n%sFile:%s %s%s\n", colorBlue+colorBold, colorReset, filePath, fileNote)
-		fmt.Printf("```%s\n%s\n```\n", lang, content)
+		fmt.Fprintf(s.out, "\n%sFile:%s %s%s\n", colorBlue+colorBold, colorReset, filePath, fileNote)
+		fmt.Fprintf(s.out, "```%s\n%s\n```\n", lang, content)
 		return true
 	case "/explain":
 		if len(args) == 0 {
-			fmt.Printf("\n%sUsage:%s /explain <path> [start:end]\n", colorRed+colorBold, colorReset)
+			fmt.Fprintf(s.out, "\n%sUsage:%s /explain <path> [start:end]\n", colorRed+colorBold, colorReset)
 			return true
 		}
 		filePath := args[0]
@@ -267,7 +318,7 @@ func (s *Session) handleCommand(input string) bool {
 			lineRange := args[1]
 			var startLine, endLine int
 			if _, err := fmt.Sscanf(lineRange, "%d:%d", &startLine, &endLine); err != nil {
-				fmt.Printf("\n%sInvalid range format:%s %s (expected start:end)\n", colorRed+colorBold, colorReset, lineRange)
+				fmt.Fprintf(s.out, "\n%sInvalid range format:%s %s (expected start:end)\n", colorRed+colorBold, colorReset, lineRange)
 				return true
 			}
 			content, err = fsctx.ReadFileRange(s.projectRoot, filePath, startLine, endLine)
@@ -275,11 +326,11 @@ func (s *Session) handleCommand(input string) bool {
 			var truncated bool
 			content, truncated, err = fsctx.ReadFile(s.projectRoot, filePath)
 			if truncated {
-				fmt.Printf("\n%sFile truncated to first 200KB%s\n", colorYellow, colorReset)
+				fmt.Fprintf(s.out, "\n%sFile truncated to first 200KB%s\n", colorYellow, colorReset)
 			}
 		}
 		if err != nil {
-			fmt.Printf("\n%sError reading file:%s %v\n", colorRed+colorBold, colorReset, err)
+			fmt.Fprintf(s.out, "\n%sError reading file:%s %v\n", colorRed+colorBold, colorReset, err)
 			return true
 		}
 		lang := getLanguageFromExt(fsctx.GetFileExtension(filePath))
@@ -291,11 +342,10 @@ func (s *Session) handleCommand(input string) bool {
 			Content: explainPrompt,
 		})
 
-		fmt.Printf("\n%sAXON is thinking...%s\n\n", colorYellow, colorReset)
-		ctx := context.Background()
-		response, err := s.client.Chat(ctx, s.messages)
+		fmt.Fprintf(s.out, "\n%sAXON is thinking...%s\n\n", colorYellow, colorReset)
+		response, err := s.client.Chat(s.turnContext(), s.messages)
 		if err != nil {
-			fmt.Printf("%sError:%s %v\n", colorRed+colorBold, colorReset, err)
+			fmt.Fprintf(s.out, "%sError:%s %v\n", colorRed+colorBold, colorReset, err)
 			s.messages = s.messages[:len(s.messages)-1]
 			return true
 		}
@@ -305,7 +355,11 @@ func (s *Session) handleCommand(input string) bool {
 			Content: response,
 		})
 
-		fmt.Printf("%sAXON:%s\n", colorMagenta+colorBold, colorReset)
+		if s.frontend {
+			s.emit(Event{Type: EventToken, Text: response})
+			return true
+		}
+		fmt.Fprintf(s.out, "%sAXON:%s\n", colorMagenta+colorBold, colorReset)
 		s.printFormattedMarkdown(response)
 		return true
 	default:
@@ -314,6 +368,32 @@ func (s *Session) handleCommand(input string) bool {
 	}
 }
 
+// reindex rebuilds the project index, reporting progress on a single status line.
+// Tools keep reading the previous index until the new one is swapped in.
+func (s *Session) reindex() {
+	if s.index == nil {
+		fmt.Printf("\n%sProject index not available.%s\n", colorRed+colorBold, colorReset)
+		return
+	}
+
+	var final indexer.IndexProgress
+	s.index.SetProgressFunc(func(p indexer.IndexProgress) {
+		if p.Done {
+			final = p
+			return
+		}
+		fmt.Printf("\r\033[K%sIndexing project... %s%s", colorYellow, p, colorReset)
+	})
+	defer s.index.SetProgressFunc(nil)
+
+	fmt.Printf("\n%sIndexing project...%s", colorYellow, colorReset)
+	if err := s.index.IndexProject(); err != nil {
+		fmt.Printf("\r\033[K%sError re-indexing project:%s %v\n", colorRed+colorBold, colorReset, err)
+		return
+	}
+	fmt.Printf("\r\033[K%sProject indexed: %s%s\n", colorGreen, final, colorReset)
+}
+
 // printWelcome prints the welcome message
 func (s *Session) printWelcome() {
 	fmt.Printf("%s╔════════════════════════════════════════════════════════════╗%s\n", colorBold+colorBlue, colorReset)
@@ -334,43 +414,24 @@ func (s *Session) printHelp() {
 	fmt.Println("   /file <path>       - Display a file's contents")
 	fmt.Println("   /explain <path>    - Explain code in a file")
 	fmt.Println("   /explain <path> <start:end> - Explain a specific line range")
+	fmt.Println("   /find <question>   - Semantic search, e.g. /find where do we validate coupons?")
+	fmt.Println("   /reindex           - Rebuild the project index")
+	fmt.Println("   /fix <command>     - Run a failing command, let AXON edit until it passes, e.g. /fix go test ./...")
+	fmt.Println("   /commit            - Write a commit message for the staged changes and commit")
+	fmt.Println("   /checkpoints       - List the checkpoints taken by /fix")
+	fmt.Println("   /diff [checkpoint] - Show changes since a checkpoint (default: the latest)")
+	fmt.Println("   /rollback <checkpoint> - Restore the files to a checkpoint")
 	fmt.Println("   /exit, /quit, /q   - Exit the chat")
+	fmt.Printf("\n%sEditing input:%s\n", colorBold+colorBlue, colorReset)
+	fmt.Println("   Up/Down            - Browse earlier messages, kept in " + lineedit.HistoryFile)
+	fmt.Println("   Ctrl+R             - Search earlier messages (Ctrl+G cancels)")
+	fmt.Println("   Tab                - Complete commands and project paths")
+	fmt.Println("   Ctrl+J, Alt+Enter  - Start a new line; pastes keep their lines")
+	fmt.Printf("   %s            - On a line of its own, starts or ends a multiline message\n", lineedit.BlockDelimiter)
 	fmt.Printf("\n%sYou can also just type questions naturally!%s\n", colorYellow, colorReset)
 	fmt.Println("   Example: \"How do I implement rate limiting in Laravel?\"")
 }
 
-// renderStreamingMarkdown renders markdown in real-time during streaming
-// It clears previous output and re-renders the accumulated markdown
-func (s *Session) renderStreamingMarkdown(renderer *glamour.TermRenderer, markdown string, lastLines *int) {
-	// Try to render the markdown
-	out, err := renderer.Render(markdown)
-	if err != nil {
-		// If rendering fails (e.g., incomplete markdown), skip this render
-		// We'll try again on the next chunk
-		return
-	}
-
-	// Count lines in the rendered output (including the header line with "AXON:")
-	renderedLines := strings.Count(out, "\n")
-	if renderedLines == 0 && out != "" {
-		renderedLines = 1
-	}
-
-	// Clear previous rendering (move up and clear)
-	if *lastLines > 0 {
-		// Move cursor up by number of rendered lines
-		fmt.Printf("\033[%dA", *lastLines)
-		// Clear from cursor to end of screen
-		fmt.Print("\033[J")
-	}
-
-	// Print the newly rendered markdown
-	fmt.Print(out)
-
-	// Update line count
-	*lastLines = renderedLines
-}
-
 // printFormattedMarkdown renders and prints markdown with syntax highlighting
 func (s *Session) printFormattedMarkdown(markdown string) {
 	// Use glamour to render markdown with terminal-friendly styling
@@ -381,20 +442,20 @@ func (s *Session) printFormattedMarkdown(markdown string) {
 	)
 	if err != nil {
 		// Fallback to basic printing if glamour fails
-		fmt.Print(markdown)
-		fmt.Println()
+		fmt.Fprint(s.out, markdown)
+		fmt.Fprintln(s.out)
 		return
 	}
 
 	out, err := r.Render(markdown)
 	if err != nil {
 		// Fallback to basic printing if rendering fails
-		fmt.Print(markdown)
-		fmt.Println()
+		fmt.Fprint(s.out, markdown)
+		fmt.Fprintln(s.out)
 		return
 	}
 
-	fmt.Print(out)
+	fmt.Fprint(s.out, out)
 }
 
 // printResponse prints the LLM response with formatting
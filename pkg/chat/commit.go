@@ -0,0 +1,163 @@
+package chat
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/axon/pkg/commitmsg"
+	"github.com/axon/pkg/git"
+	"github.com/axon/pkg/llm"
+)
+
+// maxCommitDiff is the number of bytes of staged patch read for a commit
+// message; commitmsg chunks whatever does not fit in one request
+const maxCommitDiff = 1 << 20
+
+// Commit writes a commit message for the staged changes and commits them
+// once the user accepts or edits it. Run by a frontend, the message goes to
+// the Approver as written: an editor cannot take over its screen, so it is
+// edited by declining and running git commit instead.
+func (s *Session) Commit() error {
+	if !s.frontend {
+		return CommitStaged(s.client, s.projectRoot, s.scanner)
+	}
+	fmt.Fprintf(s.out, "\n%s💭 Writing a commit message for the staged changes...%s\n", colorYellow, colorReset)
+	msg, err := GenerateCommitMessage(s.turnContext(), s.client, s.projectRoot)
+	if err != nil {
+		return err
+	}
+	ok, err := s.confirmAction("Commit the staged changes", msg.String())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		fmt.Fprintf(s.out, "%sCommit cancelled; the changes stay staged.%s\n", colorYellow, colorReset)
+		return nil
+	}
+	return gitCommit(s.projectRoot, msg, false, s.out)
+}
+
+// CommitStaged generates a conventional commit message for the staged
+// changes, shows it for approval, editing or regeneration, and runs git
+// commit. Declining is not an error.
+func CommitStaged(client *llm.Client, projectRoot string, input *bufio.Scanner) error {
+	for {
+		fmt.Printf("\n%s💭 Writing a commit message for the staged changes...%s\n", colorYellow, colorReset)
+		msg, err := GenerateCommitMessage(context.Background(), client, projectRoot)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("\n%sCommit message:%s\n", colorBlue+colorBold, colorReset)
+		for _, line := range strings.Split(strings.TrimRight(msg.String(), "\n"), "\n") {
+			if line == "" {
+				fmt.Println()
+				continue
+			}
+			fmt.Printf("   %s\n", line)
+		}
+		fmt.Printf("\n%sCommit? [y]es, [e]dit, [r]egenerate, [N]o:%s ", colorYellow+colorBold, colorReset)
+		if !input.Scan() {
+			return fmt.Errorf("failed to read confirmation input")
+		}
+		switch strings.TrimSpace(strings.ToLower(input.Text())) {
+		case "y", "yes":
+			return gitCommit(projectRoot, msg, false, os.Stdout)
+		case "e", "edit":
+			return gitCommit(projectRoot, msg, true, os.Stdout)
+		case "r", "regenerate":
+			continue
+		}
+		fmt.Printf("%sCommit cancelled; the changes stay staged.%s\n", colorYellow, colorReset)
+		return nil
+	}
+}
+
+// GenerateCommitMessage writes a conventional commit message for the staged
+// changes
+func GenerateCommitMessage(ctx context.Context, client *llm.Client, projectRoot string) (commitmsg.Message, error) {
+	repo, err := git.Open(projectRoot)
+	if err != nil {
+		return commitmsg.Message{}, err
+	}
+	diff, err := repo.Diff(git.DiffOptions{Staged: true, Limit: maxCommitDiff})
+	if err != nil {
+		return commitmsg.Message{}, err
+	}
+	if len(diff.Files) == 0 {
+		return commitmsg.Message{}, fmt.Errorf("nothing is staged; stage changes with git add first")
+	}
+
+	var stat strings.Builder
+	for _, f := range diff.Files {
+		path := f.Path
+		if f.OrigPath != "" {
+			path = f.OrigPath + " -> " + f.Path
+		}
+		if f.Binary {
+			fmt.Fprintf(&stat, "%s (binary)\n", path)
+		} else {
+			fmt.Fprintf(&stat, "%s (+%d -%d)\n", path, f.Additions, f.Deletions)
+		}
+	}
+	return commitmsg.Generate(ctx, client, strings.TrimRight(stat.String(), "\n"), git.SplitPatch(diff.Patch))
+}
+
+// gitCommit commits the staged changes with a message, opening the user's
+// editor on it first if edit is set, and writes what git prints to out
+func gitCommit(projectRoot string, msg commitmsg.Message, edit bool, out io.Writer) error {
+	file, err := os.CreateTemp("", "axon-commit-*.txt")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.WriteString(msg.String()); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	args := []string{"commit", "-F", file.Name()}
+	if edit {
+		args = append(args, "--edit")
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = projectRoot
+	cmd.Stdout, cmd.Stderr = out, out
+	if edit {
+		cmd.Stdin = os.Stdin
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git commit failed: %w", err)
+	}
+	return nil
+}
+
+// InstallCommitHook installs a prepare-commit-msg hook that fills in the
+// message of plain 'git commit' runs, and returns its path
+func InstallCommitHook(projectRoot string) (string, error) {
+	repo, err := git.Open(projectRoot)
+	if err != nil {
+		return "", err
+	}
+	path, err := repo.GitPath("hooks/" + commitmsg.HookName)
+	if err != nil {
+		return "", err
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+	return path, commitmsg.InstallHook(path, executable)
+}
@@ -0,0 +1,80 @@
+package chat
+
+import (
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/axon/pkg/fsctx"
+)
+
+// commands are the chat commands offered by tab completion
+var commands = []string{
+	"/help", "/clear", "/file", "/explain", "/find", "/reindex", "/fix", "/commit",
+	"/checkpoints", "/diff", "/rollback", "/exit",
+}
+
+// pathCommands take a path as their first argument
+var pathCommands = []string{"/file", "/explain"}
+
+// maxCompletions caps the candidates offered for a word
+const maxCompletions = 200
+
+// complete completes a command at the start of the input, or the project
+// path before the cursor
+func (s *Session) complete(head string) (int, []string) {
+	start := strings.LastIndexAny(head, " \t\n") + 1
+	word := head[start:]
+	if start == 0 && strings.HasPrefix(word, "/") {
+		var matches []string
+		for _, cmd := range commands {
+			if strings.HasPrefix(cmd, word) {
+				matches = append(matches, cmd)
+			}
+		}
+		return 0, matches
+	}
+	// An empty word is only completed where a path is expected
+	if word == "" {
+		fields := strings.Fields(head)
+		if len(fields) != 1 || !slices.Contains(pathCommands, fields[0]) {
+			return start, nil
+		}
+	}
+	return start, s.completePath(word)
+}
+
+// completePath lists the project's files and directories starting with a
+// path; directories end with a slash. Hidden entries are only listed when
+// the path's last element starts with a dot.
+func (s *Session) completePath(word string) []string {
+	dir, base := path.Split(word)
+	full, err := s.resolvePath(dir)
+	if err != nil {
+		return nil
+	}
+	entries, err := os.ReadDir(full)
+	if err != nil {
+		return nil
+	}
+	var matches []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, base) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".")) {
+			continue
+		}
+		candidate := dir + name
+		if entry.IsDir() {
+			candidate += "/"
+		}
+		if fsctx.ShouldIgnore(candidate, s.cfg) {
+			continue
+		}
+		matches = append(matches, candidate)
+		if len(matches) == maxCompletions {
+			break
+		}
+	}
+	return matches
+}
@@ -0,0 +1,40 @@
+package chat
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestComplete(t *testing.T) {
+	s, root := newTestSession(t, nil, nil, nil)
+	for _, dir := range []string{"pkg/chat", "pkg/checkpoint", "vendor/lib", ".github"} {
+		os.MkdirAll(filepath.Join(root, dir), 0o755)
+	}
+	os.WriteFile(filepath.Join(root, "main.go"), nil, 0o644)
+	os.WriteFile(filepath.Join(root, "pkg/chat/chat.go"), nil, 0o644)
+
+	tests := []struct {
+		head       string
+		start      int
+		candidates []string
+	}{
+		{"/ch", 0, []string{"/checkpoints"}},
+		{"/e", 0, []string{"/explain", "/exit"}},
+		{"explain ma", 8, []string{"main.go"}},
+		{"look at pkg/ch", 8, []string{"pkg/chat/", "pkg/checkpoint/"}},
+		{"pkg/chat/c", 0, []string{"pkg/chat/chat.go"}},
+		{"/file ", 6, []string{"main.go", "pkg/"}},
+		{"/file .g", 6, []string{".github/"}},
+		{"hello ", 6, nil},
+		{"v", 0, nil},
+		{"../", 0, nil},
+	}
+	for _, tt := range tests {
+		start, candidates := s.complete(tt.head)
+		if start != tt.start || !reflect.DeepEqual(candidates, tt.candidates) {
+			t.Errorf("complete(%q) = %d, %q; want %d, %q", tt.head, start, candidates, tt.start, tt.candidates)
+		}
+	}
+}
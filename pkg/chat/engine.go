@@ -0,0 +1,211 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/axon/pkg/llm"
+)
+
+// Event types. A turn reports tokens, tool calls and their results,
+// permission decisions and confirmations as they happen, then the tokens it
+// used if the server counts them, and ends with done or error.
+const (
+	EventToken        = "token"        // Text of the answer as it streams
+	EventToolCall     = "tool_call"    // A tool call is about to run
+	EventToolResult   = "tool_result"  // A tool call finished
+	EventPermission   = "permission"   // A permission rule allowed or denied a tool call
+	EventConfirmation = "confirmation" // A tool call waits for the Approver
+	EventNotice       = "notice"       // Progress or a warning worth showing
+	EventOutput       = "output"       // Text a chat command run by RunCommand prints
+	EventUsage        = "usage"        // Tokens used by the turn's requests
+	EventError        = "error"        // The turn failed
+	EventDone         = "done"         // The turn finished; Text holds the answer
+)
+
+// Decisions an Approver returns
+const (
+	DecisionYes    = "yes"
+	DecisionNo     = "no"
+	DecisionAlways = "always" // Yes, and add the confirmation's rules to .axon.local.yml
+)
+
+// Event is something that happened during a turn
+type Event struct {
+	Type         string                 `json:"type"`
+	Text         string                 `json:"text,omitempty"`
+	Tool         string                 `json:"tool,omitempty"`
+	Args         map[string]interface{} `json:"args,omitempty"`
+	Result       json.RawMessage        `json:"result,omitempty"` // The tool result, as JSON
+	Error        string                 `json:"error,omitempty"`
+	Decision     string                 `json:"decision,omitempty"` // allow or deny, for permission events
+	Rule         string                 `json:"rule,omitempty"`     // The rule that decided
+	Confirmation *Confirmation          `json:"confirmation,omitempty"`
+	Usage        *llm.Usage             `json:"usage,omitempty"`
+}
+
+// Confirmation is an action that waits for the user's decision
+type Confirmation struct {
+	ID          string   `json:"id"` // Unique within the session
+	Tool        string   `json:"tool,omitempty"`
+	Action      string   `json:"action"`
+	Description string   `json:"description"`
+	Rules       []string `json:"rules,omitempty"` // Allow rules DecisionAlways adds; without rules, always is not offered
+	Diff        string   `json:"diff,omitempty"`  // Unified diff of a file write
+}
+
+// Approver decides on actions that need the user's confirmation: tool calls
+// no permission rule decides, and rollbacks. Approve returns DecisionYes,
+// DecisionNo or DecisionAlways, and DecisionNo once ctx is done.
+type Approver interface {
+	Approve(ctx context.Context, c Confirmation) (string, error)
+}
+
+// ApproverFunc adapts a function to an Approver
+type ApproverFunc func(ctx context.Context, c Confirmation) (string, error)
+
+// Approve calls f
+func (f ApproverFunc) Approve(ctx context.Context, c Confirmation) (string, error) {
+	return f(ctx, c)
+}
+
+// SetApprover replaces the terminal prompt with another Approver
+func (s *Session) SetApprover(approver Approver) {
+	s.approver = approver
+}
+
+// Send answers a message: it is added to the conversation with the answer,
+// and everything that happens meanwhile is reported to handle. Frontends
+// other than the terminal drive the session through Send.
+func (s *Session) Send(ctx context.Context, input string, handle func(Event)) (string, error) {
+	s.refreshSystemPrompt()
+	s.messages = append(s.messages, llm.Message{Role: "user", Content: input})
+	originalCount := len(s.messages)
+
+	answer, err := s.turn(ctx, s.messages, handle)
+	if err != nil {
+		s.messages = s.messages[:originalCount-1]
+		return "", err
+	}
+	s.messages = append(s.messages, llm.Message{Role: "assistant", Content: answer})
+	return answer, nil
+}
+
+// runnableCommands are the chat commands RunCommand runs
+var runnableCommands = []string{"/file", "/explain", "/find", "/fix", "/commit", "/checkpoints", "/diff", "/rollback"}
+
+// IsCommand reports whether RunCommand runs input
+func IsCommand(input string) bool {
+	fields := strings.Fields(input)
+	return len(fields) > 0 && slices.Contains(runnableCommands, fields[0])
+}
+
+// RunCommand runs a chat command such as /fix or /diff for a frontend other
+// than the terminal. What the command prints is reported to handle as
+// output events, answers it asks the model for stream as in Send, and
+// confirmations go through the Approver. Cancelling ctx stops it.
+func (s *Session) RunCommand(ctx context.Context, input string, handle func(Event)) error {
+	if !IsCommand(input) {
+		return fmt.Errorf("unknown command: %s", input)
+	}
+	prevOut, prevHandle, prevCtx, prevFrontend := s.out, s.handle, s.ctx, s.frontend
+	s.out, s.handle, s.ctx, s.frontend = eventWriter(handle), handle, ctx, true
+	defer func() { s.out, s.handle, s.ctx, s.frontend = prevOut, prevHandle, prevCtx, prevFrontend }()
+
+	s.handleCommand(input)
+	return ctx.Err()
+}
+
+// eventWriter reports what is written to it as output events
+type eventWriter func(Event)
+
+func (w eventWriter) Write(p []byte) (int, error) {
+	w(Event{Type: EventOutput, Text: string(p)})
+	return len(p), nil
+}
+
+// turn sends messages to the model with tools enabled, reporting tokens,
+// tool calls and confirmations to handle, and returns the answer
+func (s *Session) turn(ctx context.Context, messages []llm.Message, handle func(Event)) (string, error) {
+	prevHandle, prevCtx := s.handle, s.ctx
+	s.handle, s.ctx = handle, ctx
+	defer func() { s.handle, s.ctx = prevHandle, prevCtx }()
+
+	executeTool := func(name string, args map[string]interface{}) (string, error) {
+		s.emit(Event{Type: EventToolCall, Tool: name, Args: args})
+		result, err := s.ExecuteTool(name, args)
+		event := Event{Type: EventToolResult, Tool: name}
+		if err != nil {
+			event.Error = err.Error()
+		} else if json.Valid([]byte(result)) {
+			event.Result = json.RawMessage(result)
+		} else {
+			event.Result, _ = json.Marshal(result)
+		}
+		s.emit(event)
+		return result, err
+	}
+	streamCallback := func(chunk string) error {
+		s.emit(Event{Type: EventToken, Text: chunk})
+		return nil
+	}
+
+	answer, usage, err := s.client.ChatWithToolsStream(ctx, messages, s.tools(), executeTool, streamCallback)
+	if usage.TotalTokens > 0 {
+		s.emit(Event{Type: EventUsage, Usage: &usage})
+	}
+	if err != nil {
+		s.emit(Event{Type: EventError, Error: err.Error()})
+		return "", err
+	}
+	s.emit(Event{Type: EventDone, Text: answer})
+	return answer, nil
+}
+
+// emit reports an event to the running turn's handler, if any
+func (s *Session) emit(e Event) {
+	if s.handle != nil {
+		s.handle(e)
+	}
+}
+
+// confirm announces a confirmation to the turn's handler and asks the
+// Approver; sessions without one refuse
+func (s *Session) confirm(c Confirmation) (string, error) {
+	s.confirmations++
+	c.ID = strconv.Itoa(s.confirmations)
+	s.emit(Event{Type: EventConfirmation, Tool: c.Tool, Confirmation: &c})
+	if s.approver == nil {
+		return DecisionNo, nil
+	}
+	return s.approver.Approve(s.turnContext(), c)
+}
+
+// turnContext is the context of the running turn. Tools derive theirs from
+// it, so that a front end stopping the turn also stops the tool it runs.
+func (s *Session) turnContext() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
+// Reset clears the conversation, keeping the system message
+func (s *Session) Reset() {
+	s.messages = []llm.Message{{Role: "system", Content: s.systemPrompt()}}
+}
+
+// History returns the user and assistant messages of the conversation
+func (s *Session) History() []llm.Message {
+	var history []llm.Message
+	for _, msg := range s.messages {
+		if msg.Role == "user" || msg.Role == "assistant" {
+			history = append(history, msg)
+		}
+	}
+	return history
+}
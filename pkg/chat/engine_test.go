@@ -0,0 +1,333 @@
+package chat
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/axon/pkg/indexer"
+	"github.com/axon/pkg/llm"
+	"github.com/axon/pkg/policy"
+	"github.com/axon/pkg/project"
+)
+
+// recordingApprover answers every confirmation with one decision and keeps
+// the confirmations it was asked
+type recordingApprover struct {
+	decision string
+	asked    []Confirmation
+}
+
+func (a *recordingApprover) Approve(ctx context.Context, c Confirmation) (string, error) {
+	a.asked = append(a.asked, c)
+	return a.decision, nil
+}
+
+// newTestSession creates a session without a model for a temporary project
+func newTestSession(t *testing.T, allow, deny []string, approver Approver) (*Session, string) {
+	root := t.TempDir()
+	cfg, err := project.LoadConfig(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Permissions = project.Permissions{Allow: allow, Deny: deny}
+	s := NewToolSession(root, cfg, nil)
+	s.unattended = false
+	s.approver = approver
+	t.Cleanup(s.Close)
+	return s, root
+}
+
+// auditEntries reads the audit log of a project
+func auditEntries(t *testing.T, root string) []policy.Entry {
+	f, err := os.Open(filepath.Join(root, ".axon", "audit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var entries []policy.Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry policy.Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestAuthorize(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		decision string // The approver's answer
+		asked    bool
+		created  bool
+		by       string
+		event    string // Permission decision reported, if any
+	}{
+		{"allowed by rule", "src/a.txt", DecisionNo, false, true, "rule", policy.Allow},
+		{"denied by rule", "secret/a.txt", DecisionYes, false, false, "rule", policy.Deny},
+		{"approved", "docs/a.txt", DecisionYes, true, true, "user", ""},
+		{"rejected", "docs/a.txt", DecisionNo, true, false, "user", ""},
+		{"always", "docs/a.txt", DecisionAlways, true, true, "always", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			approver := &recordingApprover{decision: tt.decision}
+			s, root := newTestSession(t, []string{"write: src/**"}, []string{"write: secret/**"}, approver)
+			var events []Event
+			s.handle = func(e Event) { events = append(events, e) }
+
+			result, err := s.ExecuteTool("create_file", map[string]interface{}{"path": tt.path, "content": "x"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (len(approver.asked) > 0) != tt.asked {
+				t.Errorf("approver asked %v, want %v", approver.asked, tt.asked)
+			}
+			if _, err := os.Stat(filepath.Join(root, tt.path)); (err == nil) != tt.created {
+				t.Errorf("file created = %v, want %v (result %s)", err == nil, tt.created, result)
+			}
+			if entries := auditEntries(t, root); len(entries) != 1 || entries[0].By != tt.by {
+				t.Errorf("audit log %+v, want one entry by %s", entries, tt.by)
+			}
+
+			var types []string
+			for _, e := range events {
+				types = append(types, e.Type)
+				if e.Type == EventPermission && e.Decision != tt.event {
+					t.Errorf("permission event %+v, want %s", e, tt.event)
+				}
+			}
+			want := EventPermission
+			if tt.asked {
+				want = EventConfirmation
+			}
+			if strings.Join(types, ",") != want {
+				t.Errorf("events %v, want %s", types, want)
+			}
+		})
+	}
+}
+
+func TestAuthorizeAlwaysAddsRule(t *testing.T) {
+	approver := &recordingApprover{decision: DecisionAlways}
+	s, root := newTestSession(t, nil, nil, approver)
+
+	if _, err := s.ExecuteTool("create_file", map[string]interface{}{"path": "docs/a.md", "content": "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(approver.asked) != 1 || len(approver.asked[0].Rules) == 0 {
+		t.Fatalf("expected one confirmation offering rules, got %+v", approver.asked)
+	}
+	local, err := os.ReadFile(filepath.Join(root, project.LocalConfigFile))
+	if err != nil || !strings.Contains(string(local), approver.asked[0].Rules[0]) {
+		t.Errorf("rule %q not saved: %s, %v", approver.asked[0].Rules[0], local, err)
+	}
+
+	// The saved rule decides similar calls without asking
+	if _, err := s.ExecuteTool("create_file", map[string]interface{}{"path": "docs/b.md", "content": "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(approver.asked) != 1 {
+		t.Errorf("asked again: %+v", approver.asked)
+	}
+}
+
+func TestConfirmationsWithoutApprover(t *testing.T) {
+	s, root := newTestSession(t, nil, nil, nil)
+	result, err := s.ExecuteTool("create_file", map[string]interface{}{"path": "a.txt", "content": "x"})
+	if err != nil || !strings.Contains(result, "cancelled") {
+		t.Errorf("expected the call to be refused, got %s, %v", result, err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "a.txt")); err == nil {
+		t.Error("file created without approval")
+	}
+
+	// Tool sessions for MCP clients leave confirmations to the client
+	s.unattended = true
+	if _, err := s.ExecuteTool("create_file", map[string]interface{}{"path": "a.txt", "content": "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if entries := auditEntries(t, root); len(entries) != 2 || entries[1].By != "client" {
+		t.Errorf("unexpected audit log %+v", entries)
+	}
+}
+
+func TestSend(t *testing.T) {
+	// The model asks for create_file, then answers once the result is in
+	model := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req llm.ChatCompletionRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "text/event-stream")
+		if req.Messages[len(req.Messages)-1].Role == "tool" {
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"Done.\"},\"finish_reason\":\"stop\"}]}\n\ndata: [DONE]\n\n")
+			return
+		}
+		fmt.Fprint(w, `data: {"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"create_file","arguments":"{\"path\":\"a.txt\",\"content\":\"x\"}"}}]},"finish_reason":"tool_calls"}]}`+"\n\ndata: [DONE]\n\n")
+	}))
+	defer model.Close()
+
+	root := t.TempDir()
+	cfg, err := project.LoadConfig(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewSession(llm.NewClient(model.URL, "test", 0), root, cfg, false, nil)
+	defer s.Close()
+	approver := &recordingApprover{decision: DecisionYes}
+	s.SetApprover(approver)
+
+	var types []string
+	answer, err := s.Send(context.Background(), "Create a.txt", func(e Event) {
+		types = append(types, e.Type)
+		if e.Type == EventConfirmation && (e.Confirmation == nil || e.Confirmation.ID != "1" || e.Tool != "create_file" || !strings.Contains(e.Confirmation.Diff, "+++ b/a.txt\n@@ -0,0 +1 @@\n+x\n")) {
+			t.Errorf("unexpected confirmation %+v", e)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if answer != "Done." {
+		t.Errorf("answer = %q", answer)
+	}
+	if got, want := strings.Join(types, ","), "tool_call,confirmation,tool_result,token,done"; got != want {
+		t.Errorf("events = %s, want %s", got, want)
+	}
+	if len(approver.asked) != 1 {
+		t.Errorf("approver asked %d times", len(approver.asked))
+	}
+	if history := s.History(); len(history) != 2 || history[0].Content != "Create a.txt" || history[1].Content != "Done." {
+		t.Errorf("unexpected history %+v", history)
+	}
+}
+
+func TestToolsStopWithTheTurn(t *testing.T) {
+	s, _ := newTestSession(t, []string{"execute: sleep *"}, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	s.ctx = ctx
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	result, err := s.ExecuteTool("execute", map[string]interface{}{"command": "sleep 30"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("execute ran for %s after the turn stopped", elapsed)
+	}
+	if !strings.Contains(result, `"cancelled":true`) {
+		t.Errorf("result = %s", result)
+	}
+}
+
+func TestExecuteTimeout(t *testing.T) {
+	approver := &recordingApprover{decision: DecisionNo}
+	s, _ := newTestSession(t, nil, nil, approver)
+
+	for _, tt := range []struct {
+		timeout interface{}
+		want    string
+	}{{nil, "Timeout: 120s"}, {float64(0), "Timeout: 120s"}, {float64(60), "Timeout: 60s"}, {float64(99999), "Timeout: 1800s"}} {
+		args := map[string]interface{}{"command": "true"}
+		if tt.timeout != nil {
+			args["timeout"] = tt.timeout
+		}
+		approver.asked = nil
+		s.ExecuteTool("execute", args)
+		if len(approver.asked) != 1 || !strings.Contains(approver.asked[0].Description, tt.want) {
+			t.Errorf("timeout %v: asked %+v, want %q", tt.timeout, approver.asked, tt.want)
+		}
+	}
+}
+
+func TestFixAsksTheApproverToContinue(t *testing.T) {
+	model := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"No idea.\"},\"finish_reason\":\"stop\"}]}\n\ndata: [DONE]\n\n")
+	}))
+	defer model.Close()
+
+	root := t.TempDir()
+	cfg, err := project.LoadConfig(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewSession(llm.NewClient(model.URL, "test", 0), root, cfg, false, nil)
+	defer s.Close()
+	approver := &recordingApprover{decision: DecisionNo}
+	s.SetApprover(approver)
+
+	err = s.Fix("false", 3)
+	if err == nil || !strings.Contains(err.Error(), "stopped by the user") {
+		t.Errorf("Fix = %v", err)
+	}
+	if len(approver.asked) != 1 || approver.asked[0].Action != "Continue fixing" {
+		t.Errorf("approver asked %+v", approver.asked)
+	}
+}
+
+func TestFixStopsWithTheTurn(t *testing.T) {
+	s, _ := newTestSession(t, nil, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	s.ctx = ctx
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	if err := s.Fix("sleep 30", 1); !errors.Is(err, errFixStopped) {
+		t.Errorf("Fix = %v, want %v", err, errFixStopped)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("the check ran for %s after the turn stopped", elapsed)
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	s, root := newTestSession(t, nil, nil, nil)
+	os.WriteFile(filepath.Join(root, "a.txt"), []byte("hello from a file"), 0o644)
+
+	var output strings.Builder
+	err := s.RunCommand(context.Background(), "/file a.txt", func(e Event) {
+		if e.Type == EventOutput {
+			output.WriteString(e.Text)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output.String(), "hello from a file") {
+		t.Errorf("output = %q", output.String())
+	}
+	if IsCommand("/exit") || IsCommand("fix the tests") || !IsCommand("/rollback abc") {
+		t.Error("IsCommand accepts the wrong input")
+	}
+}
+
+func TestSQLQueryNeedsAConfiguredDatabase(t *testing.T) {
+	approver := &recordingApprover{decision: "yes"}
+	s, root := newTestSession(t, nil, nil, approver)
+	os.WriteFile(filepath.Join(root, "app.db"), []byte("SQLite format 3\x00"), 0o644)
+	s.index = indexer.NewIndex(root, s.cfg)
+	if err := s.index.IndexProject(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := s.toolSQLQuery(map[string]interface{}{"query": "SELECT 1"})
+	if err == nil || !strings.Contains(err.Error(), "database.sqlite") || !strings.Contains(err.Error(), "app.db") {
+		t.Errorf("err = %v, want the detected database as a candidate to configure", err)
+	}
+	if len(approver.asked) != 0 {
+		t.Error("asked to query a database that is not configured")
+	}
+}
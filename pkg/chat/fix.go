@@ -0,0 +1,359 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/axon/pkg/checkpoint"
+	"github.com/axon/pkg/execx"
+	"github.com/axon/pkg/fsctx"
+	"github.com/axon/pkg/interrupt"
+	"github.com/axon/pkg/llm"
+	"github.com/axon/pkg/testrun"
+)
+
+// Limits on what each fix round gives the model
+const (
+	maxFixOutput    = 6000      // Tail of the command output
+	maxFixFiles     = 5         // Files referenced by the output
+	maxFixFileBytes = 20 * 1024 // Per attached file
+)
+
+// checkResult is the outcome of one run of the command /fix is driving
+type checkResult struct {
+	exitCode int
+	output   string
+	timedOut bool
+}
+
+// errFixStopped is returned when the user stops /fix, which keeps the
+// checkpoints taken so far
+var errFixStopped = errors.New("stopped by the user; the command still fails")
+
+// passed reports whether the command succeeded
+func (r checkResult) passed() bool {
+	return r.exitCode == 0 && !r.timedOut
+}
+
+// Fix runs a failing command (a build, a linter, a test suite) and lets the
+// model edit the code until it passes. Each round gives the model the
+// command output and the files it references, checkpoints the edits and
+// re-runs the command. It stops when the command passes, after
+// maxIterations rounds (0 uses fix.max_iterations), or when the user
+// declines to continue or stops it with Ctrl+C.
+func (s *Session) Fix(command string, maxIterations int) error {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return fmt.Errorf("a command to fix is required, e.g. /fix go test ./...")
+	}
+	if maxIterations <= 0 {
+		maxIterations = s.cfg.Fix.MaxIterations
+	}
+	if maxIterations <= 0 {
+		maxIterations = 5
+	}
+
+	fmt.Fprintf(s.out, "\n%sRunning:%s %s %s(Ctrl+C to stop)%s\n", colorBlue+colorBold, colorReset, command, colorYellow, colorReset)
+	result, err := s.runCheck(command)
+	if errors.Is(err, context.Canceled) {
+		return errFixStopped
+	}
+	if err != nil {
+		return err
+	}
+	if result.passed() {
+		fmt.Fprintf(s.out, "%s✅ The command already passes; nothing to fix.%s\n", colorGreen, colorReset)
+		return nil
+	}
+	s.printCheckResult(result)
+
+	store, err := s.checkpointStore()
+	if err != nil {
+		fmt.Fprintf(s.out, "%sWarning:%s checkpoints are disabled: %v\n", colorYellow+colorBold, colorReset, err)
+	}
+	var start, last checkpoint.Checkpoint
+	if store != nil {
+		if start, err = store.Create("Before /fix: " + command); err != nil {
+			fmt.Fprintf(s.out, "%sWarning:%s checkpoints are disabled: %v\n", colorYellow+colorBold, colorReset, err)
+			store = nil
+		}
+		last = start
+	}
+
+	var changed []string // Files changed by earlier rounds
+	for round := 1; round <= maxIterations; round++ {
+		fmt.Fprintf(s.out, "\n%s🔧 Fix round %d/%d%s\n", colorMagenta+colorBold, round, maxIterations, colorReset)
+
+		messages := []llm.Message{
+			{Role: "system", Content: s.systemPrompt()},
+			{Role: "user", Content: s.fixPrompt(command, result, changed)},
+		}
+		if _, err := s.streamResponse(messages); err != nil {
+			s.printFixSummary(store, start)
+			if errors.Is(err, context.Canceled) {
+				return errFixStopped
+			}
+			return fmt.Errorf("fix round %d failed: %w", round, err)
+		}
+
+		if store != nil {
+			cp, err := store.Create(fmt.Sprintf("/fix round %d: %s", round, command))
+			if err != nil {
+				fmt.Fprintf(s.out, "%sWarning:%s failed to create checkpoint: %v\n", colorYellow+colorBold, colorReset, err)
+			} else {
+				s.printRoundChanges(store, last, cp)
+				if files, err := store.ChangedFiles(start.ID, cp.ID); err == nil {
+					changed = files
+				}
+				last = cp
+			}
+		}
+
+		fmt.Fprintf(s.out, "\n%sRunning:%s %s %s(Ctrl+C to stop)%s\n", colorBlue+colorBold, colorReset, command, colorYellow, colorReset)
+		if result, err = s.runCheck(command); err != nil {
+			s.printFixSummary(store, start)
+			if errors.Is(err, context.Canceled) {
+				return errFixStopped
+			}
+			return err
+		}
+		if result.passed() {
+			fmt.Fprintf(s.out, "%s✅ The command passes after %d round(s).%s\n", colorGreen+colorBold, round, colorReset)
+			s.printFixSummary(store, start)
+			return nil
+		}
+		s.printCheckResult(result)
+
+		if round < maxIterations {
+			if ok, err := s.confirmAction("Continue fixing", fmt.Sprintf("Round %d of %d: %s still fails", round, maxIterations, command)); err != nil || !ok {
+				s.printFixSummary(store, start)
+				return errFixStopped
+			}
+		}
+	}
+
+	s.printFixSummary(store, start)
+	return fmt.Errorf("the command still fails after %d round(s)", maxIterations)
+}
+
+// runCheck runs the command /fix is driving in the project root, with the
+// sandbox, environment and output limits of execute. A zero timeout stops
+// it after the longest time execute allows. Ctrl+C or the end of the turn
+// stops it, and runCheck returns context.Canceled.
+func (s *Session) runCheck(command string) (checkResult, error) {
+	opts := s.execOptions()
+	opts.Timeout = time.Duration(s.cfg.Fix.Timeout) * time.Second
+	if opts.Timeout <= 0 {
+		opts.Timeout = maxExecuteTimeout * time.Second
+	}
+	ctx, stop := interrupt.NotifyContext(s.turnContext())
+	defer stop()
+	run, err := execx.Run(ctx, command, opts)
+	if err != nil {
+		return checkResult{}, fmt.Errorf("failed to run %q: %w", command, err)
+	}
+	if run.Cancelled {
+		return checkResult{}, context.Canceled
+	}
+	return checkResult{output: run.Output, exitCode: run.ExitCode, timedOut: run.TimedOut}, nil
+}
+
+// printCheckResult shows the tail of a failing command's output
+func (s *Session) printCheckResult(result checkResult) {
+	if result.timedOut {
+		fmt.Fprintf(s.out, "%s❌ Timed out after %ds%s\n", colorRed+colorBold, s.cfg.Fix.Timeout, colorReset)
+	} else {
+		fmt.Fprintf(s.out, "%s❌ Failed with exit code %d%s\n", colorRed+colorBold, result.exitCode, colorReset)
+	}
+	lines := strings.Split(strings.TrimRight(result.output, "\n"), "\n")
+	if len(lines) > 20 {
+		fmt.Fprintf(s.out, "   ... (%d more lines)\n", len(lines)-20)
+		lines = lines[len(lines)-20:]
+	}
+	for _, line := range lines {
+		fmt.Fprintf(s.out, "   %s\n", line)
+	}
+}
+
+// fixPrompt asks the model to fix a failing command, attaching its output and
+// the project files the output references
+func (s *Session) fixPrompt(command string, result checkResult, changed []string) string {
+	var b strings.Builder
+	if result.timedOut {
+		fmt.Fprintf(&b, "The command `%s` timed out after %d seconds.\n", command, s.cfg.Fix.Timeout)
+	} else {
+		fmt.Fprintf(&b, "The command `%s` fails with exit code %d.\n", command, result.exitCode)
+	}
+	if len(changed) > 0 {
+		fmt.Fprintf(&b, "Your earlier edits changed %s, but the command still fails.\n", strings.Join(changed, ", "))
+	}
+
+	output := strings.TrimSpace(result.output)
+	if len(output) > maxFixOutput {
+		output = "... (truncated)\n" + output[len(output)-maxFixOutput:]
+	}
+	fmt.Fprintf(&b, "\nOutput:\n```\n%s\n```\n", output)
+
+	files := testrun.FileReferences(result.output, s.projectRoot)
+	for _, file := range changed {
+		if !slices.Contains(files, file) {
+			files = append(files, file)
+		}
+	}
+	if len(files) > maxFixFiles {
+		files = files[:maxFixFiles]
+	}
+	for _, file := range files {
+		content, _, err := fsctx.ReadFile(s.projectRoot, file)
+		if err != nil {
+			continue
+		}
+		note := ""
+		if len(content) > maxFixFileBytes {
+			content = content[:maxFixFileBytes]
+			note = " (truncated; use read_file_lines for the rest)"
+		}
+		lang := getLanguageFromExt(fsctx.GetFileExtension(file))
+		fmt.Fprintf(&b, "\nFile: %s%s\n```%s\n%s\n```\n", file, note, lang, content)
+	}
+
+	b.WriteString("\nFix the code so the command passes. Read any other files you need, then make the smallest correct change with the write tools (prefer 'string_replace'). " +
+		"Do not run the command yourself: it is re-run automatically after your edits. " +
+		"Do not weaken or delete tests to make them pass unless the test itself is wrong. " +
+		"Finish with a one-paragraph summary of what you changed and why.")
+	return b.String()
+}
+
+// printRoundChanges shows the files a fix round changed
+func (s *Session) printRoundChanges(store *checkpoint.Store, from, to checkpoint.Checkpoint) {
+	stat, err := store.Diff(from.ID, to.ID, true)
+	if err != nil {
+		return
+	}
+	if stat == "" {
+		fmt.Fprintf(s.out, "\n%sNo files were changed in this round.%s\n", colorYellow, colorReset)
+		return
+	}
+	fmt.Fprintf(s.out, "\n%sChanges (checkpoint %s):%s\n", colorBlue+colorBold, to.ID, colorReset)
+	for _, line := range strings.Split(stat, "\n") {
+		fmt.Fprintf(s.out, "   %s\n", line)
+	}
+}
+
+// printFixSummary tells the user how to review or undo a /fix run
+func (s *Session) printFixSummary(store *checkpoint.Store, start checkpoint.Checkpoint) {
+	if store == nil || start.ID == "" {
+		return
+	}
+	fmt.Fprintf(s.out, "\n%sCheckpoint before /fix:%s %s\n", colorBlue+colorBold, colorReset, start.ID)
+	fmt.Fprintf(s.out, "   Review the changes with /diff %s, undo them with /rollback %s, or list checkpoints with /checkpoints.\n", start.ID, start.ID)
+}
+
+// checkpointStore opens the project's checkpoint repository on first use
+func (s *Session) checkpointStore() (*checkpoint.Store, error) {
+	if s.checkpoints == nil {
+		store, err := checkpoint.Open(s.projectRoot, s.cfg.Context.Ignore)
+		if err != nil {
+			return nil, err
+		}
+		s.checkpoints = store
+	}
+	return s.checkpoints, nil
+}
+
+// listCheckpoints prints the most recent checkpoints
+func (s *Session) listCheckpoints() {
+	store, err := s.checkpointStore()
+	if err != nil {
+		fmt.Fprintf(s.out, "\n%sError:%s %v\n", colorRed+colorBold, colorReset, err)
+		return
+	}
+	checkpoints, err := store.List(20)
+	if err != nil {
+		fmt.Fprintf(s.out, "\n%sError:%s %v\n", colorRed+colorBold, colorReset, err)
+		return
+	}
+	if len(checkpoints) == 0 {
+		fmt.Fprintf(s.out, "\n%sNo checkpoints yet. /fix creates one before each round.%s\n", colorYellow, colorReset)
+		return
+	}
+	fmt.Fprintf(s.out, "\n%sCheckpoints (newest first):%s\n", colorBlue+colorBold, colorReset)
+	for _, cp := range checkpoints {
+		fmt.Fprintf(s.out, "   %s  %s  %s\n", cp.ID, cp.Time.Format("2006-01-02 15:04:05"), cp.Message)
+	}
+}
+
+// showCheckpointDiff prints the changes from a checkpoint (the latest by
+// default) to the working tree
+func (s *Session) showCheckpointDiff(id string) {
+	store, err := s.checkpointStore()
+	if err != nil {
+		fmt.Fprintf(s.out, "\n%sError:%s %v\n", colorRed+colorBold, colorReset, err)
+		return
+	}
+	if id == "" {
+		checkpoints, err := store.List(1)
+		if err != nil || len(checkpoints) == 0 {
+			fmt.Fprintf(s.out, "\n%sNo checkpoints yet.%s\n", colorYellow, colorReset)
+			return
+		}
+		id = checkpoints[0].ID
+	}
+	diff, err := store.Diff(id, "", false)
+	if err != nil {
+		fmt.Fprintf(s.out, "\n%sError:%s %v\n", colorRed+colorBold, colorReset, err)
+		return
+	}
+	if diff == "" {
+		fmt.Fprintf(s.out, "\n%sNo changes since checkpoint %s.%s\n", colorGreen, id, colorReset)
+		return
+	}
+	fmt.Fprintf(s.out, "\n%sChanges since checkpoint %s:%s\n```diff\n%s\n```\n", colorBlue+colorBold, id, colorReset, diff)
+}
+
+// rollbackCheckpoint restores the working tree to a checkpoint after
+// confirmation
+func (s *Session) rollbackCheckpoint(id string) {
+	store, err := s.checkpointStore()
+	if err != nil {
+		fmt.Fprintf(s.out, "\n%sError:%s %v\n", colorRed+colorBold, colorReset, err)
+		return
+	}
+	files, err := store.ChangedFiles(id, "")
+	if err != nil {
+		fmt.Fprintf(s.out, "\n%sError:%s %v\n", colorRed+colorBold, colorReset, err)
+		return
+	}
+	if len(files) == 0 {
+		fmt.Fprintf(s.out, "\n%sNo changes since checkpoint %s.%s\n", colorGreen, id, colorReset)
+		return
+	}
+
+	confirmed, err := s.confirmAction("Roll back to checkpoint "+id, fmt.Sprintf("Files restored or removed (%d): %s", len(files), strings.Join(files, ", ")))
+	if err != nil || !confirmed {
+		fmt.Fprintf(s.out, "\n%sRollback cancelled.%s\n", colorYellow, colorReset)
+		return
+	}
+
+	before, restored, err := store.Restore(id)
+	if err != nil {
+		fmt.Fprintf(s.out, "\n%sError:%s %v\n", colorRed+colorBold, colorReset, err)
+		return
+	}
+	fmt.Fprintf(s.out, "\n%sRolled back %d file(s) to checkpoint %s.%s\n", colorGreen+colorBold, len(restored), id, colorReset)
+	fmt.Fprintf(s.out, "   The previous state was saved as checkpoint %s (/rollback %s to undo).\n", before.ID, before.ID)
+	switch {
+	case s.index == nil:
+	case s.frontend:
+		// The frontend shows the index state; progress lines would garble it
+		if err := s.index.IndexProject(); err != nil {
+			fmt.Fprintf(s.out, "%sError re-indexing project:%s %v\n", colorRed+colorBold, colorReset, err)
+		}
+	default:
+		s.reindex()
+	}
+}
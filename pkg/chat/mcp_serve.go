@@ -0,0 +1,63 @@
+package chat
+
+import (
+	"context"
+	"io"
+	"slices"
+
+	"github.com/axon/pkg/indexer"
+	"github.com/axon/pkg/llm"
+	"github.com/axon/pkg/mcp"
+	"github.com/axon/pkg/project"
+)
+
+// mcpWriteTools are offered only with --allow-writes
+var mcpWriteTools = []string{
+	"write_file", "create_file", "update_file", "string_replace", "create_directory", "move_file", "copy_file", "delete_file",
+}
+
+// mcpDefinitions maps tool names offered over MCP to the built-in tool whose
+// definition they share
+var mcpDefinitions = map[string]string{
+	"find_symbol_references": "find_references",
+}
+
+// mcpInstructions tells the client's model what the server is for
+const mcpInstructions = "axon indexes this project: use search_symbols, get_file_symbols and find_symbol_references to navigate code and get_tree_list or get_project_stats for an overview before reading files. Paths are relative to the project root and cannot leave it."
+
+// ServeMCP offers the project's tools to an MCP client over stdio until r
+// ends or ctx is cancelled. Write tools are offered only when allowWrites is
+// set; deny rules still apply to them and the client confirms the rest.
+func ServeMCP(ctx context.Context, projectRoot string, cfg *project.Config, projectIndex *indexer.Index, allowWrites bool, r io.Reader, w io.Writer) error {
+	s := NewToolSession(projectRoot, cfg, projectIndex)
+	defer s.Close()
+
+	names := readOnlyTools
+	if allowWrites {
+		names = append(append([]string(nil), readOnlyTools...), mcpWriteTools...)
+	}
+	definitions := make(map[string]llm.ToolFunction)
+	for _, tool := range llm.GetAvailableTools() {
+		definitions[tool.Function.Name] = tool.Function
+	}
+
+	server := mcp.NewServer("axon", mcp.Version, mcpInstructions)
+	for _, name := range names {
+		definition, ok := definitions[name]
+		if builtin := mcpDefinitions[name]; builtin != "" {
+			definition, ok = definitions[builtin]
+		}
+		if !ok {
+			continue
+		}
+		schema, _ := definition.Parameters.(map[string]interface{})
+		tool := mcp.Tool{Name: name, Description: definition.Description, InputSchema: schema}
+		if !slices.Contains(mcpWriteTools, name) {
+			tool.Annotations = &mcp.ToolAnnotations{ReadOnlyHint: true}
+		}
+		server.AddTool(tool, func(ctx context.Context, args map[string]interface{}) (string, error) {
+			return s.ExecuteTool(name, args)
+		})
+	}
+	return server.Serve(ctx, r, w)
+}
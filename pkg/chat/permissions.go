@@ -0,0 +1,121 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/axon/pkg/logger"
+	"github.com/axon/pkg/policy"
+	"github.com/axon/pkg/project"
+	"github.com/aymanbagabas/go-udiff"
+)
+
+// newPolicy builds the permission policy from the config, warning about
+// rules it cannot parse
+func newPolicy(cfg *project.Config) *policy.Policy {
+	p, err := policy.New(cfg.Permissions.Allow, cfg.Permissions.Deny, cfg.Permissions.Ask)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s⚠️  Ignoring permission rules: %v%s\n", colorYellow, err, colorReset)
+	}
+	return p
+}
+
+// maxDiff is the largest diff a confirmation carries
+const maxDiff = 64 * 1024
+
+// authorize decides whether a tool call may run: permission rules allow or
+// deny it outright, otherwise the Approver is asked, with the option to
+// always allow similar calls. Unattended sessions leave that question to
+// their MCP client. Every decision goes to the audit log. It returns the
+// tool result to send back when the call must not run, or "" to proceed.
+func (s *Session) authorize(req policy.Request, action, description string) (string, error) {
+	return s.authorizeEdit(req, action, description, "")
+}
+
+// authorizeEdit authorizes a file write, showing its diff to the Approver
+func (s *Session) authorizeEdit(req policy.Request, action, description, diff string) (string, error) {
+	// Rules match project-relative paths
+	for i, path := range req.Paths {
+		if fullPath, err := s.resolvePath(path); err == nil {
+			if rel, err := filepath.Rel(s.projectRoot, fullPath); err == nil {
+				req.Paths[i] = filepath.ToSlash(rel)
+			}
+		}
+	}
+
+	entry := policy.Entry{Tool: req.Tool, Command: req.Command, Paths: req.Paths, Target: req.Target}
+	decision := s.policy.Evaluate(req)
+	switch decision.Action {
+	case policy.Allow:
+		s.emit(Event{Type: EventPermission, Tool: req.Tool, Text: action, Decision: policy.Allow, Rule: decision.Rule})
+		entry.Decision, entry.By, entry.Rule = policy.Allow, "rule", decision.Rule
+		s.recordDecision(entry)
+		return "", nil
+	case policy.Deny:
+		s.emit(Event{Type: EventPermission, Tool: req.Tool, Text: action, Decision: policy.Deny, Rule: decision.Rule})
+		entry.Decision, entry.By, entry.Rule = policy.Deny, "rule", decision.Rule
+		s.recordDecision(entry)
+		result, _ := json.Marshal(map[string]interface{}{
+			"denied":  true,
+			"message": fmt.Sprintf("Denied by the permission rule %q. Do not retry it another way; tell the user what you wanted to do.", decision.Rule),
+		})
+		return string(result), nil
+	}
+
+	if s.unattended {
+		entry.Decision, entry.By = policy.Allow, "client"
+		s.recordDecision(entry)
+		return "", nil
+	}
+
+	suggested := policy.SuggestRule(req)
+	confirmation := Confirmation{Tool: req.Tool, Action: action, Description: description, Diff: diff}
+	for _, rule := range suggested {
+		confirmation.Rules = append(confirmation.Rules, rule.String())
+	}
+	answer, err := s.confirm(confirmation)
+	if err != nil {
+		return "", fmt.Errorf("failed to get confirmation: %w", err)
+	}
+	switch answer {
+	case DecisionAlways:
+		var added []string
+		for _, rule := range suggested {
+			s.policy.Add(rule)
+			if err := project.AddLocalPermission(s.projectRoot, policy.Allow, rule.String()); err != nil {
+				s.emit(Event{Type: EventNotice, Text: fmt.Sprintf("⚠️  The rule applies to this session only: %v", err)})
+			}
+			added = append(added, rule.String())
+		}
+		entry.Decision, entry.By, entry.Rule = policy.Allow, "always", strings.Join(added, ", ")
+		s.recordDecision(entry)
+		return "", nil
+	case DecisionYes:
+		entry.Decision, entry.By = policy.Allow, "user"
+		s.recordDecision(entry)
+		return "", nil
+	}
+	entry.Decision, entry.By = policy.Deny, "user"
+	s.recordDecision(entry)
+	return `{"cancelled": true, "message": "User cancelled the operation"}`, nil
+}
+
+// editDiff returns the unified diff of a file's content, cut short if it is
+// too large to review
+func editDiff(path, oldContent, newContent string) string {
+	diff := udiff.Unified("a/"+path, "b/"+path, oldContent, newContent)
+	if len(diff) > maxDiff {
+		diff = diff[:strings.LastIndex(diff[:maxDiff], "\n")+1] + "... (diff truncated)\n"
+	}
+	return diff
+}
+
+// recordDecision appends a permission decision to the audit log
+func (s *Session) recordDecision(entry policy.Entry) {
+	if err := s.audit.Record(entry); err != nil {
+		logger.Logf("⚠️  %v\n", err)
+	}
+}
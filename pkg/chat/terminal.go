@@ -0,0 +1,167 @@
+package chat
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/axon/pkg/policy"
+	"github.com/axon/pkg/project"
+	"github.com/charmbracelet/glamour"
+)
+
+// terminalApprover asks for confirmations on the terminal
+type terminalApprover struct {
+	scanner *bufio.Scanner
+}
+
+// Approve shows the action and reads the answer; an empty answer means no.
+// "Always" is offered when the confirmation carries rules to add.
+func (a terminalApprover) Approve(ctx context.Context, c Confirmation) (string, error) {
+	printConfirmation(c.Action, c.Description)
+	if len(c.Rules) > 0 {
+		var rules []string
+		for _, rule := range c.Rules {
+			rules = append(rules, fmt.Sprintf("%q", rule))
+		}
+		fmt.Printf("%sAlways:%s adds %s to %s\n", colorCyan, colorReset, strings.Join(rules, ", "), project.LocalConfigFile)
+		fmt.Printf("%sDo you want to proceed? [y]es, [N]o, [a]lways:%s ", colorYellow+colorBold, colorReset)
+	} else {
+		fmt.Printf("%sDo you want to proceed? [y/N]:%s ", colorYellow+colorBold, colorReset)
+	}
+
+	if !a.scanner.Scan() {
+		return "", fmt.Errorf("failed to read confirmation input")
+	}
+	switch strings.TrimSpace(strings.ToLower(a.scanner.Text())) {
+	case "y", "yes":
+		return DecisionYes, nil
+	case "a", "always":
+		if len(c.Rules) > 0 {
+			return DecisionAlways, nil
+		}
+	}
+	return DecisionNo, nil
+}
+
+// printConfirmation shows what an action about to run will do
+func printConfirmation(action, description string) {
+	fmt.Printf("\n%s⚠️  WRITE OPERATION REQUESTED%s\n", colorYellow+colorBold, colorReset)
+	fmt.Printf("%sAction:%s %s\n", colorCyan, colorReset, action)
+	fmt.Printf("%sDetails:%s %s\n", colorCyan, colorReset, description)
+}
+
+// terminalOutput prints the events of a turn, rendering the answer as
+// markdown while it streams
+type terminalOutput struct {
+	renderer          *glamour.TermRenderer // nil if glamour is unavailable
+	buffer            strings.Builder       // The answer so far
+	lastRenderedLines int
+	lastRenderLength  int
+	renderCounter     int
+	started           bool // The answer header has been printed
+}
+
+func newTerminalOutput() *terminalOutput {
+	renderer, err := glamour.NewTermRenderer(
+		glamour.WithAutoStyle(),
+		glamour.WithWordWrap(80),
+	)
+	if err != nil {
+		// Fallback to printing the chunks as they are
+		renderer = nil
+	}
+	return &terminalOutput{renderer: renderer}
+}
+
+// handle prints one event. Confirmations are printed by the terminal
+// Approver, and errors by the caller.
+func (t *terminalOutput) handle(e Event) {
+	switch e.Type {
+	case EventToken:
+		t.token(e.Text)
+	case EventPermission:
+		if e.Decision == policy.Allow {
+			fmt.Printf("\n%s✓ %s%s (allowed by %q)\n", colorGreen, e.Text, colorReset, e.Rule)
+		} else {
+			fmt.Printf("\n%s⛔ %s denied by %q%s\n", colorRed, e.Text, e.Rule, colorReset)
+		}
+	case EventNotice:
+		fmt.Printf("%s%s%s\n", colorYellow, e.Text, colorReset)
+	}
+}
+
+// token adds a chunk of the answer and re-renders it when it is worth it
+func (t *terminalOutput) token(chunk string) {
+	if !t.started {
+		fmt.Printf("\n%sAXON:%s\n", colorMagenta+colorBold, colorReset)
+		t.started = true
+	}
+
+	// Accumulate chunk
+	t.buffer.WriteString(chunk)
+	currentBuffer := t.buffer.String()
+	currentLength := len(currentBuffer)
+
+	// Render if:
+	// 1. Every 3 chunks (throttling for performance)
+	// 2. Code block opens or closes (```)
+	// 3. Newline character (likely end of sentence/paragraph)
+	// 4. Significant length change (new paragraph/section)
+	t.renderCounter++
+	shouldRender := t.renderCounter%3 == 0 ||
+		strings.Contains(chunk, "```") ||
+		strings.Contains(chunk, "\n") ||
+		currentLength-t.lastRenderLength > 50
+
+	if t.renderer != nil && shouldRender {
+		renderStreamingMarkdown(t.renderer, currentBuffer, &t.lastRenderedLines)
+		t.lastRenderLength = currentLength
+	} else if t.renderer == nil {
+		// Fallback: just print the chunk
+		fmt.Print(chunk)
+	}
+}
+
+// finish renders the complete answer if streaming did not
+func (t *terminalOutput) finish(s *Session, answer string) {
+	if answer != "" && t.renderer != nil && t.lastRenderedLines == 0 {
+		fmt.Printf("\n%sAXON:%s\n", colorMagenta+colorBold, colorReset)
+		s.printFormattedMarkdown(answer)
+		return
+	}
+	fmt.Println()
+}
+
+// renderStreamingMarkdown renders markdown in place of its previous
+// rendering
+func renderStreamingMarkdown(renderer *glamour.TermRenderer, markdown string, lastLines *int) {
+	// Try to render the markdown
+	out, err := renderer.Render(markdown)
+	if err != nil {
+		// If rendering fails (e.g., incomplete markdown), skip this render
+		// We'll try again on the next chunk
+		return
+	}
+
+	// Count lines in the rendered output (including the header line with "AXON:")
+	renderedLines := strings.Count(out, "\n")
+	if renderedLines == 0 && out != "" {
+		renderedLines = 1
+	}
+
+	// Clear previous rendering (move up and clear)
+	if *lastLines > 0 {
+		// Move cursor up by number of rendered lines
+		fmt.Printf("\033[%dA", *lastLines)
+		// Clear from cursor to end of screen
+		fmt.Print("\033[J")
+	}
+
+	// Print the newly rendered markdown
+	fmt.Print(out)
+
+	// Update line count
+	*lastLines = renderedLines
+}
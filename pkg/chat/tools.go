@@ -10,6 +10,7 @@ import (
 
 	"github.com/axon/pkg/fsctx"
 	"github.com/axon/pkg/logger"
+	"github.com/axon/pkg/policy"
 )
 
 // resolvePath safely resolves a path relative to project root and validates it's within the root
@@ -17,24 +18,46 @@ func (s *Session) resolvePath(path string) (string, error) {
 	return fsctx.ResolvePath(s.projectRoot, path)
 }
 
-// confirmAction asks the user to confirm an action interactively
-func (s *Session) confirmAction(action, description string) (bool, error) {
-	fmt.Printf("\n%s⚠️  WRITE OPERATION REQUESTED%s\n", colorYellow+colorBold, colorReset)
-	fmt.Printf("%sAction:%s %s\n", colorCyan, colorReset, action)
-	fmt.Printf("%sDetails:%s %s\n", colorCyan, colorReset, description)
-	fmt.Printf("%sDo you want to proceed? [Y,n]:%s ", colorYellow+colorBold, colorReset)
+// intArg reads an optional integer argument that may arrive as a JSON number or a string
+func intArg(args map[string]interface{}, name string, defaultValue int) (int, error) {
+	switch v := args[name].(type) {
+	case float64:
+		return int(v), nil
+	case string:
+		if v == "" {
+			return defaultValue, nil
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		return n, nil
+	default:
+		return defaultValue, nil
+	}
+}
 
-	// Use the session's scanner for input
-	if !s.scanner.Scan() {
-		return false, fmt.Errorf("failed to read confirmation input")
+// boolArg reads an optional boolean argument that may arrive as a JSON bool or a string
+func boolArg(args map[string]interface{}, name string) bool {
+	switch v := args[name].(type) {
+	case bool:
+		return v
+	case string:
+		b, _ := strconv.ParseBool(strings.TrimSpace(v))
+		return b
+	default:
+		return false
 	}
+}
 
-	response := strings.TrimSpace(strings.ToLower(s.scanner.Text()))
-	// Empty input (just Enter) defaults to "yes"
-	if response == "" {
-		return true, nil
+// confirmAction asks the Approver to confirm an action. Tool calls go
+// through authorize instead, which applies the permission rules first.
+func (s *Session) confirmAction(action, description string) (bool, error) {
+	answer, err := s.confirm(Confirmation{Action: action, Description: description})
+	if err != nil {
+		return false, err
 	}
-	return response == "yes" || response == "y", nil
+	return answer == DecisionYes || answer == DecisionAlways, nil
 }
 
 // getUnknownToolError returns a helpful error message for unknown tools
@@ -60,7 +83,7 @@ func (s *Session) getUnknownToolError(toolName string) error {
 	}
 
 	// Generic error with list of common tools
-	return fmt.Errorf("unknown tool '%s'. Available tools include: read_file, list_directory, grep, read_file_lines, write_file, create_file, update_file, string_replace, create_directory, get_tree_list, get_file_symbols, delete_file, delete_directory, move_file, copy_file, find_files, find_files_by_extension, search_symbols, get_project_stats, get_file_info, find_dependencies, git_status, git_diff, find_symbol_references, execute. Note: There is no 'cd' tool - use 'list_directory' with a 'path' parameter to list directory contents.", toolName)
+	return fmt.Errorf("unknown tool '%s'. Available tools include: read_file, list_directory, grep, read_file_lines, write_file, create_file, update_file, string_replace, create_directory, get_tree_list, get_file_symbols, delete_file, delete_directory, move_file, copy_file, find_files, find_files_by_extension, search_symbols, search_code, semantic_search, get_project_stats, get_file_info, find_dependencies, get_imports, get_dependents, impact_of_change, list_routes, find_route_handler, describe_model, describe_schema, sql_query, run_tests, start_process, process_output, stop_process, list_processes, http_request, git_status, git_diff, git_log, git_blame, git_show, git_branches, find_references, find_implementations, call_hierarchy, goto_definition, hover, diagnostics, rename_symbol, execute. Note: There is no 'cd' tool - use 'list_directory' with a 'path' parameter to list directory contents.", toolName)
 }
 
 // ExecuteTool executes a tool call and returns the result
@@ -109,21 +132,80 @@ func (s *Session) ExecuteTool(name string, args map[string]interface{}) (string,
 		result, err = s.toolFindFilesByExtension(args)
 	case "search_symbols":
 		result, err = s.toolSearchSymbols(args)
+	case "search_code":
+		result, err = s.toolSearchCode(args)
+	case "semantic_search":
+		result, err = s.toolSemanticSearch(args)
 	case "get_project_stats":
 		result, err = s.toolGetProjectStats(args)
 	case "get_file_info":
 		result, err = s.toolGetFileInfo(args)
 	case "find_dependencies":
 		result, err = s.toolFindDependencies(args)
+	case "get_imports":
+		result, err = s.toolGetImports(args)
+	case "get_dependents":
+		result, err = s.toolGetDependents(args)
+	case "impact_of_change":
+		result, err = s.toolImpactOfChange(args)
+	case "list_routes":
+		result, err = s.toolListRoutes(args)
+	case "find_route_handler":
+		result, err = s.toolFindRouteHandler(args)
+	case "describe_model":
+		result, err = s.toolDescribeModel(args)
+	case "describe_schema":
+		result, err = s.toolDescribeSchema(args)
+	case "sql_query":
+		result, err = s.toolSQLQuery(args)
+	case "run_tests":
+		result, err = s.toolRunTests(args)
+	case "start_process":
+		result, err = s.toolStartProcess(args)
+	case "process_output":
+		result, err = s.toolProcessOutput(args)
+	case "stop_process":
+		result, err = s.toolStopProcess(args)
+	case "list_processes":
+		result, err = s.toolListProcesses(args)
+	case "http_request":
+		result, err = s.toolHTTPRequest(args)
 	case "git_status":
 		result, err = s.toolGitStatus(args)
 	case "git_diff":
 		result, err = s.toolGitDiff(args)
+	case "git_log":
+		result, err = s.toolGitLog(args)
+	case "git_blame":
+		result, err = s.toolGitBlame(args)
+	case "git_show":
+		result, err = s.toolGitShow(args)
+	case "git_branches":
+		result, err = s.toolGitBranches(args)
+	case "find_references":
+		result, err = s.toolFindReferences(args)
 	case "find_symbol_references":
-		result, err = s.toolFindSymbolReferences(args)
+		// Older name of find_references, kept for saved conversations
+		result, err = s.toolFindReferences(args)
+	case "find_implementations":
+		result, err = s.toolFindImplementations(args)
+	case "call_hierarchy":
+		result, err = s.toolCallHierarchy(args)
+	case "goto_definition":
+		result, err = s.toolGotoDefinition(args)
+	case "hover":
+		result, err = s.toolHover(args)
+	case "diagnostics":
+		result, err = s.toolDiagnostics(args)
+	case "rename_symbol":
+		result, err = s.toolRenameSymbol(args)
 	case "execute":
 		result, err = s.toolExecute(args)
 	default:
+		if tool, ok := s.mcp.Lookup(name); ok {
+			result, err = s.toolMCP(tool, args)
+			break
+		}
 		// Provide helpful error message with suggestions for common mistakes
 		err = s.getUnknownToolError(name)
 	}
@@ -236,28 +318,37 @@ func (s *Session) toolGrep(args map[string]interface{}) (string, error) {
 		searchPath = resolved
 	}
 
-	// Use ripgrep if available, otherwise grep
+	// Use ripgrep if available, otherwise grep, otherwise search in-process
 	var cmd *exec.Cmd
 
 	if _, err := exec.LookPath("rg"); err == nil {
 		cmd = exec.Command("rg", "-n", "--color", "never", pattern, searchPath)
-	} else {
+	} else if _, err := exec.LookPath("grep"); err == nil {
 		cmd = exec.Command("grep", "-rn", "--color=never", pattern, searchPath)
 	}
 
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		exitError, ok := err.(*exec.ExitError)
-		if !ok || exitError.ExitCode() != 1 {
-			return "", fmt.Errorf("search command failed: %w", err)
+	var matches []string
+	if cmd != nil {
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			exitError, ok := err.(*exec.ExitError)
+			if !ok || exitError.ExitCode() != 1 {
+				return "", fmt.Errorf("search command failed: %w", err)
+			}
+			// Exit code 1 means no matches - that's OK
+			output = []byte{}
 		}
-		// Exit code 1 means no matches - that's OK
-		output = []byte{}
-	}
 
-	matches := strings.Split(strings.TrimSpace(string(output)), "\n")
-	if len(matches) == 1 && matches[0] == "" {
-		matches = []string{}
+		matches = strings.Split(strings.TrimSpace(string(output)), "\n")
+		if len(matches) == 1 && matches[0] == "" {
+			matches = []string{}
+		}
+	} else {
+		var err error
+		matches, err = s.grepInProcess(pattern, searchPath)
+		if err != nil {
+			return "", fmt.Errorf("search failed: %w", err)
+		}
 	}
 
 	result := map[string]interface{}{
@@ -339,8 +430,10 @@ func (s *Session) toolWriteFile(args map[string]interface{}) (string, error) {
 	}
 
 	fileExists := false
+	oldContent := ""
 	if info, err := os.Stat(fullPath); err == nil && !info.IsDir() {
 		fileExists = true
+		oldContent, _, _ = fsctx.ReadFile(s.projectRoot, path)
 	}
 
 	action := "Create new file"
@@ -351,12 +444,8 @@ func (s *Session) toolWriteFile(args map[string]interface{}) (string, error) {
 	}
 
 	// Require confirmation
-	confirmed, err := s.confirmAction(action, description)
-	if err != nil {
-		return "", fmt.Errorf("failed to get confirmation: %w", err)
-	}
-	if !confirmed {
-		return `{"cancelled": true, "message": "User cancelled the operation"}`, nil
+	if refusal, err := s.authorizeEdit(policy.Request{Tool: "write_file", Paths: []string{path}}, action, description, editDiff(path, oldContent, content)); err != nil || refusal != "" {
+		return refusal, err
 	}
 
 	err = fsctx.WriteFile(s.projectRoot, path, content, s.cfg)
@@ -369,6 +458,7 @@ func (s *Session) toolWriteFile(args map[string]interface{}) (string, error) {
 		"success": true,
 		"message": "File written successfully",
 	}
+	s.attachDiagnostics(result, path, content)
 
 	jsonResult, _ := json.Marshal(result)
 	return string(jsonResult), nil
@@ -399,12 +489,8 @@ func (s *Session) toolCreateFile(args map[string]interface{}) (string, error) {
 	description := fmt.Sprintf("File: %s\nSize: ~%d bytes", path, len(content))
 
 	// Require confirmation
-	confirmed, err := s.confirmAction(action, description)
-	if err != nil {
-		return "", fmt.Errorf("failed to get confirmation: %w", err)
-	}
-	if !confirmed {
-		return `{"cancelled": true, "message": "User cancelled the operation"}`, nil
+	if refusal, err := s.authorizeEdit(policy.Request{Tool: "create_file", Paths: []string{path}}, action, description, editDiff(path, "", content)); err != nil || refusal != "" {
+		return refusal, err
 	}
 
 	err = fsctx.WriteFile(s.projectRoot, path, content, s.cfg)
@@ -417,6 +503,7 @@ func (s *Session) toolCreateFile(args map[string]interface{}) (string, error) {
 		"success": true,
 		"message": "File created successfully",
 	}
+	s.attachDiagnostics(result, path, content)
 
 	jsonResult, _ := json.Marshal(result)
 	return string(jsonResult), nil
@@ -442,17 +529,14 @@ func (s *Session) toolUpdateFile(args map[string]interface{}) (string, error) {
 	if _, err := os.Stat(fullPath); os.IsNotExist(err) {
 		return "", fmt.Errorf("file does not exist: %s", path)
 	}
+	oldContent, _, _ := fsctx.ReadFile(s.projectRoot, path)
 
 	action := "Update file"
 	description := fmt.Sprintf("File: %s\nNew size: ~%d bytes", path, len(content))
 
 	// Require confirmation
-	confirmed, err := s.confirmAction(action, description)
-	if err != nil {
-		return "", fmt.Errorf("failed to get confirmation: %w", err)
-	}
-	if !confirmed {
-		return `{"cancelled": true, "message": "User cancelled the operation"}`, nil
+	if refusal, err := s.authorizeEdit(policy.Request{Tool: "update_file", Paths: []string{path}}, action, description, editDiff(path, oldContent, content)); err != nil || refusal != "" {
+		return refusal, err
 	}
 
 	err = fsctx.WriteFile(s.projectRoot, path, content, s.cfg)
@@ -465,6 +549,7 @@ func (s *Session) toolUpdateFile(args map[string]interface{}) (string, error) {
 		"success": true,
 		"message": "File updated successfully",
 	}
+	s.attachDiagnostics(result, path, content)
 
 	jsonResult, _ := json.Marshal(result)
 	return string(jsonResult), nil
@@ -504,18 +589,13 @@ func (s *Session) toolStringReplace(args map[string]interface{}) (string, error)
 	action := "Replace string in file"
 	description := fmt.Sprintf("File: %s\nPattern occurrences: %d\nReplacing: %q\nWith: %q", path, count, oldStr, newStr)
 
+	newContent := strings.ReplaceAll(content, oldStr, newStr)
+
 	// Require confirmation
-	confirmed, err := s.confirmAction(action, description)
-	if err != nil {
-		return "", fmt.Errorf("failed to get confirmation: %w", err)
-	}
-	if !confirmed {
-		return `{"cancelled": true, "message": "User cancelled the operation"}`, nil
+	if refusal, err := s.authorizeEdit(policy.Request{Tool: "string_replace", Paths: []string{path}}, action, description, editDiff(path, content, newContent)); err != nil || refusal != "" {
+		return refusal, err
 	}
 
-	// Replace
-	newContent := strings.ReplaceAll(content, oldStr, newStr)
-
 	err = fsctx.WriteFile(s.projectRoot, path, newContent, s.cfg)
 	if err != nil {
 		return "", fmt.Errorf("failed to write file: %w", err)
@@ -527,6 +607,7 @@ func (s *Session) toolStringReplace(args map[string]interface{}) (string, error)
 		"replacements": count,
 		"message":      fmt.Sprintf("Replaced %d occurrence(s)", count),
 	}
+	s.attachDiagnostics(result, path, newContent)
 
 	jsonResult, _ := json.Marshal(result)
 	return string(jsonResult), nil
@@ -556,12 +637,8 @@ func (s *Session) toolCreateDirectory(args map[string]interface{}) (string, erro
 	description := fmt.Sprintf("Path: %s", path)
 
 	// Require confirmation
-	confirmed, err := s.confirmAction(action, description)
-	if err != nil {
-		return "", fmt.Errorf("failed to get confirmation: %w", err)
-	}
-	if !confirmed {
-		return `{"cancelled": true, "message": "User cancelled the operation"}`, nil
+	if refusal, err := s.authorize(policy.Request{Tool: "create_directory", Paths: []string{path}}, action, description); err != nil || refusal != "" {
+		return refusal, err
 	}
 
 	err = os.MkdirAll(fullPath, 0755)
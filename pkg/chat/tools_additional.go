@@ -8,8 +8,12 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/axon/pkg/execx"
 	"github.com/axon/pkg/fsctx"
+	"github.com/axon/pkg/interrupt"
+	"github.com/axon/pkg/policy"
 )
 
 // toolDeleteFile deletes a file
@@ -31,12 +35,8 @@ func (s *Session) toolDeleteFile(args map[string]interface{}) (string, error) {
 	action := "Delete file"
 	description := fmt.Sprintf("File: %s\n⚠️  This action cannot be undone!", path)
 
-	confirmed, err := s.confirmAction(action, description)
-	if err != nil {
-		return "", fmt.Errorf("failed to get confirmation: %w", err)
-	}
-	if !confirmed {
-		return `{"cancelled": true, "message": "User cancelled the operation"}`, nil
+	if refusal, err := s.authorize(policy.Request{Tool: "delete_file", Paths: []string{path}}, action, description); err != nil || refusal != "" {
+		return refusal, err
 	}
 
 	err = os.Remove(fullPath)
@@ -77,12 +77,8 @@ func (s *Session) toolDeleteDirectory(args map[string]interface{}) (string, erro
 	action := "Delete directory"
 	description := fmt.Sprintf("Directory: %s\n⚠️  This will delete the directory and ALL its contents! This action cannot be undone!", path)
 
-	confirmed, err := s.confirmAction(action, description)
-	if err != nil {
-		return "", fmt.Errorf("failed to get confirmation: %w", err)
-	}
-	if !confirmed {
-		return `{"cancelled": true, "message": "User cancelled the operation"}`, nil
+	if refusal, err := s.authorize(policy.Request{Tool: "delete_directory", Paths: []string{path}}, action, description); err != nil || refusal != "" {
+		return refusal, err
 	}
 
 	err = os.RemoveAll(fullPath)
@@ -137,12 +133,8 @@ func (s *Session) toolMoveFile(args map[string]interface{}) (string, error) {
 		description += "\n⚠️  Destination file exists and will be overwritten!"
 	}
 
-	confirmed, err := s.confirmAction(action, description)
-	if err != nil {
-		return "", fmt.Errorf("failed to get confirmation: %w", err)
-	}
-	if !confirmed {
-		return `{"cancelled": true, "message": "User cancelled the operation"}`, nil
+	if refusal, err := s.authorize(policy.Request{Tool: "move_file", Paths: []string{source, destination}}, action, description); err != nil || refusal != "" {
+		return refusal, err
 	}
 
 	parentDir := filepath.Dir(destPath)
@@ -203,12 +195,8 @@ func (s *Session) toolCopyFile(args map[string]interface{}) (string, error) {
 		description += "\n⚠️  Destination file exists and will be overwritten!"
 	}
 
-	confirmed, err := s.confirmAction(action, description)
-	if err != nil {
-		return "", fmt.Errorf("failed to get confirmation: %w", err)
-	}
-	if !confirmed {
-		return `{"cancelled": true, "message": "User cancelled the operation"}`, nil
+	if refusal, err := s.authorize(policy.Request{Tool: "copy_file", Paths: []string{destination}}, action, description); err != nil || refusal != "" {
+		return refusal, err
 	}
 
 	data, err := os.ReadFile(sourcePath)
@@ -571,108 +559,8 @@ func (s *Session) toolFindDependencies(args map[string]interface{}) (string, err
 	return string(jsonResult), nil
 }
 
-// toolGitStatus returns git repository status
-func (s *Session) toolGitStatus(args map[string]interface{}) (string, error) {
-	gitDir := filepath.Join(s.projectRoot, ".git")
-	if _, err := os.Stat(gitDir); os.IsNotExist(err) {
-		return "", fmt.Errorf("not a git repository")
-	}
-
-	cmd := exec.Command("git", "-C", s.projectRoot, "status", "--porcelain")
-	output, err := cmd.Output()
-	if err != nil {
-		return "", fmt.Errorf("failed to get git status: %w", err)
-	}
-
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	if len(lines) == 1 && lines[0] == "" {
-		lines = []string{}
-	}
-
-	var modified, added, deleted, untracked []string
-
-	for _, line := range lines {
-		if len(line) < 3 {
-			continue
-		}
-		status := line[:2]
-		file := strings.TrimSpace(line[2:])
-
-		if status[0] == '?' {
-			untracked = append(untracked, file)
-		} else {
-			if strings.Contains(status, "M") || strings.Contains(status, "m") {
-				modified = append(modified, file)
-			}
-			if strings.Contains(status, "A") || strings.Contains(status, "a") {
-				added = append(added, file)
-			}
-			if strings.Contains(status, "D") || strings.Contains(status, "d") {
-				deleted = append(deleted, file)
-			}
-		}
-	}
-
-	result := map[string]interface{}{
-		"modified":  modified,
-		"added":     added,
-		"deleted":   deleted,
-		"untracked": untracked,
-		"total":     len(modified) + len(added) + len(deleted) + len(untracked),
-	}
-
-	jsonResult, _ := json.Marshal(result)
-	return string(jsonResult), nil
-}
-
-// toolGitDiff returns git diff for a file or directory
-func (s *Session) toolGitDiff(args map[string]interface{}) (string, error) {
-	gitDir := filepath.Join(s.projectRoot, ".git")
-	if _, err := os.Stat(gitDir); os.IsNotExist(err) {
-		return "", fmt.Errorf("not a git repository")
-	}
-
-	path := ""
-	if p, ok := args["path"].(string); ok {
-		path = p
-	}
-
-	cmd := exec.Command("git", "-C", s.projectRoot, "diff", path)
-	output, err := cmd.Output()
-	if err != nil {
-		return "", fmt.Errorf("failed to get git diff: %w", err)
-	}
-
-	diff := strings.TrimSpace(string(output))
-	if diff == "" {
-		diff = "No changes"
-	}
-
-	result := map[string]interface{}{
-		"path": path,
-		"diff": diff,
-	}
-
-	jsonResult, _ := json.Marshal(result)
-	return string(jsonResult), nil
-}
-
-// toolFindSymbolReferences finds all references to a symbol
-func (s *Session) toolFindSymbolReferences(args map[string]interface{}) (string, error) {
-	symbol, ok := args["symbol"].(string)
-	if !ok || symbol == "" {
-		return "", fmt.Errorf("symbol argument is required")
-	}
-
-	searchPath := s.projectRoot
-	if p, ok := args["path"].(string); ok && p != "" {
-		resolved, err := s.resolvePath(p)
-		if err != nil {
-			return "", fmt.Errorf("invalid path: %w", err)
-		}
-		searchPath = resolved
-	}
-
+// grepSymbolReferences finds whole-word occurrences of a symbol with rg or grep
+func (s *Session) grepSymbolReferences(symbol, searchPath string) ([]map[string]interface{}, error) {
 	var cmd *exec.Cmd
 	if _, err := exec.LookPath("rg"); err == nil {
 		cmd = exec.Command("rg", "-n", "--color", "never", "-w", symbol, searchPath)
@@ -684,7 +572,7 @@ func (s *Session) toolFindSymbolReferences(args map[string]interface{}) (string,
 	if err != nil {
 		exitError, ok := err.(*exec.ExitError)
 		if !ok || exitError.ExitCode() != 1 {
-			return "", fmt.Errorf("search command failed: %w", err)
+			return nil, fmt.Errorf("search command failed: %w", err)
 		}
 		output = []byte{}
 	}
@@ -696,40 +584,58 @@ func (s *Session) toolFindSymbolReferences(args map[string]interface{}) (string,
 
 	var results []map[string]interface{}
 	for _, match := range matches {
-		parts := strings.SplitN(match, ":", 2)
-		if len(parts) == 2 {
-			filePath := parts[0]
-			rest := parts[1]
-
-			lineParts := strings.SplitN(rest, ":", 2)
-			if len(lineParts) == 2 {
-				lineNum, _ := strconv.Atoi(lineParts[0])
-				content := lineParts[1]
+		if r := s.parseGrepMatch(match); r != nil {
+			results = append(results, r)
+		}
+	}
+	return results, nil
+}
 
-				relPath, _ := filepath.Rel(s.projectRoot, filePath)
-				normalizedPath := strings.ReplaceAll(relPath, "\\", "/")
+// parseGrepMatch converts a "path:line:content" match into a result with a project-relative path
+func (s *Session) parseGrepMatch(match string) map[string]interface{} {
+	parts := strings.SplitN(match, ":", 2)
+	if len(parts) != 2 {
+		return nil
+	}
+	filePath := parts[0]
+	rest := parts[1]
 
-				results = append(results, map[string]interface{}{
-					"file":    normalizedPath,
-					"line":    lineNum,
-					"content": strings.TrimSpace(content),
-				})
-			}
-		}
+	lineParts := strings.SplitN(rest, ":", 2)
+	if len(lineParts) != 2 {
+		return nil
 	}
+	lineNum, _ := strconv.Atoi(lineParts[0])
+	content := lineParts[1]
 
-	result := map[string]interface{}{
-		"symbol":  symbol,
-		"path":    searchPath,
-		"matches": results,
-		"count":   len(results),
+	relPath, _ := filepath.Rel(s.projectRoot, filePath)
+	normalizedPath := strings.ReplaceAll(relPath, "\\", "/")
+
+	return map[string]interface{}{
+		"file":    normalizedPath,
+		"line":    lineNum,
+		"content": strings.TrimSpace(content),
 	}
+}
 
-	jsonResult, _ := json.Marshal(result)
-	return string(jsonResult), nil
+// execOptions returns the execute settings of the config: output cap,
+// environment and sandbox, with the project root writable
+func (s *Session) execOptions() execx.Options {
+	return execx.Options{
+		Dir:       s.projectRoot,
+		MaxOutput: s.cfg.Execute.MaxOutput,
+		Env:       s.cfg.Execute.Env,
+		Sandbox:   s.cfg.Execute.Sandbox,
+		Network:   s.cfg.Execute.Network,
+		Writable:  append([]string{s.projectRoot}, s.cfg.Execute.Writable...),
+	}
 }
 
-// toolExecute executes a shell command
+// maxExecuteTimeout caps the timeout the model can ask for, in seconds
+const maxExecuteTimeout = 1800
+
+// toolExecute executes a shell command in the project root. The command is
+// killed with everything it started when the timeout expires or the user
+// presses Ctrl+C, so a hung command cannot freeze the chat.
 func (s *Session) toolExecute(args map[string]interface{}) (string, error) {
 	command, ok := args["command"].(string)
 	if !ok || command == "" {
@@ -741,44 +647,67 @@ func (s *Session) toolExecute(args map[string]interface{}) (string, error) {
 		description = desc
 	}
 
-	action := "Execute shell command"
-	confirmDesc := fmt.Sprintf("Command: %s\n⚠️  This will execute a shell command with your user permissions!", command)
-	if description != command {
-		confirmDesc = fmt.Sprintf("Description: %s\nCommand: %s\n⚠️  This will execute a shell command with your user permissions!", description, command)
+	timeout, err := intArg(args, "timeout", s.cfg.Execute.Timeout)
+	if err != nil {
+		return "", err
+	}
+	if timeout <= 0 {
+		timeout = s.cfg.Execute.Timeout
+	}
+	if timeout <= 0 {
+		timeout = int(execx.DefaultTimeout / time.Second)
 	}
+	timeout = min(timeout, maxExecuteTimeout)
 
-	confirmed, err := s.confirmAction(action, confirmDesc)
-	if err != nil {
-		return "", fmt.Errorf("failed to get confirmation: %w", err)
+	opts := s.execOptions()
+	opts.Timeout = time.Duration(timeout) * time.Second
+
+	limits := fmt.Sprintf("Timeout: %ds", timeout)
+	if opts.Sandbox != "" && opts.Sandbox != execx.SandboxOff {
+		limits += fmt.Sprintf(", sandbox: %s", opts.Sandbox)
+		if !opts.Network {
+			limits += " (no network)"
+		}
 	}
-	if !confirmed {
-		return `{"cancelled": true, "message": "User cancelled the operation"}`, nil
+	action := "Execute shell command"
+	confirmDesc := fmt.Sprintf("Command: %s\n%s\n⚠️  This will execute a shell command with your user permissions!", command, limits)
+	if description != command {
+		confirmDesc = fmt.Sprintf("Description: %s\nCommand: %s\n%s\n⚠️  This will execute a shell command with your user permissions!", description, command, limits)
 	}
 
-	// Execute command in project root
-	cmd := exec.Command("sh", "-c", command)
-	cmd.Dir = s.projectRoot
+	if refusal, err := s.authorize(policy.Request{Tool: "execute", Command: command}, action, confirmDesc); err != nil || refusal != "" {
+		return refusal, err
+	}
 
-	stdout, err := cmd.Output()
-	var stderr []byte
-	var exitCode int
+	ctx, stop := interrupt.NotifyContext(s.turnContext())
+	defer stop()
+	s.emit(Event{Type: EventNotice, Tool: "execute", Text: "⏳ Running (Ctrl+C to stop)..."})
 
+	run, err := execx.Run(ctx, command, opts)
 	if err != nil {
-		exitError, ok := err.(*exec.ExitError)
-		if ok {
-			stderr = exitError.Stderr
-			exitCode = exitError.ExitCode()
-		} else {
-			return "", fmt.Errorf("failed to execute command: %w", err)
-		}
+		return "", err
 	}
 
 	result := map[string]interface{}{
 		"command":   command,
-		"stdout":    string(stdout),
-		"stderr":    string(stderr),
-		"exit_code": exitCode,
-		"success":   exitCode == 0,
+		"output":    run.Output,
+		"exit_code": run.ExitCode,
+		"success":   run.ExitCode == 0 && !run.TimedOut && !run.Cancelled,
+		"sandbox":   run.Sandbox,
+	}
+	if run.TimedOut {
+		result["timed_out"] = true
+		result["message"] = fmt.Sprintf("Command was killed after %ds; pass a larger timeout if it needs more time", timeout)
+	}
+	if run.Cancelled {
+		result["cancelled"] = true
+		result["message"] = "Command was stopped by the user"
+	}
+	if run.Truncated {
+		result["truncated"] = true
+	}
+	if run.Warning != "" {
+		result["warning"] = run.Warning
 	}
 
 	jsonResult, _ := json.Marshal(result)
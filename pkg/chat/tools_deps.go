@@ -0,0 +1,120 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// indexPathArg reads a file path argument and normalizes it to the index's relative form
+func indexPathArg(args map[string]interface{}) (string, error) {
+	p, ok := args["path"].(string)
+	if !ok || strings.TrimSpace(p) == "" {
+		return "", fmt.Errorf("path argument is required")
+	}
+	return path.Clean(strings.ReplaceAll(strings.TrimSpace(p), "\\", "/")), nil
+}
+
+// toolGetImports lists the project files and external packages a file imports
+func (s *Session) toolGetImports(args map[string]interface{}) (string, error) {
+	filePath, err := indexPathArg(args)
+	if err != nil {
+		return "", err
+	}
+
+	if s.index == nil {
+		return "", fmt.Errorf("project index not available")
+	}
+
+	internal, external, err := s.index.GetImports(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to get imports: %w", err)
+	}
+
+	result := map[string]interface{}{
+		"path":     filePath,
+		"imports":  nonNil(internal),
+		"external": nonNil(external),
+		"count":    len(internal) + len(external),
+	}
+
+	jsonResult, _ := json.Marshal(result)
+	return string(jsonResult), nil
+}
+
+// toolGetDependents lists the project files that import a file directly
+func (s *Session) toolGetDependents(args map[string]interface{}) (string, error) {
+	filePath, err := indexPathArg(args)
+	if err != nil {
+		return "", err
+	}
+
+	if s.index == nil {
+		return "", fmt.Errorf("project index not available")
+	}
+
+	dependents, err := s.index.GetDependents(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to get dependents: %w", err)
+	}
+
+	result := map[string]interface{}{
+		"path":       filePath,
+		"dependents": nonNil(dependents),
+		"count":      len(dependents),
+	}
+
+	jsonResult, _ := json.Marshal(result)
+	return string(jsonResult), nil
+}
+
+// toolImpactOfChange lists every file that directly or transitively depends on a file
+func (s *Session) toolImpactOfChange(args map[string]interface{}) (string, error) {
+	filePath, err := indexPathArg(args)
+	if err != nil {
+		return "", err
+	}
+
+	if s.index == nil {
+		return "", fmt.Errorf("project index not available")
+	}
+
+	maxDepth, err := intArg(args, "max_depth", 0)
+	if err != nil {
+		return "", err
+	}
+
+	impact, err := s.index.ImpactOfChange(filePath, maxDepth)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute impact: %w", err)
+	}
+
+	direct := 0
+	for _, entry := range impact {
+		if entry.Depth == 1 {
+			direct++
+		}
+	}
+
+	result := map[string]interface{}{
+		"path":     filePath,
+		"affected": impact,
+		"direct":   direct,
+		"count":    len(impact),
+	}
+	if len(impact) == 0 {
+		result["note"] = "No project files import this file. Dynamic imports, reflection and framework conventions are not tracked."
+	}
+
+	jsonResult, _ := json.Marshal(result)
+	return string(jsonResult), nil
+}
+
+// nonNil returns an empty slice instead of nil so JSON output shows [] rather than null
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,272 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/axon/pkg/git"
+)
+
+const (
+	defaultGitLogLimit = 20
+	maxGitLogLimit     = 200
+	defaultBlameLines  = 100 // Lines blamed when no end line is given
+	maxBlameLines      = 500
+	maxCommitBody      = 500 // Characters of each commit message body in git_log
+)
+
+// gitRepo opens the repository containing the project
+func (s *Session) gitRepo() (*git.Repo, error) {
+	return git.Open(s.projectRoot)
+}
+
+// gitPathArg reads an optional path argument and makes it relative to the
+// project root, refusing paths outside it
+func (s *Session) gitPathArg(args map[string]interface{}) (string, error) {
+	path, _ := args["path"].(string)
+	path = strings.TrimSpace(path)
+	if path == "" || path == "." {
+		return "", nil
+	}
+	fullPath, err := s.resolvePath(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid path: %w", err)
+	}
+	rel, err := filepath.Rel(s.projectRoot, fullPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// toolGitStatus returns the branch and the staged, unstaged, untracked and
+// conflicted files, with renames reported as such
+func (s *Session) toolGitStatus(args map[string]interface{}) (string, error) {
+	repo, err := s.gitRepo()
+	if err != nil {
+		return "", err
+	}
+	path, err := s.gitPathArg(args)
+	if err != nil {
+		return "", err
+	}
+	status, err := repo.Status(path)
+	if err != nil {
+		return "", err
+	}
+
+	staged, unstaged, untracked, conflicted := 0, 0, 0, 0
+	for _, f := range status.Files {
+		switch {
+		case f.Conflicted:
+			conflicted++
+		case f.Untracked:
+			untracked++
+		}
+		if f.Index != "" {
+			staged++
+		}
+		if f.Worktree != "" {
+			unstaged++
+		}
+	}
+	result := map[string]interface{}{
+		"branch":     status.Branch,
+		"commit":     status.Commit,
+		"files":      status.Files,
+		"staged":     staged,
+		"unstaged":   unstaged,
+		"untracked":  untracked,
+		"conflicted": conflicted,
+		"total":      len(status.Files),
+	}
+	if status.Branch == "" {
+		result["detached"] = true
+	}
+	if status.Upstream != "" {
+		result["upstream"] = status.Upstream
+		result["ahead"] = status.Ahead
+		result["behind"] = status.Behind
+	}
+
+	jsonResult, _ := json.Marshal(result)
+	return string(jsonResult), nil
+}
+
+// toolGitDiff returns the unstaged, staged or revision diff, or only the
+// changed files and line counts in stat mode
+func (s *Session) toolGitDiff(args map[string]interface{}) (string, error) {
+	repo, err := s.gitRepo()
+	if err != nil {
+		return "", err
+	}
+	path, err := s.gitPathArg(args)
+	if err != nil {
+		return "", err
+	}
+	staged := boolArg(args, "staged")
+	stat := boolArg(args, "stat")
+	rev, _ := args["rev"].(string)
+
+	diff, err := repo.Diff(git.DiffOptions{Staged: staged, Rev: strings.TrimSpace(rev), Path: path, Stat: stat})
+	if err != nil {
+		return "", err
+	}
+	result := map[string]interface{}{
+		"path":      path,
+		"staged":    staged,
+		"files":     diff.Files,
+		"additions": diff.Additions,
+		"deletions": diff.Deletions,
+	}
+	if rev != "" {
+		result["rev"] = rev
+	}
+	if !stat {
+		result["diff"] = diff.Patch
+		if len(diff.Files) == 0 {
+			result["diff"] = "No changes"
+		}
+	}
+	if diff.Truncated {
+		result["truncated"] = true
+		result["note"] = "The diff was truncated; use stat mode to list files, then diff them one 'path' at a time."
+	}
+
+	jsonResult, _ := json.Marshal(result)
+	return string(jsonResult), nil
+}
+
+// toolGitLog lists commits, optionally those touching a path or adding or
+// removing a symbol
+func (s *Session) toolGitLog(args map[string]interface{}) (string, error) {
+	repo, err := s.gitRepo()
+	if err != nil {
+		return "", err
+	}
+	path, err := s.gitPathArg(args)
+	if err != nil {
+		return "", err
+	}
+	limit, err := intArg(args, "limit", defaultGitLogLimit)
+	if err != nil {
+		return "", err
+	}
+	if limit <= 0 || limit > maxGitLogLimit {
+		limit = maxGitLogLimit
+	}
+	opts := git.LogOptions{Path: path, Limit: limit}
+	opts.Rev, _ = args["rev"].(string)
+	opts.Symbol, _ = args["symbol"].(string)
+	opts.Author, _ = args["author"].(string)
+	opts.Since, _ = args["since"].(string)
+
+	commits, err := repo.Log(opts)
+	if err != nil {
+		return "", err
+	}
+	for i := range commits {
+		if len(commits[i].Body) > maxCommitBody {
+			commits[i].Body = commits[i].Body[:maxCommitBody] + "..."
+		}
+	}
+
+	jsonResult, _ := json.Marshal(map[string]interface{}{
+		"commits": commits,
+		"count":   len(commits),
+	})
+	return string(jsonResult), nil
+}
+
+// toolGitBlame tells which commit last changed each line of a range, with
+// the commits' messages, to answer why a line looks the way it does
+func (s *Session) toolGitBlame(args map[string]interface{}) (string, error) {
+	repo, err := s.gitRepo()
+	if err != nil {
+		return "", err
+	}
+	path, err := s.gitPathArg(args)
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		return "", fmt.Errorf("path argument is required")
+	}
+	start, err := intArg(args, "start_line", 1)
+	if err != nil {
+		return "", err
+	}
+	if start < 1 {
+		start = 1
+	}
+	end, err := intArg(args, "end_line", 0)
+	if err != nil {
+		return "", err
+	}
+	if end <= 0 {
+		end = start + defaultBlameLines - 1
+	}
+	if end-start+1 > maxBlameLines {
+		end = start + maxBlameLines - 1
+	}
+	rev, _ := args["rev"].(string)
+
+	blame, err := repo.Blame(path, start, end, strings.TrimSpace(rev))
+	if err != nil {
+		// Asking past the end of the file is common; retry up to the end
+		if !strings.Contains(err.Error(), "lines") {
+			return "", err
+		}
+		if blame, err = repo.Blame(path, start, 0, strings.TrimSpace(rev)); err != nil {
+			return "", err
+		}
+		if len(blame.Lines) > maxBlameLines {
+			blame.Lines = blame.Lines[:maxBlameLines]
+		}
+	}
+
+	jsonResult, _ := json.Marshal(blame)
+	return string(jsonResult), nil
+}
+
+// toolGitShow returns a commit's message, changed files and patch
+func (s *Session) toolGitShow(args map[string]interface{}) (string, error) {
+	repo, err := s.gitRepo()
+	if err != nil {
+		return "", err
+	}
+	rev, _ := args["rev"].(string)
+	path, err := s.gitPathArg(args)
+	if err != nil {
+		return "", err
+	}
+	stat := boolArg(args, "stat")
+
+	show, err := repo.Show(strings.TrimSpace(rev), path, stat)
+	if err != nil {
+		return "", err
+	}
+	jsonResult, _ := json.Marshal(show)
+	return string(jsonResult), nil
+}
+
+// toolGitBranches lists branches with their last commit and how far they
+// are ahead of or behind their upstream
+func (s *Session) toolGitBranches(args map[string]interface{}) (string, error) {
+	repo, err := s.gitRepo()
+	if err != nil {
+		return "", err
+	}
+	remote := boolArg(args, "remote")
+	branches, err := repo.Branches(remote)
+	if err != nil {
+		return "", err
+	}
+	jsonResult, _ := json.Marshal(map[string]interface{}{
+		"branches": branches,
+		"count":    len(branches),
+	})
+	return string(jsonResult), nil
+}
@@ -0,0 +1,119 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/axon/pkg/localhttp"
+	"github.com/axon/pkg/policy"
+)
+
+// maxHTTPTimeout caps the timeout the model can ask for, in seconds
+const maxHTTPTimeout = 300
+
+// toolHTTPRequest sends a request to a local service; methods that change
+// state need confirmation
+func (s *Session) toolHTTPRequest(args map[string]interface{}) (string, error) {
+	rawURL, _ := args["url"].(string)
+	u, err := localhttp.ParseURL(rawURL)
+	if err != nil {
+		return "", err
+	}
+	client := localhttp.NewClient(s.cfg.HTTP.AllowedHosts)
+	if err := client.Check(u); err != nil {
+		return "", err
+	}
+
+	method, _ := args["method"].(string)
+	method = strings.ToUpper(strings.TrimSpace(method))
+	if method == "" {
+		method = "GET"
+	}
+
+	headers := map[string]string{}
+	if raw, ok := args["headers"].(map[string]interface{}); ok {
+		for name, value := range raw {
+			headers[name] = fmt.Sprint(value)
+		}
+	}
+
+	// The body may be a JSON value or a raw string
+	var body []byte
+	switch v := args["body"].(type) {
+	case nil:
+	case string:
+		body = []byte(v)
+	default:
+		if body, err = json.Marshal(v); err != nil {
+			return "", fmt.Errorf("invalid body: %w", err)
+		}
+	}
+
+	timeout, err := intArg(args, "timeout", s.cfg.HTTP.Timeout)
+	if err != nil {
+		return "", err
+	}
+	if timeout <= 0 || timeout > maxHTTPTimeout {
+		timeout = maxHTTPTimeout
+	}
+
+	if localhttp.Mutating(method) {
+		var description strings.Builder
+		fmt.Fprintf(&description, "%s %s", method, u)
+		names := make([]string, 0, len(headers))
+		for name := range headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(&description, "\n%s: %s", name, headers[name])
+		}
+		if len(body) > 0 {
+			fmt.Fprintf(&description, "\nBody: %s", truncateForPrompt(string(body), 500))
+		}
+		req := policy.Request{Tool: "http_request", Target: method + " " + u.String()}
+		if refusal, err := s.authorize(req, "Send HTTP request", description.String()); err != nil || refusal != "" {
+			return refusal, err
+		}
+	}
+
+	resp, err := client.Do(s.turnContext(), localhttp.Request{
+		Method:  method,
+		URL:     u.String(),
+		Headers: headers,
+		Body:    body,
+		Timeout: time.Duration(timeout) * time.Second,
+		MaxBody: s.cfg.HTTP.MaxBody,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	result := map[string]interface{}{
+		"method":      method,
+		"url":         resp.URL,
+		"status":      resp.Status,
+		"status_code": resp.StatusCode,
+		"headers":     resp.Headers,
+		"body":        resp.Body,
+		"size":        resp.Size,
+		"duration_ms": resp.Duration.Milliseconds(),
+	}
+	if resp.Truncated {
+		result["truncated"] = true
+	}
+
+	jsonResult, _ := json.Marshal(result)
+	return string(jsonResult), nil
+}
+
+// truncateForPrompt shortens text shown in a confirmation prompt
+func truncateForPrompt(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+	return text[:max] + "..."
+}
@@ -0,0 +1,163 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/axon/pkg/indexer"
+)
+
+// maxListedRoutes caps the routes returned by list_routes
+const maxListedRoutes = 200
+
+// laravelApp returns the Laravel analysis of the project
+func (s *Session) laravelApp() (*indexer.LaravelApp, error) {
+	if s.index == nil {
+		return nil, fmt.Errorf("project index not available")
+	}
+	app := s.index.Laravel()
+	if app == nil {
+		return nil, fmt.Errorf("not a Laravel project (no artisan file or laravel/framework dependency)")
+	}
+	return app, nil
+}
+
+// toolListRoutes lists the route table, optionally filtered by URI, name,
+// action or middleware, and by HTTP method
+func (s *Session) toolListRoutes(args map[string]interface{}) (string, error) {
+	app, err := s.laravelApp()
+	if err != nil {
+		return "", err
+	}
+
+	filter, _ := args["filter"].(string)
+	filter = strings.ToLower(strings.TrimSpace(filter))
+	method, _ := args["method"].(string)
+	method = strings.ToUpper(strings.TrimSpace(method))
+
+	var routes []indexer.Route
+	total := 0
+	for _, r := range app.Routes {
+		if method != "" && !r.AllowsMethod(method) {
+			continue
+		}
+		if filter != "" && !routeMatchesFilter(r, filter) {
+			continue
+		}
+		total++
+		if len(routes) < maxListedRoutes {
+			routes = append(routes, r)
+		}
+	}
+
+	result := map[string]interface{}{
+		"routes": routes,
+		"count":  total,
+	}
+	if routes == nil {
+		result["routes"] = []indexer.Route{}
+	}
+	if total > len(routes) {
+		result["truncated"] = true
+		result["note"] = fmt.Sprintf("Showing the first %d routes; use 'filter' or 'method' to narrow the list.", maxListedRoutes)
+	}
+
+	jsonResult, _ := json.Marshal(result)
+	return string(jsonResult), nil
+}
+
+// routeMatchesFilter does a case-insensitive substring match on the route's
+// URI, name, action and middleware
+func routeMatchesFilter(r indexer.Route, filter string) bool {
+	fields := append([]string{r.URI, r.Name, r.Action}, r.Middleware...)
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field), filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// toolFindRouteHandler finds the controller method handling a URI or route name
+func (s *Session) toolFindRouteHandler(args map[string]interface{}) (string, error) {
+	app, err := s.laravelApp()
+	if err != nil {
+		return "", err
+	}
+
+	uri, _ := args["uri"].(string)
+	name, _ := args["name"].(string)
+	method, _ := args["method"].(string)
+	uri, name = strings.TrimSpace(uri), strings.TrimSpace(name)
+	if uri == "" && name == "" {
+		return "", fmt.Errorf("uri or name argument is required")
+	}
+
+	var matches []indexer.Route
+	if name != "" {
+		for _, r := range app.Routes {
+			if r.Name == name {
+				matches = append(matches, r)
+			}
+		}
+	} else {
+		matches = app.MatchRoutes(uri, method)
+	}
+
+	result := map[string]interface{}{
+		"matches": matches,
+		"count":   len(matches),
+	}
+	if len(matches) == 0 {
+		result["matches"] = []indexer.Route{}
+		result["note"] = "No route matches. Use 'list_routes' with a filter to see similar routes; routes registered by packages or service providers are not included."
+	} else if len(matches) > 1 && name == "" {
+		result["note"] = "Several routes match; Laravel uses the first one registered for the request method."
+	}
+
+	jsonResult, _ := json.Marshal(result)
+	return string(jsonResult), nil
+}
+
+// toolDescribeModel describes an Eloquent model with its table schema
+func (s *Session) toolDescribeModel(args map[string]interface{}) (string, error) {
+	app, err := s.laravelApp()
+	if err != nil {
+		return "", err
+	}
+
+	name, _ := args["name"].(string)
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("name argument is required")
+	}
+
+	model, ok := app.FindModel(name)
+	if !ok {
+		// A table without a model is still worth describing
+		if table, ok := app.Tables[name]; ok {
+			jsonResult, _ := json.Marshal(map[string]interface{}{"table": table})
+			return string(jsonResult), nil
+		}
+		names := make([]string, 0, len(app.Models))
+		for _, m := range app.Models {
+			names = append(names, m.Name)
+		}
+		sort.Strings(names)
+		return "", fmt.Errorf("model not found: %s (models: %s)", name, strings.Join(names, ", "))
+	}
+
+	result := map[string]interface{}{
+		"model": model,
+	}
+	if table, ok := app.Tables[model.Table]; ok {
+		result["table"] = table
+	} else {
+		result["note"] = fmt.Sprintf("No migration creates the '%s' table.", model.Table)
+	}
+
+	jsonResult, _ := json.Marshal(result)
+	return string(jsonResult), nil
+}
@@ -0,0 +1,396 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/axon/pkg/fsctx"
+	"github.com/axon/pkg/lsp"
+	"github.com/axon/pkg/policy"
+)
+
+const (
+	// lspRequestTimeout bounds navigation requests to a language server
+	lspRequestTimeout = 30 * time.Second
+
+	// maxReportedDiagnostics caps the diagnostics attached to a tool result
+	maxReportedDiagnostics = 20
+)
+
+// lspTarget is a file position named by tool arguments
+type lspTarget struct {
+	path     string // Relative to the project root
+	fullPath string
+	content  string
+	pos      lsp.Position
+}
+
+// parseLSPTarget reads path, line and either column or symbol from tool arguments.
+// Lines and columns are 1-based; symbol picks the first occurrence on the line.
+func (s *Session) parseLSPTarget(args map[string]interface{}) (*lspTarget, error) {
+	path, ok := args["path"].(string)
+	if !ok || path == "" {
+		return nil, fmt.Errorf("path argument is required")
+	}
+	line, err := intArg(args, "line", 0)
+	if err != nil {
+		return nil, err
+	}
+	if line < 1 {
+		return nil, fmt.Errorf("line argument is required (1-based)")
+	}
+
+	fullPath, err := s.resolvePath(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+	data, err := os.ReadFile(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	content := string(data)
+
+	lines := strings.Split(content, "\n")
+	if line > len(lines) {
+		return nil, fmt.Errorf("line %d is past the end of %s (%d lines)", line, path, len(lines))
+	}
+	lineText := strings.TrimRight(lines[line-1], "\r")
+
+	byteCol := len(lineText) - len(strings.TrimLeft(lineText, " \t"))
+	if symbol, ok := args["symbol"].(string); ok && symbol != "" {
+		idx := strings.Index(lineText, symbol)
+		if idx < 0 {
+			return nil, fmt.Errorf("symbol %q not found on line %d of %s", symbol, line, path)
+		}
+		byteCol = idx
+	} else if column, err := intArg(args, "column", 0); err != nil {
+		return nil, err
+	} else if column > 0 {
+		byteCol = column - 1
+	}
+
+	return &lspTarget{
+		path:     strings.ReplaceAll(path, "\\", "/"),
+		fullPath: fullPath,
+		content:  content,
+		pos:      lsp.Position{Line: line - 1, Character: lsp.UTF16Offset(lineText, byteCol)},
+	}, nil
+}
+
+// lspClient returns a client for a file with the file's current content synced
+func (s *Session) lspClient(t *lspTarget) (*lsp.Client, error) {
+	if s.lsp == nil {
+		return nil, lsp.ErrNoServer
+	}
+	client, err := s.lsp.ClientFor(t.fullPath)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := client.Sync(t.fullPath, t.content); err != nil {
+		return nil, fmt.Errorf("failed to send %s to %s: %w", t.path, client.Name(), err)
+	}
+	return client, nil
+}
+
+// lspUnavailable builds the tool error for a file without a language server
+func lspUnavailable(path string, err error) error {
+	if errors.Is(err, lsp.ErrNoServer) {
+		return fmt.Errorf("no language server for %s (%v). Configure one under lsp.servers in .axon.yml, or use find_references/grep instead", path, err)
+	}
+	return fmt.Errorf("language server error: %w", err)
+}
+
+// locationResult converts an LSP location into a tool result with a 1-based
+// position and the source line
+func (s *Session) locationResult(loc lsp.Location) map[string]interface{} {
+	path, err := lsp.URIToPath(loc.URI)
+	if err != nil {
+		return map[string]interface{}{"uri": loc.URI}
+	}
+
+	result := map[string]interface{}{
+		"file": path,
+		"line": loc.Range.Start.Line + 1,
+	}
+	if rel, err := filepath.Rel(s.projectRoot, path); err == nil && !strings.HasPrefix(rel, "..") {
+		result["file"] = filepath.ToSlash(rel)
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		lines := strings.Split(string(data), "\n")
+		if loc.Range.Start.Line < len(lines) {
+			text := lines[loc.Range.Start.Line]
+			result["column"] = lsp.ByteOffset(text, loc.Range.Start.Character) + 1
+			result["content"] = strings.TrimSpace(text)
+		}
+	}
+	return result
+}
+
+// toolGotoDefinition finds where the symbol at a position is defined
+func (s *Session) toolGotoDefinition(args map[string]interface{}) (string, error) {
+	target, err := s.parseLSPTarget(args)
+	if err != nil {
+		return "", err
+	}
+	client, err := s.lspClient(target)
+	if err != nil {
+		return "", lspUnavailable(target.path, err)
+	}
+
+	ctx, cancel := context.WithTimeout(s.turnContext(), lspRequestTimeout)
+	defer cancel()
+	locations, err := client.Definition(ctx, target.fullPath, target.pos)
+	if err != nil {
+		return "", fmt.Errorf("definition request failed: %w", err)
+	}
+
+	definitions := []map[string]interface{}{}
+	for _, loc := range locations {
+		definitions = append(definitions, s.locationResult(loc))
+	}
+
+	result := map[string]interface{}{
+		"path":        target.path,
+		"line":        target.pos.Line + 1,
+		"definitions": definitions,
+		"count":       len(definitions),
+		"server":      client.Name(),
+	}
+
+	jsonResult, _ := json.Marshal(result)
+	return string(jsonResult), nil
+}
+
+// toolHover returns the type, signature and documentation of the symbol at a position
+func (s *Session) toolHover(args map[string]interface{}) (string, error) {
+	target, err := s.parseLSPTarget(args)
+	if err != nil {
+		return "", err
+	}
+	client, err := s.lspClient(target)
+	if err != nil {
+		return "", lspUnavailable(target.path, err)
+	}
+
+	ctx, cancel := context.WithTimeout(s.turnContext(), lspRequestTimeout)
+	defer cancel()
+	hover, err := client.Hover(ctx, target.fullPath, target.pos)
+	if err != nil {
+		return "", fmt.Errorf("hover request failed: %w", err)
+	}
+
+	result := map[string]interface{}{
+		"path":   target.path,
+		"line":   target.pos.Line + 1,
+		"hover":  hover,
+		"server": client.Name(),
+	}
+	if hover == "" {
+		result["note"] = "No information at this position. Pass 'symbol' to point at an identifier on the line."
+	}
+
+	jsonResult, _ := json.Marshal(result)
+	return string(jsonResult), nil
+}
+
+// toolDiagnostics reports the compiler errors and warnings for a file
+func (s *Session) toolDiagnostics(args map[string]interface{}) (string, error) {
+	path, ok := args["path"].(string)
+	if !ok || path == "" {
+		return "", fmt.Errorf("path argument is required")
+	}
+	fullPath, err := s.resolvePath(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid path: %w", err)
+	}
+	data, err := os.ReadFile(fullPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	diagnostics, complete, err := s.collectDiagnostics(fullPath, string(data), lspRequestTimeout)
+	if err != nil {
+		return "", lspUnavailable(path, err)
+	}
+
+	result := map[string]interface{}{
+		"path":        path,
+		"diagnostics": formatDiagnostics(diagnostics, lsp.SeverityHint),
+		"count":       len(diagnostics),
+	}
+	if !complete {
+		result["note"] = "The language server did not report in time; results may be incomplete."
+	}
+
+	jsonResult, _ := json.Marshal(result)
+	return string(jsonResult), nil
+}
+
+// toolRenameSymbol renames a symbol across the project using the language server
+func (s *Session) toolRenameSymbol(args map[string]interface{}) (string, error) {
+	newName, ok := args["new_name"].(string)
+	if !ok || strings.TrimSpace(newName) == "" {
+		return "", fmt.Errorf("new_name argument is required")
+	}
+	target, err := s.parseLSPTarget(args)
+	if err != nil {
+		return "", err
+	}
+	client, err := s.lspClient(target)
+	if err != nil {
+		return "", lspUnavailable(target.path, err)
+	}
+
+	ctx, cancel := context.WithTimeout(s.turnContext(), lspRequestTimeout)
+	defer cancel()
+	changes, err := client.Rename(ctx, target.fullPath, target.pos, newName)
+	if err != nil {
+		return "", fmt.Errorf("rename request failed: %w", err)
+	}
+	if len(changes) == 0 {
+		return "", fmt.Errorf("the language server returned no edits; check that line and symbol point at an identifier")
+	}
+
+	// Compute every new file before touching the disk, so a bad edit aborts the whole rename
+	type fileChange struct {
+		path    string // Relative to project root
+		content string
+		edits   int
+	}
+	var planned []fileChange
+	for fullPath, edits := range changes {
+		rel, err := filepath.Rel(s.projectRoot, fullPath)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return "", fmt.Errorf("rename would modify %s, which is outside the project", fullPath)
+		}
+		data, err := os.ReadFile(fullPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", rel, err)
+		}
+		content, err := lsp.ApplyEdits(string(data), edits)
+		if err != nil {
+			return "", fmt.Errorf("failed to apply edits to %s: %w", rel, err)
+		}
+		planned = append(planned, fileChange{path: filepath.ToSlash(rel), content: content, edits: len(edits)})
+	}
+	sort.Slice(planned, func(i, j int) bool { return planned[i].path < planned[j].path })
+
+	var details strings.Builder
+	var paths []string
+	fmt.Fprintf(&details, "Rename to %q in %d file(s):", newName, len(planned))
+	for _, change := range planned {
+		fmt.Fprintf(&details, "\n  - %s (%d edit(s))", change.path, change.edits)
+		paths = append(paths, change.path)
+	}
+	if refusal, err := s.authorize(policy.Request{Tool: "rename_symbol", Paths: paths}, "Rename symbol", details.String()); err != nil || refusal != "" {
+		return refusal, err
+	}
+
+	files := []map[string]interface{}{}
+	for _, change := range planned {
+		if err := fsctx.WriteFile(s.projectRoot, change.path, change.content, s.cfg); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", change.path, err)
+		}
+		entry := map[string]interface{}{"path": change.path, "edits": change.edits}
+		s.attachDiagnostics(entry, change.path, change.content)
+		files = append(files, entry)
+	}
+
+	result := map[string]interface{}{
+		"success":  true,
+		"new_name": newName,
+		"files":    files,
+		"message":  fmt.Sprintf("Renamed in %d file(s)", len(files)),
+	}
+
+	jsonResult, _ := json.Marshal(result)
+	return string(jsonResult), nil
+}
+
+// collectDiagnostics syncs a file to its language server and waits for fresh
+// diagnostics. complete is false if the server did not answer in time.
+func (s *Session) collectDiagnostics(fullPath, content string, timeout time.Duration) (diagnostics []lsp.Diagnostic, complete bool, err error) {
+	if s.lsp == nil {
+		return nil, false, lsp.ErrNoServer
+	}
+	client, err := s.lsp.ClientFor(fullPath)
+	if err != nil {
+		return nil, false, err
+	}
+	token, err := client.Sync(fullPath, content)
+	if err != nil {
+		return nil, false, err
+	}
+
+	ctx, cancel := context.WithTimeout(s.turnContext(), timeout)
+	defer cancel()
+	diagnostics, complete = client.WaitDiagnostics(ctx, token)
+	return diagnostics, complete, nil
+}
+
+// attachDiagnostics adds the errors and warnings a language server reports for
+// a file that was just written, so the model notices broken code immediately.
+// It does nothing when no server handles the file type.
+func (s *Session) attachDiagnostics(result map[string]interface{}, path, content string) {
+	if s.lsp == nil || s.cfg.LSP.DiagnosticsTimeout <= 0 {
+		return
+	}
+	fullPath, err := s.resolvePath(path)
+	if err != nil || !s.lsp.Handles(fullPath) {
+		return
+	}
+
+	timeout := time.Duration(s.cfg.LSP.DiagnosticsTimeout) * time.Second
+	diagnostics, complete, err := s.collectDiagnostics(fullPath, content, timeout)
+	if err != nil || !complete {
+		return
+	}
+
+	reported := formatDiagnostics(diagnostics, lsp.SeverityWarning)
+	result["diagnostics"] = reported
+	for _, d := range diagnostics {
+		if d.Severity == lsp.SeverityError || d.Severity == 0 {
+			result["diagnostics_note"] = "The file has errors; fix them before moving on."
+			break
+		}
+	}
+}
+
+// formatDiagnostics converts diagnostics up to a severity into tool results
+func formatDiagnostics(diagnostics []lsp.Diagnostic, maxSeverity int) []map[string]interface{} {
+	sorted := append([]lsp.Diagnostic(nil), diagnostics...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Range.Start.Line != sorted[j].Range.Start.Line {
+			return sorted[i].Range.Start.Line < sorted[j].Range.Start.Line
+		}
+		return sorted[i].Range.Start.Character < sorted[j].Range.Start.Character
+	})
+
+	formatted := []map[string]interface{}{}
+	for _, d := range sorted {
+		if d.Severity > maxSeverity {
+			continue
+		}
+		if len(formatted) >= maxReportedDiagnostics {
+			break
+		}
+		entry := map[string]interface{}{
+			"line":     d.Range.Start.Line + 1,
+			"column":   d.Range.Start.Character + 1,
+			"severity": d.SeverityName(),
+			"message":  d.Message,
+		}
+		if d.Source != "" {
+			entry["source"] = d.Source
+		}
+		formatted = append(formatted, entry)
+	}
+	return formatted
+}
@@ -0,0 +1,107 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/axon/pkg/interrupt"
+	"github.com/axon/pkg/llm"
+	"github.com/axon/pkg/mcp"
+	"github.com/axon/pkg/policy"
+)
+
+// maxMCPResult is the number of bytes of an MCP tool result given to the model
+const maxMCPResult = 30000
+
+// startMCP starts the MCP servers configured in .axon.yml and reports which
+// ones are available
+func (s *Session) startMCP() {
+	timeout := time.Duration(s.cfg.MCP.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 60 * time.Second
+	}
+	s.mcp = mcp.NewManager(s.projectRoot, s.cfg.MCP.Servers, timeout)
+	if len(s.cfg.MCP.Servers) == 0 {
+		return
+	}
+
+	fmt.Printf("%s🔌 Starting MCP servers...%s", colorYellow, colorReset)
+	s.mcp.Start(context.Background())
+	fmt.Print("\r\033[K")
+
+	servers := s.mcp.Servers()
+	var names []string
+	for name, count := range servers {
+		names = append(names, fmt.Sprintf("%s (%d tools)", name, count))
+	}
+	sort.Strings(names)
+	if len(names) > 0 {
+		fmt.Printf("%s🔌 MCP servers: %s%s\n", colorGreen, strings.Join(names, ", "), colorReset)
+	}
+	for name, err := range s.mcp.Failed() {
+		fmt.Fprintf(os.Stderr, "%s⚠️  MCP server %s is unavailable: %v%s\n", colorYellow, name, err, colorReset)
+	}
+}
+
+// tools returns the built-in tools followed by the tools of the MCP servers
+func (s *Session) tools() []llm.Tool {
+	tools := llm.GetAvailableTools()
+	if s.mcp == nil {
+		return tools
+	}
+	for _, tool := range s.mcp.Tools() {
+		params := tool.InputSchema
+		if params == nil {
+			params = map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+		}
+		description := tool.Description
+		if description == "" {
+			description = tool.Title
+		}
+		tools = append(tools, llm.Tool{
+			Type: "function",
+			Function: llm.ToolFunction{
+				Name:        tool.QualifiedName,
+				Description: fmt.Sprintf("[MCP server %s] %s", tool.Server, description),
+				Parameters:  params,
+			},
+		})
+	}
+	return tools
+}
+
+// toolMCP calls a tool of an MCP server. Every call goes through the
+// permission rules, matched on the qualified tool name.
+func (s *Session) toolMCP(tool mcp.ServerTool, args map[string]interface{}) (string, error) {
+	argsJSON, _ := json.MarshalIndent(args, "", "  ")
+	refusal, err := s.authorize(
+		policy.Request{Tool: tool.QualifiedName},
+		fmt.Sprintf("Call %s on MCP server %s", tool.Name, tool.Server),
+		string(argsJSON),
+	)
+	if err != nil || refusal != "" {
+		return refusal, err
+	}
+
+	ctx, stop := interrupt.NotifyContext(s.turnContext())
+	defer stop()
+	result, err := s.mcp.Call(ctx, tool.QualifiedName, args)
+	if err != nil {
+		return "", fmt.Errorf("MCP server %s: %w", tool.Server, err)
+	}
+
+	response := map[string]interface{}{
+		"server":  tool.Server,
+		"content": truncateForPrompt(result.Text(), maxMCPResult),
+	}
+	if result.IsError {
+		response["is_error"] = true
+	}
+	jsonResult, _ := json.Marshal(response)
+	return string(jsonResult), nil
+}
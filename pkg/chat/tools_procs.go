@@ -0,0 +1,185 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/axon/pkg/execx"
+	"github.com/axon/pkg/policy"
+	"github.com/axon/pkg/procs"
+)
+
+const (
+	defaultProcessLines  = 50    // Lines of output returned when no offset is given
+	maxProcessOutput     = 20000 // Bytes of process output returned per call
+	defaultReadyTimeout  = 30    // Seconds start_process waits for the ready text
+	startupOutputTimeout = time.Second
+)
+
+// processArg reads the id argument, which may be a number or a name
+func processArg(args map[string]interface{}) (string, error) {
+	switch v := args["id"].(type) {
+	case float64:
+		return fmt.Sprintf("%d", int(v)), nil
+	case string:
+		if strings.TrimSpace(v) != "" {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("id argument is required (process ID or name)")
+}
+
+// toolStartProcess starts a long-running command such as a dev server in the
+// background and returns its first output
+func (s *Session) toolStartProcess(args map[string]interface{}) (string, error) {
+	command, ok := args["command"].(string)
+	if !ok || strings.TrimSpace(command) == "" {
+		return "", fmt.Errorf("command argument is required")
+	}
+	name, _ := args["name"].(string)
+	name = strings.TrimSpace(name)
+	ready, _ := args["ready"].(string)
+	readyTimeout, err := intArg(args, "timeout", defaultReadyTimeout)
+	if err != nil {
+		return "", err
+	}
+	if readyTimeout <= 0 || readyTimeout > maxExecuteTimeout {
+		readyTimeout = defaultReadyTimeout
+	}
+
+	// A server in a network namespace of its own could not be reached,
+	// so background processes keep the network even when sandboxed
+	opts := s.execOptions()
+	opts.Network = true
+
+	description := fmt.Sprintf("Command: %s\nRuns in the background until stopped or the session ends", command)
+	if opts.Sandbox != "" && opts.Sandbox != execx.SandboxOff {
+		description += fmt.Sprintf("\nSandbox: %s (network allowed)", opts.Sandbox)
+	}
+	if refusal, err := s.authorize(policy.Request{Tool: "start_process", Command: command}, "Start background process", description); err != nil || refusal != "" {
+		return refusal, err
+	}
+
+	proc, err := s.procs.Start(command, name, opts)
+	if err != nil {
+		return "", err
+	}
+
+	result := map[string]interface{}{
+		"id":      proc.ID,
+		"name":    proc.Name,
+		"command": command,
+		"pid":     proc.Info().PID,
+		"sandbox": proc.Sandbox,
+	}
+	if proc.Warning != "" {
+		result["warning"] = proc.Warning
+	}
+	if ready != "" {
+		found := proc.WaitForOutput(ready, time.Duration(readyTimeout)*time.Second)
+		result["ready"] = found
+		if !found && proc.Running() {
+			result["message"] = fmt.Sprintf("%q did not appear within %ds; the process is still running, check process_output", ready, readyTimeout)
+		}
+	} else {
+		// Give commands that fail immediately (bad flags, port in use) a chance to exit
+		proc.Wait(startupOutputTimeout)
+	}
+
+	output, next, _ := proc.Output(-1, defaultProcessLines)
+	result["output"] = output
+	result["next_offset"] = next
+	result["running"] = proc.Running()
+	if info := proc.Info(); info.ExitCode != nil {
+		result["exit_code"] = *info.ExitCode
+		result["message"] = "The process exited right away; see output"
+	}
+
+	jsonResult, _ := json.Marshal(result)
+	return string(jsonResult), nil
+}
+
+// toolProcessOutput returns the recent or new output of a background process
+func (s *Session) toolProcessOutput(args map[string]interface{}) (string, error) {
+	ref, err := processArg(args)
+	if err != nil {
+		return "", err
+	}
+	proc, err := s.procs.Get(ref)
+	if err != nil {
+		return "", err
+	}
+	offset, err := intArg(args, "since", -1)
+	if err != nil {
+		return "", err
+	}
+	lines, err := intArg(args, "lines", defaultProcessLines)
+	if err != nil {
+		return "", err
+	}
+
+	output, next, dropped := proc.Output(int64(offset), lines)
+	if len(output) > maxProcessOutput {
+		output, dropped = output[len(output)-maxProcessOutput:], true
+	}
+	info := proc.Info()
+	result := map[string]interface{}{
+		"id":          info.ID,
+		"name":        info.Name,
+		"running":     info.Running,
+		"output":      output,
+		"next_offset": next,
+	}
+	if dropped {
+		result["truncated"] = true
+	}
+	if info.ExitCode != nil {
+		result["exit_code"] = *info.ExitCode
+	}
+
+	jsonResult, _ := json.Marshal(result)
+	return string(jsonResult), nil
+}
+
+// toolStopProcess stops a background process and everything it started
+func (s *Session) toolStopProcess(args map[string]interface{}) (string, error) {
+	ref, err := processArg(args)
+	if err != nil {
+		return "", err
+	}
+	proc, err := s.procs.Get(ref)
+	if err != nil {
+		return "", err
+	}
+	wasRunning := proc.Running()
+	proc.Stop(procs.StopGrace)
+
+	output, _, _ := proc.Output(-1, 20)
+	info := proc.Info()
+	result := map[string]interface{}{
+		"id":          info.ID,
+		"name":        info.Name,
+		"success":     true,
+		"was_running": wasRunning,
+		"uptime":      info.Uptime,
+		"last_output": output,
+	}
+	if info.ExitCode != nil {
+		result["exit_code"] = *info.ExitCode
+	}
+
+	jsonResult, _ := json.Marshal(result)
+	return string(jsonResult), nil
+}
+
+// toolListProcesses lists the background processes of the session
+func (s *Session) toolListProcesses(args map[string]interface{}) (string, error) {
+	processes := s.procs.List()
+	jsonResult, _ := json.Marshal(map[string]interface{}{
+		"processes": processes,
+		"count":     len(processes),
+	})
+	return string(jsonResult), nil
+}
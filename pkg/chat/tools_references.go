@@ -0,0 +1,316 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/axon/pkg/goanalysis"
+)
+
+// goAnalysisTimeout bounds package loading for the Go analysis tools
+const goAnalysisTimeout = 2 * time.Minute
+
+// symbolQuery holds the arguments shared by find_references, find_implementations and call_hierarchy
+type symbolQuery struct {
+	symbol string // "Name", "Type.Method" or "pkg.Name"
+	path   string // File containing the symbol, or a directory to limit results to
+	line   int    // Line of the symbol in path, for disambiguation
+}
+
+// parseSymbolQuery reads the symbol, path and line arguments
+func parseSymbolQuery(args map[string]interface{}) (symbolQuery, error) {
+	q := symbolQuery{}
+	symbol, ok := args["symbol"].(string)
+	if !ok || strings.TrimSpace(symbol) == "" {
+		return q, fmt.Errorf("symbol argument is required")
+	}
+	q.symbol = strings.TrimSpace(symbol)
+
+	if p, ok := args["path"].(string); ok && strings.TrimSpace(p) != "" {
+		q.path = path.Clean(strings.ReplaceAll(strings.TrimSpace(p), "\\", "/"))
+	}
+
+	line, err := intArg(args, "line", 0)
+	if err != nil {
+		return q, err
+	}
+	q.line = line
+	return q, nil
+}
+
+// name returns the bare identifier, without a type or package qualifier
+func (q symbolQuery) name() string {
+	if i := strings.LastIndex(q.symbol, "."); i >= 0 {
+		return q.symbol[i+1:]
+	}
+	return q.symbol
+}
+
+// position returns the file and line to resolve the symbol at, if the query names one
+func (q symbolQuery) position() (string, int) {
+	if q.line > 0 && strings.HasSuffix(q.path, ".go") {
+		return q.path, q.line
+	}
+	return "", 0
+}
+
+// useGoAnalysis reports whether a query can be answered with go/types
+func (s *Session) useGoAnalysis(q symbolQuery) bool {
+	if s.golang == nil {
+		return false
+	}
+	if q.path == "" || strings.HasSuffix(q.path, ".go") {
+		return true
+	}
+	// A directory limits the results; anything else is another language
+	info, err := os.Stat(s.absPath(q.path))
+	return err == nil && info.IsDir()
+}
+
+// absPath joins a project-relative path with the project root
+func (s *Session) absPath(rel string) string {
+	resolved, err := s.resolvePath(rel)
+	if err != nil {
+		return rel
+	}
+	return resolved
+}
+
+// inScope checks if a location is inside the directory a query is limited to
+func (q symbolQuery) inScope(file string) bool {
+	if q.path == "" || q.path == "." || q.line > 0 {
+		return true
+	}
+	return file == q.path || strings.HasPrefix(file, q.path+"/")
+}
+
+// toolFindReferences finds references to a symbol. Go symbols are resolved with
+// go/types; other languages fall back to a whole-word text search.
+func (s *Session) toolFindReferences(args map[string]interface{}) (string, error) {
+	q, err := parseSymbolQuery(args)
+	if err != nil {
+		return "", err
+	}
+
+	note := ""
+	if s.useGoAnalysis(q) {
+		ctx, cancel := context.WithTimeout(s.turnContext(), goAnalysisTimeout)
+		defer cancel()
+
+		file, line := q.position()
+		defs, refs, err := s.golang.References(ctx, q.symbol, file, line)
+		if err == nil && len(defs) > 0 {
+			var scoped []goanalysis.Location
+			for _, ref := range refs {
+				if q.inScope(ref.Path) {
+					scoped = append(scoped, ref)
+				}
+			}
+			result := map[string]interface{}{
+				"symbol":      q.symbol,
+				"precision":   "types",
+				"definitions": defs,
+				"references":  scoped,
+				"count":       len(scoped),
+			}
+			if len(defs) > 1 {
+				result["note"] = "The symbol is ambiguous; pass 'path' and 'line' of the declaration, or qualify it as Type.Method or pkg.Name."
+			}
+			jsonResult, _ := json.Marshal(result)
+			return string(jsonResult), nil
+		}
+		note = goFallbackNote(err)
+	}
+
+	searchPath := s.projectRoot
+	if q.path != "" {
+		resolved, err := s.resolvePath(q.path)
+		if err != nil {
+			return "", fmt.Errorf("invalid path: %w", err)
+		}
+		searchPath = resolved
+	}
+
+	matches, err := s.grepSymbolReferences(q.name(), searchPath)
+	if err != nil {
+		return "", err
+	}
+
+	result := map[string]interface{}{
+		"symbol":    q.symbol,
+		"precision": "text",
+		"matches":   matches,
+		"count":     len(matches),
+	}
+	if note != "" {
+		result["note"] = note
+	}
+
+	jsonResult, _ := json.Marshal(result)
+	return string(jsonResult), nil
+}
+
+// toolFindImplementations lists the types implementing an interface, or the
+// interfaces a type implements
+func (s *Session) toolFindImplementations(args map[string]interface{}) (string, error) {
+	q, err := parseSymbolQuery(args)
+	if err != nil {
+		return "", err
+	}
+
+	note := ""
+	if s.useGoAnalysis(q) {
+		ctx, cancel := context.WithTimeout(s.turnContext(), goAnalysisTimeout)
+		defer cancel()
+
+		file, line := q.position()
+		defs, impls, err := s.golang.Implementations(ctx, q.symbol, file, line)
+		if err == nil && len(defs) > 0 {
+			result := map[string]interface{}{
+				"symbol":          q.symbol,
+				"precision":       "types",
+				"definitions":     defs,
+				"implementations": impls,
+				"count":           len(impls),
+			}
+			jsonResult, _ := json.Marshal(result)
+			return string(jsonResult), nil
+		}
+		note = goFallbackNote(err)
+	}
+
+	// Other languages declare what they implement: class X implements Y, extends Y
+	pattern := fmt.Sprintf(`\b(implements|extends)\b[^{]*\b%s\b`, regexp.QuoteMeta(q.name()))
+	matches, err := s.grepInScope(pattern, q)
+	if err != nil {
+		return "", err
+	}
+
+	result := map[string]interface{}{
+		"symbol":          q.symbol,
+		"precision":       "text",
+		"implementations": matches,
+		"count":           len(matches),
+	}
+	if note != "" {
+		result["note"] = note
+	}
+
+	jsonResult, _ := json.Marshal(result)
+	return string(jsonResult), nil
+}
+
+// toolCallHierarchy lists the callers and callees of a function
+func (s *Session) toolCallHierarchy(args map[string]interface{}) (string, error) {
+	q, err := parseSymbolQuery(args)
+	if err != nil {
+		return "", err
+	}
+
+	depth, err := intArg(args, "depth", 1)
+	if err != nil {
+		return "", err
+	}
+
+	direction := "both"
+	if d, ok := args["direction"].(string); ok && d != "" {
+		direction = d
+	}
+	if direction != "both" && direction != "incoming" && direction != "outgoing" {
+		return "", fmt.Errorf("direction must be 'incoming', 'outgoing' or 'both'")
+	}
+
+	note := ""
+	if s.useGoAnalysis(q) {
+		ctx, cancel := context.WithTimeout(s.turnContext(), goAnalysisTimeout)
+		defer cancel()
+
+		file, line := q.position()
+		hierarchies, err := s.golang.CallHierarchy(ctx, q.symbol, file, line, depth)
+		if err == nil && len(hierarchies) > 0 {
+			for i := range hierarchies {
+				if direction == "outgoing" {
+					hierarchies[i].Incoming = nil
+				}
+				if direction == "incoming" {
+					hierarchies[i].Outgoing = nil
+				}
+			}
+			result := map[string]interface{}{
+				"symbol":    q.symbol,
+				"precision": "types",
+				"functions": hierarchies,
+			}
+			jsonResult, _ := json.Marshal(result)
+			return string(jsonResult), nil
+		}
+		note = goFallbackNote(err)
+	}
+
+	// Without type information only call sites can be found, by name
+	name := regexp.QuoteMeta(q.name())
+	matches, err := s.grepInScope(fmt.Sprintf(`\b%s\s*\(`, name), q)
+	if err != nil {
+		return "", err
+	}
+	declaration := regexp.MustCompile(fmt.Sprintf(`\b(func|function|def|fn)\s+(\([^)]*\)\s*)?%s\b`, name))
+	var callers []map[string]interface{}
+	for _, m := range matches {
+		if !declaration.MatchString(m["content"].(string)) {
+			callers = append(callers, m)
+		}
+	}
+
+	if note == "" {
+		note = "Call sites were found by name; callees are not available without type information."
+	}
+	result := map[string]interface{}{
+		"symbol":    q.symbol,
+		"precision": "text",
+		"incoming":  callers,
+		"count":     len(callers),
+		"note":      note,
+	}
+
+	jsonResult, _ := json.Marshal(result)
+	return string(jsonResult), nil
+}
+
+// grepInScope runs an in-process regex search over the directory a query is limited to
+func (s *Session) grepInScope(pattern string, q symbolQuery) ([]map[string]interface{}, error) {
+	searchPath := s.projectRoot
+	if q.path != "" && q.line == 0 {
+		resolved, err := s.resolvePath(q.path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path: %w", err)
+		}
+		searchPath = resolved
+	}
+
+	lines, err := s.grepInProcess(pattern, searchPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []map[string]interface{}
+	for _, line := range lines {
+		if m := s.parseGrepMatch(line); m != nil {
+			matches = append(matches, m)
+		}
+	}
+	return matches, nil
+}
+
+// goFallbackNote explains why a Go query fell back to text search
+func goFallbackNote(err error) string {
+	if err != nil {
+		return fmt.Sprintf("Go type information unavailable (%v); results are text matches.", err)
+	}
+	return "Symbol not found by the Go type checker; results are text matches."
+}
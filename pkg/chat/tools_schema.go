@@ -0,0 +1,210 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/axon/pkg/policy"
+	"github.com/axon/pkg/schema"
+)
+
+// schemaDatabases returns the schema model of the project, rebuilt when the
+// index changes
+func (s *Session) schemaDatabases() ([]*schema.Database, error) {
+	if s.index == nil {
+		return nil, fmt.Errorf("project index not available")
+	}
+	gen := s.index.Generation()
+	if s.schema != nil && s.schemaGen == gen {
+		return s.schema, nil
+	}
+
+	files := s.index.GetAllFilePaths()
+	sort.Strings(files)
+	s.schema = schema.Build(s.projectRoot, files, s.sqliteFiles(files))
+	s.schemaGen = gen
+	return s.schema, nil
+}
+
+// sqliteFiles returns the configured SQLite databases, or the indexed files
+// that look like SQLite databases when none are configured
+func (s *Session) sqliteFiles(files []string) []string {
+	if len(s.cfg.Database.SQLite) > 0 {
+		return s.cfg.Database.SQLite
+	}
+	var found []string
+	for _, file := range files {
+		switch strings.ToLower(filepath.Ext(file)) {
+		case ".db", ".sqlite", ".sqlite3":
+			if schema.IsSQLiteFile(filepath.Join(s.projectRoot, file)) {
+				found = append(found, file)
+			}
+		}
+	}
+	return found
+}
+
+// toolDescribeSchema lists the tables of each schema source, or describes one
+// table in full
+func (s *Session) toolDescribeSchema(args map[string]interface{}) (string, error) {
+	databases, err := s.schemaDatabases()
+	if err != nil {
+		return "", err
+	}
+
+	dbName, _ := args["database"].(string)
+	dbName = strings.TrimSpace(dbName)
+	if dbName != "" {
+		db := findDatabase(databases, dbName)
+		if db == nil {
+			return "", fmt.Errorf("database not found: %s (available: %s)", dbName, strings.Join(databaseNames(databases), ", "))
+		}
+		databases = []*schema.Database{db}
+	}
+	if len(databases) == 0 {
+		return "", fmt.Errorf("no schema found: no SQL or goose migrations and no SQLite databases in the project (configure database.sqlite in .axon.yml)")
+	}
+
+	tableName, _ := args["table"].(string)
+	tableName = strings.TrimSpace(tableName)
+	if tableName == "" {
+		var summaries []map[string]interface{}
+		for _, db := range databases {
+			tables := make(map[string][]string, len(db.Tables))
+			for name, table := range db.Tables {
+				tables[name] = table.ColumnNames()
+			}
+			summary := map[string]interface{}{
+				"database": db.Name,
+				"kind":     db.Kind,
+				"tables":   tables,
+			}
+			if db.Error != "" {
+				summary["error"] = db.Error
+			}
+			summaries = append(summaries, summary)
+		}
+		jsonResult, _ := json.Marshal(map[string]interface{}{"databases": summaries})
+		return string(jsonResult), nil
+	}
+
+	var matches []map[string]interface{}
+	var available []string
+	for _, db := range databases {
+		if table, ok := db.Table(tableName); ok {
+			matches = append(matches, map[string]interface{}{
+				"database": db.Name,
+				"table":    table,
+			})
+		}
+		for _, name := range db.TableNames() {
+			available = append(available, db.Name+"."+name)
+		}
+	}
+	if len(matches) == 0 {
+		return "", fmt.Errorf("table not found: %s (available: %s)", tableName, strings.Join(available, ", "))
+	}
+
+	jsonResult, _ := json.Marshal(map[string]interface{}{"matches": matches})
+	return string(jsonResult), nil
+}
+
+// toolSQLQuery runs a read-only query against a SQLite database after
+// confirmation. Only databases listed in database.sqlite are queried: a
+// detected file may hold data the user never meant to expose, so it is
+// only offered as a candidate to configure.
+func (s *Session) toolSQLQuery(args map[string]interface{}) (string, error) {
+	query, ok := args["query"].(string)
+	if !ok || strings.TrimSpace(query) == "" {
+		return "", fmt.Errorf("query parameter is required")
+	}
+
+	databases, err := s.schemaDatabases()
+	if err != nil {
+		return "", err
+	}
+	var sqlite []*schema.Database
+	for _, db := range databases {
+		if db.Kind == "sqlite" {
+			sqlite = append(sqlite, db)
+		}
+	}
+	if len(s.cfg.Database.SQLite) == 0 {
+		if len(sqlite) == 0 {
+			return "", fmt.Errorf("no SQLite database configured (add one to database.sqlite in .axon.yml)")
+		}
+		return "", fmt.Errorf("no SQLite database configured; ask the user which of these to add to database.sqlite in .axon.yml: %s", strings.Join(databaseNames(sqlite), ", "))
+	}
+
+	var db *schema.Database
+	dbName, _ := args["database"].(string)
+	dbName = strings.TrimSpace(dbName)
+	switch {
+	case dbName != "":
+		db = findDatabase(sqlite, dbName)
+		if db == nil {
+			return "", fmt.Errorf("SQLite database not found: %s (available: %s)", dbName, strings.Join(databaseNames(sqlite), ", "))
+		}
+	case len(sqlite) == 1:
+		db = sqlite[0]
+	default:
+		return "", fmt.Errorf("database parameter is required (available: %s)", strings.Join(databaseNames(sqlite), ", "))
+	}
+
+	maxRows := s.cfg.Database.MaxRows
+	if maxRows <= 0 {
+		maxRows = 50
+	}
+	limit, err := intArg(args, "limit", maxRows)
+	if err != nil {
+		return "", err
+	}
+	if limit <= 0 || limit > maxRows {
+		limit = maxRows
+	}
+
+	description := fmt.Sprintf("Database: %s (read-only, at most %d rows)\nQuery: %s", db.Path, limit, strings.TrimSpace(query))
+	if refusal, err := s.authorize(policy.Request{Tool: "sql_query"}, "Run SQL query", description); err != nil || refusal != "" {
+		return refusal, err
+	}
+
+	result, err := schema.Query(s.turnContext(), filepath.Join(s.projectRoot, db.Path), query, limit)
+	if err != nil {
+		return "", err
+	}
+
+	response := map[string]interface{}{
+		"database": db.Name,
+		"columns":  nonNil(result.Columns),
+		"rows":     result.Rows,
+		"count":    len(result.Rows),
+	}
+	if result.Truncated {
+		response["truncated"] = true
+		response["note"] = fmt.Sprintf("Showing the first %d rows; add a WHERE clause or aggregate to narrow the result.", limit)
+	}
+	jsonResult, _ := json.Marshal(response)
+	return string(jsonResult), nil
+}
+
+// findDatabase finds a database by name or path
+func findDatabase(databases []*schema.Database, name string) *schema.Database {
+	for _, db := range databases {
+		if db.Name == name || db.Path == name || filepath.Base(db.Path) == name {
+			return db
+		}
+	}
+	return nil
+}
+
+// databaseNames returns the names of the databases
+func databaseNames(databases []*schema.Database) []string {
+	names := make([]string, len(databases))
+	for i, db := range databases {
+		names[i] = db.Name
+	}
+	return names
+}
@@ -0,0 +1,116 @@
+package chat
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/axon/pkg/fsctx"
+)
+
+// maxInProcessGrepMatches caps the output of the in-process grep fallback
+const maxInProcessGrepMatches = 500
+
+// toolSearchCode runs a BM25-ranked full-text search over the project index
+func (s *Session) toolSearchCode(args map[string]interface{}) (string, error) {
+	query, ok := args["query"].(string)
+	if !ok || strings.TrimSpace(query) == "" {
+		return "", fmt.Errorf("query argument is required")
+	}
+
+	if s.index == nil {
+		return "", fmt.Errorf("project index not available")
+	}
+
+	limit, err := intArg(args, "limit", 10)
+	if err != nil {
+		return "", err
+	}
+
+	searchPath := ""
+	if p, ok := args["path"].(string); ok {
+		searchPath = p
+	}
+
+	results := s.index.Search(query, limit, searchPath)
+
+	result := map[string]interface{}{
+		"query":   query,
+		"path":    searchPath,
+		"results": results,
+		"count":   len(results),
+	}
+	if len(results) == 0 {
+		result["note"] = "No matches. Try different words, an identifier name, or the grep tool for exact patterns."
+	}
+
+	jsonResult, _ := json.Marshal(result)
+	return string(jsonResult), nil
+}
+
+// grepInProcess searches files under searchPath for a regular expression without
+// relying on external binaries. Output lines use the grep format "path:line:text".
+func (s *Session) grepInProcess(pattern, searchPath string) ([]string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	var matches []string
+	err = filepath.Walk(searchPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if len(matches) >= maxInProcessGrepMatches {
+			return filepath.SkipAll
+		}
+
+		relPath, err := filepath.Rel(s.projectRoot, path)
+		if err != nil {
+			return nil
+		}
+		normalizedPath := strings.ReplaceAll(relPath, "\\", "/")
+		if normalizedPath != "." && fsctx.ShouldIgnore(normalizedPath, s.cfg) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() || info.Size() > fsctx.MaxFileSize {
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return nil
+		}
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 64*1024), fsctx.MaxFileSize)
+		lineNum := 0
+		for scanner.Scan() {
+			lineNum++
+			line := scanner.Text()
+			if strings.IndexByte(line, 0) >= 0 {
+				return nil // Binary file
+			}
+			if re.MatchString(line) {
+				matches = append(matches, fmt.Sprintf("%s:%d:%s", path, lineNum, line))
+				if len(matches) >= maxInProcessGrepMatches {
+					break
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
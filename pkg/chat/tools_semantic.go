@@ -0,0 +1,84 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/axon/pkg/semantic"
+)
+
+// semanticSearch brings the embeddings up to date and runs a semantic query
+func (s *Session) semanticSearch(ctx context.Context, query string, limit int) ([]semantic.Result, semantic.UpdateStats, error) {
+	if s.semantic == nil {
+		return nil, semantic.UpdateStats{}, fmt.Errorf("semantic search not available")
+	}
+
+	stats, err := s.semantic.Update(ctx)
+	if err != nil {
+		return nil, stats, err
+	}
+
+	results, err := s.semantic.Search(ctx, query, limit)
+	return results, stats, err
+}
+
+// toolSemanticSearch finds code by meaning using embeddings
+func (s *Session) toolSemanticSearch(args map[string]interface{}) (string, error) {
+	query, ok := args["query"].(string)
+	if !ok || strings.TrimSpace(query) == "" {
+		return "", fmt.Errorf("query argument is required")
+	}
+
+	limit, err := intArg(args, "limit", 8)
+	if err != nil {
+		return "", err
+	}
+
+	results, stats, err := s.semanticSearch(s.turnContext(), query, limit)
+	if err != nil {
+		return "", fmt.Errorf("semantic search failed: %w", err)
+	}
+
+	result := map[string]interface{}{
+		"query":          query,
+		"results":        results,
+		"count":          len(results),
+		"indexed_chunks": stats.Chunks,
+	}
+
+	jsonResult, _ := json.Marshal(result)
+	return string(jsonResult), nil
+}
+
+// findSemantic handles the /find command
+func (s *Session) findSemantic(query string) {
+	fmt.Fprintf(s.out, "\n%sSearching...%s\n", colorYellow, colorReset)
+
+	results, stats, err := s.semanticSearch(s.turnContext(), query, 8)
+	if err != nil {
+		fmt.Fprintf(s.out, "%sError:%s %v\n", colorRed+colorBold, colorReset, err)
+		return
+	}
+	if stats.Embedded > 0 {
+		fmt.Fprintf(s.out, "%sEmbedded %d new or changed chunks (%d total)%s\n", colorYellow, stats.Embedded, stats.Chunks, colorReset)
+	}
+
+	if len(results) == 0 {
+		fmt.Fprintf(s.out, "%sNo results.%s\n", colorYellow, colorReset)
+		return
+	}
+
+	fmt.Fprintln(s.out)
+	for _, r := range results {
+		location := fmt.Sprintf("%s:%d-%d", r.Path, r.Line, r.EndLine)
+		if r.Symbol != "" {
+			location += " " + r.Symbol
+		}
+		fmt.Fprintf(s.out, "%s%.3f%s  %s%s%s\n", colorCyan, r.Score, colorReset, colorBold, location, colorReset)
+		if first, _, _ := strings.Cut(r.Preview, "\n"); first != "" {
+			fmt.Fprintf(s.out, "       %s\n", strings.TrimSpace(first))
+		}
+	}
+}
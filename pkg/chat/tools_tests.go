@@ -0,0 +1,109 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/axon/pkg/policy"
+	"github.com/axon/pkg/testrun"
+)
+
+// Limits on the tests listed by run_tests
+const (
+	maxListedTests    = 20 // All tests are listed when there are at most this many
+	maxListedFailures = 50
+)
+
+// toolRunTests runs the project's tests with the detected runner after
+// confirmation and returns structured results
+func (s *Session) toolRunTests(args map[string]interface{}) (string, error) {
+	target, _ := args["target"].(string)
+	target = strings.TrimSpace(target)
+	name, _ := args["name"].(string)
+	name = strings.TrimSpace(name)
+	runnerName, _ := args["runner"].(string)
+	runnerName = strings.TrimSpace(runnerName)
+
+	if target != "" {
+		fullPath, err := s.resolvePath(target)
+		if err != nil {
+			return "", err
+		}
+		if target, err = filepath.Rel(s.projectRoot, fullPath); err != nil {
+			return "", err
+		}
+		target = filepath.ToSlash(target)
+	}
+
+	runner, err := testrun.Select(testrun.Detect(s.projectRoot), runnerName, target)
+	if err != nil {
+		return "", err
+	}
+
+	timeout := time.Duration(s.cfg.Tests.Timeout) * time.Second
+	scope := "all tests"
+	if target != "" {
+		scope = target
+	}
+	if name != "" {
+		scope += fmt.Sprintf(" matching %q", name)
+	}
+	description := fmt.Sprintf("Runner: %s\nTests: %s", runner.Name, scope)
+	if timeout > 0 {
+		description += fmt.Sprintf("\nTimeout: %s", timeout)
+	}
+	if refusal, err := s.authorize(policy.Request{Tool: "run_tests"}, "Run tests", description); err != nil || refusal != "" {
+		return refusal, err
+	}
+
+	result, err := testrun.Run(s.turnContext(), s.projectRoot, runner, testrun.Options{
+		Target:  target,
+		Name:    name,
+		Timeout: timeout,
+		Exec:    s.execOptions(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	response := map[string]interface{}{
+		"runner":    result.Runner,
+		"command":   result.Command,
+		"exit_code": result.ExitCode,
+		"success":   result.ExitCode == 0 && result.Failed == 0 && !result.TimedOut,
+		"passed":    result.Passed,
+		"failed":    result.Failed,
+		"skipped":   result.Skipped,
+		"duration":  result.Duration,
+	}
+	if result.TimedOut {
+		response["timed_out"] = true
+		response["note"] = fmt.Sprintf("The test run was stopped after %s; run a narrower 'target' or 'name'.", timeout)
+	}
+
+	if len(result.Tests) <= maxListedTests {
+		response["tests"] = result.Tests
+	} else {
+		failures := result.Failures()
+		if len(failures) > maxListedFailures {
+			failures = failures[:maxListedFailures]
+			response["failures_truncated"] = true
+		}
+		if failures == nil {
+			failures = []testrun.TestCase{}
+		}
+		response["failures"] = failures
+	}
+
+	// The runner's own output explains failures the reports don't cover:
+	// build errors, crashes, missing dependencies
+	if result.Output != "" && (result.TimedOut || len(result.Tests) == 0 || (result.ExitCode != 0 && result.Failed == 0)) {
+		response["output"] = result.Output
+	}
+
+	jsonResult, _ := json.Marshal(response)
+	return string(jsonResult), nil
+}
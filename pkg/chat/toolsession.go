@@ -0,0 +1,50 @@
+package chat
+
+import (
+	"io"
+	"slices"
+
+	"github.com/axon/pkg/goanalysis"
+	"github.com/axon/pkg/indexer"
+	"github.com/axon/pkg/lsp"
+	"github.com/axon/pkg/mcp"
+	"github.com/axon/pkg/policy"
+	"github.com/axon/pkg/procs"
+	"github.com/axon/pkg/project"
+)
+
+// readOnlyTools are the tools offered to clients that query the project
+// without a conversation: the index-backed navigation tools and the file
+// readers confined to the project root
+var readOnlyTools = []string{
+	"search_symbols", "get_file_symbols", "get_tree_list", "find_symbol_references", "get_project_stats",
+	"read_file", "read_file_lines", "list_directory", "find_files", "find_files_by_extension", "get_file_info",
+}
+
+// IsReadOnlyTool reports whether a tool is one of the read-only tools a tool
+// session offers
+func IsReadOnlyTool(name string) bool {
+	return slices.Contains(readOnlyTools, name)
+}
+
+// NewToolSession creates a session without a model that only runs tools for
+// another client, such as an MCP client or the HTTP API. Calls no permission
+// rule decides run without asking, as the client confirms them itself.
+func NewToolSession(projectRoot string, cfg *project.Config, projectIndex *indexer.Index) *Session {
+	s := &Session{
+		projectRoot: projectRoot,
+		cfg:         cfg,
+		index:       projectIndex,
+		policy:      newPolicy(cfg),
+		audit:       policy.OpenAudit(projectRoot),
+		procs:       procs.NewManager(),
+		mcp:         mcp.NewManager(projectRoot, nil, 0),
+		unattended:  true,
+		out:         io.Discard, // Stdout may carry a protocol
+	}
+	if goanalysis.IsGoProject(projectRoot) {
+		s.golang = goanalysis.New(projectRoot)
+	}
+	s.lsp = lsp.NewManager(projectRoot, cfg.LSP.Servers)
+	return s
+}
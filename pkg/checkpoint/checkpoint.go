@@ -0,0 +1,280 @@
+// Package checkpoint snapshots the project's working tree into a private git
+// repository under .axon/checkpoints, so edits made by the assistant can be
+// diffed and rolled back without touching the project's own git history,
+// index or stash.
+package checkpoint
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dir is the checkpoint repository, relative to the project root
+const Dir = ".axon/checkpoints"
+
+// Checkpoint is one snapshot of the working tree
+type Checkpoint struct {
+	ID      string    `json:"id"` // Abbreviated commit hash
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+// Store creates and restores checkpoints of a project
+type Store struct {
+	root   string
+	gitDir string
+}
+
+// Open opens the checkpoint repository of a project, creating it on first
+// use. ignore lists extra gitignore patterns (e.g. context.ignore) excluded
+// from snapshots on top of the project's .gitignore files.
+func Open(projectRoot string, ignore []string) (*Store, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("checkpoints require git: %w", err)
+	}
+	s := &Store{root: projectRoot, gitDir: filepath.Join(projectRoot, Dir)}
+	if _, err := os.Stat(filepath.Join(s.gitDir, "HEAD")); err != nil {
+		if err := os.MkdirAll(s.gitDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create checkpoint directory: %w", err)
+		}
+		if _, err := s.git("init", "-q"); err != nil {
+			return nil, err
+		}
+	}
+
+	// Rewritten on every open so changes to the ignore list apply
+	exclude := append([]string{"/.axon/", ".git"}, ignore...)
+	if err := os.MkdirAll(filepath.Join(s.gitDir, "info"), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(s.gitDir, "info", "exclude"), []byte(strings.Join(exclude, "\n")+"\n"), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write checkpoint excludes: %w", err)
+	}
+	return s, nil
+}
+
+// Create snapshots the working tree
+func (s *Store) Create(message string) (Checkpoint, error) {
+	tree, err := s.snapshotTree()
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	args := []string{"commit-tree", tree, "-m", message}
+	if parent, err := s.git("rev-parse", "-q", "--verify", "HEAD"); err == nil {
+		args = append(args, "-p", parent)
+	}
+	hash, err := s.git(args...)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	if _, err := s.git("update-ref", "HEAD", hash); err != nil {
+		return Checkpoint{}, err
+	}
+	return Checkpoint{ID: shortID(hash), Message: message, Time: time.Now()}, nil
+}
+
+// List returns the checkpoints, newest first
+func (s *Store) List(limit int) ([]Checkpoint, error) {
+	if _, err := s.git("rev-parse", "-q", "--verify", "HEAD"); err != nil {
+		return []Checkpoint{}, nil
+	}
+	args := []string{"log", "--format=%H%x00%ct%x00%s"}
+	if limit > 0 {
+		args = append(args, "-n", strconv.Itoa(limit))
+	}
+	out, err := s.git(args...)
+	if err != nil {
+		return nil, err
+	}
+	checkpoints := []Checkpoint{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, "\x00", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		seconds, _ := strconv.ParseInt(fields[1], 10, 64)
+		checkpoints = append(checkpoints, Checkpoint{ID: shortID(fields[0]), Message: fields[2], Time: time.Unix(seconds, 0)})
+	}
+	return checkpoints, nil
+}
+
+// Diff returns the unified diff from a checkpoint to another checkpoint, or
+// to the current working tree when to is empty. With stat, it returns a
+// diffstat instead.
+func (s *Store) Diff(from, to string, stat bool) (string, error) {
+	fromHash, err := s.resolve(from)
+	if err != nil {
+		return "", err
+	}
+	var toHash string
+	if to == "" {
+		toHash, err = s.snapshotTree()
+	} else {
+		toHash, err = s.resolve(to)
+	}
+	if err != nil {
+		return "", err
+	}
+	args := []string{"diff", "--no-color", "--no-ext-diff"}
+	if stat {
+		args = append(args, "--stat")
+	}
+	return s.git(append(args, fromHash, toHash)...)
+}
+
+// ChangedFiles returns the files that differ between two checkpoints, or
+// between a checkpoint and the working tree when to is empty
+func (s *Store) ChangedFiles(from, to string) ([]string, error) {
+	fromHash, err := s.resolve(from)
+	if err != nil {
+		return nil, err
+	}
+	var toHash string
+	if to == "" {
+		toHash, err = s.snapshotTree()
+	} else {
+		toHash, err = s.resolve(to)
+	}
+	if err != nil {
+		return nil, err
+	}
+	out, err := s.git("diff", "--name-only", "--no-renames", fromHash, toHash)
+	if err != nil {
+		return nil, err
+	}
+	return splitLines(out), nil
+}
+
+// Restore rolls the working tree back to a checkpoint: changed and deleted
+// files are restored and files created since are removed. The current state
+// is checkpointed first, so a restore can itself be undone. It returns the
+// checkpoint of the state before the restore and the files it changed.
+func (s *Store) Restore(id string) (Checkpoint, []string, error) {
+	target, err := s.resolve(id)
+	if err != nil {
+		return Checkpoint{}, nil, err
+	}
+	before, err := s.Create("Before rollback to " + shortID(target))
+	if err != nil {
+		return Checkpoint{}, nil, err
+	}
+
+	changed, err := s.ChangedFiles(target, before.ID)
+	if err != nil {
+		return before, nil, err
+	}
+	if len(changed) == 0 {
+		return before, changed, nil
+	}
+
+	out, err := s.git("diff", "--name-only", "--no-renames", "--diff-filter=A", target, before.ID)
+	if err != nil {
+		return before, nil, err
+	}
+	added := splitLines(out)
+	for _, file := range added {
+		path := filepath.Join(s.root, filepath.FromSlash(file))
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return before, nil, fmt.Errorf("failed to remove %s: %w", file, err)
+		}
+		// Remove directories left empty, up to the project root
+		for dir := filepath.Dir(path); dir != s.root && strings.HasPrefix(dir, s.root); dir = filepath.Dir(dir) {
+			if os.Remove(dir) != nil {
+				break
+			}
+		}
+	}
+
+	// Restore the files that existed in the checkpoint
+	var paths []string
+	for _, file := range changed {
+		if !slices.Contains(added, file) {
+			paths = append(paths, file)
+		}
+	}
+	if len(paths) > 0 {
+		if _, err := s.git(append([]string{"checkout", target, "--"}, paths...)...); err != nil {
+			return before, nil, err
+		}
+	}
+	return before, changed, nil
+}
+
+// snapshotTree stages the working tree in the checkpoint index and returns
+// the tree hash
+func (s *Store) snapshotTree() (string, error) {
+	if _, err := s.git("add", "-A", "--ignore-errors", "."); err != nil {
+		return "", err
+	}
+	return s.git("write-tree")
+}
+
+// resolve turns a checkpoint ID into a full commit hash
+func (s *Store) resolve(id string) (string, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return "", fmt.Errorf("checkpoint ID is required")
+	}
+	hash, err := s.git("rev-parse", "-q", "--verify", id+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("unknown checkpoint: %s", id)
+	}
+	return hash, nil
+}
+
+// git runs a git command against the checkpoint repository with the project
+// as its work tree. User configuration that could interfere (hooks, signing,
+// line ending conversion) is overridden.
+func (s *Store) git(args ...string) (string, error) {
+	base := []string{
+		"--git-dir=" + s.gitDir,
+		"--work-tree=" + s.root,
+		"-c", "core.autocrlf=false",
+		"-c", "core.safecrlf=false",
+		"-c", "core.hooksPath=/dev/null",
+		"-c", "commit.gpgSign=false",
+		"-c", "core.quotePath=false",
+	}
+	cmd := exec.Command("git", append(base, args...)...)
+	cmd.Dir = s.root
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=axon", "GIT_AUTHOR_EMAIL=axon@localhost",
+		"GIT_COMMITTER_NAME=axon", "GIT_COMMITTER_EMAIL=axon@localhost",
+	)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// shortID abbreviates a commit hash
+func shortID(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
+
+// splitLines splits command output into non-empty lines
+func splitLines(out string) []string {
+	var lines []string
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,113 @@
+package checkpoint
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, root, name, content string) {
+	t.Helper()
+	full := filepath.Join(root, name)
+	if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, root, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(root, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestCheckpoints(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	root := t.TempDir()
+	writeFile(t, root, "main.go", "package main\n")
+	writeFile(t, root, "lib/util.go", "package lib\n")
+	writeFile(t, root, "node_modules/x/index.js", "ignored\n")
+	writeFile(t, root, ".gitignore", "*.log\n")
+	writeFile(t, root, "debug.log", "ignored\n")
+
+	store, err := Open(root, []string{"node_modules/"})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	first, err := store.Create("Before fix")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	// Edit, delete and create files
+	writeFile(t, root, "main.go", "package main\n\nfunc main() {}\n")
+	os.Remove(filepath.Join(root, "lib/util.go"))
+	writeFile(t, root, "pkg/new/new.go", "package new\n")
+	writeFile(t, root, "node_modules/x/index.js", "changed\n")
+
+	changed, err := store.ChangedFiles(first.ID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"lib/util.go", "main.go", "pkg/new/new.go"}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("ChangedFiles = %v, want %v", changed, want)
+	}
+	diff, err := store.Diff(first.ID, "", false)
+	if err != nil || !strings.Contains(diff, "+func main() {}") {
+		t.Errorf("Unexpected diff (%v):\n%s", err, diff)
+	}
+
+	second, err := store.Create("Fix round 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, err := store.List(0)
+	if err != nil || len(list) != 2 || list[0].ID != second.ID || list[1].Message != "Before fix" {
+		t.Fatalf("Unexpected checkpoints (%v): %+v", err, list)
+	}
+
+	before, restored, err := store.Restore(first.ID)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if !reflect.DeepEqual(restored, changed) {
+		t.Errorf("Restore changed %v, want %v", restored, changed)
+	}
+	if got := readFile(t, root, "main.go"); got != "package main\n" {
+		t.Errorf("main.go not restored: %q", got)
+	}
+	if got := readFile(t, root, "lib/util.go"); got != "package lib\n" {
+		t.Errorf("lib/util.go not restored: %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(root, "pkg")); !os.IsNotExist(err) {
+		t.Error("Expected the created file and its empty directories to be removed")
+	}
+	if got := readFile(t, root, "node_modules/x/index.js"); got != "changed\n" {
+		t.Errorf("Ignored file was touched: %q", got)
+	}
+	if got := readFile(t, root, "debug.log"); got != "ignored\n" {
+		t.Errorf("Ignored file was touched: %q", got)
+	}
+
+	// The rollback itself can be undone
+	if _, _, err := store.Restore(before.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, root, "pkg/new/new.go"); got != "package new\n" {
+		t.Errorf("Undo of rollback failed: %q", got)
+	}
+
+	if _, _, err := store.Restore("nope"); err == nil {
+		t.Error("Expected an error for an unknown checkpoint")
+	}
+}
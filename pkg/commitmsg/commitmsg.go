@@ -0,0 +1,223 @@
+// Package commitmsg writes conventional commit messages for staged changes.
+// The model answers with constrained JSON (type, scope, subject, body);
+// diffs too large for one request are summarized per group of files first.
+package commitmsg
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/axon/pkg/git"
+	"github.com/axon/pkg/llm"
+)
+
+// Limits on the diff text sent to the model
+const (
+	MaxPrompt  = 12000 // Bytes of patch per request
+	MaxSubject = 72    // Characters of the header line
+)
+
+// Types are the conventional commit types the model chooses from
+var Types = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+
+// Message is a conventional commit message
+type Message struct {
+	Type    string `json:"type"`
+	Scope   string `json:"scope"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// Header returns the first line, "type(scope): subject"
+func (m Message) Header() string {
+	if m.Scope == "" {
+		return m.Type + ": " + m.Subject
+	}
+	return m.Type + "(" + m.Scope + "): " + m.Subject
+}
+
+// String returns the message as passed to git commit
+func (m Message) String() string {
+	if m.Body == "" {
+		return m.Header() + "\n"
+	}
+	return m.Header() + "\n\n" + m.Body + "\n"
+}
+
+// Schema is the JSON schema the model's answer is constrained to
+func Schema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"type": map[string]interface{}{
+				"type": "string",
+				"enum": Types,
+			},
+			"scope": map[string]interface{}{
+				"type":        "string",
+				"description": "Area of the code base, e.g. a package or module name; empty if the change is broad",
+			},
+			"subject": map[string]interface{}{
+				"type":        "string",
+				"description": "Imperative summary, lower case, no trailing period",
+			},
+			"body": map[string]interface{}{
+				"type":        "string",
+				"description": "What changed and why, wrapped at 72 columns; empty for trivial changes",
+			},
+		},
+		"required": []string{"type", "scope", "subject", "body"},
+	}
+}
+
+// Model is the part of the LLM client Generate uses
+type Model interface {
+	Chat(ctx context.Context, messages []llm.Message) (string, error)
+	ChatJSON(ctx context.Context, messages []llm.Message, name string, schema map[string]interface{}, v interface{}) error
+}
+
+// Chunk groups file patches into chunks of at most max bytes, truncating
+// patches that are larger on their own
+func Chunk(files []git.FilePatch, max int) [][]git.FilePatch {
+	var chunks [][]git.FilePatch
+	var current []git.FilePatch
+	size := 0
+	for _, f := range files {
+		if len(f.Patch) > max {
+			f.Patch = f.Patch[:max] + "\n... [truncated]\n"
+		}
+		if len(current) > 0 && size+len(f.Patch) > max {
+			chunks = append(chunks, current)
+			current, size = nil, 0
+		}
+		current = append(current, f)
+		size += len(f.Patch)
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+// systemPrompt tells the model how to write the message
+const systemPrompt = "You write git commit messages in the Conventional Commits format for the staged changes you are given.\n" +
+	"Pick the type that fits the change as a whole: feat for new behavior, fix for bug fixes, refactor when behavior is unchanged, docs, test, build, ci, perf, style or chore otherwise.\n" +
+	"The scope is the main package, module or area touched, in lower case; leave it empty when the change spans the whole project.\n" +
+	"The subject is an imperative summary in lower case without a trailing period, at most 60 characters, e.g. \"add rate limiting to the login route\".\n" +
+	"The body explains what changed and why in plain sentences or '- ' bullets wrapped at 72 columns; leave it empty for small, self-explanatory changes.\n" +
+	"Describe only what the diff shows. Do not mention file names unless they matter."
+
+// Generate writes a commit message for a staged diff. stat lists the changed
+// files with their line counts. A diff that fits in one request is sent
+// whole; otherwise each chunk of files is summarized first and the message is
+// written from the summaries.
+func Generate(ctx context.Context, model Model, stat string, files []git.FilePatch) (Message, error) {
+	if len(files) == 0 {
+		return Message{}, fmt.Errorf("no staged changes")
+	}
+
+	var changes string
+	if size := patchSize(files); size <= MaxPrompt {
+		changes = "Staged diff:\n" + joinPatches(files)
+	} else {
+		chunks := Chunk(files, MaxPrompt)
+		var summaries []string
+		for i, chunk := range chunks {
+			summary, err := summarize(ctx, model, chunk)
+			if err != nil {
+				return Message{}, fmt.Errorf("failed to summarize part %d of %d of the diff: %w", i+1, len(chunks), err)
+			}
+			summaries = append(summaries, summary)
+		}
+		changes = "The diff is too large to show; summaries of its parts:\n\n" + strings.Join(summaries, "\n\n")
+	}
+
+	messages := []llm.Message{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: "Changed files:\n" + stat + "\n\n" + changes},
+	}
+	var msg Message
+	if err := model.ChatJSON(ctx, messages, "commit_message", Schema(), &msg); err != nil {
+		return Message{}, err
+	}
+	msg = Normalize(msg)
+	if msg.Subject == "" {
+		return Message{}, fmt.Errorf("the model returned an empty subject")
+	}
+	return msg, nil
+}
+
+// summarize asks for a short summary of a chunk of file patches
+func summarize(ctx context.Context, model Model, chunk []git.FilePatch) (string, error) {
+	var paths []string
+	for _, f := range chunk {
+		paths = append(paths, f.Path)
+	}
+	messages := []llm.Message{
+		{Role: "system", Content: "You summarize parts of a git diff for someone writing the commit message. Answer with at most five short '- ' bullets about what changed and why; no preamble."},
+		{Role: "user", Content: joinPatches(chunk)},
+	}
+	summary, err := model.Chat(ctx, messages)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(paths, ", ") + ":\n" + strings.TrimSpace(summary), nil
+}
+
+// patchSize returns the total size of the patches
+func patchSize(files []git.FilePatch) int {
+	size := 0
+	for _, f := range files {
+		size += len(f.Patch)
+	}
+	return size
+}
+
+// joinPatches concatenates patches
+func joinPatches(files []git.FilePatch) string {
+	var b strings.Builder
+	for _, f := range files {
+		b.WriteString(f.Patch)
+	}
+	return b.String()
+}
+
+// headerPrefix matches a "type(scope): " prefix the model repeated in the
+// subject
+var headerPrefix = regexp.MustCompile(`^[a-z]+(\([^)]*\))?!?:\s*`)
+
+// Normalize tidies a message from the model: a known type, a lower-case
+// scope, and a subject without a repeated prefix or trailing period that
+// fits the header in MaxSubject characters
+func Normalize(m Message) Message {
+	m.Type = strings.ToLower(strings.TrimSpace(m.Type))
+	if !slices.Contains(Types, m.Type) {
+		m.Type = "chore"
+	}
+	m.Scope = strings.ToLower(strings.Trim(strings.TrimSpace(m.Scope), "()"))
+
+	subject := strings.TrimSpace(strings.SplitN(strings.TrimSpace(m.Subject), "\n", 2)[0])
+	subject = headerPrefix.ReplaceAllString(subject, "")
+	subject = strings.TrimRight(subject, ". ")
+	// Lower-case the first word unless it is an acronym or identifier
+	if first, rest, _ := strings.Cut(subject, " "); first != "" && strings.ToUpper(first[:1]) == first[:1] && strings.ToLower(first[1:]) == first[1:] {
+		subject = strings.ToLower(first[:1]) + first[1:]
+		if rest != "" {
+			subject += " " + rest
+		}
+	}
+	m.Subject = subject
+	if over := len(m.Header()) - MaxSubject; over > 0 && over < len(m.Subject) {
+		cut := strings.LastIndex(m.Subject[:len(m.Subject)-over], " ")
+		if cut <= 0 {
+			cut = len(m.Subject) - over
+		}
+		m.Subject = strings.TrimRight(m.Subject[:cut], " ,;:-")
+	}
+
+	m.Body = strings.TrimSpace(m.Body)
+	return m
+}
@@ -0,0 +1,137 @@
+package commitmsg
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/axon/pkg/git"
+	"github.com/axon/pkg/llm"
+)
+
+func TestChunk(t *testing.T) {
+	files := []git.FilePatch{
+		{Path: "a", Patch: strings.Repeat("a", 40)},
+		{Path: "b", Patch: strings.Repeat("b", 40)},
+		{Path: "c", Patch: strings.Repeat("c", 150)},
+		{Path: "d", Patch: strings.Repeat("d", 10)},
+	}
+	chunks := Chunk(files, 100)
+	if len(chunks) != 3 || len(chunks[0]) != 2 || len(chunks[1]) != 1 || len(chunks[2]) != 1 {
+		t.Fatalf("unexpected chunks: %v", chunks)
+	}
+	if !strings.HasSuffix(chunks[1][0].Patch, "[truncated]\n") {
+		t.Errorf("oversized patch was not truncated")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in     Message
+		header string
+	}{
+		{Message{Type: "Feat", Scope: "(Chat)", Subject: "Add /commit command."}, "feat(chat): add /commit command"},
+		{Message{Type: "feature", Subject: "fix(api): handle empty body"}, "chore: handle empty body"},
+		{Message{Type: "fix", Subject: "HTTP client ignores proxy"}, "fix: HTTP client ignores proxy"},
+		{Message{Type: "docs", Scope: "readme", Subject: "describe the commit command, the prepare-commit-msg hook and the chunking of large diffs"}, "docs(readme): describe the commit command, the prepare-commit-msg hook"},
+	}
+	for _, tt := range tests {
+		got := Normalize(tt.in)
+		if got.Header() != tt.header {
+			t.Errorf("Normalize(%+v).Header() = %q, want %q", tt.in, got.Header(), tt.header)
+		}
+		if len(got.Header()) > MaxSubject {
+			t.Errorf("header %q is longer than %d", got.Header(), MaxSubject)
+		}
+	}
+}
+
+func TestMessageString(t *testing.T) {
+	m := Message{Type: "fix", Scope: "git", Subject: "follow renames", Body: "Log used to stop at the rename."}
+	if got := m.String(); got != "fix(git): follow renames\n\nLog used to stop at the rename.\n" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := (Message{Type: "chore", Subject: "bump deps"}).String(); got != "chore: bump deps\n" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+// fakeModel records requests and answers with a fixed message
+type fakeModel struct {
+	summaries int
+	prompt    string
+}
+
+func (f *fakeModel) Chat(ctx context.Context, messages []llm.Message) (string, error) {
+	f.summaries++
+	return "- changed things", nil
+}
+
+func (f *fakeModel) ChatJSON(ctx context.Context, messages []llm.Message, name string, schema map[string]interface{}, v interface{}) error {
+	f.prompt = messages[len(messages)-1].Content
+	return json.Unmarshal([]byte(`{"type":"feat","scope":"git","subject":"Add blame.","body":""}`), v)
+}
+
+const samplePatch = `diff --git a/pkg/a.go b/pkg/a.go
+index 1111111..2222222 100644
+--- a/pkg/a.go
++++ b/pkg/a.go
+@@ -1 +1 @@
+-old
++new
+`
+
+func TestGenerate(t *testing.T) {
+	model := &fakeModel{}
+	msg, err := Generate(context.Background(), model, "pkg/a.go +1 -1", git.SplitPatch(samplePatch))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Header() != "feat(git): add blame" || model.summaries != 0 || !strings.Contains(model.prompt, "+new") {
+		t.Errorf("unexpected result %q after %d summaries, prompt:\n%s", msg.Header(), model.summaries, model.prompt)
+	}
+
+	// A large diff is summarized per chunk first
+	model = &fakeModel{}
+	var files []git.FilePatch
+	for _, name := range []string{"a", "b", "c"} {
+		files = append(files, git.FilePatch{Path: name, Patch: strings.Repeat("+x\n", MaxPrompt/4)})
+	}
+	if _, err := Generate(context.Background(), model, "a, b, c", files); err != nil {
+		t.Fatal(err)
+	}
+	if model.summaries != 3 || strings.Contains(model.prompt, "+x") || !strings.Contains(model.prompt, "- changed things") {
+		t.Errorf("expected 3 summaries in the prompt, got %d:\n%s", model.summaries, model.prompt)
+	}
+}
+
+func TestInstallHook(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hooks", HookName)
+	if err := InstallHook(path, "/usr/local/bin/axon"); err != nil {
+		t.Fatal(err)
+	}
+	script, _ := os.ReadFile(path)
+	if !strings.Contains(string(script), `'/usr/local/bin/axon' commit --message-file "$1"`) {
+		t.Errorf("unexpected hook:\n%s", script)
+	}
+	// Reinstalling over our own hook works, over someone else's does not
+	if err := InstallHook(path, "/usr/local/bin/axon"); err != nil {
+		t.Errorf("reinstall failed: %v", err)
+	}
+	os.WriteFile(path, []byte("#!/bin/sh\nexit 0\n"), 0755)
+	if err := InstallHook(path, "/usr/local/bin/axon"); err == nil {
+		t.Error("expected an error for a foreign hook")
+	}
+
+	msgFile := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+	os.WriteFile(msgFile, []byte("\n# Please enter the commit message\n"), 0644)
+	if err := WriteMessageFile(msgFile, Message{Type: "fix", Subject: "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(msgFile); string(got) != "fix: x\n\n# Please enter the commit message\n" {
+		t.Errorf("message file = %q", got)
+	}
+}
@@ -0,0 +1,61 @@
+package commitmsg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// HookName is the git hook axon installs
+const HookName = "prepare-commit-msg"
+
+// hookMarker identifies a hook written by InstallHook
+const hookMarker = "# Installed by axon commit --hook"
+
+// hookScript runs axon when git is about to open the editor for a new
+// commit, i.e. without -m, -F, a template, a merge, a squash or --amend. A
+// failure never blocks the commit.
+const hookScript = `#!/bin/sh
+%s: suggests a commit message for the staged changes
+case "$2" in
+"") ;;
+*) exit 0 ;;
+esac
+%s commit --message-file "$1" </dev/null || true
+exit 0
+`
+
+// InstallHook writes the prepare-commit-msg hook at path, running the axon
+// binary at executable. An existing hook that axon did not write is left
+// alone.
+func InstallHook(path, executable string) error {
+	existing, err := os.ReadFile(path)
+	if err == nil && !strings.Contains(string(existing), hookMarker) {
+		return fmt.Errorf("%s already exists; add '%s commit --message-file \"$1\"' to it yourself", path, executable)
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	script := fmt.Sprintf(hookScript, hookMarker, shellQuote(executable))
+	return os.WriteFile(path, []byte(script), 0755)
+}
+
+// WriteMessageFile puts a message at the top of the file git opens in the
+// editor, keeping the comments git wrote below it
+func WriteMessageFile(path string, m Message) error {
+	existing, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return os.WriteFile(path, []byte(m.String()+string(existing)), 0644)
+}
+
+// shellQuote quotes a word for sh
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,36 @@
+package execx
+
+import "strings"
+
+// DefaultEnv lists the environment variables passed to commands. Entries
+// ending in * match a prefix. Everything else, notably API keys and cloud
+// credentials, is dropped unless configured.
+var DefaultEnv = []string{
+	"PATH", "HOME", "USER", "LOGNAME", "SHELL", "TERM", "TZ", "TMPDIR", "LANG", "LANGUAGE", "LC_*",
+	"XDG_CONFIG_HOME", "XDG_CACHE_HOME", "XDG_DATA_HOME", "XDG_RUNTIME_DIR",
+	"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy",
+	"SSL_CERT_FILE", "SSL_CERT_DIR",
+	"GOPATH", "GOROOT", "GOBIN", "GOCACHE", "GOMODCACHE", "GOFLAGS", "GOPROXY", "GOPRIVATE",
+	"GONOPROXY", "GONOSUMDB", "GOSUMDB", "GOTOOLCHAIN", "CGO_ENABLED",
+	"NODE_ENV", "NODE_OPTIONS", "NODE_PATH", "NVM_DIR", "npm_config_*", "NPM_CONFIG_*",
+	"COMPOSER_HOME", "COMPOSER_CACHE_DIR", "PHP_INI_SCAN_DIR",
+	"PYTHONPATH", "VIRTUAL_ENV", "CONDA_PREFIX", "PIP_INDEX_URL",
+	"JAVA_HOME", "CARGO_HOME", "RUSTUP_HOME",
+}
+
+// FilterEnv keeps the variables of environ allowed by DefaultEnv or extra.
+// An extra entry "*" keeps everything.
+func FilterEnv(environ []string, extra []string) []string {
+	allowed := append(append([]string{}, DefaultEnv...), extra...)
+	var kept []string
+	for _, kv := range environ {
+		name, _, _ := strings.Cut(kv, "=")
+		for _, pattern := range allowed {
+			if pattern == "*" || pattern == name || (strings.HasSuffix(pattern, "*") && strings.HasPrefix(name, strings.TrimSuffix(pattern, "*"))) {
+				kept = append(kept, kv)
+				break
+			}
+		}
+	}
+	return kept
+}
@@ -0,0 +1,155 @@
+// Package execx runs shell commands on behalf of the assistant with a
+// timeout, cancellation, a bounded output buffer, a filtered environment and
+// optional Linux sandboxing (bubblewrap or user namespaces) that removes
+// network access and makes the filesystem read-only outside the project.
+package execx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Sandbox modes
+const (
+	SandboxOff        = "off"        // Run commands directly
+	SandboxAuto       = "auto"       // Best available sandbox, unsandboxed with a warning if none
+	SandboxBwrap      = "bwrap"      // Require bubblewrap
+	SandboxNamespaces = "namespaces" // Require user, mount and network namespaces
+)
+
+// Defaults used when Options leaves a limit unset
+const (
+	DefaultTimeout   = 2 * time.Minute
+	DefaultMaxOutput = 30000
+)
+
+// Options configures how a command runs
+type Options struct {
+	Dir       string        // Working directory
+	Timeout   time.Duration // Zero uses DefaultTimeout
+	MaxOutput int           // Bytes of combined output kept; zero uses DefaultMaxOutput
+	Env       []string      // Variables passed through on top of DefaultEnv ("*" passes everything)
+	Sandbox   string        // One of the Sandbox modes; empty means off
+	Network   bool          // Keep network access inside the sandbox
+	Writable  []string      // Paths writable inside the sandbox (typically the project root)
+}
+
+// Result is the outcome of a command
+type Result struct {
+	Output    string        `json:"output"` // Combined stdout and stderr, head and tail kept when truncated
+	ExitCode  int           `json:"exit_code"`
+	TimedOut  bool          `json:"timed_out,omitempty"`
+	Cancelled bool          `json:"cancelled,omitempty"`
+	Truncated bool          `json:"truncated,omitempty"`
+	Duration  time.Duration `json:"-"`
+	Sandbox   string        `json:"sandbox"` // none, bwrap or namespaces
+	Warning   string        `json:"warning,omitempty"`
+}
+
+// Run runs a command with sh -c. Standard input is empty, so commands that
+// prompt fail instead of waiting forever. The command and everything it
+// starts are killed when the timeout expires or ctx is cancelled.
+func Run(ctx context.Context, command string, opts Options) (*Result, error) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.MaxOutput <= 0 {
+		opts.MaxOutput = DefaultMaxOutput
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	cmd, method, warning, err := Command(ctx, command, opts)
+	if err != nil {
+		return nil, err
+	}
+	cmd.WaitDelay = 2 * time.Second // Don't wait on descendants that keep the pipes open
+
+	output := NewOutputBuffer(opts.MaxOutput)
+	cmd.Stdout, cmd.Stderr = output, output
+
+	start := time.Now()
+	runErr := cmd.Run()
+	result := &Result{
+		Duration: time.Since(start),
+		Sandbox:  method,
+		Warning:  warning,
+	}
+	if runErr != nil {
+		var exitErr *exec.ExitError
+		switch {
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			result.TimedOut, result.ExitCode = true, -1
+		case errors.Is(ctx.Err(), context.Canceled):
+			result.Cancelled, result.ExitCode = true, -1
+		case errors.As(runErr, &exitErr):
+			result.ExitCode = exitErr.ExitCode()
+		case errors.Is(runErr, exec.ErrWaitDelay):
+			// Exited, but a background child still held the output open
+		default:
+			return nil, fmt.Errorf("failed to run command: %w", runErr)
+		}
+	}
+	result.Output, result.Truncated = output.String(), output.Truncated()
+	return result, nil
+}
+
+// Command prepares a command the way Run runs it, without starting it or
+// applying a timeout: sandboxed according to opts, with the filtered
+// environment, and in its own process group that is killed when ctx is
+// done. It also returns the sandbox method used and any warning about it.
+func Command(ctx context.Context, command string, opts Options) (cmd *exec.Cmd, sandbox, warning string, err error) {
+	sandbox, warning, err = sandboxMethod(opts.Sandbox)
+	if err != nil {
+		return nil, "", "", err
+	}
+	cmd, err = sandboxCommand(ctx, sandbox, command, opts)
+	if err != nil {
+		return nil, "", "", err
+	}
+	cmd.Dir = opts.Dir
+	cmd.Env = FilterEnv(cmd.Environ(), opts.Env)
+	killProcessGroup(cmd)
+	return cmd, sandbox, warning, nil
+}
+
+// Quote quotes a word for the shell commands Run and Command take
+func Quote(word string) string {
+	if word != "" && strings.Trim(word, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-./=:,+@%") == "" {
+		return word
+	}
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
+
+// sandboxMethod resolves a sandbox mode to the method used on this system
+func sandboxMethod(mode string) (method, warning string, err error) {
+	switch mode {
+	case "", SandboxOff:
+		return "none", "", nil
+	case SandboxBwrap:
+		if !bwrapAvailable() {
+			return "", "", fmt.Errorf("sandbox is set to bwrap but bubblewrap (bwrap) is not installed")
+		}
+		return SandboxBwrap, "", nil
+	case SandboxNamespaces:
+		if err := namespacesAvailable(); err != nil {
+			return "", "", fmt.Errorf("sandbox is set to namespaces but they are unavailable: %w", err)
+		}
+		return SandboxNamespaces, "", nil
+	case SandboxAuto:
+		if bwrapAvailable() {
+			return SandboxBwrap, "", nil
+		}
+		nsErr := namespacesAvailable()
+		if nsErr == nil {
+			return SandboxNamespaces, "", nil
+		}
+		return "none", fmt.Sprintf("no sandbox available (install bubblewrap or enable user namespaces: %v); the command ran unsandboxed", nsErr), nil
+	}
+	return "", "", fmt.Errorf("unknown sandbox mode %q (use off, auto, bwrap or namespaces)", mode)
+}
@@ -0,0 +1,130 @@
+package execx
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	// The namespaces sandbox re-executes the test binary as its helper
+	RunSandboxHelper()
+	os.Exit(m.Run())
+}
+
+func TestOutputBuffer(t *testing.T) {
+	b := NewOutputBuffer(10)
+	b.Write([]byte("abc"))
+	if b.Truncated() || b.String() != "abc" {
+		t.Fatalf("short output: got %q, truncated %v", b.String(), b.Truncated())
+	}
+	b.Write([]byte("defghijklmnopqrstuvwxyz"))
+	if !b.Truncated() {
+		t.Fatal("expected truncation")
+	}
+	got := b.String()
+	if !strings.HasPrefix(got, "abcde") || !strings.HasSuffix(got, "vwxyz") || !strings.Contains(got, "16 bytes omitted") {
+		t.Errorf("unexpected truncated output %q", got)
+	}
+}
+
+func TestFilterEnv(t *testing.T) {
+	environ := []string{"PATH=/bin", "OPENAI_API_KEY=secret", "LC_ALL=C", "npm_config_cache=/c", "MY_VAR=1"}
+	got := strings.Join(FilterEnv(environ, []string{"MY_VAR"}), " ")
+	if got != "PATH=/bin LC_ALL=C npm_config_cache=/c MY_VAR=1" {
+		t.Errorf("FilterEnv = %q", got)
+	}
+	if all := FilterEnv(environ, []string{"*"}); len(all) != len(environ) {
+		t.Errorf("* kept %d of %d variables", len(all), len(environ))
+	}
+}
+
+func TestRunKeepsOutputOnFailure(t *testing.T) {
+	result, err := Run(context.Background(), "echo out; echo err >&2; exit 3", Options{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode != 3 || !strings.Contains(result.Output, "out") || !strings.Contains(result.Output, "err") {
+		t.Errorf("got exit %d, output %q", result.ExitCode, result.Output)
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs sh")
+	}
+	start := time.Now()
+	// The background sleep keeps the output pipe open; it must be killed too
+	result, err := Run(context.Background(), "sleep 30 & echo started; sleep 30", Options{Timeout: 300 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.TimedOut || !strings.Contains(result.Output, "started") {
+		t.Errorf("got timed out %v, output %q", result.TimedOut, result.Output)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("took %v to stop", elapsed)
+	}
+}
+
+func TestRunCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+	result, err := Run(ctx, "sleep 30", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Cancelled || result.TimedOut {
+		t.Errorf("got cancelled %v, timed out %v", result.Cancelled, result.TimedOut)
+	}
+}
+
+func TestRunUnknownSandbox(t *testing.T) {
+	if _, err := Run(context.Background(), "true", Options{Sandbox: "docker"}); err == nil {
+		t.Error("expected an error for an unknown sandbox mode")
+	}
+}
+
+func TestNamespacesSandbox(t *testing.T) {
+	if err := namespacesAvailable(); err != nil {
+		t.Skipf("namespaces unavailable: %v", err)
+	}
+	project := t.TempDir()
+	outside := t.TempDir()
+	opts := Options{Dir: project, Sandbox: SandboxNamespaces, Writable: []string{project}}
+
+	result, err := Run(context.Background(), "echo ok > inside.txt && cat inside.txt", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode != 0 || strings.TrimSpace(result.Output) != "ok" {
+		t.Fatalf("write inside the project: exit %d, output %q", result.ExitCode, result.Output)
+	}
+	if result.Sandbox != SandboxNamespaces {
+		t.Errorf("sandbox = %q", result.Sandbox)
+	}
+
+	result, err = Run(context.Background(), "echo no > "+filepath.Join(outside, "outside.txt"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode == 0 {
+		t.Error("write outside the project succeeded")
+	}
+	if _, err := os.Stat(filepath.Join(outside, "outside.txt")); err == nil {
+		t.Error("file outside the project was created")
+	}
+
+	// Only the loopback interface, down, exists in a new network namespace
+	result, err = Run(context.Background(), "cat /proc/net/dev | tail -n +3 | cut -d: -f1 | tr -d ' '", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ifaces := strings.Fields(result.Output); len(ifaces) != 1 || ifaces[0] != "lo" {
+		t.Errorf("interfaces in the sandbox: %q", result.Output)
+	}
+}
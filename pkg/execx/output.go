@@ -0,0 +1,72 @@
+package execx
+
+import (
+	"fmt"
+	"sync"
+)
+
+// OutputBuffer keeps the first and last bytes written to it, so both the
+// command that started a long log and the error that ended it survive. It
+// bounds what Run keeps of a command's output, and what callers running a
+// prepared Command keep of theirs.
+type OutputBuffer struct {
+	mu      sync.Mutex
+	max     int
+	head    []byte
+	tail    []byte // Ring buffer once full
+	tailPos int
+	total   int64
+}
+
+// NewOutputBuffer creates a buffer keeping at most max bytes
+func NewOutputBuffer(max int) *OutputBuffer {
+	return &OutputBuffer{max: max}
+}
+
+// Write implements io.Writer; it never fails
+func (b *OutputBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := len(p)
+	b.total += int64(n)
+
+	headMax := b.max / 2
+	if room := headMax - len(b.head); room > 0 {
+		take := min(room, len(p))
+		b.head = append(b.head, p[:take]...)
+		p = p[take:]
+	}
+
+	tailMax := b.max - headMax
+	for len(p) > 0 && tailMax > 0 {
+		if len(b.tail) < tailMax {
+			take := min(tailMax-len(b.tail), len(p))
+			b.tail = append(b.tail, p[:take]...)
+			p = p[take:]
+			continue
+		}
+		copied := copy(b.tail[b.tailPos:], p)
+		b.tailPos = (b.tailPos + copied) % tailMax
+		p = p[copied:]
+	}
+	return n, nil
+}
+
+// Truncated reports whether output was dropped
+func (b *OutputBuffer) Truncated() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.total > int64(len(b.head)+len(b.tail))
+}
+
+// String returns the kept output with a marker where bytes were dropped
+func (b *OutputBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	tail := append(append([]byte{}, b.tail[b.tailPos:]...), b.tail[:b.tailPos]...)
+	dropped := b.total - int64(len(b.head)+len(tail))
+	if dropped <= 0 {
+		return string(b.head) + string(tail)
+	}
+	return fmt.Sprintf("%s\n\n... [%d bytes omitted] ...\n\n%s", b.head, dropped, tail)
+}
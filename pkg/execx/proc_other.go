@@ -0,0 +1,19 @@
+//go:build !unix
+
+package execx
+
+import "os/exec"
+
+// killProcessGroup is a no-op where process groups are unavailable; the
+// command itself is still killed on cancellation
+func killProcessGroup(cmd *exec.Cmd) {}
+
+// Terminate kills a started command; there is no gentler signal here
+func Terminate(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
+
+// Kill kills a started command
+func Kill(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
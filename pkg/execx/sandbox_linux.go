@@ -0,0 +1,211 @@
+//go:build linux
+
+package execx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// helperArg is the first argument of the axon process re-executed inside new
+// namespaces to set up the mounts before running the command
+const helperArg = "__axon_sandbox"
+
+// helperSpec tells the sandbox helper what to run
+type helperSpec struct {
+	Command  string   `json:"command"`
+	Dir      string   `json:"dir"`
+	Writable []string `json:"writable"`
+}
+
+var (
+	nsOnce sync.Once
+	nsErr  error
+)
+
+// bwrapAvailable reports whether bubblewrap is installed
+func bwrapAvailable() bool {
+	_, err := exec.LookPath("bwrap")
+	return err == nil
+}
+
+// namespacesAvailable reports whether unprivileged user, mount and network
+// namespaces can be created, checking once per process
+func namespacesAvailable() error {
+	nsOnce.Do(func() {
+		cmd := exec.Command("/bin/true")
+		cmd.SysProcAttr = namespaceAttr(false)
+		nsErr = cmd.Run()
+	})
+	return nsErr
+}
+
+// namespaceAttr creates new user and mount namespaces, and a network
+// namespace without interfaces unless network access is kept. The current
+// user is mapped to itself so files written in the project keep their owner.
+func namespaceAttr(network bool) *syscall.SysProcAttr {
+	flags := uintptr(syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS)
+	if !network {
+		flags |= syscall.CLONE_NEWNET
+	}
+	return &syscall.SysProcAttr{
+		Cloneflags:                 flags,
+		UidMappings:                []syscall.SysProcIDMap{{ContainerID: os.Getuid(), HostID: os.Getuid(), Size: 1}},
+		GidMappings:                []syscall.SysProcIDMap{{ContainerID: os.Getgid(), HostID: os.Getgid(), Size: 1}},
+		GidMappingsEnableSetgroups: false,
+	}
+}
+
+// sandboxCommand builds the command for a sandbox method
+func sandboxCommand(ctx context.Context, method, command string, opts Options) (*exec.Cmd, error) {
+	writable := writablePaths(opts)
+	switch method {
+	case SandboxBwrap:
+		args := []string{"--die-with-parent", "--new-session", "--unshare-all"}
+		if opts.Network {
+			args = append(args, "--share-net")
+		}
+		args = append(args, "--ro-bind", "/", "/", "--dev", "/dev", "--proc", "/proc", "--tmpfs", "/tmp")
+		for _, path := range writable {
+			args = append(args, "--bind", path, path)
+		}
+		if opts.Dir != "" {
+			args = append(args, "--chdir", opts.Dir)
+		}
+		args = append(args, "--", "sh", "-c", command)
+		return exec.CommandContext(ctx, "bwrap", args...), nil
+
+	case SandboxNamespaces:
+		self, err := os.Executable()
+		if err != nil {
+			return nil, fmt.Errorf("failed to locate the axon executable for the sandbox: %w", err)
+		}
+		spec, _ := json.Marshal(helperSpec{Command: command, Dir: opts.Dir, Writable: writable})
+		cmd := exec.CommandContext(ctx, self, helperArg, string(spec))
+		cmd.SysProcAttr = namespaceAttr(opts.Network)
+		return cmd, nil
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command), nil
+}
+
+// writablePaths returns the existing absolute paths among the writable paths
+func writablePaths(opts Options) []string {
+	var paths []string
+	home, _ := os.UserHomeDir()
+	for _, path := range opts.Writable {
+		if rest, ok := strings.CutPrefix(path, "~/"); ok && home != "" {
+			path = filepath.Join(home, rest)
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			continue
+		}
+		if _, err := os.Stat(abs); err == nil {
+			paths = append(paths, abs)
+		}
+	}
+	return paths
+}
+
+// RunSandboxHelper must be called first thing in main. In a process started
+// by the namespaces sandbox it makes the filesystem read-only except for the
+// writable paths, mounts a private /tmp and replaces itself with the
+// command; otherwise it returns immediately.
+func RunSandboxHelper() {
+	if len(os.Args) < 3 || os.Args[1] != helperArg {
+		return
+	}
+	var spec helperSpec
+	if err := json.Unmarshal([]byte(os.Args[2]), &spec); err != nil {
+		fmt.Fprintf(os.Stderr, "axon sandbox: invalid spec: %v\n", err)
+		os.Exit(125)
+	}
+	if err := enterSandbox(spec); err != nil {
+		fmt.Fprintf(os.Stderr, "axon sandbox: %v\n", err)
+		os.Exit(125)
+	}
+	err := syscall.Exec("/bin/sh", []string{"sh", "-c", spec.Command}, os.Environ())
+	fmt.Fprintf(os.Stderr, "axon sandbox: failed to run sh: %v\n", err)
+	os.Exit(127)
+}
+
+// enterSandbox sets up the mounts of the sandbox. It runs inside fresh user
+// and mount namespaces, so the changes are invisible outside the command.
+func enterSandbox(spec helperSpec) error {
+	if err := unix.Mount("", "/", "", unix.MS_REC|unix.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("failed to make mounts private: %w", err)
+	}
+	// Hold the writable paths open so they stay reachable once a tmpfs
+	// covers /tmp, where the project may live
+	fds := make([]int, len(spec.Writable))
+	for i, path := range spec.Writable {
+		fd, err := unix.Open(path, unix.O_PATH|unix.O_CLOEXEC, 0)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", path, err)
+		}
+		fds[i] = fd
+	}
+	if err := unix.Mount("tmpfs", "/tmp", "tmpfs", 0, "mode=1777"); err != nil {
+		return fmt.Errorf("failed to mount /tmp: %w", err)
+	}
+	// Bind mounts make each writable path its own mount, so it can be
+	// exempted from the read-only flag below
+	for i, path := range spec.Writable {
+		if err := mountPoint(path, fds[i]); err != nil {
+			return err
+		}
+		if err := unix.Mount(fmt.Sprintf("/proc/self/fd/%d", fds[i]), path, "", unix.MS_BIND|unix.MS_REC, ""); err != nil {
+			return fmt.Errorf("failed to bind %s: %w", path, err)
+		}
+		unix.Close(fds[i])
+	}
+	if err := unix.MountSetattr(unix.AT_FDCWD, "/", unix.AT_RECURSIVE, &unix.MountAttr{Attr_set: unix.MOUNT_ATTR_RDONLY}); err != nil {
+		return fmt.Errorf("failed to make the filesystem read-only (needs Linux 5.12+): %w", err)
+	}
+	// The private /tmp stays writable, as does /dev (for /dev/null) when it
+	// is a mount of its own
+	unix.MountSetattr(unix.AT_FDCWD, "/dev", unix.AT_RECURSIVE, &unix.MountAttr{Attr_clr: unix.MOUNT_ATTR_RDONLY})
+	for _, path := range append([]string{"/tmp"}, spec.Writable...) {
+		if err := unix.MountSetattr(unix.AT_FDCWD, path, unix.AT_RECURSIVE, &unix.MountAttr{Attr_clr: unix.MOUNT_ATTR_RDONLY}); err != nil {
+			return fmt.Errorf("failed to make %s writable: %w", path, err)
+		}
+	}
+	if spec.Dir != "" {
+		if err := os.Chdir(spec.Dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mountPoint makes sure a bind mount target exists, recreating it when the
+// tmpfs on /tmp hid it
+func mountPoint(path string, fd int) error {
+	if _, err := os.Lstat(path); err == nil {
+		return nil
+	}
+	var st unix.Stat_t
+	if err := unix.Fstat(fd, &st); err != nil {
+		return fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	if st.Mode&unix.S_IFMT == unix.S_IFDIR {
+		return os.MkdirAll(path, 0755)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
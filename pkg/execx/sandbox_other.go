@@ -0,0 +1,27 @@
+//go:build !linux
+
+package execx
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+)
+
+// bwrapAvailable reports false: bubblewrap is Linux-only
+func bwrapAvailable() bool {
+	return false
+}
+
+// namespacesAvailable reports that namespaces are Linux-only
+func namespacesAvailable() error {
+	return errors.New("namespaces are only supported on Linux")
+}
+
+// sandboxCommand runs the command directly
+func sandboxCommand(ctx context.Context, method, command string, opts Options) (*exec.Cmd, error) {
+	return exec.CommandContext(ctx, "sh", "-c", command), nil
+}
+
+// RunSandboxHelper is a no-op outside Linux
+func RunSandboxHelper() {}
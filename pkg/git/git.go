@@ -0,0 +1,494 @@
+// Package git reads repository history and state through the git command:
+// status, log, blame, show, diffs and branches, parsed into structured
+// results. It never changes the repository.
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxPatch is the default number of bytes of patch text returned by Show
+// and Diff
+const MaxPatch = 30000
+
+// Repo runs git in a directory inside a work tree
+type Repo struct {
+	dir string
+}
+
+// Open returns the repository containing dir
+func Open(dir string) (*Repo, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("git is not installed: %w", err)
+	}
+	r := &Repo{dir: dir}
+	if _, err := r.git("rev-parse", "--is-inside-work-tree"); err != nil {
+		return nil, fmt.Errorf("not a git repository: %s", dir)
+	}
+	return r, nil
+}
+
+// Commit is a commit's metadata
+type Commit struct {
+	Hash    string    `json:"hash"`
+	Short   string    `json:"short"`
+	Author  string    `json:"author"`
+	Email   string    `json:"email,omitempty"`
+	Date    time.Time `json:"date"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body,omitempty"`
+}
+
+// FileChange is a file changed by a commit or diff, from --numstat
+type FileChange struct {
+	Path      string `json:"path"`
+	OrigPath  string `json:"orig_path,omitempty"` // Set for renames and copies
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+	Binary    bool   `json:"binary,omitempty"`
+}
+
+// commitFormat separates the fields of a commit with NULs and ends each
+// commit with a record separator
+const commitFormat = "--format=%H%x00%an%x00%ae%x00%aI%x00%s%x00%b%x1e"
+
+// parseCommits parses output produced with commitFormat
+func parseCommits(out string) []Commit {
+	commits := []Commit{}
+	for _, record := range strings.Split(out, "\x1e") {
+		fields := strings.Split(strings.TrimLeft(record, "\n"), "\x00")
+		if len(fields) < 6 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, fields[3])
+		commits = append(commits, Commit{
+			Hash:    fields[0],
+			Short:   shortHash(fields[0]),
+			Author:  fields[1],
+			Email:   fields[2],
+			Date:    date,
+			Subject: fields[4],
+			Body:    strings.TrimSpace(fields[5]),
+		})
+	}
+	return commits
+}
+
+// LogOptions filters Log
+type LogOptions struct {
+	Rev    string // Revision or range to start from (default HEAD)
+	Path   string // Only commits touching this path (renames followed for files)
+	Symbol string // Only commits that add or remove occurrences of this text (git log -S)
+	Author string
+	Since  string // e.g. "2 weeks ago" or "2024-01-31"
+	Limit  int
+}
+
+// Log lists commits, newest first
+func (r *Repo) Log(opts LogOptions) ([]Commit, error) {
+	if err := checkRev(opts.Rev); err != nil {
+		return nil, err
+	}
+	args := []string{"log", commitFormat}
+	if opts.Limit > 0 {
+		args = append(args, "-n", strconv.Itoa(opts.Limit))
+	}
+	if opts.Symbol != "" {
+		args = append(args, "-S", opts.Symbol)
+	}
+	if opts.Author != "" {
+		args = append(args, "--author="+opts.Author)
+	}
+	if opts.Since != "" {
+		args = append(args, "--since="+opts.Since)
+	}
+	if opts.Path != "" && r.isFile(opts.Rev, opts.Path) {
+		args = append(args, "--follow")
+	}
+	if opts.Rev != "" {
+		args = append(args, opts.Rev)
+	}
+	if opts.Path != "" {
+		args = append(args, "--", opts.Path)
+	}
+	out, err := r.git(args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseCommits(out), nil
+}
+
+// isFile reports whether path is a file at rev; --follow only works on files
+func (r *Repo) isFile(rev, path string) bool {
+	if rev == "" || strings.Contains(rev, "..") {
+		rev = "HEAD"
+	}
+	out, err := r.git("cat-file", "-t", rev+":"+path)
+	return err == nil && out == "blob"
+}
+
+// BlameLine is one line of a blamed file
+type BlameLine struct {
+	Line    int    `json:"line"`
+	Commit  string `json:"commit"` // Short hash; all zeros for uncommitted lines
+	Content string `json:"content"`
+}
+
+// Blame is the result of blaming a line range
+type Blame struct {
+	Path    string            `json:"path"`
+	Lines   []BlameLine       `json:"lines"`
+	Commits map[string]Commit `json:"commits"` // By short hash
+}
+
+// Blame tells which commit last changed each line from start to end
+// (1-based, inclusive; zero end means the end of the file) as of rev, or
+// the working tree when rev is empty
+func (r *Repo) Blame(path string, start, end int, rev string) (*Blame, error) {
+	if err := checkRev(rev); err != nil {
+		return nil, err
+	}
+	if start < 1 {
+		start = 1
+	}
+	lineRange := strconv.Itoa(start) + ","
+	if end > 0 {
+		if end < start {
+			return nil, fmt.Errorf("end line %d is before start line %d", end, start)
+		}
+		lineRange += strconv.Itoa(end)
+	}
+	args := []string{"blame", "--porcelain", "-w", "-L", lineRange}
+	if rev != "" {
+		args = append(args, rev)
+	}
+	out, err := r.git(append(args, "--", path)...)
+	if err != nil {
+		return nil, err
+	}
+	return parseBlame(path, out), nil
+}
+
+// parseBlame parses git blame --porcelain output, where commit details are
+// only given the first time a commit appears
+func parseBlame(path, out string) *Blame {
+	blame := &Blame{Path: path, Lines: []BlameLine{}, Commits: map[string]Commit{}}
+	details := map[string]*Commit{}
+	var current *Commit
+	var currentLine int
+	for _, line := range strings.Split(out, "\n") {
+		if content, ok := strings.CutPrefix(line, "\t"); ok {
+			if current != nil {
+				blame.Lines = append(blame.Lines, BlameLine{Line: currentLine, Commit: current.Short, Content: content})
+			}
+			continue
+		}
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "author":
+			current.Author = value
+		case "author-mail":
+			current.Email = strings.Trim(value, "<>")
+		case "author-time":
+			seconds, _ := strconv.ParseInt(value, 10, 64)
+			current.Date = time.Unix(seconds, 0)
+		case "author-tz":
+			if loc := parseZone(value); loc != nil {
+				current.Date = current.Date.In(loc)
+			}
+		case "summary":
+			current.Subject = value
+		default:
+			fields := strings.Fields(line)
+			if len(fields) >= 3 && len(fields[0]) >= 40 && isHex(fields[0]) {
+				hash := fields[0]
+				if details[hash] == nil {
+					details[hash] = &Commit{Hash: hash, Short: shortHash(hash)}
+				}
+				current = details[hash]
+				currentLine, _ = strconv.Atoi(fields[2])
+			}
+		}
+	}
+	for _, commit := range details {
+		if strings.Trim(commit.Hash, "0") == "" {
+			commit.Subject = "Not committed yet"
+		}
+		blame.Commits[commit.Short] = *commit
+	}
+	return blame
+}
+
+// parseZone turns a +hhmm offset into a location
+func parseZone(offset string) *time.Location {
+	t, err := time.Parse("-0700", offset)
+	if err != nil {
+		return nil
+	}
+	return t.Location()
+}
+
+// ShowResult is a commit with its changes
+type ShowResult struct {
+	Commit
+	Parents   []string     `json:"parents"`
+	Files     []FileChange `json:"files"`
+	Patch     string       `json:"patch,omitempty"`
+	Truncated bool         `json:"truncated,omitempty"`
+}
+
+// Show returns a commit, the files it changed and, unless stat is set, its
+// patch, optionally limited to a path
+func (r *Repo) Show(rev, path string, stat bool) (*ShowResult, error) {
+	if rev == "" {
+		rev = "HEAD"
+	}
+	if err := checkRev(rev); err != nil {
+		return nil, err
+	}
+	out, err := r.git("show", "-s", commitFormat, rev)
+	if err != nil {
+		return nil, err
+	}
+	commits := parseCommits(out)
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("unknown revision %q", rev)
+	}
+	result := &ShowResult{Commit: commits[0]}
+
+	parents, err := r.git("rev-list", "--parents", "-n", "1", result.Hash)
+	if err != nil {
+		return nil, err
+	}
+	if fields := strings.Fields(parents); len(fields) > 1 {
+		for _, parent := range fields[1:] {
+			result.Parents = append(result.Parents, shortHash(parent))
+		}
+	}
+
+	// Merges are shown against their first parent
+	args := []string{"show", "--format=", "--first-parent", "-M", "--numstat", "-z", result.Hash}
+	if path != "" {
+		args = append(args, "--", path)
+	}
+	if out, err = r.git(args...); err != nil {
+		return nil, err
+	}
+	result.Files = parseNumstat(out)
+
+	if !stat {
+		args := []string{"show", "--format=", "--first-parent", "-M", "--no-color", "--no-ext-diff", result.Hash}
+		if path != "" {
+			args = append(args, "--", path)
+		}
+		if out, err = r.git(args...); err != nil {
+			return nil, err
+		}
+		result.Patch, result.Truncated = truncate(out, MaxPatch)
+	}
+	return result, nil
+}
+
+// DiffOptions selects what Diff compares
+type DiffOptions struct {
+	Staged bool   // Index against HEAD (or Rev) instead of the working tree against the index
+	Rev    string // Revision ("main"), or range ("main..feature", "HEAD~3..HEAD", "main...feature")
+	Path   string
+	Stat   bool // Files and line counts only, without the patch
+	Limit  int  // Bytes of patch returned (0 uses MaxPatch)
+}
+
+// DiffResult lists changed files and, unless stat was requested, the patch
+type DiffResult struct {
+	Files     []FileChange `json:"files"`
+	Additions int          `json:"additions"`
+	Deletions int          `json:"deletions"`
+	Patch     string       `json:"patch,omitempty"`
+	Truncated bool         `json:"truncated,omitempty"`
+}
+
+// Diff compares the working tree, the index or revisions
+func (r *Repo) Diff(opts DiffOptions) (*DiffResult, error) {
+	if err := checkRev(opts.Rev); err != nil {
+		return nil, err
+	}
+	base := []string{"diff", "-M", "--no-color", "--no-ext-diff"}
+	if opts.Staged {
+		base = append(base, "--cached")
+	}
+	if opts.Rev != "" {
+		base = append(base, opts.Rev)
+	}
+	var pathArgs []string
+	if opts.Path != "" {
+		pathArgs = []string{"--", opts.Path}
+	}
+
+	out, err := r.git(append(append(append([]string{}, base...), "--numstat", "-z"), pathArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	result := &DiffResult{Files: parseNumstat(out)}
+	for _, f := range result.Files {
+		result.Additions += f.Additions
+		result.Deletions += f.Deletions
+	}
+	if !opts.Stat && len(result.Files) > 0 {
+		if out, err = r.git(append(base, pathArgs...)...); err != nil {
+			return nil, err
+		}
+		limit := opts.Limit
+		if limit <= 0 {
+			limit = MaxPatch
+		}
+		result.Patch, result.Truncated = truncate(out, limit)
+	}
+	return result, nil
+}
+
+// parseNumstat parses --numstat -z output. Renamed files are given as an
+// empty path followed by the old and new paths.
+func parseNumstat(out string) []FileChange {
+	files := []FileChange{}
+	fields := strings.Split(out, "\x00")
+	for i := 0; i < len(fields); i++ {
+		parts := strings.SplitN(strings.TrimLeft(fields[i], "\n"), "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		change := FileChange{Path: parts[2]}
+		if parts[0] == "-" && parts[1] == "-" {
+			change.Binary = true
+		} else {
+			change.Additions, _ = strconv.Atoi(parts[0])
+			change.Deletions, _ = strconv.Atoi(parts[1])
+		}
+		if change.Path == "" && i+2 < len(fields) {
+			change.OrigPath, change.Path = fields[i+1], fields[i+2]
+			i += 2
+		}
+		files = append(files, change)
+	}
+	return files
+}
+
+// Branch is a local or remote-tracking branch
+type Branch struct {
+	Name     string    `json:"name"`
+	Current  bool      `json:"current,omitempty"`
+	Remote   bool      `json:"remote,omitempty"`
+	Commit   string    `json:"commit"`
+	Subject  string    `json:"subject"`
+	Date     time.Time `json:"date"`
+	Upstream string    `json:"upstream,omitempty"`
+	Ahead    int       `json:"ahead,omitempty"`
+	Behind   int       `json:"behind,omitempty"`
+	Gone     bool      `json:"gone,omitempty"` // The upstream was deleted
+}
+
+// Branches lists local branches, and remote-tracking branches if remote is
+// set, most recently committed first
+func (r *Repo) Branches(remote bool) ([]Branch, error) {
+	args := []string{"for-each-ref", "--sort=-committerdate",
+		"--format=%(refname)%00%(HEAD)%00%(objectname:short=12)%00%(committerdate:iso-strict)%00%(upstream:short)%00%(upstream:track,nobracket)%00%(contents:subject)",
+		"refs/heads"}
+	if remote {
+		args = append(args, "refs/remotes")
+	}
+	out, err := r.git(args...)
+	if err != nil {
+		return nil, err
+	}
+	branches := []Branch{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 7 || strings.HasSuffix(fields[0], "/HEAD") {
+			continue
+		}
+		b := Branch{Current: fields[1] == "*", Commit: fields[2], Upstream: fields[4], Subject: fields[6]}
+		if name, ok := strings.CutPrefix(fields[0], "refs/heads/"); ok {
+			b.Name = name
+		} else {
+			b.Name, b.Remote = strings.TrimPrefix(fields[0], "refs/remotes/"), true
+		}
+		b.Date, _ = time.Parse(time.RFC3339, fields[3])
+		for _, part := range strings.Split(fields[5], ", ") {
+			switch {
+			case part == "gone":
+				b.Gone = true
+			case strings.HasPrefix(part, "ahead "):
+				b.Ahead, _ = strconv.Atoi(strings.TrimPrefix(part, "ahead "))
+			case strings.HasPrefix(part, "behind "):
+				b.Behind, _ = strconv.Atoi(strings.TrimPrefix(part, "behind "))
+			}
+		}
+		branches = append(branches, b)
+	}
+	return branches, nil
+}
+
+// GitPath returns the absolute path of a file in the repository's git
+// directory, e.g. "hooks/prepare-commit-msg", following worktrees and
+// core.hooksPath
+func (r *Repo) GitPath(name string) (string, error) {
+	return r.git("rev-parse", "--path-format=absolute", "--git-path", name)
+}
+
+// checkRev rejects revisions that git would read as options
+func checkRev(rev string) error {
+	if strings.HasPrefix(strings.TrimSpace(rev), "-") {
+		return fmt.Errorf("invalid revision %q", rev)
+	}
+	return nil
+}
+
+// git runs a read-only git command in the repository
+func (r *Repo) git(args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-c", "core.quotePath=false", "--no-pager"}, args...)...)
+	cmd.Dir = r.dir
+	// Don't take the index lock for the refresh status and diff do
+	cmd.Env = append(cmd.Environ(), "GIT_OPTIONAL_LOCKS=0")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		var exitErr *exec.ExitError
+		if msg == "" || !errors.As(err, &exitErr) {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return strings.TrimRight(stdout.String(), "\n"), nil
+}
+
+// truncate cuts text to max bytes
+func truncate(text string, max int) (string, bool) {
+	if len(text) <= max {
+		return text, false
+	}
+	return text[:max] + "\n... [truncated]", true
+}
+
+// shortHash abbreviates a commit hash
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
+
+// isHex reports whether s is a hexadecimal string
+func isHex(s string) bool {
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,255 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestRepo creates a repository with two commits: a.txt added, then
+// a.txt changed and b.txt added
+func newTestRepo(t *testing.T) (string, *Repo) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	t.Setenv("GIT_AUTHOR_NAME", "Ada")
+	t.Setenv("GIT_AUTHOR_EMAIL", "ada@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Ada")
+	t.Setenv("GIT_COMMITTER_EMAIL", "ada@example.com")
+	t.Setenv("GIT_CONFIG_GLOBAL", "/dev/null")
+
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run("init", "-q", "-b", "main")
+	write("a.txt", "one\ntwo\nthree\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "Add a")
+	write("a.txt", "one\nTWO\nthree\n")
+	write("b.txt", "computeTotal()\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "Change a and add b", "-m", "Details here.")
+	run("branch", "feature")
+
+	repo, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, repo
+}
+
+func TestLog(t *testing.T) {
+	_, repo := newTestRepo(t)
+	commits, err := repo.Log(LogOptions{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 2 || commits[0].Subject != "Change a and add b" || commits[0].Body != "Details here." || commits[0].Author != "Ada" {
+		t.Fatalf("unexpected commits %+v", commits)
+	}
+	if len(commits[0].Short) != 12 || commits[0].Date.IsZero() {
+		t.Errorf("short %q, date %v", commits[0].Short, commits[0].Date)
+	}
+
+	commits, err = repo.Log(LogOptions{Symbol: "computeTotal"})
+	if err != nil || len(commits) != 1 {
+		t.Errorf("symbol filter: %+v, %v", commits, err)
+	}
+	commits, err = repo.Log(LogOptions{Path: "b.txt"})
+	if err != nil || len(commits) != 1 {
+		t.Errorf("path filter: %+v, %v", commits, err)
+	}
+	if _, err := repo.Log(LogOptions{Rev: "--output=/tmp/x"}); err == nil {
+		t.Error("expected an option-like revision to be refused")
+	}
+}
+
+func TestBlame(t *testing.T) {
+	dir, repo := newTestRepo(t)
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("one\nTWO\nthree\nfour\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	blame, err := repo.Blame("a.txt", 2, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blame.Lines) != 3 || blame.Lines[0].Line != 2 || blame.Lines[0].Content != "TWO" {
+		t.Fatalf("unexpected lines %+v", blame.Lines)
+	}
+	if c := blame.Commits[blame.Lines[0].Commit]; c.Subject != "Change a and add b" || c.Author != "Ada" {
+		t.Errorf("line 2 commit %+v", c)
+	}
+	if c := blame.Commits[blame.Lines[1].Commit]; c.Subject != "Add a" {
+		t.Errorf("line 3 commit %+v", c)
+	}
+	if c := blame.Commits[blame.Lines[2].Commit]; c.Subject != "Not committed yet" {
+		t.Errorf("line 4 commit %+v", c)
+	}
+}
+
+func TestShowAndDiff(t *testing.T) {
+	dir, repo := newTestRepo(t)
+	show, err := repo.Show("HEAD", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(show.Files) != 2 || len(show.Parents) != 1 || !strings.Contains(show.Patch, "+TWO") {
+		t.Errorf("unexpected show %+v", show)
+	}
+	show, err = repo.Show("HEAD", "b.txt", true)
+	if err != nil || len(show.Files) != 1 || show.Patch != "" {
+		t.Errorf("path-limited stat show: %+v, %v", show, err)
+	}
+
+	diff, err := repo.Diff(DiffOptions{Rev: "HEAD~1..HEAD", Stat: true})
+	if err != nil || len(diff.Files) != 2 || diff.Additions != 2 || diff.Deletions != 1 || diff.Patch != "" {
+		t.Errorf("range diff: %+v, %v", diff, err)
+	}
+
+	cmd := exec.Command("git", "mv", "b.txt", "c.txt")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git mv: %v\n%s", err, out)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	staged, err := repo.Diff(DiffOptions{Staged: true})
+	if err != nil || len(staged.Files) != 1 || staged.Files[0].Path != "c.txt" || staged.Files[0].OrigPath != "b.txt" {
+		t.Errorf("staged diff: %+v, %v", staged, err)
+	}
+	unstaged, err := repo.Diff(DiffOptions{})
+	if err != nil || len(unstaged.Files) != 1 || unstaged.Files[0].Path != "a.txt" || !strings.Contains(unstaged.Patch, "+changed") {
+		t.Errorf("unstaged diff: %+v, %v", unstaged, err)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	dir, repo := newTestRepo(t)
+	cmd := exec.Command("git", "mv", "b.txt", "renamed file.txt")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git mv: %v\n%s", err, out)
+	}
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("x\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "new.txt"), []byte("x\n"), 0644)
+
+	status, err := repo.Status("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Branch != "main" || status.Commit == "" {
+		t.Errorf("branch %q, commit %q", status.Branch, status.Commit)
+	}
+	byPath := map[string]FileStatus{}
+	for _, f := range status.Files {
+		byPath[f.Path] = f
+	}
+	if f := byPath["renamed file.txt"]; f.Index != "renamed" || f.OrigPath != "b.txt" {
+		t.Errorf("rename: %+v", f)
+	}
+	if f := byPath["a.txt"]; f.Worktree != "modified" || f.Index != "" {
+		t.Errorf("modified: %+v", f)
+	}
+	if f := byPath["new.txt"]; !f.Untracked {
+		t.Errorf("untracked: %+v", f)
+	}
+}
+
+func TestBranches(t *testing.T) {
+	_, repo := newTestRepo(t)
+	branches, err := repo.Branches(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := map[string]bool{}
+	for _, b := range branches {
+		names[b.Name] = b.Current
+	}
+	if len(branches) != 2 || !names["main"] || names["feature"] {
+		t.Errorf("unexpected branches %+v", branches)
+	}
+}
+
+const samplePatch = `diff --git a/pkg/a.go b/pkg/a.go
+index 1111111..2222222 100644
+--- a/pkg/a.go
++++ b/pkg/a.go
+@@ -1 +1 @@
+-old
++new
+diff --git a/old.txt b/new.txt
+similarity index 100%
+rename from old.txt
+rename to new.txt
+diff --git a/gone.txt b/gone.txt
+deleted file mode 100644
+index 3333333..0000000
+--- a/gone.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-bye
+diff --git a/logo.png b/logo.png
+new file mode 100644
+index 0000000..4444444
+Binary files /dev/null and b/logo.png differ
+`
+
+func TestSplitPatch(t *testing.T) {
+	files := SplitPatch(samplePatch)
+	var paths []string
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	if got := strings.Join(paths, ","); got != "pkg/a.go,new.txt,gone.txt,logo.png" {
+		t.Errorf("paths = %s", got)
+	}
+	if !strings.HasPrefix(files[0].Patch, "diff --git a/pkg/a.go") || !strings.HasSuffix(files[0].Patch, "+new\n") {
+		t.Errorf("unexpected first patch:\n%s", files[0].Patch)
+	}
+}
+
+func TestHunks(t *testing.T) {
+	patch := `diff --git a/a.go b/a.go
+--- a/a.go
++++ b/a.go
+@@ -3,4 +3,5 @@ func main() {
+ 	a := 1
+-	b := 2
++	b := 3
++	c := 4
+ 	fmt.Println(a, b)
+@@ -20 +21 @@
+-x
++y
+`
+	hunks := SplitPatch(patch)[0].Hunks()
+	if len(hunks) != 2 {
+		t.Fatalf("got %d hunks", len(hunks))
+	}
+	h := hunks[0]
+	if h.Path != "a.go" || h.OldStart != 3 || h.OldLines != 4 || h.NewStart != 3 || h.NewLines != 5 || h.NewEnd() != 7 || h.Section != "func main() {" {
+		t.Errorf("unexpected first hunk %+v", h)
+	}
+	if !strings.HasPrefix(h.Text, "@@ -3,4") || !strings.HasSuffix(h.Text, "fmt.Println(a, b)\n") {
+		t.Errorf("unexpected hunk text %q", h.Text)
+	}
+	if h := hunks[1]; h.NewStart != 21 || h.NewLines != 1 || h.OldLines != 1 {
+		t.Errorf("unexpected second hunk %+v", h)
+	}
+}
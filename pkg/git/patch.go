@@ -0,0 +1,127 @@
+package git
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FilePatch is one file's part of a unified diff
+type FilePatch struct {
+	Path  string // New path, or the old one for deleted files
+	Patch string
+}
+
+// Hunk is one @@ section of a file's patch
+type Hunk struct {
+	Path     string
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	Section  string // Text after the closing @@, usually the enclosing function
+	Text     string // The @@ line and the hunk's lines
+}
+
+// NewEnd returns the last line of the hunk in the new file
+func (h Hunk) NewEnd() int {
+	if h.NewLines == 0 {
+		return h.NewStart
+	}
+	return h.NewStart + h.NewLines - 1
+}
+
+// diffHeader matches the first line of a file's patch
+var diffHeader = regexp.MustCompile(`(?m)^diff --git `)
+
+// hunkHeader matches "@@ -12,5 +12,7 @@ func name()"
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@ ?(.*)$`)
+
+// SplitPatch splits a unified diff into per-file patches
+func SplitPatch(patch string) []FilePatch {
+	starts := diffHeader.FindAllStringIndex(patch, -1)
+	var files []FilePatch
+	for i, start := range starts {
+		end := len(patch)
+		if i+1 < len(starts) {
+			end = starts[i+1][0]
+		}
+		text := patch[start[0]:end]
+		files = append(files, FilePatch{Path: patchPath(text), Patch: strings.TrimRight(text, "\n") + "\n"})
+	}
+	return files
+}
+
+// patchPath finds the new path of a file's patch, or the old one if the file
+// was deleted
+func patchPath(patch string) string {
+	var oldPath string
+	for _, line := range strings.Split(patch, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++ b/"):
+			return strings.TrimPrefix(line, "+++ b/")
+		case strings.HasPrefix(line, "rename to "):
+			return strings.TrimPrefix(line, "rename to ")
+		case strings.HasPrefix(line, "--- a/"):
+			oldPath = strings.TrimPrefix(line, "--- a/")
+		case strings.HasPrefix(line, "@@"):
+			return oldPath
+		}
+	}
+	if oldPath != "" {
+		return oldPath
+	}
+	// Binary files and mode changes only have the header: diff --git a/x b/x
+	header, _, _ := strings.Cut(patch, "\n")
+	if i := strings.LastIndex(header, " b/"); i >= 0 {
+		return header[i+3:]
+	}
+	return ""
+}
+
+// Hunks splits a file's patch into its hunks; binary files have none
+func (f FilePatch) Hunks() []Hunk {
+	var hunks []Hunk
+	var current *Hunk
+	var text strings.Builder
+	flush := func() {
+		if current != nil {
+			current.Text = text.String()
+			hunks = append(hunks, *current)
+		}
+		text.Reset()
+	}
+	for _, line := range strings.Split(strings.TrimRight(f.Patch, "\n"), "\n") {
+		if m := hunkHeader.FindStringSubmatch(line); m != nil {
+			flush()
+			current = &Hunk{
+				Path:     f.Path,
+				OldStart: atoi(m[1]),
+				OldLines: countOr1(m[2]),
+				NewStart: atoi(m[3]),
+				NewLines: countOr1(m[4]),
+				Section:  m[5],
+			}
+		}
+		if current != nil {
+			text.WriteString(line)
+			text.WriteByte('\n')
+		}
+	}
+	flush()
+	return hunks
+}
+
+// countOr1 parses a hunk line count, which git omits when it is 1
+func countOr1(s string) int {
+	if s == "" {
+		return 1
+	}
+	return atoi(s)
+}
+
+// atoi parses a number, returning 0 for invalid input
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
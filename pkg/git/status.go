@@ -0,0 +1,121 @@
+package git
+
+import (
+	"strconv"
+	"strings"
+)
+
+// FileStatus is the state of a changed file in the index and work tree
+type FileStatus struct {
+	Path       string `json:"path"`
+	OrigPath   string `json:"orig_path,omitempty"` // Source of a rename or copy
+	Index      string `json:"index,omitempty"`     // Staged change: modified, added, deleted, renamed, copied or type_changed
+	Worktree   string `json:"worktree,omitempty"`  // Unstaged change, same values
+	Untracked  bool   `json:"untracked,omitempty"`
+	Conflicted bool   `json:"conflicted,omitempty"`
+}
+
+// Status is the state of the work tree
+type Status struct {
+	Branch   string       `json:"branch"` // Empty when HEAD is detached
+	Commit   string       `json:"commit,omitempty"`
+	Upstream string       `json:"upstream,omitempty"`
+	Ahead    int          `json:"ahead,omitempty"`
+	Behind   int          `json:"behind,omitempty"`
+	Files    []FileStatus `json:"files"`
+}
+
+// Status returns the branch and the changed, untracked and conflicted
+// files, optionally limited to a path
+func (r *Repo) Status(path string) (*Status, error) {
+	args := []string{"status", "--porcelain=v2", "--branch", "-z", "--untracked-files=all"}
+	if path != "" {
+		args = append(args, "--", path)
+	}
+	out, err := r.git(args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseStatus(out), nil
+}
+
+// parseStatus parses git status --porcelain=v2 --branch -z output
+func parseStatus(out string) *Status {
+	status := &Status{Files: []FileStatus{}}
+	entries := strings.Split(out, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if header, ok := strings.CutPrefix(entry, "# "); ok {
+			key, value, _ := strings.Cut(header, " ")
+			switch key {
+			case "branch.oid":
+				status.Commit = shortHash(value)
+			case "branch.head":
+				if value != "(detached)" {
+					status.Branch = value
+				}
+			case "branch.upstream":
+				status.Upstream = value
+			case "branch.ab":
+				for _, count := range strings.Fields(value) {
+					n, _ := strconv.Atoi(count[1:])
+					if count[0] == '+' {
+						status.Ahead = n
+					} else {
+						status.Behind = n
+					}
+				}
+			}
+			continue
+		}
+		if len(entry) < 2 {
+			continue
+		}
+		switch entry[0] {
+		case '1', '2':
+			// 1 XY sub mH mI mW hH hI path
+			// 2 XY sub mH mI mW hH hI Xscore path, then the original path
+			n := 9
+			if entry[0] == '2' {
+				n = 10
+			}
+			fields := strings.SplitN(entry, " ", n)
+			if len(fields) != n {
+				continue
+			}
+			file := FileStatus{Path: fields[n-1], Index: changeName(fields[1][0]), Worktree: changeName(fields[1][1])}
+			if entry[0] == '2' && i+1 < len(entries) {
+				file.OrigPath = entries[i+1]
+				i++
+			}
+			status.Files = append(status.Files, file)
+		case 'u':
+			// u XY sub m1 m2 m3 mW h1 h2 h3 path
+			if fields := strings.SplitN(entry, " ", 11); len(fields) == 11 {
+				status.Files = append(status.Files, FileStatus{Path: fields[10], Conflicted: true})
+			}
+		case '?':
+			status.Files = append(status.Files, FileStatus{Path: entry[2:], Untracked: true})
+		}
+	}
+	return status
+}
+
+// changeName names a porcelain status letter
+func changeName(c byte) string {
+	switch c {
+	case 'M':
+		return "modified"
+	case 'A':
+		return "added"
+	case 'D':
+		return "deleted"
+	case 'R':
+		return "renamed"
+	case 'C':
+		return "copied"
+	case 'T':
+		return "type_changed"
+	}
+	return ""
+}
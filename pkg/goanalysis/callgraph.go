@@ -0,0 +1,196 @@
+package goanalysis
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"sort"
+)
+
+// maxCallDepth caps how far CallHierarchy follows calls
+const maxCallDepth = 5
+
+// CallEdge is a call from one function to another
+type CallEdge struct {
+	Function Definition `json:"function"` // Caller for incoming calls, callee for outgoing calls
+	Site     Location   `json:"site"`     // Where the call happens
+	Depth    int        `json:"depth"`    // 1 for direct calls
+}
+
+// CallHierarchy holds the callers and callees of a function
+type CallHierarchy struct {
+	Function Definition `json:"function"`
+	Incoming []CallEdge `json:"incoming"`
+	Outgoing []CallEdge `json:"outgoing"`
+}
+
+// call is a static call site in the project
+type call struct {
+	caller string // Object key of the enclosing function
+	callee string // Object key of the called function
+	site   Location
+}
+
+// callGraph is a static call graph built from the project's syntax trees.
+// Calls through interfaces point at the interface method; calls through
+// function values are not tracked.
+type callGraph struct {
+	funcs    map[string]types.Object // Object key -> function
+	outgoing map[string][]call
+	incoming map[string][]call
+}
+
+// buildCallGraph walks every function body of the loaded packages.
+// Must be called with a.mu held.
+func (a *Analyzer) buildCallGraph() *callGraph {
+	g := &callGraph{
+		funcs:    make(map[string]types.Object),
+		outgoing: make(map[string][]call),
+		incoming: make(map[string][]call),
+	}
+	seen := make(map[string]bool)
+
+	for _, pkg := range a.pkgs {
+		for _, file := range pkg.Syntax {
+			for _, decl := range file.Decls {
+				fd, ok := decl.(*ast.FuncDecl)
+				if !ok || fd.Body == nil {
+					continue
+				}
+				caller := pkg.TypesInfo.Defs[fd.Name]
+				callerKey := a.objectKey(caller)
+				if callerKey == "" {
+					continue
+				}
+				g.funcs[callerKey] = caller
+
+				ast.Inspect(fd.Body, func(n ast.Node) bool {
+					ce, ok := n.(*ast.CallExpr)
+					if !ok {
+						return true
+					}
+					callee := calledFunc(pkg.TypesInfo, ce.Fun)
+					calleeKey := a.objectKey(callee)
+					if calleeKey == "" {
+						return true
+					}
+					loc := a.location(ce.Lparen)
+					if loc == nil {
+						return true
+					}
+					// Test variants repeat the same call sites
+					siteKey := fmt.Sprintf("%s|%s|%s:%d:%d", callerKey, calleeKey, loc.Path, loc.Line, loc.Column)
+					if seen[siteKey] {
+						return true
+					}
+					seen[siteKey] = true
+
+					if _, ok := g.funcs[calleeKey]; !ok {
+						g.funcs[calleeKey] = callee
+					}
+					c := call{caller: callerKey, callee: calleeKey, site: *loc}
+					g.outgoing[callerKey] = append(g.outgoing[callerKey], c)
+					g.incoming[calleeKey] = append(g.incoming[calleeKey], c)
+					return true
+				})
+			}
+		}
+	}
+	return g
+}
+
+// calledFunc resolves the function a call expression calls, if it is static
+func calledFunc(info *types.Info, fun ast.Expr) types.Object {
+	for {
+		switch e := fun.(type) {
+		case *ast.ParenExpr:
+			fun = e.X
+			continue
+		case *ast.IndexExpr: // Explicit generic instantiation
+			fun = e.X
+			continue
+		case *ast.IndexListExpr:
+			fun = e.X
+			continue
+		case *ast.Ident:
+			if fn, ok := info.Uses[e].(*types.Func); ok {
+				return fn
+			}
+		case *ast.SelectorExpr:
+			if fn, ok := info.Uses[e.Sel].(*types.Func); ok {
+				return fn
+			}
+		}
+		return nil
+	}
+}
+
+// CallHierarchy returns the callers and callees of the functions a symbol
+// refers to, following calls up to depth levels (default 1)
+func (a *Analyzer) CallHierarchy(ctx context.Context, symbol, file string, line, depth int) ([]CallHierarchy, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.load(ctx); err != nil {
+		return nil, err
+	}
+	if a.calls == nil {
+		a.calls = a.buildCallGraph()
+	}
+	if depth <= 0 {
+		depth = 1
+	}
+	if depth > maxCallDepth {
+		depth = maxCallDepth
+	}
+
+	var hierarchies []CallHierarchy
+	for _, obj := range a.resolve(symbol, file, line) {
+		if _, ok := obj.(*types.Func); !ok {
+			continue
+		}
+		key := a.objectKey(obj)
+		hierarchies = append(hierarchies, CallHierarchy{
+			Function: a.definition(obj),
+			Incoming: a.walkCalls(key, depth, a.calls.incoming, func(c call) string { return c.caller }),
+			Outgoing: a.walkCalls(key, depth, a.calls.outgoing, func(c call) string { return c.callee }),
+		})
+	}
+	return hierarchies, nil
+}
+
+// walkCalls follows call edges breadth-first from a function
+func (a *Analyzer) walkCalls(start string, depth int, edges map[string][]call, next func(call) string) []CallEdge {
+	result := []CallEdge{}
+	visited := map[string]bool{start: true}
+	frontier := []string{start}
+
+	for level := 1; level <= depth && len(frontier) > 0; level++ {
+		var nextFrontier []string
+		var levelEdges []CallEdge
+		for _, key := range frontier {
+			for _, c := range edges[key] {
+				other := next(c)
+				levelEdges = append(levelEdges, CallEdge{
+					Function: a.definition(a.calls.funcs[other]),
+					Site:     c.site,
+					Depth:    level,
+				})
+				if !visited[other] {
+					visited[other] = true
+					nextFrontier = append(nextFrontier, other)
+				}
+			}
+		}
+		sort.Slice(levelEdges, func(i, j int) bool {
+			if levelEdges[i].Site.Path != levelEdges[j].Site.Path {
+				return levelEdges[i].Site.Path < levelEdges[j].Site.Path
+			}
+			return levelEdges[i].Site.Line < levelEdges[j].Site.Line
+		})
+		result = append(result, levelEdges...)
+		frontier = nextFrontier
+	}
+	return result
+}
@@ -0,0 +1,546 @@
+package goanalysis
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// loadMode is what the analyzer needs from go/packages. Dependencies are
+// type-checked from source rather than export data, so the loader does not
+// depend on the export format of the installed toolchain.
+const loadMode = packages.NeedName | packages.NeedFiles | packages.NeedSyntax |
+	packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports | packages.NeedDeps
+
+// Location is a position in a project file
+type Location struct {
+	Path   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Text   string `json:"content,omitempty"`
+}
+
+// Definition is a resolved Go object
+type Definition struct {
+	Name     string    `json:"name"`
+	Kind     string    `json:"kind"` // "func", "method", "type", "interface", "var", "const", "field"
+	Package  string    `json:"package"`
+	Location *Location `json:"location,omitempty"` // nil for objects outside the project
+}
+
+// Analyzer resolves identifiers in a Go module using go/types.
+// Packages are loaded lazily and reloaded when a Go file changes.
+type Analyzer struct {
+	root string
+
+	mu       sync.Mutex
+	fset     *token.FileSet
+	pkgs     []*packages.Package
+	modTimes map[string]time.Time // Go files seen by the last load
+	calls    *callGraph           // Built on demand from pkgs
+	lines    map[string][]string  // File contents for location snippets
+}
+
+// IsGoProject checks if a project root contains a go.mod
+func IsGoProject(root string) bool {
+	_, err := os.Stat(filepath.Join(root, "go.mod"))
+	return err == nil
+}
+
+// New creates an analyzer for the Go module at root
+func New(root string) *Analyzer {
+	return &Analyzer{root: root}
+}
+
+// load (re)loads the module's packages if any Go file changed since the last load.
+// Must be called with a.mu held.
+func (a *Analyzer) load(ctx context.Context) error {
+	modTimes := a.scanGoFiles()
+	if a.pkgs != nil && sameModTimes(a.modTimes, modTimes) {
+		return nil
+	}
+
+	fset := token.NewFileSet()
+	cfg := &packages.Config{
+		Context: ctx,
+		Mode:    loadMode,
+		Dir:     a.root,
+		Fset:    fset,
+		Tests:   true,
+		ParseFile: func(fset *token.FileSet, filename string, src []byte) (*ast.File, error) {
+			return a.parseFile(fset, filename, src)
+		},
+		// Never reach out to the network while resolving symbols
+		Env: append(os.Environ(), "GOPROXY=off", "GOWORK=off"),
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		return fmt.Errorf("failed to load Go packages: %w", err)
+	}
+
+	var loaded []*packages.Package
+	for _, pkg := range pkgs {
+		if pkg.Types != nil && pkg.TypesInfo != nil {
+			loaded = append(loaded, pkg)
+		}
+	}
+	if len(loaded) == 0 {
+		return fmt.Errorf("no Go packages could be loaded in %s", a.root)
+	}
+
+	a.fset = fset
+	a.pkgs = loaded
+	a.modTimes = modTimes
+	a.calls = nil
+	a.lines = make(map[string][]string)
+	return nil
+}
+
+// parseFile parses a Go file for the loader. Function bodies outside the
+// project are dropped: only their declarations matter for type checking.
+func (a *Analyzer) parseFile(fset *token.FileSet, filename string, src []byte) (*ast.File, error) {
+	file, err := parser.ParseFile(fset, filename, src, parser.AllErrors|parser.SkipObjectResolution)
+	if file == nil {
+		return nil, err
+	}
+	if rel, relErr := filepath.Rel(a.root, filename); relErr != nil || strings.HasPrefix(rel, "..") {
+		for _, decl := range file.Decls {
+			if fd, ok := decl.(*ast.FuncDecl); ok {
+				fd.Body = nil
+			}
+		}
+	}
+	return file, err
+}
+
+// scanGoFiles collects the modification times of the module's Go files
+func (a *Analyzer) scanGoFiles() map[string]time.Time {
+	modTimes := make(map[string]time.Time)
+	filepath.Walk(a.root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if path != a.root && (strings.HasPrefix(name, ".") || name == "vendor" || name == "testdata" || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(path, ".go") || info.Name() == "go.mod" {
+			modTimes[path] = info.ModTime()
+		}
+		return nil
+	})
+	return modTimes
+}
+
+// sameModTimes compares two file snapshots
+func sameModTimes(a, b map[string]time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, t := range a {
+		if !b[path].Equal(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// objectKey identifies an object across package variants. The same source file
+// is type-checked separately for a package and its test variant, and imported
+// packages come from export data, so object identity cannot be used directly.
+func (a *Analyzer) objectKey(obj types.Object) string {
+	if obj == nil || !obj.Pos().IsValid() {
+		return ""
+	}
+	pos := a.fset.Position(obj.Pos())
+	return fmt.Sprintf("%s:%d:%s", pos.Filename, pos.Line, obj.Name())
+}
+
+// location converts a token position into a project location, or nil if the
+// position is outside the project
+func (a *Analyzer) location(pos token.Pos) *Location {
+	if !pos.IsValid() {
+		return nil
+	}
+	p := a.fset.Position(pos)
+	rel, err := filepath.Rel(a.root, p.Filename)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return nil
+	}
+	loc := &Location{
+		Path:   filepath.ToSlash(rel),
+		Line:   p.Line,
+		Column: p.Column,
+	}
+	loc.Text = a.lineText(p.Filename, p.Line)
+	return loc
+}
+
+// lineText returns a trimmed source line, reading each file at most once per load
+func (a *Analyzer) lineText(filename string, line int) string {
+	lines, ok := a.lines[filename]
+	if !ok {
+		data, err := os.ReadFile(filename)
+		if err == nil {
+			lines = strings.Split(string(data), "\n")
+		}
+		a.lines[filename] = lines
+	}
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	return strings.TrimSpace(lines[line-1])
+}
+
+// definition describes an object
+func (a *Analyzer) definition(obj types.Object) Definition {
+	def := Definition{
+		Name:     objectName(obj),
+		Kind:     objectKind(obj),
+		Location: a.location(obj.Pos()),
+	}
+	if obj.Pkg() != nil {
+		def.Package = obj.Pkg().Path()
+	}
+	return def
+}
+
+// objectName returns a readable name: pkg.Func, (*Type).Method, Type.Field
+func objectName(obj types.Object) string {
+	if fn, ok := obj.(*types.Func); ok {
+		if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+			recvType := recv.Type()
+			pointer := ""
+			if ptr, ok := recvType.(*types.Pointer); ok {
+				recvType = ptr.Elem()
+				pointer = "*"
+			}
+			name := types.TypeString(recvType, func(*types.Package) string { return "" })
+			if pointer != "" {
+				return fmt.Sprintf("(*%s).%s", name, fn.Name())
+			}
+			return name + "." + fn.Name()
+		}
+	}
+	if obj.Pkg() != nil && obj.Parent() == obj.Pkg().Scope() {
+		return obj.Pkg().Name() + "." + obj.Name()
+	}
+	return obj.Name()
+}
+
+// objectKind classifies an object
+func objectKind(obj types.Object) string {
+	switch o := obj.(type) {
+	case *types.Func:
+		if o.Type().(*types.Signature).Recv() != nil {
+			return "method"
+		}
+		return "func"
+	case *types.TypeName:
+		if types.IsInterface(o.Type()) {
+			return "interface"
+		}
+		return "type"
+	case *types.Const:
+		return "const"
+	case *types.Var:
+		if o.IsField() {
+			return "field"
+		}
+		return "var"
+	}
+	return "other"
+}
+
+// resolve finds the objects a symbol refers to. With a file and line the
+// identifier on that line is used; otherwise symbol is matched against
+// package-level objects, methods and fields ("Name", "Type.Method", "pkg.Name").
+// Must be called with a.mu held.
+func (a *Analyzer) resolve(symbol, file string, line int) []types.Object {
+	name := symbol
+	qualifier := ""
+	if i := strings.LastIndex(symbol, "."); i >= 0 {
+		qualifier = strings.Trim(symbol[:i], "(*)")
+		name = symbol[i+1:]
+	}
+
+	seen := make(map[string]bool)
+	var objects []types.Object
+	add := func(obj types.Object) {
+		key := a.objectKey(obj)
+		if key != "" && !seen[key] {
+			seen[key] = true
+			objects = append(objects, obj)
+		}
+	}
+
+	if file != "" && line > 0 {
+		target := filepath.Join(a.root, filepath.FromSlash(file))
+		for _, pkg := range a.pkgs {
+			for _, f := range pkg.Syntax {
+				if a.fset.Position(f.Pos()).Filename != target {
+					continue
+				}
+				ast.Inspect(f, func(n ast.Node) bool {
+					id, ok := n.(*ast.Ident)
+					if !ok || a.fset.Position(id.Pos()).Line != line || (name != "" && id.Name != name) {
+						return true
+					}
+					if obj := pkg.TypesInfo.ObjectOf(id); obj != nil {
+						add(obj)
+					}
+					return true
+				})
+			}
+		}
+		return objects
+	}
+
+	for _, pkg := range a.pkgs {
+		for id, obj := range pkg.TypesInfo.Defs {
+			if obj == nil || id.Name != name || !isAddressable(obj) {
+				continue
+			}
+			if qualifier != "" && !matchesQualifier(obj, qualifier) {
+				continue
+			}
+			add(obj)
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool { return a.objectKey(objects[i]) < a.objectKey(objects[j]) })
+	return objects
+}
+
+// isAddressable reports whether an object can be looked up by name: package-level
+// objects, methods and struct fields (not locals or parameters)
+func isAddressable(obj types.Object) bool {
+	switch o := obj.(type) {
+	case *types.Func:
+		return true
+	case *types.Var:
+		if o.IsField() {
+			return true
+		}
+	case *types.PkgName:
+		return false
+	}
+	return obj.Pkg() != nil && obj.Parent() == obj.Pkg().Scope()
+}
+
+// matchesQualifier checks a "Type." or "pkg." qualifier against an object
+func matchesQualifier(obj types.Object, qualifier string) bool {
+	if obj.Pkg() != nil && (obj.Pkg().Name() == qualifier || obj.Pkg().Path() == qualifier) && obj.Parent() == obj.Pkg().Scope() {
+		return true
+	}
+	if fn, ok := obj.(*types.Func); ok {
+		if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+			return namedTypeName(recv.Type()) == qualifier
+		}
+	}
+	if v, ok := obj.(*types.Var); ok && v.IsField() {
+		// Fields have no link to their struct; match on the declaring type's name
+		return fieldOwner(v) == qualifier
+	}
+	return false
+}
+
+// fieldOwner finds the name of the named struct type declaring a field
+func fieldOwner(field *types.Var) string {
+	if field.Pkg() == nil {
+		return ""
+	}
+	scope := field.Pkg().Scope()
+	for _, name := range scope.Names() {
+		tn, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok {
+			continue
+		}
+		st, ok := tn.Type().Underlying().(*types.Struct)
+		if !ok {
+			continue
+		}
+		for i := 0; i < st.NumFields(); i++ {
+			if st.Field(i).Pos() == field.Pos() {
+				return tn.Name()
+			}
+		}
+	}
+	return ""
+}
+
+// namedTypeName returns the name of a (pointer to a) named type
+func namedTypeName(t types.Type) string {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	if named, ok := t.(*types.Named); ok {
+		return named.Obj().Name()
+	}
+	return ""
+}
+
+// References lists every use of the object a symbol refers to, including its declaration
+func (a *Analyzer) References(ctx context.Context, symbol, file string, line int) ([]Definition, []Location, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.load(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	objects := a.resolve(symbol, file, line)
+	if len(objects) == 0 {
+		return nil, nil, nil
+	}
+
+	targets := make(map[string]bool)
+	var defs []Definition
+	for _, obj := range objects {
+		targets[a.objectKey(obj)] = true
+		defs = append(defs, a.definition(obj))
+	}
+
+	seen := make(map[string]bool)
+	var refs []Location
+	collect := func(ids map[*ast.Ident]types.Object) {
+		for id, obj := range ids {
+			if obj == nil || !targets[a.objectKey(obj)] {
+				continue
+			}
+			loc := a.location(id.Pos())
+			if loc == nil {
+				continue
+			}
+			key := fmt.Sprintf("%s:%d:%d", loc.Path, loc.Line, loc.Column)
+			if !seen[key] {
+				seen[key] = true
+				refs = append(refs, *loc)
+			}
+		}
+	}
+	for _, pkg := range a.pkgs {
+		collect(pkg.TypesInfo.Defs)
+		collect(pkg.TypesInfo.Uses)
+	}
+
+	sortLocations(refs)
+	return defs, refs, nil
+}
+
+// Implementations lists the project types implementing an interface, or the
+// project interfaces implemented by a concrete type
+func (a *Analyzer) Implementations(ctx context.Context, symbol, file string, line int) ([]Definition, []Definition, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.load(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	var targets []*types.TypeName
+	for _, obj := range a.resolve(symbol, file, line) {
+		if tn, ok := obj.(*types.TypeName); ok {
+			targets = append(targets, tn)
+		}
+	}
+	if len(targets) == 0 {
+		return nil, nil, nil
+	}
+
+	seen := make(map[string]bool)
+	var defs, matches []Definition
+	for _, target := range targets {
+		defs = append(defs, a.definition(target))
+		wantInterface := types.IsInterface(target.Type())
+
+		for _, pkg := range a.pkgs {
+			// Compare against the target as the candidate's package sees it, so
+			// named types in method signatures are identical
+			local := a.lookupIn(pkg, target)
+
+			scope := pkg.Types.Scope()
+			for _, name := range scope.Names() {
+				candidate, ok := scope.Lookup(name).(*types.TypeName)
+				if !ok || candidate.IsAlias() || a.objectKey(candidate) == a.objectKey(target) {
+					continue
+				}
+				if types.IsInterface(candidate.Type()) == wantInterface {
+					continue
+				}
+
+				var match bool
+				if wantInterface {
+					match = implements(candidate.Type(), local.Type())
+				} else {
+					match = implements(local.Type(), candidate.Type())
+				}
+				if !match {
+					continue
+				}
+
+				key := a.objectKey(candidate)
+				if !seen[key] {
+					seen[key] = true
+					matches = append(matches, a.definition(candidate))
+				}
+			}
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Name < matches[j].Name })
+	return defs, matches, nil
+}
+
+// lookupIn returns the object for target as seen from pkg, falling back to target itself
+func (a *Analyzer) lookupIn(pkg *packages.Package, target *types.TypeName) *types.TypeName {
+	if target.Pkg() == nil {
+		return target
+	}
+	scopes := []*types.Package{pkg.Types}
+	scopes = append(scopes, pkg.Types.Imports()...)
+	for _, p := range scopes {
+		if p.Path() != target.Pkg().Path() {
+			continue
+		}
+		if tn, ok := p.Scope().Lookup(target.Name()).(*types.TypeName); ok {
+			return tn
+		}
+	}
+	return target
+}
+
+// implements checks whether a concrete type or its pointer satisfies a non-empty interface
+func implements(concrete, iface types.Type) bool {
+	it, ok := iface.Underlying().(*types.Interface)
+	if !ok || it.Empty() {
+		return false
+	}
+	return types.Implements(concrete, it) || types.Implements(types.NewPointer(concrete), it)
+}
+
+// sortLocations orders locations by file and position
+func sortLocations(locs []Location) {
+	sort.Slice(locs, func(i, j int) bool {
+		if locs[i].Path != locs[j].Path {
+			return locs[i].Path < locs[j].Path
+		}
+		if locs[i].Line != locs[j].Line {
+			return locs[i].Line < locs[j].Line
+		}
+		return locs[i].Column < locs[j].Column
+	})
+}
@@ -0,0 +1,161 @@
+package goanalysis
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestModule writes a small Go module with a cross-package interface and calls
+func newTestModule(t *testing.T) string {
+	t.Helper()
+	files := map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.22\n",
+		"store/store.go": `package store
+
+// Store persists values
+type Store interface {
+	Get(key string) string
+}
+
+// Memory is an in-memory Store
+type Memory struct{ data map[string]string }
+
+// Get returns a value
+func (m *Memory) Get(key string) string { return m.data[key] }
+
+// Start is unrelated to server.Start
+func Start() {}
+`,
+		"server/server.go": `package server
+
+import "example.com/app/store"
+
+type Server struct{ st store.Store }
+
+func New() *Server { return &Server{st: &store.Memory{}} }
+
+func (s *Server) Start() string { return s.lookup("a") }
+
+func (s *Server) lookup(key string) string { return s.st.Get(key) }
+`,
+		"main.go": `package main
+
+import "example.com/app/server"
+
+func main() {
+	srv := server.New()
+	srv.Start()
+}
+`,
+		"server/server_test.go": `package server
+
+import "testing"
+
+func TestStart(t *testing.T) { New().Start() }
+`,
+	}
+	root := t.TempDir()
+	for path, content := range files {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestReferences(t *testing.T) {
+	a := New(newTestModule(t))
+	ctx := context.Background()
+
+	defs, refs, err := a.References(ctx, "Server.Start", "", 0)
+	if err != nil {
+		t.Fatalf("References failed: %v", err)
+	}
+	if len(defs) != 1 || defs[0].Name != "(*Server).Start" || defs[0].Kind != "method" {
+		t.Fatalf("Unexpected definitions: %+v", defs)
+	}
+
+	// Declaration, call in main.go and call in the test; store.Start must not match
+	got := map[string]int{}
+	for _, ref := range refs {
+		got[ref.Path]++
+	}
+	want := map[string]int{"server/server.go": 1, "main.go": 1, "server/server_test.go": 1}
+	for path, n := range want {
+		if got[path] != n {
+			t.Errorf("Expected %d reference(s) in %s, got %d (%+v)", n, path, got[path], refs)
+		}
+	}
+	if got["store/store.go"] != 0 {
+		t.Errorf("store.Start must not be a reference of Server.Start: %+v", refs)
+	}
+
+	// Resolving by position picks the identifier on that line
+	defs, _, err = a.References(ctx, "Start", "main.go", 7)
+	if err != nil || len(defs) != 1 || defs[0].Package != "example.com/app/server" {
+		t.Errorf("Expected position lookup to resolve server.Start, got %+v (err %v)", defs, err)
+	}
+}
+
+func TestImplementations(t *testing.T) {
+	a := New(newTestModule(t))
+	ctx := context.Background()
+
+	_, impls, err := a.Implementations(ctx, "store.Store", "", 0)
+	if err != nil {
+		t.Fatalf("Implementations failed: %v", err)
+	}
+	if len(impls) != 1 || impls[0].Name != "store.Memory" {
+		t.Errorf("Expected store.Memory to implement store.Store, got %+v", impls)
+	}
+
+	_, ifaces, err := a.Implementations(ctx, "Memory", "", 0)
+	if err != nil {
+		t.Fatalf("Implementations failed: %v", err)
+	}
+	if len(ifaces) != 1 || ifaces[0].Name != "store.Store" {
+		t.Errorf("Expected store.Memory to implement store.Store, got %+v", ifaces)
+	}
+}
+
+func TestCallHierarchy(t *testing.T) {
+	a := New(newTestModule(t))
+
+	hierarchies, err := a.CallHierarchy(context.Background(), "Server.Start", "", 0, 2)
+	if err != nil {
+		t.Fatalf("CallHierarchy failed: %v", err)
+	}
+	if len(hierarchies) != 1 {
+		t.Fatalf("Expected one function, got %+v", hierarchies)
+	}
+	h := hierarchies[0]
+
+	callers := map[string]bool{}
+	for _, edge := range h.Incoming {
+		callers[edge.Function.Name] = true
+	}
+	if !callers["main.main"] || !callers["server.TestStart"] {
+		t.Errorf("Expected main.main and server.TestStart as callers, got %+v", h.Incoming)
+	}
+
+	var direct, indirect []string
+	for _, edge := range h.Outgoing {
+		if edge.Depth == 1 {
+			direct = append(direct, edge.Function.Name)
+		} else {
+			indirect = append(indirect, edge.Function.Name)
+		}
+	}
+	if len(direct) != 1 || direct[0] != "(*Server).lookup" {
+		t.Errorf("Expected Start to call lookup, got %v", direct)
+	}
+	if len(indirect) != 1 || indirect[0] != "Store.Get" {
+		t.Errorf("Expected lookup to call Store.Get, got %v", indirect)
+	}
+}
@@ -0,0 +1,528 @@
+package indexer
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Import extraction patterns
+var (
+	phpUsePattern       = regexp.MustCompile(`(?m)^\s*use\s+(?:function\s+|const\s+)?\\?([A-Za-z_][A-Za-z0-9_\\]*)(?:\s*\{([^}]*)\})?`)
+	jsImportFromPattern = regexp.MustCompile(`(?m)^\s*(?:import|export)\s[^'"]*?\sfrom\s*['"]([^'"]+)['"]`)
+	jsSideEffectPattern = regexp.MustCompile(`(?m)^\s*import\s*['"]([^'"]+)['"]`)
+	jsRequirePattern    = regexp.MustCompile(`(?:require|import)\s*\(\s*['"]([^'"]+)['"]\s*\)`)
+	pyImportPattern     = regexp.MustCompile(`(?m)^\s*import\s+([A-Za-z0-9_.,\s]+?)\s*(?:#.*)?$`)
+	pyFromPattern       = regexp.MustCompile(`(?m)^\s*from\s+(\.*[A-Za-z0-9_.]*)\s+import\s+(?:\(([^)]*)\)|([A-Za-z0-9_*, \t]+))`)
+)
+
+// jsExtensions are tried, in order, when resolving extension-less JS/TS imports
+var jsExtensions = []string{".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs", ".vue"}
+
+// depGraph is a file-level dependency graph between indexed files
+type depGraph struct {
+	imports    map[string][]string // file -> files it imports
+	external   map[string][]string // file -> imports that did not resolve to a project file
+	dependents map[string][]string // file -> files importing it
+}
+
+// ImpactEntry is a file affected by a change, with its distance in the dependency graph
+type ImpactEntry struct {
+	Path  string `json:"path"`
+	Depth int    `json:"depth"`
+}
+
+// extractImports returns the raw import specifiers of a source file
+func extractImports(ext string, content []byte) []string {
+	switch ext {
+	case ".go":
+		return extractGoImports(content)
+	case ".php":
+		return extractPHPImports(string(content))
+	case ".js", ".jsx", ".ts", ".tsx", ".mjs", ".cjs", ".vue":
+		return extractJSImports(string(content))
+	case ".py":
+		return extractPythonImports(string(content))
+	}
+	return nil
+}
+
+// extractGoImports parses the import declarations of a Go file
+func extractGoImports(content []byte) []string {
+	file, err := parser.ParseFile(token.NewFileSet(), "", content, parser.ImportsOnly)
+	if err != nil {
+		return nil
+	}
+	var imports []string
+	for _, spec := range file.Imports {
+		if p, err := strconv.Unquote(spec.Path.Value); err == nil {
+			imports = append(imports, p)
+		}
+	}
+	return imports
+}
+
+// extractPHPImports returns the fully qualified class names of PHP use statements
+func extractPHPImports(content string) []string {
+	var imports []string
+	for _, m := range phpUsePattern.FindAllStringSubmatch(content, -1) {
+		prefix := strings.TrimSuffix(m[1], "\\")
+		if m[2] == "" {
+			imports = append(imports, prefix)
+			continue
+		}
+		// Group use: use App\Models\{User, Post as P};
+		for _, item := range strings.Split(m[2], ",") {
+			name := strings.TrimSpace(strings.SplitN(strings.TrimSpace(item), " ", 2)[0])
+			if name != "" {
+				imports = append(imports, prefix+"\\"+name)
+			}
+		}
+	}
+	return uniqueStrings(imports)
+}
+
+// extractJSImports returns the module specifiers of import/export/require statements
+func extractJSImports(content string) []string {
+	var imports []string
+	for _, pattern := range []*regexp.Regexp{jsImportFromPattern, jsSideEffectPattern, jsRequirePattern} {
+		for _, m := range pattern.FindAllStringSubmatch(content, -1) {
+			imports = append(imports, m[1])
+		}
+	}
+	return uniqueStrings(imports)
+}
+
+// extractPythonImports returns dotted module names; relative imports keep their leading dots.
+// For "from pkg import name" both "pkg" and "pkg.name" are returned, since name may be a submodule.
+func extractPythonImports(content string) []string {
+	var imports []string
+	for _, m := range pyImportPattern.FindAllStringSubmatch(content, -1) {
+		for _, item := range strings.Split(m[1], ",") {
+			name := strings.Fields(strings.TrimSpace(item))
+			if len(name) > 0 {
+				imports = append(imports, name[0])
+			}
+		}
+	}
+	for _, m := range pyFromPattern.FindAllStringSubmatch(content, -1) {
+		module := m[1]
+		imports = append(imports, module)
+		for _, item := range strings.Split(m[2]+m[3], ",") {
+			name := strings.Fields(strings.TrimSpace(item))
+			if len(name) == 0 || name[0] == "*" {
+				continue
+			}
+			if strings.HasSuffix(module, ".") {
+				imports = append(imports, module+name[0])
+			} else {
+				imports = append(imports, module+"."+name[0])
+			}
+		}
+	}
+	return uniqueStrings(imports)
+}
+
+// importResolver maps import specifiers to project files
+type importResolver struct {
+	files     map[string]*FileInfo
+	goModule  string              // Module path from go.mod
+	goPkgs    map[string][]string // directory -> non-test Go files
+	psr4      []psr4Mapping       // Composer PSR-4 autoload map, longest prefix first
+	tsBaseURL string              // compilerOptions.baseUrl from tsconfig.json
+	tsPaths   map[string][]string // compilerOptions.paths from tsconfig.json
+}
+
+// psr4Mapping maps a namespace prefix to source directories
+type psr4Mapping struct {
+	prefix string
+	dirs   []string
+}
+
+// newImportResolver reads go.mod, composer.json and tsconfig.json from the project root
+func newImportResolver(projectRoot string, files map[string]*FileInfo) *importResolver {
+	r := &importResolver{
+		files:  files,
+		goPkgs: make(map[string][]string),
+	}
+
+	for p, info := range files {
+		if !info.IsDir && info.Extension == ".go" && !strings.HasSuffix(p, "_test.go") {
+			dir := path.Dir(p)
+			r.goPkgs[dir] = append(r.goPkgs[dir], p)
+		}
+	}
+	for dir := range r.goPkgs {
+		sort.Strings(r.goPkgs[dir])
+	}
+
+	if data, err := os.ReadFile(filepath.Join(projectRoot, "go.mod")); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) >= 2 && fields[0] == "module" {
+				r.goModule = strings.Trim(fields[1], `"`)
+				break
+			}
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(projectRoot, "composer.json")); err == nil {
+		var composer struct {
+			Autoload struct {
+				PSR4 map[string]json.RawMessage `json:"psr-4"`
+			} `json:"autoload"`
+			AutoloadDev struct {
+				PSR4 map[string]json.RawMessage `json:"psr-4"`
+			} `json:"autoload-dev"`
+		}
+		if json.Unmarshal(data, &composer) == nil {
+			for _, section := range []map[string]json.RawMessage{composer.Autoload.PSR4, composer.AutoloadDev.PSR4} {
+				for prefix, raw := range section {
+					r.psr4 = append(r.psr4, psr4Mapping{prefix: prefix, dirs: decodeStringOrList(raw)})
+				}
+			}
+			sort.Slice(r.psr4, func(i, j int) bool { return len(r.psr4[i].prefix) > len(r.psr4[j].prefix) })
+		}
+	}
+
+	for _, name := range []string{"tsconfig.json", "jsconfig.json"} {
+		data, err := os.ReadFile(filepath.Join(projectRoot, name))
+		if err != nil {
+			continue
+		}
+		var tsconfig struct {
+			CompilerOptions struct {
+				BaseURL string              `json:"baseUrl"`
+				Paths   map[string][]string `json:"paths"`
+			} `json:"compilerOptions"`
+		}
+		if json.Unmarshal(cleanJSONC(data), &tsconfig) == nil {
+			r.tsBaseURL = path.Clean(strings.TrimPrefix(tsconfig.CompilerOptions.BaseURL, "./"))
+			r.tsPaths = tsconfig.CompilerOptions.Paths
+			break
+		}
+	}
+
+	return r
+}
+
+// resolve returns the project files an import specifier refers to
+func (r *importResolver) resolve(from, ext, spec string) []string {
+	switch ext {
+	case ".go":
+		return r.resolveGo(spec)
+	case ".php":
+		return r.resolvePHP(spec)
+	case ".js", ".jsx", ".ts", ".tsx", ".mjs", ".cjs", ".vue":
+		return r.resolveJS(from, spec)
+	case ".py":
+		return r.resolvePython(from, spec)
+	}
+	return nil
+}
+
+// resolveGo maps an import path inside the module to the files of that package
+func (r *importResolver) resolveGo(spec string) []string {
+	if r.goModule == "" {
+		return nil
+	}
+	var dir string
+	switch {
+	case spec == r.goModule:
+		dir = "."
+	case strings.HasPrefix(spec, r.goModule+"/"):
+		dir = strings.TrimPrefix(spec, r.goModule+"/")
+	default:
+		return nil
+	}
+	return r.goPkgs[dir]
+}
+
+// resolvePHP maps a fully qualified class name to a file via the PSR-4 map
+func (r *importResolver) resolvePHP(spec string) []string {
+	for _, m := range r.psr4 {
+		if !strings.HasPrefix(spec, m.prefix) {
+			continue
+		}
+		rel := strings.ReplaceAll(strings.TrimPrefix(spec, m.prefix), "\\", "/") + ".php"
+		for _, dir := range m.dirs {
+			candidate := path.Clean(path.Join(dir, rel))
+			if r.isFile(candidate) {
+				return []string{candidate}
+			}
+		}
+	}
+	return nil
+}
+
+// resolveJS resolves relative imports, tsconfig path aliases and baseUrl imports
+func (r *importResolver) resolveJS(from, spec string) []string {
+	var bases []string
+	switch {
+	case strings.HasPrefix(spec, "./") || strings.HasPrefix(spec, "../"):
+		bases = append(bases, path.Join(path.Dir(from), spec))
+	default:
+		for pattern, targets := range r.tsPaths {
+			prefix, wildcard := strings.CutSuffix(pattern, "*")
+			if !(wildcard && strings.HasPrefix(spec, prefix)) && pattern != spec {
+				continue
+			}
+			rest := strings.TrimPrefix(spec, prefix)
+			for _, target := range targets {
+				target = strings.Replace(target, "*", rest, 1)
+				bases = append(bases, path.Join(r.tsBaseURL, target))
+			}
+		}
+		if r.tsBaseURL != "" {
+			bases = append(bases, path.Join(r.tsBaseURL, spec))
+		}
+	}
+
+	for _, base := range bases {
+		if file := r.resolveJSFile(path.Clean(base)); file != "" {
+			return []string{file}
+		}
+	}
+	return nil
+}
+
+// resolveJSFile applies Node-style extension and index file resolution
+func (r *importResolver) resolveJSFile(base string) string {
+	if r.isFile(base) {
+		return base
+	}
+	for _, ext := range jsExtensions {
+		if r.isFile(base + ext) {
+			return base + ext
+		}
+	}
+	for _, ext := range jsExtensions {
+		if candidate := base + "/index" + ext; r.isFile(candidate) {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// resolvePython maps a dotted module name to module.py or package/__init__.py
+func (r *importResolver) resolvePython(from, spec string) []string {
+	dots := len(spec) - len(strings.TrimLeft(spec, "."))
+	module := strings.ReplaceAll(strings.TrimLeft(spec, "."), ".", "/")
+
+	var bases []string
+	if dots > 0 {
+		dir := path.Dir(from)
+		for i := 1; i < dots; i++ {
+			dir = path.Dir(dir)
+		}
+		bases = append(bases, path.Join(dir, module))
+	} else {
+		bases = append(bases, module, path.Join("src", module))
+	}
+
+	for _, base := range bases {
+		base = path.Clean(base)
+		if r.isFile(base + ".py") {
+			return []string{base + ".py"}
+		}
+		if r.isFile(base + "/__init__.py") {
+			return []string{base + "/__init__.py"}
+		}
+	}
+	return nil
+}
+
+// isFile checks if a path is an indexed file
+func (r *importResolver) isFile(p string) bool {
+	info, ok := r.files[p]
+	return ok && !info.IsDir
+}
+
+// buildDepGraph resolves the raw imports of all files into a dependency graph
+func buildDepGraph(projectRoot string, files map[string]*FileInfo) *depGraph {
+	r := newImportResolver(projectRoot, files)
+	g := &depGraph{
+		imports:    make(map[string][]string),
+		external:   make(map[string][]string),
+		dependents: make(map[string][]string),
+	}
+
+	for p, info := range files {
+		if info.IsDir || len(info.Imports) == 0 {
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, spec := range info.Imports {
+			targets := r.resolve(p, info.Extension, spec)
+			if len(targets) == 0 {
+				// Python "from pkg import name" also yields pkg.name, which is usually not a module
+				if !(info.Extension == ".py" && strings.Contains(strings.TrimLeft(spec, "."), ".") && r.resolvePython(p, spec[:strings.LastIndex(spec, ".")]) != nil) {
+					g.external[p] = append(g.external[p], spec)
+				}
+				continue
+			}
+			for _, target := range targets {
+				if target == p || seen[target] {
+					continue
+				}
+				seen[target] = true
+				g.imports[p] = append(g.imports[p], target)
+				g.dependents[target] = append(g.dependents[target], p)
+			}
+		}
+	}
+
+	for _, m := range []map[string][]string{g.imports, g.external, g.dependents} {
+		for k := range m {
+			sort.Strings(m[k])
+		}
+	}
+	return g
+}
+
+// GetImports returns the project files a file imports, and its imports that
+// refer to external packages
+func (idx *Index) GetImports(filePath string) (internal []string, external []string, err error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	if _, ok := idx.files[filePath]; !ok {
+		return nil, nil, fmt.Errorf("file not found in index: %s", filePath)
+	}
+	if idx.deps == nil {
+		return nil, nil, nil
+	}
+	return idx.deps.imports[filePath], idx.deps.external[filePath], nil
+}
+
+// GetDependents returns the project files that import a file directly
+func (idx *Index) GetDependents(filePath string) ([]string, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	if _, ok := idx.files[filePath]; !ok {
+		return nil, fmt.Errorf("file not found in index: %s", filePath)
+	}
+	if idx.deps == nil {
+		return nil, nil
+	}
+	return idx.deps.dependents[filePath], nil
+}
+
+// ImpactOfChange returns every file that directly or transitively depends on a
+// file, ordered by distance. maxDepth limits the traversal (0 means unlimited).
+func (idx *Index) ImpactOfChange(filePath string, maxDepth int) ([]ImpactEntry, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	if _, ok := idx.files[filePath]; !ok {
+		return nil, fmt.Errorf("file not found in index: %s", filePath)
+	}
+	if idx.deps == nil {
+		return nil, nil
+	}
+
+	var impact []ImpactEntry
+	visited := map[string]bool{filePath: true}
+	frontier := []string{filePath}
+	for depth := 1; len(frontier) > 0 && (maxDepth <= 0 || depth <= maxDepth); depth++ {
+		var next []string
+		for _, file := range frontier {
+			for _, dependent := range idx.deps.dependents[file] {
+				if visited[dependent] {
+					continue
+				}
+				visited[dependent] = true
+				next = append(next, dependent)
+			}
+		}
+		sort.Strings(next)
+		for _, file := range next {
+			impact = append(impact, ImpactEntry{Path: file, Depth: depth})
+		}
+		frontier = next
+	}
+	return impact, nil
+}
+
+// decodeStringOrList decodes a JSON value that is either a string or a list of strings
+func decodeStringOrList(raw json.RawMessage) []string {
+	var single string
+	if json.Unmarshal(raw, &single) == nil {
+		return []string{single}
+	}
+	var list []string
+	json.Unmarshal(raw, &list)
+	return list
+}
+
+// cleanJSONC strips comments and trailing commas so tsconfig-style JSON can be decoded
+func cleanJSONC(data []byte) []byte {
+	var out []byte
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if inString {
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+		switch {
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			out = append(out, '\n')
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			i += 2
+			for i+1 < len(data) && !(data[i] == '*' && data[i+1] == '/') {
+				i++
+			}
+			i++
+		default:
+			out = append(out, c)
+		}
+	}
+	return trailingCommaPattern.ReplaceAll(out, []byte("$1"))
+}
+
+// trailingCommaPattern matches a comma directly before a closing bracket
+var trailingCommaPattern = regexp.MustCompile(`,(\s*[}\]])`)
+
+// uniqueStrings removes duplicates while keeping order
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var unique []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+// isImportSource checks if imports are extracted for a file extension
+func isImportSource(ext string) bool {
+	switch ext {
+	case ".go", ".php", ".js", ".jsx", ".ts", ".tsx", ".mjs", ".cjs", ".vue", ".py":
+		return true
+	}
+	return false
+}
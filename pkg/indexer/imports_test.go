@@ -0,0 +1,125 @@
+package indexer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractImports(t *testing.T) {
+	tests := []struct {
+		ext     string
+		content string
+		want    []string
+	}{
+		{".go", "package main\n\nimport (\n\t\"fmt\"\n\tu \"example.com/app/pkg/util\"\n)\n", []string{"fmt", "example.com/app/pkg/util"}},
+		{".php", "<?php\nnamespace App;\n\nuse App\\Models\\User;\nuse App\\Services\\{Mailer, Billing as B};\n", []string{"App\\Models\\User", "App\\Services\\Mailer", "App\\Services\\Billing"}},
+		{".ts", "import { a } from './a'\nimport type { B } from \"@/b\";\nexport * from '../c'\nimport './styles.css'\nconst d = require('d')\n", []string{"./a", "@/b", "../c", "./styles.css", "d"}},
+		{".py", "import os, sys\nfrom .models import User\nfrom app.core import (config, db)\n", []string{"os", "sys", "app.core", "app.core.config", "app.core.db", ".models", ".models.User"}},
+	}
+
+	for _, tt := range tests {
+		got := extractImports(tt.ext, []byte(tt.content))
+		want := map[string]bool{}
+		for _, w := range tt.want {
+			want[w] = true
+		}
+		gotSet := map[string]bool{}
+		for _, g := range got {
+			gotSet[g] = true
+		}
+		if !reflect.DeepEqual(gotSet, want) {
+			t.Errorf("extractImports(%s) = %v, want %v", tt.ext, got, tt.want)
+		}
+	}
+}
+
+func TestDependencyGraph(t *testing.T) {
+	files := map[string]string{
+		// Go
+		"go.mod":                 "module example.com/app\n\ngo 1.22\n",
+		"main.go":                "package main\n\nimport \"example.com/app/pkg/util\"\n\nfunc main() { util.Add(1, 2) }\n",
+		"pkg/util/util.go":       "package util\n\nimport \"strings\"\n\nfunc Add(a, b int) int { return a + b }\n",
+		"pkg/util/extra.go":      "package util\n",
+		"pkg/util/extra_test.go": "package util\n",
+		// PHP
+		"composer.json":               `{"autoload": {"psr-4": {"App\\": "app/"}}}`,
+		"app/Models/User.php":         "<?php\nnamespace App\\Models;\n\nclass User {}\n",
+		"app/Http/UserController.php": "<?php\nnamespace App\\Http;\n\nuse App\\Models\\User;\nuse Illuminate\\Http\\Request;\n\nclass UserController {}\n",
+		// TypeScript
+		"tsconfig.json":         "{\n  // comment\n  \"compilerOptions\": {\n    \"baseUrl\": \".\",\n    \"paths\": {\"@/*\": [\"web/src/*\"]},\n  },\n}\n",
+		"web/src/api/client.ts": "export const client = {}\n",
+		"web/src/api/index.ts":  "export * from './client'\n",
+		"web/src/app.tsx":       "import { client } from '@/api'\nimport React from 'react'\n",
+		// Python
+		"app_py/__init__.py": "",
+		"app_py/models.py":   "class User: pass\n",
+		"app_py/views.py":    "from .models import User\nfrom app_py import models\nimport requests\n",
+	}
+	root, cfg := newTestProject(t, files)
+
+	idx := NewIndex(root, cfg)
+	if err := idx.IndexProject(); err != nil {
+		t.Fatalf("IndexProject failed: %v", err)
+	}
+
+	imports, external, err := idx.GetImports("main.go")
+	if err != nil {
+		t.Fatalf("GetImports failed: %v", err)
+	}
+	if want := []string{"pkg/util/extra.go", "pkg/util/util.go"}; !reflect.DeepEqual(imports, want) {
+		t.Errorf("Go imports = %v, want %v", imports, want)
+	}
+	if len(external) != 0 {
+		t.Errorf("Expected no external Go imports, got %v", external)
+	}
+
+	imports, external, _ = idx.GetImports("app/Http/UserController.php")
+	if want := []string{"app/Models/User.php"}; !reflect.DeepEqual(imports, want) {
+		t.Errorf("PHP imports = %v, want %v", imports, want)
+	}
+	if want := []string{"Illuminate\\Http\\Request"}; !reflect.DeepEqual(external, want) {
+		t.Errorf("PHP external imports = %v, want %v", external, want)
+	}
+
+	imports, external, _ = idx.GetImports("web/src/app.tsx")
+	if want := []string{"web/src/api/index.ts"}; !reflect.DeepEqual(imports, want) {
+		t.Errorf("TS imports = %v, want %v", imports, want)
+	}
+	if want := []string{"react"}; !reflect.DeepEqual(external, want) {
+		t.Errorf("TS external imports = %v, want %v", external, want)
+	}
+
+	imports, external, _ = idx.GetImports("app_py/views.py")
+	if want := []string{"app_py/__init__.py", "app_py/models.py"}; !reflect.DeepEqual(imports, want) {
+		t.Errorf("Python imports = %v, want %v", imports, want)
+	}
+	if want := []string{"requests"}; !reflect.DeepEqual(external, want) {
+		t.Errorf("Python external imports = %v, want %v", external, want)
+	}
+
+	dependents, err := idx.GetDependents("app/Models/User.php")
+	if err != nil {
+		t.Fatalf("GetDependents failed: %v", err)
+	}
+	if want := []string{"app/Http/UserController.php"}; !reflect.DeepEqual(dependents, want) {
+		t.Errorf("Dependents = %v, want %v", dependents, want)
+	}
+
+	impact, err := idx.ImpactOfChange("web/src/api/client.ts", 0)
+	if err != nil {
+		t.Fatalf("ImpactOfChange failed: %v", err)
+	}
+	wantImpact := []ImpactEntry{{Path: "web/src/api/index.ts", Depth: 1}, {Path: "web/src/app.tsx", Depth: 2}}
+	if !reflect.DeepEqual(impact, wantImpact) {
+		t.Errorf("ImpactOfChange = %v, want %v", impact, wantImpact)
+	}
+
+	impact, _ = idx.ImpactOfChange("web/src/api/client.ts", 1)
+	if len(impact) != 1 {
+		t.Errorf("Expected max depth 1 to limit impact, got %v", impact)
+	}
+
+	if _, err := idx.GetDependents("missing.go"); err == nil {
+		t.Error("Expected error for file not in index")
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/axon/pkg/fsctx"
 	"github.com/axon/pkg/project"
@@ -13,13 +16,15 @@ import (
 
 // FileInfo represents information about an indexed file
 type FileInfo struct {
-	Path        string   `json:"path"`
-	Size        int64    `json:"size"`
-	Extension   string   `json:"extension"`
-	IsDir       bool     `json:"is_dir"`
-	Classes     []string `json:"classes,omitempty"`
-	Functions   []string `json:"functions,omitempty"`
-	Symbols     []Symbol `json:"symbols,omitempty"`
+	Path      string    `json:"path"`
+	Size      int64     `json:"size"`
+	ModTime   time.Time `json:"mod_time"`
+	Extension string    `json:"extension"`
+	IsDir     bool      `json:"is_dir"`
+	Classes   []string  `json:"classes,omitempty"`
+	Functions []string  `json:"functions,omitempty"`
+	Symbols   []Symbol  `json:"symbols,omitempty"`
+	Imports   []string  `json:"imports,omitempty"` // Raw import specifiers
 }
 
 // Symbol represents a code symbol (class, function, etc.)
@@ -36,7 +41,18 @@ type Index struct {
 	cfg         *project.Config
 	files       map[string]*FileInfo // path -> FileInfo
 	tree        *TreeNode
+	search      *searchIndex // Inverted index for full-text search
+	deps        *depGraph    // File-level import graph
+	generation  uint64       // Incremented every time a new index is swapped in
 	mu          sync.RWMutex
+
+	laravel    *LaravelApp // Laravel analysis, computed on demand
+	laravelGen uint64      // Generation the Laravel analysis was computed for
+	laravelMu  sync.Mutex
+
+	workers  int                 // Number of parser workers (0 = runtime.NumCPU())
+	progress func(IndexProgress) // Optional progress callback
+	indexing sync.Mutex          // Serializes IndexProject calls
 }
 
 // TreeNode represents a directory tree node
@@ -48,30 +64,161 @@ type TreeNode struct {
 	Files    []string             `json:"files,omitempty"`
 }
 
+// IndexProgress describes the state of a running IndexProject call
+type IndexProgress struct {
+	Discovered int           // Files found by the directory walker so far
+	Processed  int           // Files fully processed by the parser workers
+	WalkDone   bool          // True once the directory walk has finished
+	Done       bool          // True for the final report of a run
+	Elapsed    time.Duration // Time since indexing started
+}
+
+// FilesPerSecond returns the processing rate so far
+func (p IndexProgress) FilesPerSecond() float64 {
+	if p.Elapsed <= 0 {
+		return 0
+	}
+	return float64(p.Processed) / p.Elapsed.Seconds()
+}
+
+// Remaining returns the number of discovered files not yet processed
+func (p IndexProgress) Remaining() int {
+	return p.Discovered - p.Processed
+}
+
+// String formats the progress as a short status line
+func (p IndexProgress) String() string {
+	if p.Done {
+		return fmt.Sprintf("%d files in %s (%.0f files/s)", p.Processed, p.Elapsed.Round(time.Millisecond), p.FilesPerSecond())
+	}
+	total := fmt.Sprintf("%d", p.Discovered)
+	if !p.WalkDone {
+		total += "+"
+	}
+	return fmt.Sprintf("%d/%s files, %.0f files/s, %d remaining", p.Processed, total, p.FilesPerSecond(), p.Remaining())
+}
+
+// parseJob is a single file handed from the walker to a parser worker
+type parseJob struct {
+	fullPath string
+	info     *FileInfo
+	terms    map[string]int // Search terms, filled in by the worker
+}
+
+// progressInterval is how often the progress callback fires while indexing
+const progressInterval = 100 * time.Millisecond
+
 // NewIndex creates a new project index
 func NewIndex(projectRoot string, cfg *project.Config) *Index {
 	return &Index{
 		projectRoot: projectRoot,
 		cfg:         cfg,
 		files:       make(map[string]*FileInfo),
+		tree:        newRootNode(projectRoot),
+		search:      newSearchIndex(),
 	}
 }
 
-// IndexProject indexes the entire project
-func (idx *Index) IndexProject() error {
+// SetWorkers sets the number of parser workers used by IndexProject.
+// Zero or a negative value means one worker per CPU. A run already in
+// progress keeps the workers it started with.
+func (idx *Index) SetWorkers(n int) {
 	idx.mu.Lock()
 	defer idx.mu.Unlock()
+	idx.workers = n
+}
 
-	// Clear existing index
-	idx.files = make(map[string]*FileInfo)
-	idx.tree = &TreeNode{
-		Name:     filepath.Base(idx.projectRoot),
-		Path:     "",
-		IsDir:    true,
-		Children: make(map[string]*TreeNode),
+// SetProgressFunc sets a callback that receives progress reports while indexing.
+// The callback is invoked from a background goroutine; a run already in
+// progress keeps reporting to the callback it started with.
+func (idx *Index) SetProgressFunc(fn func(IndexProgress)) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.progress = fn
+}
+
+// IndexProject indexes the entire project.
+// A directory walker feeds code and text files to a pool of parser workers
+// that extract symbols and build the full-text search index. The new index
+// is built off to the side and swapped in at the end, so readers keep seeing the
+// previous index while re-indexing is in progress.
+func (idx *Index) IndexProject() error {
+	idx.indexing.Lock()
+	defer idx.indexing.Unlock()
+
+	start := time.Now()
+	files := make(map[string]*FileInfo)
+	tree := newRootNode(idx.projectRoot)
+
+	idx.mu.RLock()
+	workers, progress := idx.workers, idx.progress
+	idx.mu.RUnlock()
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	var discovered, processed int64
+	var walkDone atomic.Bool
+	snapshot := func(done bool) IndexProgress {
+		return IndexProgress{
+			Discovered: int(atomic.LoadInt64(&discovered)),
+			Processed:  int(atomic.LoadInt64(&processed)),
+			WalkDone:   walkDone.Load(),
+			Done:       done,
+			Elapsed:    time.Since(start),
+		}
+	}
+
+	// Periodic progress reports
+	stopReports := make(chan struct{})
+	reportsDone := make(chan struct{})
+	go func() {
+		defer close(reportsDone)
+		if progress == nil {
+			return
+		}
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopReports:
+				return
+			case <-ticker.C:
+				progress(snapshot(false))
+			}
+		}
+	}()
+
+	// Parser workers
+	jobs := make(chan *parseJob, workers*4)
+	var queued []*parseJob
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				var content []byte
+				if job.info.Size <= fsctx.MaxFileSize {
+					content, _ = os.ReadFile(job.fullPath)
+				}
+				if idx.isCodeFile(job.fullPath) {
+					symbols := idx.extractSymbols(job.fullPath, job.info.Path)
+					fillSignatures(symbols, content)
+					job.info.Symbols = symbols
+					job.info.Classes = extractClassNames(symbols)
+					job.info.Functions = extractFunctionNames(symbols)
+				}
+				if isImportSource(job.info.Extension) {
+					job.info.Imports = extractImports(job.info.Extension, content)
+				}
+				job.terms = documentTerms(job.info.Path, content)
+				atomic.AddInt64(&processed, 1)
+			}
+		}()
 	}
 
-	return filepath.Walk(idx.projectRoot, func(path string, info os.FileInfo, err error) error {
+	walkErr := filepath.Walk(idx.projectRoot, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // Skip errors
 		}
@@ -102,25 +249,76 @@ func (idx *Index) IndexProject() error {
 		fileInfo := &FileInfo{
 			Path:      normalizedPath,
 			Size:      info.Size(),
+			ModTime:   info.ModTime(),
 			Extension: fsctx.GetFileExtension(path),
 			IsDir:     info.IsDir(),
 		}
 
-		// If it's a code file, extract symbols
-		if !info.IsDir() && idx.isCodeFile(path) {
-			symbols := idx.extractSymbols(path, normalizedPath)
-			fileInfo.Symbols = symbols
-			fileInfo.Classes = extractClassNames(symbols)
-			fileInfo.Functions = extractFunctionNames(symbols)
+		files[normalizedPath] = fileInfo
+
+		// Build tree structure
+		addToTree(tree, normalizedPath, fileInfo)
+
+		if info.IsDir() {
+			return nil
 		}
 
-		idx.files[normalizedPath] = fileInfo
+		atomic.AddInt64(&discovered, 1)
 
-		// Build tree structure
-		idx.addToTree(normalizedPath, fileInfo)
+		// Code and text files are handed to the workers for symbol
+		// extraction and full-text indexing
+		if idx.isSearchableFile(path) {
+			job := &parseJob{fullPath: path, info: fileInfo}
+			queued = append(queued, job)
+			jobs <- job
+		} else {
+			atomic.AddInt64(&processed, 1)
+		}
 
 		return nil
 	})
+	walkDone.Store(true)
+
+	close(jobs)
+	wg.Wait()
+
+	close(stopReports)
+	<-reportsDone
+
+	if walkErr != nil {
+		return walkErr
+	}
+
+	search := newSearchIndex()
+	for _, job := range queued {
+		search.addDocument(job.info.Path, job.terms)
+	}
+	deps := buildDepGraph(idx.projectRoot, files)
+
+	// Swap in the new index
+	idx.mu.Lock()
+	idx.files = files
+	idx.tree = tree
+	idx.search = search
+	idx.deps = deps
+	idx.generation++
+	idx.mu.Unlock()
+
+	if progress != nil {
+		progress(snapshot(true))
+	}
+
+	return nil
+}
+
+// newRootNode creates the root node of a project tree
+func newRootNode(projectRoot string) *TreeNode {
+	return &TreeNode{
+		Name:     filepath.Base(projectRoot),
+		Path:     "",
+		IsDir:    true,
+		Children: make(map[string]*TreeNode),
+	}
 }
 
 // shouldIgnore checks if a path should be ignored
@@ -166,7 +364,7 @@ func (idx *Index) isCodeFile(path string) bool {
 // extractSymbols extracts code symbols (classes, functions) from a file
 func (idx *Index) extractSymbols(fullPath, relPath string) []Symbol {
 	ext := fsctx.GetFileExtension(fullPath)
-	
+
 	switch ext {
 	case ".go":
 		return parseGoFile(fullPath)
@@ -195,10 +393,10 @@ func (idx *Index) extractSymbols(fullPath, relPath string) []Symbol {
 	}
 }
 
-// addToTree adds a file to the tree structure
-func (idx *Index) addToTree(path string, fileInfo *FileInfo) {
+// addToTree adds a file to the tree structure rooted at root
+func addToTree(root *TreeNode, path string, fileInfo *FileInfo) {
 	parts := strings.Split(path, "/")
-	current := idx.tree
+	current := root
 
 	for i, part := range parts {
 		if i == len(parts)-1 {
@@ -221,25 +419,32 @@ func (idx *Index) addToTree(path string, fileInfo *FileInfo) {
 			}
 		} else {
 			// Intermediate directory
-				if current.Children[part] == nil {
-					current.Children[part] = &TreeNode{
-						Name:     part,
-						Path:     strings.Join(parts[:i+1], "/"),
-						IsDir:    true,
-						Children: make(map[string]*TreeNode),
-						Files:    []string{},
-					}
+			if current.Children[part] == nil {
+				current.Children[part] = &TreeNode{
+					Name:     part,
+					Path:     strings.Join(parts[:i+1], "/"),
+					IsDir:    true,
+					Children: make(map[string]*TreeNode),
+					Files:    []string{},
 				}
+			}
 			current = current.Children[part]
 		}
 	}
 }
 
+// Generation returns a counter that changes every time the index is rebuilt
+func (idx *Index) Generation() uint64 {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.generation
+}
+
 // GetFileInfo returns information about a file
 func (idx *Index) GetFileInfo(path string) (*FileInfo, bool) {
 	idx.mu.RLock()
 	defer idx.mu.RUnlock()
-	
+
 	info, ok := idx.files[path]
 	return info, ok
 }
@@ -294,6 +499,29 @@ func (idx *Index) GetAllFilePaths() []string {
 	return paths
 }
 
+// maxSignatureLength caps the length of a symbol signature
+const maxSignatureLength = 160
+
+// fillSignatures sets each symbol's signature to its trimmed declaration line
+// when the language parser did not provide one
+func fillSignatures(symbols []Symbol, content []byte) {
+	if len(symbols) == 0 || len(content) == 0 {
+		return
+	}
+	lines := strings.Split(string(content), "\n")
+	for i := range symbols {
+		if symbols[i].Signature != "" || symbols[i].Line < 1 || symbols[i].Line > len(lines) {
+			continue
+		}
+		sig := strings.TrimSpace(lines[symbols[i].Line-1])
+		sig = strings.TrimSpace(strings.TrimSuffix(sig, "{"))
+		if len(sig) > maxSignatureLength {
+			sig = sig[:maxSignatureLength] + "..."
+		}
+		symbols[i].Signature = sig
+	}
+}
+
 // Helper functions to extract class and function names
 func extractClassNames(symbols []Symbol) []string {
 	classes := []string{}
@@ -314,4 +542,3 @@ func extractFunctionNames(symbols []Symbol) []string {
 	}
 	return functions
 }
-
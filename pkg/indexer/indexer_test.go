@@ -0,0 +1,152 @@
+package indexer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/axon/pkg/project"
+)
+
+// newTestProject writes files into a temporary project and returns its root and config
+func newTestProject(t *testing.T, files map[string]string) (string, *project.Config) {
+	t.Helper()
+	root := t.TempDir()
+	for path, content := range files {
+		fullPath := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	cfg := &project.Config{}
+	cfg.Context.Ignore = []string{"vendor/", ".git/"}
+	return root, cfg
+}
+
+func TestIndexProject(t *testing.T) {
+	files := map[string]string{
+		"main.go":             "package main\n\nfunc main() {}\n",
+		"pkg/util/util.go":    "package util\n\ntype Helper struct{}\n\nfunc (h *Helper) Run() {}\n",
+		"app/User.php":        "<?php\nclass User\n{\n    public function name() {}\n}\n",
+		"README.md":           "# readme\n",
+		"vendor/lib/lib.go":   "package lib\n\nfunc Ignored() {}\n",
+		"web/src/index.ts":    "export function render() {}\n",
+		"scripts/install.sh":  "install() {\n}\n",
+		"pkg/util/helpers.go": "package util\n\nfunc Add(a, b int) int { return a + b }\n",
+	}
+	root, cfg := newTestProject(t, files)
+
+	idx := NewIndex(root, cfg)
+	idx.SetWorkers(3)
+	if err := idx.IndexProject(); err != nil {
+		t.Fatalf("IndexProject failed: %v", err)
+	}
+
+	if _, ok := idx.GetFileInfo("vendor/lib/lib.go"); ok {
+		t.Error("Expected vendor/ to be ignored")
+	}
+
+	symbols, err := idx.GetFileSymbols("pkg/util/util.go")
+	if err != nil {
+		t.Fatalf("GetFileSymbols failed: %v", err)
+	}
+	names := map[string]string{}
+	for _, sym := range symbols {
+		names[sym.Name] = sym.Type
+	}
+	if names["Helper"] != "struct" || names["Run"] != "function" {
+		t.Errorf("Unexpected symbols for util.go: %+v", symbols)
+	}
+
+	info, ok := idx.GetFileInfo("app/User.php")
+	if !ok || len(info.Classes) != 1 || info.Classes[0] != "User" {
+		t.Errorf("Expected User class in app/User.php, got %+v", info)
+	}
+
+	tree, err := idx.GetTree("pkg/util")
+	if err != nil {
+		t.Fatalf("GetTree failed: %v", err)
+	}
+	if len(tree.Files) != 2 {
+		t.Errorf("Expected 2 files under pkg/util, got %v", tree.Files)
+	}
+}
+
+func TestIndexProject_Progress(t *testing.T) {
+	files := map[string]string{}
+	for i := 0; i < 50; i++ {
+		files[fmt.Sprintf("pkg/p%d/file.go", i)] = "package p\n\nfunc F() {}\n"
+	}
+	files["notes.txt"] = "not code\n"
+	root, cfg := newTestProject(t, files)
+
+	var mu sync.Mutex
+	var reports []IndexProgress
+	idx := NewIndex(root, cfg)
+	idx.SetProgressFunc(func(p IndexProgress) {
+		mu.Lock()
+		defer mu.Unlock()
+		reports = append(reports, p)
+	})
+	if err := idx.IndexProject(); err != nil {
+		t.Fatalf("IndexProject failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reports) == 0 {
+		t.Fatal("Expected at least one progress report")
+	}
+	final := reports[len(reports)-1]
+	if !final.Done || !final.WalkDone {
+		t.Errorf("Expected final report to be done, got %+v", final)
+	}
+	if final.Discovered != 51 || final.Processed != 51 || final.Remaining() != 0 {
+		t.Errorf("Unexpected final counts: %+v", final)
+	}
+}
+
+func TestIndexProject_ReadersDuringReindex(t *testing.T) {
+	files := map[string]string{}
+	for i := 0; i < 200; i++ {
+		files[fmt.Sprintf("src/f%d.go", i)] = "package src\n\nfunc F() {}\n"
+	}
+	root, cfg := newTestProject(t, files)
+
+	idx := NewIndex(root, cfg)
+	if err := idx.IndexProject(); err != nil {
+		t.Fatalf("IndexProject failed: %v", err)
+	}
+
+	// Readers must always see a complete index while re-indexing runs
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if n := len(idx.GetAllFilePaths()); n != 201 { // 200 files + src/
+				t.Errorf("Reader saw partial index with %d entries", n)
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < 3; i++ {
+		if err := idx.IndexProject(); err != nil {
+			t.Fatalf("IndexProject failed: %v", err)
+		}
+	}
+	close(done)
+	wg.Wait()
+}
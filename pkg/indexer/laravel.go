@@ -0,0 +1,508 @@
+package indexer
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// LaravelApp is the Laravel-specific model of a project: its route table,
+// Eloquent models and the database schema reconstructed from migrations
+type LaravelApp struct {
+	Routes []Route           `json:"routes"`
+	Models []Model           `json:"models"`
+	Tables map[string]*Table `json:"tables"`
+}
+
+// Route is one entry of the route table
+type Route struct {
+	Methods     []string `json:"methods"`
+	URI         string   `json:"uri"`
+	Name        string   `json:"name,omitempty"`
+	Action      string   `json:"action"`               // Controller@method, Closure, view:... or redirect:...
+	Controller  string   `json:"controller,omitempty"` // Fully qualified controller class
+	Handler     string   `json:"handler,omitempty"`    // Controller method
+	Middleware  []string `json:"middleware,omitempty"`
+	File        string   `json:"file"` // Routes file defining the route
+	Line        int      `json:"line"`
+	HandlerFile string   `json:"handler_file,omitempty"`
+	HandlerLine int      `json:"handler_line,omitempty"`
+}
+
+// Model is an Eloquent model class
+type Model struct {
+	Name      string            `json:"name"`
+	Class     string            `json:"class"` // Fully qualified class name
+	File      string            `json:"file"`
+	Line      int               `json:"line"`
+	Table     string            `json:"table"`
+	Fillable  []string          `json:"fillable,omitempty"`
+	Guarded   []string          `json:"guarded,omitempty"`
+	Hidden    []string          `json:"hidden,omitempty"`
+	Casts     map[string]string `json:"casts,omitempty"`
+	Relations []Relation        `json:"relations,omitempty"`
+}
+
+// Relation is a relationship method of a model
+type Relation struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`              // hasMany, belongsTo, ...
+	Related string `json:"related,omitempty"` // Fully qualified class of the related model
+	Line    int    `json:"line"`
+}
+
+// laravelModelBases are the framework classes an Eloquent model extends
+var laravelModelBases = map[string]bool{
+	"Illuminate\\Database\\Eloquent\\Model":                 true,
+	"Illuminate\\Foundation\\Auth\\User":                    true,
+	"Illuminate\\Database\\Eloquent\\Relations\\Pivot":      true,
+	"Illuminate\\Database\\Eloquent\\Relations\\MorphPivot": true,
+}
+
+// laravelRelations are the relationship builders recognized in model methods
+var laravelRelations = map[string]bool{
+	"hasOne": true, "hasMany": true, "belongsTo": true, "belongsToMany": true,
+	"hasOneThrough": true, "hasManyThrough": true, "morphTo": true, "morphOne": true,
+	"morphMany": true, "morphToMany": true, "morphedByMany": true,
+}
+
+var (
+	phpClassDeclPattern = regexp.MustCompile(`(?m)^\s*(?:(?:abstract|final|readonly)\s+)*class\s+([A-Za-z_][A-Za-z0-9_]*)\s+extends\s+\\?([A-Za-z_][A-Za-z0-9_\\]*)`)
+	phpMethodPattern    = regexp.MustCompile(`function\s+&?\s*([A-Za-z_][A-Za-z0-9_]*)\s*\(`)
+)
+
+// IsLaravelProject reports whether a directory contains a Laravel application
+func IsLaravelProject(root string) bool {
+	if _, err := os.Stat(filepath.Join(root, "artisan")); err != nil {
+		return false
+	}
+	data, err := os.ReadFile(filepath.Join(root, "composer.json"))
+	return err == nil && strings.Contains(string(data), "laravel/framework")
+}
+
+// Laravel analyzes the project as a Laravel application. The result is cached
+// until the index is rebuilt. It returns nil if the project is not a Laravel app.
+func (idx *Index) Laravel() *LaravelApp {
+	if !IsLaravelProject(idx.projectRoot) {
+		return nil
+	}
+
+	idx.mu.RLock()
+	files, generation := idx.files, idx.generation
+	idx.mu.RUnlock()
+
+	idx.laravelMu.Lock()
+	defer idx.laravelMu.Unlock()
+	if idx.laravel != nil && idx.laravelGen == generation {
+		return idx.laravel
+	}
+
+	resolver := newImportResolver(idx.projectRoot, files)
+	app := &LaravelApp{
+		Models: parseLaravelModels(idx.projectRoot, files),
+		Tables: parseLaravelMigrations(idx.projectRoot, files),
+	}
+	app.Routes = parseLaravelRoutes(idx.projectRoot, files, resolver)
+
+	idx.laravel, idx.laravelGen = app, generation
+	return app
+}
+
+// FindModel finds a model by class name, fully qualified class or table name
+func (app *LaravelApp) FindModel(name string) (*Model, bool) {
+	name = strings.TrimPrefix(name, "\\")
+	for i := range app.Models {
+		m := &app.Models[i]
+		if strings.EqualFold(m.Name, name) || strings.EqualFold(m.Class, name) {
+			return m, true
+		}
+	}
+	for i := range app.Models {
+		if app.Models[i].Table == name {
+			return &app.Models[i], true
+		}
+	}
+	return nil, false
+}
+
+// MatchRoutes returns the routes matching a concrete or pattern URI (e.g.
+// /users/42 or /users/{user}) and optionally an HTTP method
+func (app *LaravelApp) MatchRoutes(uri, method string) []Route {
+	method = strings.ToUpper(method)
+	var matches []Route
+	for _, r := range app.Routes {
+		if method != "" && !r.AllowsMethod(method) {
+			continue
+		}
+		if matchRouteURI(r.URI, uri) {
+			matches = append(matches, r)
+		}
+	}
+	return matches
+}
+
+// AllowsMethod reports whether a route answers an HTTP method
+func (r Route) AllowsMethod(method string) bool {
+	method = strings.ToUpper(method)
+	for _, m := range r.Methods {
+		if m == method || m == "ANY" {
+			return true
+		}
+	}
+	return false
+}
+
+// matchRouteURI matches a URI against a route pattern with {param} and {param?} segments
+func matchRouteURI(pattern, uri string) bool {
+	if i := strings.IndexAny(uri, "?#"); i >= 0 {
+		uri = uri[:i]
+	}
+	if strings.HasPrefix(uri, "http://") || strings.HasPrefix(uri, "https://") {
+		// Drop the scheme and host of a full URL
+		rest := uri[strings.Index(uri, "//")+2:]
+		uri = "/"
+		if i := strings.IndexByte(rest, '/'); i >= 0 {
+			uri = rest[i:]
+		}
+	}
+	want := splitURI(pattern)
+	got := splitURI(uri)
+	for i, seg := range want {
+		isParam := strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}")
+		if i >= len(got) {
+			return isParam && strings.HasSuffix(seg, "?}")
+		}
+		if isParam {
+			continue
+		}
+		if !strings.EqualFold(seg, got[i]) {
+			return false
+		}
+	}
+	return len(got) == len(want)
+}
+
+// splitURI splits a URI into its non-empty path segments
+func splitURI(uri string) []string {
+	var segments []string
+	for _, s := range strings.Split(uri, "/") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+	return segments
+}
+
+// parseLaravelModels finds the Eloquent models among the project's PHP files
+func parseLaravelModels(projectRoot string, files map[string]*FileInfo) []Model {
+	type candidate struct {
+		path, name, class, base string
+		src                     *phpSource
+		uses                    map[string]string
+		namespace               string
+		offset                  int
+	}
+
+	var candidates []candidate
+	for p, info := range files {
+		if info.IsDir || info.Extension != ".php" || strings.HasPrefix(p, "vendor/") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(projectRoot, p))
+		if err != nil || !strings.Contains(string(data), "extends") {
+			continue
+		}
+		src := newPHPSource(string(data))
+		m := phpClassDeclPattern.FindStringSubmatchIndex(src.text)
+		if m == nil {
+			continue
+		}
+		namespace, uses := phpNamespace(src.text), phpUses(src.text)
+		name := src.text[m[2]:m[3]]
+		class := name
+		if namespace != "" {
+			class = namespace + "\\" + name
+		}
+		candidates = append(candidates, candidate{
+			path: p, name: name, class: class,
+			base: resolvePHPClass(src.text[m[4]:m[5]], namespace, uses),
+			src:  src, uses: uses, namespace: namespace, offset: m[2],
+		})
+	}
+
+	// Models can extend other models (e.g. a shared base model), so keep
+	// adding classes whose parent is known to be a model until nothing changes
+	isModel := make(map[string]bool)
+	for changed := true; changed; {
+		changed = false
+		for _, c := range candidates {
+			if !isModel[c.class] && (laravelModelBases[c.base] || isModel[c.base]) {
+				isModel[c.class] = true
+				changed = true
+			}
+		}
+	}
+
+	var models []Model
+	for _, c := range candidates {
+		if !isModel[c.class] {
+			continue
+		}
+		model := Model{
+			Name:  c.name,
+			Class: c.class,
+			File:  c.path,
+			Line:  c.src.line(c.offset),
+		}
+		if table, ok := phpProperty(c.src, "table"); ok {
+			model.Table, _ = phpString(table)
+		}
+		if model.Table == "" {
+			model.Table = laravelTableName(c.name, strings.HasSuffix(c.base, "Pivot"))
+		}
+		if v, ok := phpProperty(c.src, "fillable"); ok {
+			model.Fillable = phpStrings(v)
+		}
+		if v, ok := phpProperty(c.src, "guarded"); ok {
+			model.Guarded = phpStrings(v)
+		}
+		if v, ok := phpProperty(c.src, "hidden"); ok {
+			model.Hidden = phpStrings(v)
+		}
+		model.Casts = phpModelCasts(c.src)
+		model.Relations = phpModelRelations(c.src, c.namespace, c.uses)
+		models = append(models, model)
+	}
+
+	sort.Slice(models, func(i, j int) bool { return models[i].Class < models[j].Class })
+	return models
+}
+
+// phpProperty returns the raw initializer of a class property such as $table
+func phpProperty(src *phpSource, name string) (string, bool) {
+	for _, offset := range src.findAll("$"+name, 0, len(src.text)) {
+		i := offset + len(name) + 1
+		if i < len(src.text) && isIdentByte(src.text[i]) {
+			continue // A longer name such as $tableName
+		}
+		i = skipSpaces(src.text, i)
+		if i >= len(src.text) || src.text[i] != '=' || strings.HasPrefix(src.text[i:], "==") {
+			continue
+		}
+		if value, ok := phpExpression(src.text, i+1); ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// phpExpression returns the expression starting at i, up to the terminating semicolon
+func phpExpression(s string, i int) (string, bool) {
+	start := skipSpaces(s, i)
+	for j := start; j < len(s); j++ {
+		switch s[j] {
+		case '\'', '"':
+			j = skipPHPString(s, j) - 1
+		case '(', '[', '{':
+			end := matchingBracket(s, j)
+			if end < 0 {
+				return "", false
+			}
+			j = end
+		case ';':
+			return strings.TrimSpace(s[start:j]), true
+		}
+	}
+	return "", false
+}
+
+// phpModelCasts reads the $casts property and the casts() method of a model
+func phpModelCasts(src *phpSource) map[string]string {
+	casts := make(map[string]string)
+	if v, ok := phpProperty(src, "casts"); ok {
+		for k, v := range phpArrayMap(v) {
+			casts[k] = phpCastType(v)
+		}
+	}
+	for _, loc := range phpMethodPattern.FindAllStringSubmatchIndex(src.text, -1) {
+		if src.text[loc[2]:loc[3]] != "casts" {
+			continue
+		}
+		start, end, ok := phpMethodBody(src.text, loc[1])
+		if !ok {
+			continue
+		}
+		body := src.text[start:end]
+		if r := strings.Index(body, "return"); r >= 0 {
+			if v, ok := phpExpression(body, r+len("return")); ok {
+				for k, v := range phpArrayMap(v) {
+					casts[k] = phpCastType(v)
+				}
+			}
+		}
+	}
+	if len(casts) == 0 {
+		return nil
+	}
+	return casts
+}
+
+// phpCastType turns a cast value (a string or a class reference) into a readable type
+func phpCastType(v string) string {
+	if s, ok := phpString(v); ok {
+		return s
+	}
+	if c, ok := phpClassRef(v); ok {
+		return c
+	}
+	return v
+}
+
+// phpMethodBody returns the body offsets of the method whose parameter list opens just before i
+func phpMethodBody(s string, i int) (start, end int, ok bool) {
+	closeParams := matchingBracket(s, i-1)
+	if closeParams < 0 {
+		return 0, 0, false
+	}
+	open := strings.IndexAny(s[closeParams:], "{;")
+	if open < 0 || s[closeParams+open] == ';' {
+		return 0, 0, false // Abstract or interface method
+	}
+	open += closeParams
+	close := matchingBracket(s, open)
+	if close < 0 {
+		return 0, 0, false
+	}
+	return open + 1, close, true
+}
+
+// phpModelRelations finds the methods of a model that return a relationship
+func phpModelRelations(src *phpSource, namespace string, uses map[string]string) []Relation {
+	var relations []Relation
+	for _, loc := range phpMethodPattern.FindAllStringSubmatchIndex(src.text, -1) {
+		start, end, ok := phpMethodBody(src.text, loc[1])
+		if !ok {
+			continue
+		}
+		for _, offset := range src.findAll("$this->", start, end) {
+			calls, _ := src.parseChain(offset + len("$this->"))
+			if len(calls) == 0 || !laravelRelations[calls[0].name] {
+				continue
+			}
+			relation := Relation{Name: src.text[loc[2]:loc[3]], Type: calls[0].name, Line: src.line(loc[0])}
+			if len(calls[0].args) > 0 {
+				if class, ok := phpClassRef(calls[0].args[0]); ok {
+					relation.Related = resolvePHPClass(class, namespace, uses)
+				} else if s, ok := phpString(calls[0].args[0]); ok && calls[0].name != "morphTo" {
+					relation.Related = strings.TrimPrefix(s, "\\")
+				}
+			}
+			relations = append(relations, relation)
+			break
+		}
+	}
+	return relations
+}
+
+// laravelTableName infers a model's table the way Eloquent does: the snake
+// case plural of the class name (singular for pivot models)
+func laravelTableName(class string, pivot bool) string {
+	snake := snakeCase(class)
+	if pivot {
+		return snake
+	}
+	return pluralize(snake)
+}
+
+// snakeCase converts StudlyCase to snake_case
+func snakeCase(s string) string {
+	var sb strings.Builder
+	runes := []rune(s)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				sb.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// irregularPlurals covers common English words the suffix rules get wrong
+var irregularPlurals = map[string]string{
+	"person": "people", "man": "men", "woman": "women", "child": "children",
+	"mouse": "mice", "goose": "geese", "foot": "feet", "tooth": "teeth",
+	"datum": "data", "criterion": "criteria", "medium": "media",
+}
+
+// uncountableWords are left unchanged by pluralize and singularize
+var uncountableWords = map[string]bool{
+	"data": true, "equipment": true, "information": true, "media": true, "metadata": true,
+	"news": true, "series": true, "species": true, "feedback": true, "software": true,
+}
+
+// pluralize pluralizes the last word of a snake_case name
+func pluralize(s string) string {
+	prefix, word := "", s
+	if i := strings.LastIndexByte(s, '_'); i >= 0 {
+		prefix, word = s[:i+1], s[i+1:]
+	}
+	switch {
+	case uncountableWords[word]:
+	case irregularPlurals[word] != "":
+		word = irregularPlurals[word]
+	case strings.HasSuffix(word, "y") && len(word) > 1 && !strings.ContainsRune("aeiou", rune(word[len(word)-2])):
+		word = word[:len(word)-1] + "ies"
+	case strings.HasSuffix(word, "s"), strings.HasSuffix(word, "x"), strings.HasSuffix(word, "z"),
+		strings.HasSuffix(word, "ch"), strings.HasSuffix(word, "sh"):
+		word += "es"
+	default:
+		word += "s"
+	}
+	return prefix + word
+}
+
+// singularize reverses pluralize for the last word of a snake_case name
+func singularize(s string) string {
+	prefix, word := "", s
+	if i := strings.LastIndexByte(s, '_'); i >= 0 {
+		prefix, word = s[:i+1], s[i+1:]
+	}
+	for singular, plural := range irregularPlurals {
+		if word == plural {
+			return prefix + singular
+		}
+	}
+	switch {
+	case uncountableWords[word]:
+	case strings.HasSuffix(word, "ies") && len(word) > 3:
+		word = word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "ses"), strings.HasSuffix(word, "xes"), strings.HasSuffix(word, "zes"),
+		strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "shes"):
+		word = word[:len(word)-2]
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+		word = word[:len(word)-1]
+	}
+	return prefix + word
+}
+
+// laravelFiles returns the indexed PHP files under a directory, sorted by path
+func laravelFiles(files map[string]*FileInfo, dir string) []string {
+	var paths []string
+	for p, info := range files {
+		if !info.IsDir && info.Extension == ".php" && strings.HasPrefix(p, dir+"/") {
+			paths = append(paths, p)
+		}
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		return path.Base(paths[i]) < path.Base(paths[j]) || path.Base(paths[i]) == path.Base(paths[j]) && paths[i] < paths[j]
+	})
+	return paths
+}
@@ -0,0 +1,464 @@
+package indexer
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Table is a database table as built up by the migrations
+type Table struct {
+	Name        string       `json:"name"`
+	Columns     []Column     `json:"columns"`
+	Indexes     []TableIndex `json:"indexes,omitempty"`
+	ForeignKeys []ForeignKey `json:"foreign_keys,omitempty"`
+	Migrations  []string     `json:"migrations"` // Migration files that created or altered the table, in order
+}
+
+// Column is a table column
+type Column struct {
+	Name          string `json:"name"`
+	Type          string `json:"type"` // Blueprint method, e.g. string(100) or unsignedBigInteger
+	Nullable      bool   `json:"nullable,omitempty"`
+	Default       string `json:"default,omitempty"`
+	Primary       bool   `json:"primary,omitempty"`
+	Unique        bool   `json:"unique,omitempty"`
+	Unsigned      bool   `json:"unsigned,omitempty"`
+	AutoIncrement bool   `json:"auto_increment,omitempty"`
+}
+
+// TableIndex is an index over one or more columns
+type TableIndex struct {
+	Type    string   `json:"type"` // index, unique, primary, fullText or spatialIndex
+	Columns []string `json:"columns"`
+}
+
+// ForeignKey is a foreign key constraint
+type ForeignKey struct {
+	Column     string `json:"column"`
+	References string `json:"references"` // Referenced column
+	On         string `json:"on"`         // Referenced table
+	OnDelete   string `json:"on_delete,omitempty"`
+	OnUpdate   string `json:"on_update,omitempty"`
+}
+
+// blueprintParamPattern matches the Blueprint parameter of a Schema closure
+var blueprintParamPattern = regexp.MustCompile(`^\s*(?:static\s+)?function\s*\(\s*(?:[A-Za-z_\\][A-Za-z0-9_\\]*\s+)?(\$[A-Za-z_][A-Za-z0-9_]*)`)
+
+// primaryColumnTypes are the auto-incrementing primary key column types
+var primaryColumnTypes = map[string]bool{
+	"increments": true, "bigIncrements": true, "smallIncrements": true,
+	"tinyIncrements": true, "mediumIncrements": true, "integerIncrements": true,
+}
+
+// tableLevelIndexes are Blueprint methods that add an index when called on the table
+var tableLevelIndexes = map[string]bool{
+	"index": true, "unique": true, "primary": true, "fullText": true, "spatialIndex": true,
+}
+
+// parseLaravelMigrations replays the up() methods of database/migrations in
+// filename order to reconstruct the schema
+func parseLaravelMigrations(projectRoot string, files map[string]*FileInfo) map[string]*Table {
+	tables := make(map[string]*Table)
+	for _, file := range laravelFiles(files, "database/migrations") {
+		data, err := os.ReadFile(filepath.Join(projectRoot, file))
+		if err != nil {
+			continue
+		}
+		src := newPHPSource(string(data))
+		for _, loc := range phpMethodPattern.FindAllStringSubmatchIndex(src.text, -1) {
+			if src.text[loc[2]:loc[3]] != "up" {
+				continue
+			}
+			start, end, ok := phpMethodBody(src.text, loc[1])
+			if !ok {
+				continue
+			}
+			m := &migration{file: file, src: src, tables: tables}
+			m.replay(start, end)
+		}
+	}
+	return tables
+}
+
+// migration replays one migration file against the schema
+type migration struct {
+	file   string
+	src    *phpSource
+	tables map[string]*Table
+}
+
+// replay applies the Schema:: calls in text[start:end]
+func (m *migration) replay(start, end int) {
+	next := start
+	for _, offset := range m.src.findAll("Schema::", start, end) {
+		if offset < next {
+			continue
+		}
+		calls, after := m.src.parseChain(offset + len("Schema::"))
+		next = after
+		for _, call := range calls {
+			m.schemaCall(call)
+		}
+	}
+}
+
+// schemaCall applies one Schema facade call such as create or dropIfExists
+func (m *migration) schemaCall(call phpCall) {
+	if len(call.args) == 0 {
+		return
+	}
+	name, ok := phpString(call.args[0])
+	if !ok {
+		return
+	}
+
+	switch call.name {
+	case "create", "table":
+		if call.name == "create" || m.tables[name] == nil {
+			m.tables[name] = &Table{Name: name}
+		}
+		table := m.table(name)
+		if len(call.args) < 2 {
+			return
+		}
+		start, end, ok := m.src.phpClosureBody(call, 1)
+		if !ok {
+			return
+		}
+		param := blueprintParamPattern.FindStringSubmatch(call.args[1])
+		if param == nil {
+			return
+		}
+		for _, offset := range m.src.findAll(param[1]+"->", start, end) {
+			calls, _ := m.src.parseChain(offset + len(param[1]) + 2)
+			if len(calls) > 0 {
+				m.blueprintCall(table, calls[0], calls[1:])
+			}
+		}
+	case "rename":
+		to := argString(call.args, 1)
+		if table, ok := m.tables[name]; ok && to != "" {
+			delete(m.tables, name)
+			table.Name = to
+			m.tables[to] = table
+			table.Migrations = append(table.Migrations, m.file)
+		}
+	case "drop", "dropIfExists":
+		delete(m.tables, name)
+	case "dropColumns":
+		if table, ok := m.tables[name]; ok && len(call.args) > 1 {
+			for _, column := range phpStrings(call.args[1]) {
+				table.dropColumn(column)
+			}
+			table.Migrations = append(table.Migrations, m.file)
+		}
+	}
+}
+
+// table returns a table and records the current migration as touching it
+func (m *migration) table(name string) *Table {
+	table := m.tables[name]
+	if n := len(table.Migrations); n == 0 || table.Migrations[n-1] != m.file {
+		table.Migrations = append(table.Migrations, m.file)
+	}
+	return table
+}
+
+// blueprintCall applies one $table->... chain inside a Schema closure
+func (m *migration) blueprintCall(table *Table, call phpCall, modifiers []phpCall) {
+	first := func(def string) string {
+		if s := argString(call.args, 0); s != "" {
+			return s
+		}
+		return def
+	}
+
+	switch call.name {
+	case "id":
+		table.addColumn(Column{Name: first("id"), Type: "bigIncrements", Primary: true, Unsigned: true, AutoIncrement: true}, modifiers)
+	case "timestamps", "timestampsTz", "nullableTimestamps":
+		columnType := "timestamp"
+		if call.name == "timestampsTz" {
+			columnType = "timestampTz"
+		}
+		table.addColumn(Column{Name: "created_at", Type: columnType, Nullable: true}, nil)
+		table.addColumn(Column{Name: "updated_at", Type: columnType, Nullable: true}, nil)
+	case "softDeletes", "softDeletesTz":
+		table.addColumn(Column{Name: first("deleted_at"), Type: strings.Replace(call.name, "softDeletes", "timestamp", 1), Nullable: true}, modifiers)
+	case "rememberToken":
+		table.addColumn(Column{Name: "remember_token", Type: "string(100)", Nullable: true}, nil)
+	case "morphs", "nullableMorphs", "uuidMorphs", "nullableUuidMorphs", "ulidMorphs", "nullableUlidMorphs":
+		name := first("")
+		idType := "unsignedBigInteger"
+		if strings.Contains(call.name, "Uuid") {
+			idType = "uuid"
+		} else if strings.Contains(call.name, "Ulid") {
+			idType = "ulid"
+		}
+		nullable := strings.HasPrefix(call.name, "nullable")
+		table.addColumn(Column{Name: name + "_type", Type: "string", Nullable: nullable}, nil)
+		table.addColumn(Column{Name: name + "_id", Type: idType, Nullable: nullable}, nil)
+		table.Indexes = append(table.Indexes, TableIndex{Type: "index", Columns: []string{name + "_type", name + "_id"}})
+	case "foreignIdFor":
+		class, ok := phpClassRef(argString(call.args, 0))
+		if !ok {
+			return
+		}
+		base := class[strings.LastIndex(class, "\\")+1:]
+		name := snakeCase(base) + "_id"
+		if len(call.args) > 1 {
+			name = argString(call.args, 1)
+		}
+		table.addColumn(Column{Name: name, Type: "unsignedBigInteger", Unsigned: true}, modifiers)
+		table.addForeignKey(name, laravelTableName(base, false), false, modifiers)
+	case "foreignId", "foreignUuid", "foreignUlid":
+		name := first("")
+		columnType := map[string]string{"foreignId": "unsignedBigInteger", "foreignUuid": "uuid", "foreignUlid": "ulid"}[call.name]
+		table.addColumn(Column{Name: name, Type: columnType, Unsigned: call.name == "foreignId"}, modifiers)
+		table.addForeignKey(name, "", false, modifiers)
+	case "foreign":
+		for _, column := range phpStrings(argString(call.args, 0)) {
+			table.addForeignKey(column, "", true, modifiers)
+		}
+	case "dropColumn":
+		for _, a := range call.args {
+			for _, column := range phpStrings(a) {
+				table.dropColumn(column)
+			}
+		}
+	case "dropTimestamps", "dropTimestampsTz":
+		table.dropColumn("created_at")
+		table.dropColumn("updated_at")
+	case "dropSoftDeletes", "dropSoftDeletesTz":
+		table.dropColumn(first("deleted_at"))
+	case "dropRememberToken":
+		table.dropColumn("remember_token")
+	case "dropMorphs":
+		table.dropColumn(first("") + "_type")
+		table.dropColumn(first("") + "_id")
+	case "renameColumn":
+		from, to := argString(call.args, 0), argString(call.args, 1)
+		for i := range table.Columns {
+			if table.Columns[i].Name == from {
+				table.Columns[i].Name = to
+			}
+		}
+		for i := range table.ForeignKeys {
+			if table.ForeignKeys[i].Column == from {
+				table.ForeignKeys[i].Column = to
+			}
+		}
+	case "dropForeign":
+		for _, column := range foreignKeyColumns(table.Name, call.args) {
+			table.dropForeignKey(column)
+		}
+	case "dropConstrainedForeignId":
+		table.dropForeignKey(first(""))
+		table.dropColumn(first(""))
+	case "dropPrimary", "dropUnique", "dropIndex", "dropFullText", "dropSpatialIndex":
+		if len(call.args) > 0 {
+			if columns, ok := phpArrayItems(call.args[0]); ok {
+				table.dropIndex(phpStrings("[" + strings.Join(columns, ",") + "]"))
+			}
+		}
+	default:
+		if tableLevelIndexes[call.name] {
+			if len(call.args) == 0 {
+				return
+			}
+			columns := phpStrings(call.args[0])
+			table.Indexes = append(table.Indexes, TableIndex{Type: call.name, Columns: columns})
+			if len(columns) == 1 {
+				for i := range table.Columns {
+					if table.Columns[i].Name == columns[0] {
+						table.Columns[i].Unique = table.Columns[i].Unique || call.name == "unique"
+						table.Columns[i].Primary = table.Columns[i].Primary || call.name == "primary"
+					}
+				}
+			}
+			return
+		}
+
+		// Any other method taking a column name is a column type
+		if len(call.args) == 0 {
+			return
+		}
+		name, ok := phpString(call.args[0])
+		if !ok {
+			return
+		}
+		column := Column{Name: name, Type: columnTypeName(call)}
+		if primaryColumnTypes[call.name] {
+			column.Primary, column.Unsigned, column.AutoIncrement = true, true, true
+		}
+		if strings.HasPrefix(call.name, "unsigned") {
+			column.Unsigned = true
+		}
+		table.addColumn(column, modifiers)
+	}
+}
+
+// columnTypeName renders a column method with its length, precision or values
+func columnTypeName(call phpCall) string {
+	var params []string
+	for _, a := range call.args[1:] {
+		a = strings.TrimSpace(a)
+		if strings.Contains(a, ":") {
+			// Named argument such as precision: 0
+			a = strings.TrimSpace(a[strings.Index(a, ":")+1:])
+		}
+		if values := phpStrings(a); len(values) > 0 {
+			params = append(params, strings.Join(values, ","))
+		} else if a != "" && strings.Trim(a, "0123456789") == "" {
+			params = append(params, a)
+		}
+	}
+	if len(params) == 0 {
+		return call.name
+	}
+	return call.name + "(" + strings.Join(params, ",") + ")"
+}
+
+// addColumn adds or, for ->change() and redefinitions, replaces a column
+// after applying its modifiers
+func (t *Table) addColumn(column Column, modifiers []phpCall) {
+	for _, mod := range modifiers {
+		switch mod.name {
+		case "nullable":
+			column.Nullable = len(mod.args) == 0 || strings.TrimSpace(mod.args[0]) != "false"
+		case "default":
+			if len(mod.args) > 0 {
+				column.Default = argString(mod.args, 0)
+			}
+		case "useCurrent":
+			column.Default = "CURRENT_TIMESTAMP"
+		case "unsigned":
+			column.Unsigned = true
+		case "autoIncrement":
+			column.AutoIncrement = true
+		case "primary":
+			column.Primary = true
+		case "unique":
+			column.Unique = true
+		case "index":
+			t.Indexes = append(t.Indexes, TableIndex{Type: "index", Columns: []string{column.Name}})
+		}
+	}
+
+	for i := range t.Columns {
+		if t.Columns[i].Name == column.Name {
+			t.Columns[i] = column
+			return
+		}
+	}
+	t.Columns = append(t.Columns, column)
+}
+
+// addForeignKey adds a foreign key if the column is constrained, either by
+// $table->foreign() or by a constrained()/references() modifier. onTable is
+// the referenced table when it is known from the column definition.
+func (t *Table) addForeignKey(column, onTable string, constrained bool, modifiers []phpCall) {
+	fk := ForeignKey{Column: column, References: "id", On: onTable}
+	for _, mod := range modifiers {
+		switch mod.name {
+		case "constrained":
+			constrained = true
+			if s := argString(mod.args, 0); s != "" {
+				fk.On = s
+			}
+			if s := argString(mod.args, 1); s != "" {
+				fk.References = s
+			}
+		case "references":
+			constrained = true
+			if s := argString(mod.args, 0); s != "" {
+				fk.References = s
+			}
+		case "on":
+			fk.On = argString(mod.args, 0)
+		case "onDelete":
+			fk.OnDelete = argString(mod.args, 0)
+		case "onUpdate":
+			fk.OnUpdate = argString(mod.args, 0)
+		case "cascadeOnDelete":
+			fk.OnDelete = "cascade"
+		case "restrictOnDelete":
+			fk.OnDelete = "restrict"
+		case "nullOnDelete":
+			fk.OnDelete = "set null"
+		case "noActionOnDelete":
+			fk.OnDelete = "no action"
+		case "cascadeOnUpdate":
+			fk.OnUpdate = "cascade"
+		case "restrictOnUpdate":
+			fk.OnUpdate = "restrict"
+		case "nullOnUpdate":
+			fk.OnUpdate = "set null"
+		}
+	}
+	if !constrained {
+		return
+	}
+	if fk.On == "" {
+		fk.On = pluralize(strings.TrimSuffix(column, "_"+fk.References))
+	}
+	t.dropForeignKey(column)
+	t.ForeignKeys = append(t.ForeignKeys, fk)
+}
+
+// dropColumn removes a column with its foreign keys and indexes
+func (t *Table) dropColumn(name string) {
+	for i := range t.Columns {
+		if t.Columns[i].Name == name {
+			t.Columns = append(t.Columns[:i], t.Columns[i+1:]...)
+			break
+		}
+	}
+	t.dropForeignKey(name)
+	var indexes []TableIndex
+	for _, index := range t.Indexes {
+		if !slices.Contains(index.Columns, name) {
+			indexes = append(indexes, index)
+		}
+	}
+	t.Indexes = indexes
+}
+
+// dropForeignKey removes the foreign key on a column
+func (t *Table) dropForeignKey(column string) {
+	for i := range t.ForeignKeys {
+		if t.ForeignKeys[i].Column == column {
+			t.ForeignKeys = append(t.ForeignKeys[:i], t.ForeignKeys[i+1:]...)
+			return
+		}
+	}
+}
+
+// dropIndex removes the index over exactly the given columns
+func (t *Table) dropIndex(columns []string) {
+	for i, index := range t.Indexes {
+		if strings.Join(index.Columns, ",") == strings.Join(columns, ",") {
+			t.Indexes = append(t.Indexes[:i], t.Indexes[i+1:]...)
+			return
+		}
+	}
+}
+
+// foreignKeyColumns returns the columns named by dropForeign: either a list of
+// columns, or a constraint name following the table_column_foreign convention
+func foreignKeyColumns(table string, args []string) []string {
+	if len(args) == 0 {
+		return nil
+	}
+	if _, ok := phpArrayItems(args[0]); ok {
+		return phpStrings(args[0])
+	}
+	name, ok := phpString(args[0])
+	if !ok {
+		return nil
+	}
+	return []string{strings.TrimSuffix(strings.TrimPrefix(name, table+"_"), "_foreign")}
+}
@@ -0,0 +1,569 @@
+package indexer
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// defaultControllerNamespace is where Laravel looked up 'Controller@method'
+// strings before route namespaces became opt-in
+const defaultControllerNamespace = "App\\Http\\Controllers"
+
+// routeVerbs maps the Route:: registration methods to the HTTP methods they answer
+var routeVerbs = map[string][]string{
+	"get":               {"GET", "HEAD"},
+	"post":              {"POST"},
+	"put":               {"PUT"},
+	"patch":             {"PATCH"},
+	"delete":            {"DELETE"},
+	"options":           {"OPTIONS"},
+	"any":               {"ANY"},
+	"view":              {"GET", "HEAD"},
+	"redirect":          {"ANY"},
+	"permanentRedirect": {"ANY"},
+}
+
+// resourceAction is one of the routes registered by Route::resource
+type resourceAction struct {
+	name    string
+	methods []string
+	suffix  string // Appended to the resource URI; {} is replaced by the parameter
+	api     bool   // Also registered by Route::apiResource
+}
+
+var resourceActions = []resourceAction{
+	{"index", []string{"GET", "HEAD"}, "", true},
+	{"create", []string{"GET", "HEAD"}, "/create", false},
+	{"store", []string{"POST"}, "", true},
+	{"show", []string{"GET", "HEAD"}, "/{}", true},
+	{"edit", []string{"GET", "HEAD"}, "/{}/edit", false},
+	{"update", []string{"PUT", "PATCH"}, "/{}", true},
+	{"destroy", []string{"DELETE"}, "/{}", true},
+}
+
+// routeRequirePattern matches the file of a require __DIR__.'/auth.php' statement
+var routeRequirePattern = regexp.MustCompile(`__DIR__\s*\.\s*['"]/?([^'"]+\.php)['"]`)
+
+// routeIncludeKeywords are the statements that load another routes file
+var routeIncludeKeywords = map[string]bool{"require": true, "require_once": true, "include": true, "include_once": true}
+
+// routeGroup holds the attributes a group applies to the routes inside it
+type routeGroup struct {
+	prefix     string
+	name       string
+	middleware []string
+	controller string
+	namespace  string
+}
+
+// routeParser builds the route table from the files in routes/
+type routeParser struct {
+	projectRoot string
+	files       map[string]*FileInfo
+	resolver    *importResolver
+	visited     map[string]bool
+	routes      []Route
+}
+
+// routeFile is one parsed routes file
+type routeFile struct {
+	path      string
+	src       *phpSource
+	namespace string
+	uses      map[string]string
+}
+
+// parseLaravelRoutes parses routes/*.php into a route table. web.php and
+// api.php get the middleware groups and prefix Laravel applies by default.
+func parseLaravelRoutes(projectRoot string, files map[string]*FileInfo, resolver *importResolver) []Route {
+	p := &routeParser{projectRoot: projectRoot, files: files, resolver: resolver, visited: make(map[string]bool)}
+
+	paths := laravelFiles(files, "routes")
+	sort.SliceStable(paths, func(i, j int) bool { return routeFileRank(paths[i]) < routeFileRank(paths[j]) })
+	for _, file := range paths {
+		switch file {
+		case "routes/web.php":
+			p.parseFile(file, routeGroup{middleware: []string{"web"}})
+		case "routes/api.php":
+			p.parseFile(file, routeGroup{prefix: "api", middleware: []string{"api"}})
+		default:
+			p.parseFile(file, routeGroup{})
+		}
+	}
+	return p.routes
+}
+
+// routeFileRank orders web.php and api.php first, so files they require are
+// parsed with their middleware rather than on their own
+func routeFileRank(file string) int {
+	switch file {
+	case "routes/web.php":
+		return 0
+	case "routes/api.php":
+		return 1
+	}
+	return 2
+}
+
+// parseFile parses a routes file once, with the attributes of the enclosing group
+func (p *routeParser) parseFile(file string, group routeGroup) {
+	if p.visited[file] {
+		return
+	}
+	p.visited[file] = true
+
+	data, err := os.ReadFile(filepath.Join(p.projectRoot, file))
+	if err != nil {
+		return
+	}
+	src := newPHPSource(string(data))
+	rf := &routeFile{path: file, src: src, namespace: phpNamespace(src.text), uses: phpUses(src.text)}
+	p.parseBlock(rf, 0, len(src.text), group)
+}
+
+// parseBlock parses the route definitions in text[start:end]. Nested groups
+// are parsed recursively and skipped by the outer scan.
+func (p *routeParser) parseBlock(rf *routeFile, start, end int, group routeGroup) {
+	src := rf.src
+	offsets := src.findAll("Route::", start, end)
+	for _, keyword := range []string{"require", "include"} {
+		offsets = append(offsets, src.findAll(keyword, start, end)...)
+	}
+	sort.Ints(offsets)
+
+	next := start
+	for _, offset := range offsets {
+		if offset < next {
+			continue
+		}
+		if !strings.HasPrefix(src.text[offset:], "Route::") {
+			word := src.text[offset:]
+			if n := strings.IndexFunc(word, func(r rune) bool { return r != '_' && (r < 'a' || r > 'z') }); n >= 0 {
+				word = word[:n]
+			}
+			if !routeIncludeKeywords[word] {
+				continue // An identifier such as required()
+			}
+			expr, ok := phpExpression(src.text, offset)
+			next = offset + len(expr)
+			if m := routeRequirePattern.FindStringSubmatch(expr); ok && m != nil {
+				p.parseFile(path.Join(path.Dir(rf.path), m[1]), group)
+			}
+			continue
+		}
+		calls, after := src.parseChain(offset + len("Route::"))
+		next = after
+		p.parseChain(rf, calls, group)
+	}
+}
+
+// parseChain handles one Route:: call chain: group attributes, a group, or
+// a route registration followed by modifiers such as ->name() and ->middleware()
+func (p *routeParser) parseChain(rf *routeFile, calls []phpCall, group routeGroup) {
+	g := group
+	g.middleware = append([]string(nil), group.middleware...)
+	name := "" // A name() before the verb: the route name, or a prefix for a group
+
+	for i, call := range calls {
+		switch call.name {
+		case "prefix":
+			if len(call.args) > 0 {
+				if s, ok := phpString(call.args[0]); ok {
+					g.prefix = joinURI(g.prefix, s)
+				}
+			}
+		case "middleware":
+			for _, arg := range call.args {
+				g.middleware = append(g.middleware, phpStrings(arg)...)
+			}
+		case "name", "as":
+			if len(call.args) > 0 {
+				if s, ok := phpString(call.args[0]); ok {
+					name += s
+				}
+			}
+		case "controller":
+			if len(call.args) > 0 {
+				g.controller = p.controllerClass(rf, call.args[0], g)
+			}
+		case "namespace":
+			if len(call.args) > 0 {
+				if s, ok := phpString(call.args[0]); ok {
+					g.namespace = joinNamespace(g.namespace, s)
+				}
+			}
+		case "group":
+			g.name += name
+			p.parseGroup(rf, call, g)
+			return
+		case "resource", "apiResource", "resources", "apiResources":
+			g.name += name
+			p.addResources(rf, call, calls[i+1:], g)
+			return
+		default:
+			if _, ok := routeVerbs[call.name]; ok || call.name == "match" {
+				p.addRoute(rf, call, calls[i+1:], g, name)
+				return
+			}
+		}
+	}
+}
+
+// parseGroup parses Route::group([attributes], function () { ... })
+func (p *routeParser) parseGroup(rf *routeFile, call phpCall, g routeGroup) {
+	if len(call.args) == 0 {
+		return
+	}
+	if len(call.args) > 1 {
+		g = p.applyGroupAttributes(rf, g, phpArrayMap(call.args[0]))
+	}
+	last := len(call.args) - 1
+	if start, end, ok := rf.src.phpClosureBody(call, last); ok {
+		p.parseBlock(rf, start, end, g)
+	} else if s, ok := phpString(call.args[last]); ok && strings.HasSuffix(s, ".php") {
+		p.parseFile(path.Clean(strings.TrimPrefix(s, p.projectRoot+"/")), g)
+	}
+}
+
+// applyGroupAttributes applies the array form of group attributes
+func (p *routeParser) applyGroupAttributes(rf *routeFile, g routeGroup, attrs map[string]string) routeGroup {
+	g.middleware = append([]string(nil), g.middleware...)
+	if v, ok := phpString(attrs["prefix"]); ok {
+		g.prefix = joinURI(g.prefix, v)
+	}
+	if v, ok := attrs["middleware"]; ok {
+		g.middleware = append(g.middleware, phpStrings(v)...)
+	}
+	if v, ok := phpString(attrs["as"]); ok {
+		g.name += v
+	}
+	if v, ok := phpString(attrs["namespace"]); ok {
+		g.namespace = joinNamespace(g.namespace, v)
+	}
+	if v, ok := attrs["controller"]; ok {
+		g.controller = p.controllerClass(rf, v, g)
+	}
+	return g
+}
+
+// addRoute registers a single route and applies the modifiers chained after it
+func (p *routeParser) addRoute(rf *routeFile, call phpCall, modifiers []phpCall, g routeGroup, name string) {
+	args := call.args
+	methods := routeVerbs[call.name]
+	if call.name == "match" {
+		if len(args) == 0 {
+			return
+		}
+		methods = nil
+		for _, m := range phpStrings(args[0]) {
+			methods = append(methods, strings.ToUpper(m))
+		}
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return
+	}
+	uri, ok := phpString(args[0])
+	if !ok {
+		return
+	}
+
+	route := Route{
+		Methods:    methods,
+		URI:        joinURI(g.prefix, uri),
+		Middleware: g.middleware,
+		File:       rf.path,
+		Line:       rf.src.line(call.offset),
+	}
+	if name != "" {
+		route.Name = g.name + name
+	}
+
+	switch {
+	case call.name == "view":
+		route.Action = "view:" + argString(args, 1)
+	case call.name == "redirect" || call.name == "permanentRedirect":
+		route.Action = "redirect:" + argString(args, 1)
+	case len(args) < 2:
+		return
+	default:
+		p.setAction(rf, &route, args[1], g)
+	}
+
+	for _, m := range modifiers {
+		switch m.name {
+		case "name", "as":
+			if len(m.args) > 0 {
+				if s, ok := phpString(m.args[0]); ok {
+					route.Name = g.name + s
+				}
+			}
+		case "middleware":
+			for _, arg := range m.args {
+				route.Middleware = append(route.Middleware, phpStrings(arg)...)
+			}
+		case "withoutMiddleware":
+			for _, arg := range m.args {
+				route.Middleware = removeStrings(route.Middleware, phpStrings(arg))
+			}
+		}
+	}
+	route.Middleware = uniqueStrings(route.Middleware)
+	p.routes = append(p.routes, route)
+}
+
+// setAction resolves a route action argument: [Controller::class, 'method'],
+// 'Controller@method', an invokable controller, a closure, or the legacy
+// ['uses' => ..., 'as' => ..., 'middleware' => ...] array
+func (p *routeParser) setAction(rf *routeFile, route *Route, arg string, g routeGroup) {
+	trimmed := strings.TrimSpace(arg)
+	if strings.HasPrefix(trimmed, "function") || strings.HasPrefix(trimmed, "fn") || strings.HasPrefix(trimmed, "static ") {
+		route.Action = "Closure"
+		return
+	}
+
+	if attrs := phpArrayMap(trimmed); len(attrs) > 0 {
+		if v, ok := phpString(attrs["as"]); ok {
+			route.Name = g.name + v
+		}
+		if v, ok := attrs["middleware"]; ok {
+			route.Middleware = append(route.Middleware, phpStrings(v)...)
+		}
+		if v, ok := attrs["uses"]; ok {
+			p.setAction(rf, route, v, g)
+		} else {
+			route.Action = "Closure"
+		}
+		return
+	}
+
+	var controller, method string
+	if items, ok := phpArrayItems(trimmed); ok && len(items) == 2 {
+		controller = p.controllerClass(rf, items[0], g)
+		method, _ = phpString(items[1])
+	} else if s, ok := phpString(trimmed); ok {
+		if class, m, found := strings.Cut(s, "@"); found {
+			controller, method = p.controllerClass(rf, "'"+class+"'", g), m
+		} else if g.controller != "" {
+			controller, method = g.controller, s
+		} else {
+			controller, method = p.controllerClass(rf, trimmed, g), "__invoke"
+		}
+	} else if _, ok := phpClassRef(trimmed); ok {
+		controller, method = p.controllerClass(rf, trimmed, g), "__invoke"
+	}
+	if controller == "" || method == "" {
+		route.Action = trimmed
+		return
+	}
+	p.setHandler(route, controller, method)
+}
+
+// setHandler records a controller action and locates its method
+func (p *routeParser) setHandler(route *Route, controller, method string) {
+	route.Controller = controller
+	route.Handler = method
+	route.Action = controller + "@" + method
+	if method == "__invoke" {
+		route.Action = controller
+	}
+
+	files := p.resolver.resolvePHP(controller)
+	if len(files) == 0 {
+		return
+	}
+	route.HandlerFile = files[0]
+	if info, ok := p.files[files[0]]; ok {
+		for _, sym := range info.Symbols {
+			if sym.Name == method && (sym.Type == "function" || sym.Type == "method") {
+				route.HandlerLine = sym.Line
+				break
+			}
+		}
+	}
+}
+
+// controllerClass resolves a controller argument (X::class or a string) to a
+// fully qualified class name
+func (p *routeParser) controllerClass(rf *routeFile, arg string, g routeGroup) string {
+	if class, ok := phpClassRef(arg); ok {
+		return resolvePHPClass(class, rf.namespace, rf.uses)
+	}
+	s, ok := phpString(arg)
+	if !ok || s == "" {
+		return ""
+	}
+	if strings.HasPrefix(s, "\\") {
+		return strings.TrimPrefix(s, "\\")
+	}
+	if g.namespace != "" {
+		return g.namespace + "\\" + s
+	}
+	if fqn, ok := rf.uses[s]; ok {
+		return fqn
+	}
+	if strings.Contains(s, "\\") && strings.HasPrefix(s, "App\\") {
+		return s
+	}
+	return defaultControllerNamespace + "\\" + s
+}
+
+// addResources registers the routes of Route::resource and friends
+func (p *routeParser) addResources(rf *routeFile, call phpCall, modifiers []phpCall, g routeGroup) {
+	api := strings.HasPrefix(call.name, "api")
+	if call.name == "resources" || call.name == "apiResources" {
+		if len(call.args) == 0 {
+			return
+		}
+		items, _ := phpArrayItems(call.args[0])
+		for _, item := range items {
+			name, controller, found := strings.Cut(item, "=>")
+			if found {
+				p.addResource(rf, call, strings.TrimSpace(name), strings.TrimSpace(controller), "", modifiers, g, api)
+			}
+		}
+		return
+	}
+	if len(call.args) < 2 {
+		return
+	}
+	options := ""
+	if len(call.args) > 2 {
+		options = call.args[2]
+	}
+	p.addResource(rf, call, call.args[0], call.args[1], options, modifiers, g, api)
+}
+
+// addResource registers the routes of one resource controller
+func (p *routeParser) addResource(rf *routeFile, call phpCall, nameArg, controllerArg, options string, modifiers []phpCall, g routeGroup, api bool) {
+	name, ok := phpString(nameArg)
+	if !ok {
+		return
+	}
+	controller := p.controllerClass(rf, controllerArg, g)
+
+	only, except := map[string]bool{}, map[string]bool{}
+	names := map[string]string{}
+	middleware := append([]string(nil), g.middleware...)
+	opts := phpArrayMap(options)
+	for _, v := range phpStrings(opts["only"]) {
+		only[v] = true
+	}
+	for _, v := range phpStrings(opts["except"]) {
+		except[v] = true
+	}
+	for _, m := range modifiers {
+		if len(m.args) == 0 {
+			continue
+		}
+		switch m.name {
+		case "only":
+			for _, arg := range m.args {
+				for _, v := range phpStrings(arg) {
+					only[v] = true
+				}
+			}
+		case "except":
+			for _, arg := range m.args {
+				for _, v := range phpStrings(arg) {
+					except[v] = true
+				}
+			}
+		case "names":
+			for k, v := range phpArrayMap(m.args[0]) {
+				names[k], _ = phpString(v)
+			}
+			if s, ok := phpString(m.args[0]); ok {
+				name = s // Route::resource(...)->names('prefix')
+			}
+		case "middleware":
+			for _, arg := range m.args {
+				middleware = append(middleware, phpStrings(arg)...)
+			}
+		}
+	}
+
+	// Nested resources (photos.comments) become photos/{photo}/comments/{comment}
+	segments := strings.Split(name, ".")
+	var base []string
+	for i, seg := range segments {
+		base = append(base, seg)
+		if i < len(segments)-1 {
+			base = append(base, "{"+singularize(strings.ReplaceAll(seg, "-", "_"))+"}")
+		}
+	}
+	param := "{" + singularize(strings.ReplaceAll(segments[len(segments)-1], "-", "_")) + "}"
+
+	for _, action := range resourceActions {
+		if (api && !action.api) || (len(only) > 0 && !only[action.name]) || except[action.name] {
+			continue
+		}
+		route := Route{
+			Methods:    action.methods,
+			URI:        joinURI(g.prefix, strings.Join(base, "/")+strings.ReplaceAll(action.suffix, "{}", param)),
+			Name:       g.name + name + "." + action.name,
+			Middleware: uniqueStrings(middleware),
+			File:       rf.path,
+			Line:       rf.src.line(call.offset),
+		}
+		if custom := names[action.name]; custom != "" {
+			route.Name = g.name + custom
+		}
+		if controller != "" {
+			p.setHandler(&route, controller, action.name)
+		}
+		p.routes = append(p.routes, route)
+	}
+}
+
+// argString returns a string argument, or its raw source if it is not a literal
+func argString(args []string, i int) string {
+	if i >= len(args) {
+		return ""
+	}
+	if s, ok := phpString(args[i]); ok {
+		return s
+	}
+	return args[i]
+}
+
+// joinURI joins a group prefix and a route URI into a URI with a leading slash
+func joinURI(prefix, uri string) string {
+	parts := []string{}
+	for _, part := range []string{prefix, uri} {
+		if part = strings.Trim(part, "/"); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return "/" + strings.Join(parts, "/")
+}
+
+// joinNamespace appends a relative group namespace to the enclosing one
+func joinNamespace(outer, inner string) string {
+	if strings.HasPrefix(inner, "\\") || outer == "" {
+		return strings.Trim(inner, "\\")
+	}
+	return outer + "\\" + strings.Trim(inner, "\\")
+}
+
+// removeStrings returns values without the entries in remove
+func removeStrings(values, remove []string) []string {
+	var kept []string
+	for _, v := range values {
+		drop := false
+		for _, r := range remove {
+			if v == r {
+				drop = true
+				break
+			}
+		}
+		if !drop {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,318 @@
+package indexer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var laravelFixture = map[string]string{
+	"artisan":       "#!/usr/bin/env php\n<?php\n",
+	"composer.json": `{"require": {"laravel/framework": "^11.0"}, "autoload": {"psr-4": {"App\\": "app/"}}}`,
+	"routes/web.php": `<?php
+
+use App\Http\Controllers\PostController;
+use App\Http\Controllers\Admin\DashboardController as Dashboard;
+use Illuminate\Support\Facades\Route;
+
+// Route::get('/commented-out', fn () => 'no');
+
+Route::get('/', function () {
+    return view('welcome');
+})->name('home');
+
+Route::view('/about', 'pages.about');
+
+Route::middleware(['auth'])->prefix('admin')->name('admin.')->group(function () {
+    Route::get('/dashboard', Dashboard::class)->name('dashboard');
+    Route::resource('posts', PostController::class)->only(['index', 'show', 'destroy']);
+});
+
+Route::controller(PostController::class)->group(function () {
+    Route::post('/posts/{post}/publish', 'publish')->middleware('can:publish,post');
+});
+
+require __DIR__.'/auth.php';
+`,
+	"routes/auth.php": `<?php
+
+use Illuminate\Support\Facades\Route;
+
+Route::group(['prefix' => 'auth', 'as' => 'auth.'], function () {
+    Route::match(['get', 'post'], 'login', 'Auth\LoginController@login')->name('login');
+});
+`,
+	"routes/api.php": `<?php
+
+use App\Http\Controllers\CommentController;
+use Illuminate\Support\Facades\Route;
+
+Route::apiResource('posts.comments', CommentController::class);
+`,
+	"app/Http/Controllers/PostController.php": `<?php
+
+namespace App\Http\Controllers;
+
+class PostController extends Controller
+{
+    public function index()
+    {
+    }
+
+    public function show(Post $post)
+    {
+    }
+
+    public function publish(Post $post)
+    {
+    }
+}
+`,
+	"app/Models/Post.php": `<?php
+
+namespace App\Models;
+
+use Illuminate\Database\Eloquent\Model;
+use Illuminate\Database\Eloquent\Relations\BelongsTo;
+
+class Post extends Model
+{
+    protected $fillable = ['title', 'body', 'user_id'];
+
+    protected $hidden = ['secret'];
+
+    protected function casts(): array
+    {
+        return [
+            'published_at' => 'datetime',
+            'meta' => AsArrayObject::class,
+        ];
+    }
+
+    public function author(): BelongsTo
+    {
+        return $this->belongsTo(User::class, 'user_id');
+    }
+
+    public function comments()
+    {
+        return $this->hasMany(\App\Models\Comment::class);
+    }
+}
+`,
+	"app/Models/Category.php": `<?php
+
+namespace App\Models;
+
+class Category extends BaseModel
+{
+    protected $table = 'post_categories';
+}
+`,
+	"app/Models/BaseModel.php": `<?php
+
+namespace App\Models;
+
+use Illuminate\Database\Eloquent\Model;
+
+abstract class BaseModel extends Model
+{
+}
+`,
+	"app/Services/Mailer.php": "<?php\n\nnamespace App\\Services;\n\nclass Mailer extends Base {}\n",
+	"database/migrations/2024_01_01_000000_create_posts_table.php": `<?php
+
+use Illuminate\Database\Migrations\Migration;
+use Illuminate\Database\Schema\Blueprint;
+use Illuminate\Support\Facades\Schema;
+
+return new class extends Migration
+{
+    public function up(): void
+    {
+        Schema::create('posts', function (Blueprint $table) {
+            $table->id();
+            $table->foreignId('user_id')->constrained()->cascadeOnDelete();
+            $table->string('title', 200);
+            $table->text('body')->nullable();
+            $table->string('slug')->unique();
+            $table->timestamps();
+        });
+    }
+
+    public function down(): void
+    {
+        Schema::dropIfExists('posts');
+    }
+};
+`,
+	"database/migrations/2024_02_01_000000_alter_posts_table.php": `<?php
+
+return new class extends Migration
+{
+    public function up(): void
+    {
+        Schema::table('posts', function (Blueprint $t) {
+            $t->dropColumn('slug');
+            $t->renameColumn('body', 'content');
+            $t->enum('status', ['draft', 'published'])->default('draft');
+        });
+        Schema::create('tmp', function (Blueprint $table) {
+            $table->id();
+        });
+        Schema::drop('tmp');
+    }
+};
+`,
+}
+
+func TestLaravelRoutes(t *testing.T) {
+	root, cfg := newTestProject(t, laravelFixture)
+	idx := NewIndex(root, cfg)
+	if err := idx.IndexProject(); err != nil {
+		t.Fatalf("IndexProject failed: %v", err)
+	}
+	app := idx.Laravel()
+	if app == nil {
+		t.Fatal("Expected the fixture to be detected as a Laravel app")
+	}
+
+	routes := make(map[string]Route)
+	for _, r := range app.Routes {
+		routes[strings.Join(r.Methods, "|")+" "+r.URI] = r
+	}
+
+	home, ok := routes["GET|HEAD /"]
+	if !ok || home.Action != "Closure" || home.Name != "home" || !reflect.DeepEqual(home.Middleware, []string{"web"}) {
+		t.Errorf("Unexpected home route: %+v", home)
+	}
+	if _, ok := routes["GET|HEAD /commented-out"]; ok {
+		t.Error("Route in a comment should be ignored")
+	}
+	if about := routes["GET|HEAD /about"]; about.Action != "view:pages.about" {
+		t.Errorf("Unexpected view route: %+v", about)
+	}
+
+	dashboard := routes["GET|HEAD /admin/dashboard"]
+	if dashboard.Name != "admin.dashboard" || dashboard.Controller != "App\\Http\\Controllers\\Admin\\DashboardController" ||
+		dashboard.Handler != "__invoke" || !reflect.DeepEqual(dashboard.Middleware, []string{"web", "auth"}) {
+		t.Errorf("Unexpected dashboard route: %+v", dashboard)
+	}
+
+	show, ok := routes["GET|HEAD /admin/posts/{post}"]
+	if !ok || show.Name != "admin.posts.show" || show.HandlerFile != "app/Http/Controllers/PostController.php" || show.HandlerLine != 11 {
+		t.Errorf("Unexpected resource show route: %+v", show)
+	}
+	if _, ok := routes["GET|HEAD /admin/posts/create"]; ok {
+		t.Error("only() should exclude the create route")
+	}
+
+	publish := routes["POST /posts/{post}/publish"]
+	if publish.Action != "App\\Http\\Controllers\\PostController@publish" || publish.HandlerLine != 15 ||
+		!reflect.DeepEqual(publish.Middleware, []string{"web", "can:publish,post"}) {
+		t.Errorf("Unexpected controller group route: %+v", publish)
+	}
+
+	// auth.php is required from web.php, so it inherits the web middleware
+	login := routes["GET|POST /auth/login"]
+	if login.Name != "auth.login" || login.Controller != "App\\Http\\Controllers\\Auth\\LoginController" || login.File != "routes/auth.php" ||
+		!reflect.DeepEqual(login.Middleware, []string{"web"}) {
+		t.Errorf("Unexpected required route: %+v", login)
+	}
+
+	comments, ok := routes["PUT|PATCH /api/posts/{post}/comments/{comment}"]
+	if !ok || comments.Name != "posts.comments.update" || comments.Controller != "App\\Http\\Controllers\\CommentController" {
+		t.Errorf("Unexpected nested api resource route: %+v", comments)
+	}
+	if _, ok := routes["GET|HEAD /api/posts/{post}/comments/create"]; ok {
+		t.Error("apiResource should not register create")
+	}
+
+	matches := app.MatchRoutes("https://example.com/admin/posts/42?x=1", "delete")
+	if len(matches) != 1 || matches[0].Handler != "destroy" {
+		t.Errorf("Unexpected matches for DELETE /admin/posts/42: %+v", matches)
+	}
+}
+
+func TestLaravelModelsAndMigrations(t *testing.T) {
+	root, cfg := newTestProject(t, laravelFixture)
+	idx := NewIndex(root, cfg)
+	if err := idx.IndexProject(); err != nil {
+		t.Fatalf("IndexProject failed: %v", err)
+	}
+	app := idx.Laravel()
+
+	var names []string
+	for _, m := range app.Models {
+		names = append(names, m.Name)
+	}
+	if want := []string{"BaseModel", "Category", "Post"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("Models = %v, want %v", names, want)
+	}
+
+	post, ok := app.FindModel("post")
+	if !ok || post.Table != "posts" || post.Line != 8 {
+		t.Fatalf("Unexpected Post model: %+v", post)
+	}
+	if !reflect.DeepEqual(post.Fillable, []string{"title", "body", "user_id"}) || !reflect.DeepEqual(post.Hidden, []string{"secret"}) {
+		t.Errorf("Unexpected attributes: %+v", post)
+	}
+	if post.Casts["published_at"] != "datetime" || post.Casts["meta"] != "AsArrayObject" {
+		t.Errorf("Unexpected casts: %v", post.Casts)
+	}
+	wantRelations := []Relation{
+		{Name: "author", Type: "belongsTo", Related: "App\\Models\\User", Line: 22},
+		{Name: "comments", Type: "hasMany", Related: "App\\Models\\Comment", Line: 27},
+	}
+	if !reflect.DeepEqual(post.Relations, wantRelations) {
+		t.Errorf("Relations = %+v, want %+v", post.Relations, wantRelations)
+	}
+	if category, ok := app.FindModel("post_categories"); !ok || category.Name != "Category" {
+		t.Errorf("Expected lookup by table name to find Category, got %+v", category)
+	}
+
+	if _, ok := app.Tables["tmp"]; ok {
+		t.Error("Dropped table should not be in the schema")
+	}
+	posts := app.Tables["posts"]
+	if posts == nil {
+		t.Fatal("Expected a posts table")
+	}
+	var columns []string
+	for _, c := range posts.Columns {
+		columns = append(columns, c.Name+":"+c.Type)
+	}
+	wantColumns := []string{"id:bigIncrements", "user_id:unsignedBigInteger", "title:string(200)", "content:text",
+		"created_at:timestamp", "updated_at:timestamp", "status:enum(draft,published)"}
+	if !reflect.DeepEqual(columns, wantColumns) {
+		t.Errorf("Columns = %v, want %v", columns, wantColumns)
+	}
+	if status := posts.Columns[6]; status.Default != "draft" {
+		t.Errorf("Expected status default 'draft', got %+v", status)
+	}
+	wantFK := []ForeignKey{{Column: "user_id", References: "id", On: "users", OnDelete: "cascade"}}
+	if !reflect.DeepEqual(posts.ForeignKeys, wantFK) {
+		t.Errorf("ForeignKeys = %+v, want %+v", posts.ForeignKeys, wantFK)
+	}
+	if len(posts.Migrations) != 2 {
+		t.Errorf("Expected two migrations touching posts, got %v", posts.Migrations)
+	}
+}
+
+func TestLaravelNaming(t *testing.T) {
+	tests := map[string]string{
+		"User": "users", "UserProfile": "user_profiles", "Category": "categories",
+		"Address": "addresses", "Person": "people", "HTTPLog": "http_logs",
+	}
+	for class, want := range tests {
+		if got := laravelTableName(class, false); got != want {
+			t.Errorf("laravelTableName(%s) = %s, want %s", class, got, want)
+		}
+	}
+	if got := singularize("categories"); got != "category" {
+		t.Errorf("singularize(categories) = %s", got)
+	}
+	if !matchRouteURI("/users/{user}/posts/{post?}", "/users/1/posts") || matchRouteURI("/users/{user}", "/users") {
+		t.Error("Unexpected optional parameter matching")
+	}
+}
@@ -0,0 +1,334 @@
+package indexer
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// phpSource is PHP code with comments blanked out and a line index, so the
+// Laravel analyzers can scan it with offsets that map back to source lines
+type phpSource struct {
+	text       string
+	lineStarts []int
+}
+
+// phpCall is one call of a method chain such as Route::get(...)->name(...)
+type phpCall struct {
+	name   string
+	args   []string // Raw argument source, split at top-level commas
+	offset int      // Offset of the method name
+}
+
+// phpUsePattern matches a use statement with an optional alias
+var phpAliasedUsePattern = regexp.MustCompile(`(?m)^\s*use\s+\\?([A-Za-z_][A-Za-z0-9_\\]*)(?:\s+as\s+([A-Za-z_][A-Za-z0-9_]*))?\s*;`)
+
+// phpNamespacePattern matches the namespace declaration of a file
+var phpNamespacePattern = regexp.MustCompile(`(?m)^\s*namespace\s+([A-Za-z_][A-Za-z0-9_\\]*)\s*;`)
+
+// newPHPSource prepares PHP code for scanning: comments are replaced with
+// spaces (newlines kept) so offsets and line numbers stay valid
+func newPHPSource(code string) *phpSource {
+	b := []byte(code)
+	for i := 0; i < len(b); i++ {
+		switch {
+		case b[i] == '\'' || b[i] == '"':
+			i = skipPHPString(code, i) - 1
+		case b[i] == '/' && i+1 < len(b) && b[i+1] == '/', b[i] == '#' && (i+1 >= len(b) || b[i+1] != '['):
+			for i < len(b) && b[i] != '\n' {
+				b[i] = ' '
+				i++
+			}
+		case b[i] == '/' && i+1 < len(b) && b[i+1] == '*':
+			for i < len(b) && !(b[i] == '*' && i+1 < len(b) && b[i+1] == '/') {
+				if b[i] != '\n' {
+					b[i] = ' '
+				}
+				i++
+			}
+			if i+1 < len(b) {
+				b[i], b[i+1] = ' ', ' '
+				i++
+			}
+		}
+	}
+
+	src := &phpSource{text: string(b), lineStarts: []int{0}}
+	for i, c := range b {
+		if c == '\n' {
+			src.lineStarts = append(src.lineStarts, i+1)
+		}
+	}
+	return src
+}
+
+// line returns the 1-based line of an offset
+func (p *phpSource) line(offset int) int {
+	return sort.Search(len(p.lineStarts), func(i int) bool { return p.lineStarts[i] > offset })
+}
+
+// skipPHPString returns the offset just past the string literal starting at i
+func skipPHPString(s string, i int) int {
+	quote := s[i]
+	for j := i + 1; j < len(s); j++ {
+		if s[j] == '\\' {
+			j++
+			continue
+		}
+		if s[j] == quote {
+			return j + 1
+		}
+	}
+	return len(s)
+}
+
+// matchingBracket returns the offset of the bracket closing the one at i, or -1
+func matchingBracket(s string, i int) int {
+	depth := 0
+	for j := i; j < len(s); j++ {
+		switch s[j] {
+		case '\'', '"':
+			j = skipPHPString(s, j) - 1
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+// findAll returns the offsets of needle in text[start:end] outside string literals,
+// skipping matches preceded by an identifier character
+func (p *phpSource) findAll(needle string, start, end int) []int {
+	var offsets []int
+	for i := start; i < end; i++ {
+		c := p.text[i]
+		if c == '\'' || c == '"' {
+			i = skipPHPString(p.text, i) - 1
+			continue
+		}
+		if strings.HasPrefix(p.text[i:end], needle) && (i == 0 || !isIdentByte(p.text[i-1])) {
+			offsets = append(offsets, i)
+			i += len(needle) - 1
+		}
+	}
+	return offsets
+}
+
+// parseChain parses a method chain starting at offset i, e.g. the
+// `get('/x', ...)->name('x')` after `Route::`. It returns the calls and the
+// offset after the chain.
+func (p *phpSource) parseChain(i int) ([]phpCall, int) {
+	var calls []phpCall
+	s := p.text
+	for {
+		i = skipSpaces(s, i)
+		nameStart := i
+		for i < len(s) && isIdentByte(s[i]) {
+			i++
+		}
+		if i == nameStart {
+			return calls, i
+		}
+		name := s[nameStart:i]
+		i = skipSpaces(s, i)
+		if i >= len(s) || s[i] != '(' {
+			return calls, i
+		}
+		end := matchingBracket(s, i)
+		if end < 0 {
+			return calls, len(s)
+		}
+		calls = append(calls, phpCall{name: name, args: splitPHPArgs(s[i+1 : end]), offset: nameStart})
+		i = skipSpaces(s, end+1)
+		if !strings.HasPrefix(s[i:], "->") {
+			return calls, i
+		}
+		i += 2
+	}
+}
+
+// argOffset returns the source offset of a call's argument
+func (p *phpSource) argOffset(call phpCall, n int) int {
+	open := strings.IndexByte(p.text[call.offset:], '(') + call.offset
+	arg := call.args[n]
+	if idx := strings.Index(p.text[open:], arg); idx >= 0 {
+		return open + idx
+	}
+	return open
+}
+
+// splitPHPArgs splits an argument list at top-level commas
+func splitPHPArgs(s string) []string {
+	var args []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\'', '"':
+			i = skipPHPString(s, i) - 1
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		args = append(args, last)
+	}
+	return args
+}
+
+// phpString decodes a string literal
+func phpString(arg string) (string, bool) {
+	arg = strings.TrimSpace(arg)
+	if len(arg) < 2 || (arg[0] != '\'' && arg[0] != '"') || arg[len(arg)-1] != arg[0] {
+		return "", false
+	}
+	inner := arg[1 : len(arg)-1]
+	if arg[0] == '\'' {
+		return strings.NewReplacer(`\'`, `'`, `\\`, `\`).Replace(inner), true
+	}
+	return strings.NewReplacer(`\"`, `"`, `\\`, `\`, `\$`, `$`).Replace(inner), true
+}
+
+// phpClassRef decodes `Foo::class`, returning the class name as written
+func phpClassRef(arg string) (string, bool) {
+	name, ok := strings.CutSuffix(strings.TrimSpace(arg), "::class")
+	if !ok || name == "" {
+		return "", false
+	}
+	return name, true
+}
+
+// phpArrayItems returns the raw items of an array literal ([...] or array(...))
+func phpArrayItems(arg string) ([]string, bool) {
+	arg = strings.TrimSpace(arg)
+	switch {
+	case strings.HasPrefix(arg, "[") && strings.HasSuffix(arg, "]"):
+		return splitPHPArgs(arg[1 : len(arg)-1]), true
+	case strings.HasPrefix(strings.ToLower(arg), "array(") && strings.HasSuffix(arg, ")"):
+		return splitPHPArgs(arg[6 : len(arg)-1]), true
+	}
+	return nil, false
+}
+
+// phpStrings decodes a string, a class reference, or an array of them
+func phpStrings(arg string) []string {
+	if s, ok := phpString(arg); ok {
+		return []string{s}
+	}
+	if c, ok := phpClassRef(arg); ok {
+		return []string{c}
+	}
+	items, ok := phpArrayItems(arg)
+	if !ok {
+		return nil
+	}
+	var values []string
+	for _, item := range items {
+		values = append(values, phpStrings(item)...)
+	}
+	return values
+}
+
+// phpArrayMap decodes the 'key' => value pairs of an array literal, keeping raw values
+func phpArrayMap(arg string) map[string]string {
+	items, ok := phpArrayItems(arg)
+	if !ok {
+		return nil
+	}
+	m := make(map[string]string)
+	for _, item := range items {
+		key, value, found := strings.Cut(item, "=>")
+		if !found {
+			continue
+		}
+		if k, ok := phpString(key); ok {
+			m[k] = strings.TrimSpace(value)
+		}
+	}
+	return m
+}
+
+// phpClosureBody returns the offsets of the body of the closure in a call
+// argument, or ok=false if the argument is not a closure
+func (p *phpSource) phpClosureBody(call phpCall, n int) (start, end int, ok bool) {
+	arg := call.args[n]
+	trimmed := strings.TrimPrefix(strings.TrimSpace(arg), "static ")
+	if !strings.HasPrefix(strings.TrimSpace(trimmed), "function") {
+		return 0, 0, false
+	}
+	argStart := p.argOffset(call, n)
+	open := strings.IndexByte(p.text[argStart:], '{')
+	if open < 0 {
+		return 0, 0, false
+	}
+	open += argStart
+	close := matchingBracket(p.text, open)
+	if close < 0 {
+		return 0, 0, false
+	}
+	return open + 1, close, true
+}
+
+// phpUses maps the short names (or aliases) imported by use statements to
+// fully qualified class names
+func phpUses(code string) map[string]string {
+	uses := make(map[string]string)
+	for _, m := range phpAliasedUsePattern.FindAllStringSubmatch(code, -1) {
+		alias := m[2]
+		if alias == "" {
+			alias = m[1][strings.LastIndex(m[1], "\\")+1:]
+		}
+		uses[alias] = m[1]
+	}
+	return uses
+}
+
+// phpNamespace returns the namespace declared by a file
+func phpNamespace(code string) string {
+	if m := phpNamespacePattern.FindStringSubmatch(code); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// resolvePHPClass turns a class name as written into a fully qualified name
+func resolvePHPClass(name, namespace string, uses map[string]string) string {
+	if strings.HasPrefix(name, "\\") {
+		return strings.TrimPrefix(name, "\\")
+	}
+	first, rest, qualified := strings.Cut(name, "\\")
+	if fqn, ok := uses[first]; ok {
+		if qualified {
+			return fqn + "\\" + rest
+		}
+		return fqn
+	}
+	if namespace != "" {
+		return namespace + "\\" + name
+	}
+	return name
+}
+
+// isIdentByte reports whether c can be part of a PHP identifier or variable
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c == '\\' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// skipSpaces returns the offset of the next non-whitespace character
+func skipSpaces(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t' || s[i] == '\n' || s[i] == '\r') {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,165 @@
+package indexer
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// maxRepoMapSymbols is the maximum number of symbols listed per file
+	maxRepoMapSymbols = 6
+
+	// recencyHalfLife controls how quickly the recency bonus decays
+	recencyHalfLife = 7 * 24 * time.Hour
+)
+
+// rankedFile is a file considered for the repository map
+type rankedFile struct {
+	path    string
+	score   float64
+	symbols []rankedSymbol
+}
+
+// rankedSymbol is a symbol with the number of other files referring to it
+type rankedSymbol struct {
+	symbol Symbol
+	refs   int
+}
+
+// RepoMap renders a compact map of the most important files and their key symbols.
+// Files are ranked by how many other files reference their symbols, with a bonus
+// for recently modified files, and the output is trimmed to roughly tokenBudget
+// tokens (estimated at four characters per token).
+func (idx *Index) RepoMap(tokenBudget int) string {
+	if tokenBudget <= 0 {
+		return ""
+	}
+
+	idx.mu.RLock()
+	ranked := idx.rankFiles(time.Now())
+	idx.mu.RUnlock()
+
+	charBudget := tokenBudget * 4
+	var sb strings.Builder
+	for _, file := range ranked {
+		var block strings.Builder
+		block.WriteString(file.path + ":\n")
+		for _, rs := range file.symbols {
+			block.WriteString("  " + describeSymbol(rs.symbol) + "\n")
+		}
+		if sb.Len()+block.Len() > charBudget {
+			// Try to fit a smaller file later in the list
+			continue
+		}
+		sb.WriteString(block.String())
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// rankFiles scores every file with symbols; the caller must hold idx.mu
+func (idx *Index) rankFiles(now time.Time) []rankedFile {
+	docCount := 0
+	if idx.search != nil {
+		docCount = len(idx.search.docLength)
+	}
+	// Names used by a large share of files (main, init, handle...) say nothing about importance
+	maxDocFreq := docCount / 4
+	if maxDocFreq < 10 {
+		maxDocFreq = 10
+	}
+
+	var ranked []rankedFile
+	for path, info := range idx.files {
+		if info.IsDir || len(info.Symbols) == 0 {
+			continue
+		}
+
+		file := rankedFile{path: path}
+		totalRefs := 0
+		seen := make(map[string]bool)
+		for _, sym := range info.Symbols {
+			refs := 0
+			term := symbolTerm(sym.Name)
+			if idx.search != nil && term != "" {
+				postings := idx.search.postings[term]
+				if len(postings) <= maxDocFreq {
+					refs = len(postings)
+					if _, self := postings[path]; self {
+						refs--
+					}
+				}
+			}
+			if !seen[term] {
+				seen[term] = true
+				totalRefs += refs
+			}
+			file.symbols = append(file.symbols, rankedSymbol{symbol: sym, refs: refs})
+		}
+
+		file.score = math.Log1p(float64(totalRefs)) + recencyBonus(info.ModTime, now)
+		if isTestPath(path) {
+			// Tests are rarely the entry point for a question
+			file.score /= 2
+		}
+
+		// Keep the most referenced symbols, listed in source order
+		sort.SliceStable(file.symbols, func(i, j int) bool { return file.symbols[i].refs > file.symbols[j].refs })
+		if len(file.symbols) > maxRepoMapSymbols {
+			file.symbols = file.symbols[:maxRepoMapSymbols]
+		}
+		sort.Slice(file.symbols, func(i, j int) bool { return file.symbols[i].symbol.Line < file.symbols[j].symbol.Line })
+
+		ranked = append(ranked, file)
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		return ranked[i].path < ranked[j].path
+	})
+	return ranked
+}
+
+// symbolTerm returns the search term under which a symbol name is indexed
+func symbolTerm(name string) string {
+	if i := strings.LastIndexAny(name, ".:"); i >= 0 {
+		name = name[i+1:]
+	}
+	return normalizeTerm(strings.ToLower(strings.ReplaceAll(name, "_", "")))
+}
+
+// recencyBonus gives recently modified files up to one extra point
+func recencyBonus(modTime, now time.Time) float64 {
+	if modTime.IsZero() {
+		return 0
+	}
+	age := now.Sub(modTime)
+	if age < 0 {
+		age = 0
+	}
+	return math.Pow(0.5, float64(age)/float64(recencyHalfLife))
+}
+
+// isTestPath checks if a path looks like a test file
+func isTestPath(path string) bool {
+	base := path[strings.LastIndex(path, "/")+1:]
+	return strings.HasSuffix(base, "_test.go") ||
+		strings.Contains(base, ".test.") ||
+		strings.Contains(base, ".spec.") ||
+		strings.HasSuffix(base, "Test.php") ||
+		strings.HasPrefix(base, "test_") ||
+		strings.HasPrefix(path, "tests/") ||
+		strings.Contains(path, "/tests/")
+}
+
+// describeSymbol formats a symbol for the repository map
+func describeSymbol(sym Symbol) string {
+	if sym.Signature != "" {
+		return sym.Signature
+	}
+	return fmt.Sprintf("%s %s", sym.Type, sym.Name)
+}
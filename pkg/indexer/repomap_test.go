@@ -0,0 +1,39 @@
+package indexer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRepoMap(t *testing.T) {
+	files := map[string]string{
+		"pkg/store/store.go": "package store\n\ntype Store struct{}\n\nfunc NewStore() *Store {\n\treturn &Store{}\n}\n",
+		"cmd/a/main.go":      "package main\n\nfunc runA() { store.NewStore() }\n",
+		"cmd/b/main.go":      "package main\n\nfunc runB() { store.NewStore() }\n",
+		"pkg/lonely/x.go":    "package lonely\n\nfunc Unused() {}\n",
+	}
+	root, cfg := newTestProject(t, files)
+
+	idx := NewIndex(root, cfg)
+	if err := idx.IndexProject(); err != nil {
+		t.Fatalf("IndexProject failed: %v", err)
+	}
+
+	repoMap := idx.RepoMap(1000)
+	if !strings.HasPrefix(repoMap, "pkg/store/store.go:\n") {
+		t.Errorf("Expected the most referenced file first, got:\n%s", repoMap)
+	}
+	if !strings.Contains(repoMap, "  func NewStore() *Store") {
+		t.Errorf("Expected signatures in the map, got:\n%s", repoMap)
+	}
+
+	// A tiny budget keeps only what fits
+	small := idx.RepoMap(20)
+	if len(small) > 80 || !strings.HasPrefix(small, "pkg/store/store.go:") || strings.Contains(small, "main.go") {
+		t.Errorf("Expected only the top file within the budget, got:\n%s", small)
+	}
+
+	if idx.RepoMap(0) != "" {
+		t.Error("Expected empty map for zero budget")
+	}
+}
@@ -0,0 +1,340 @@
+package indexer
+
+import (
+	"bufio"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/axon/pkg/fsctx"
+)
+
+// BM25 tuning parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	// pathTermWeight is how many times each token of a file's path is counted,
+	// so that files named after a concept rank above files that only mention it
+	pathTermWeight = 3
+
+	// maxSnippetsPerFile limits how many matching lines are returned per file
+	maxSnippetsPerFile = 3
+)
+
+// searchableTextExtensions lists non-code files that are included in full-text search
+var searchableTextExtensions = map[string]bool{
+	".md":   true,
+	".txt":  true,
+	".json": true,
+	".yml":  true,
+	".yaml": true,
+	".toml": true,
+	".xml":  true,
+	".sql":  true,
+	".html": true,
+	".css":  true,
+	".scss": true,
+	".vue":  true,
+	".twig": true,
+	".env":  true,
+	".ini":  true,
+}
+
+// stopWords are common English words that carry no meaning in code search queries
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "do": true, "does": true, "for": true, "from": true,
+	"how": true, "in": true, "is": true, "it": true, "of": true, "on": true,
+	"or": true, "the": true, "to": true, "we": true, "what": true, "where": true,
+	"which": true, "who": true, "with": true,
+}
+
+// SearchResult is a single file returned by Search
+type SearchResult struct {
+	Path     string    `json:"file"`
+	Score    float64   `json:"score"`
+	Snippets []Snippet `json:"snippets,omitempty"`
+}
+
+// Snippet is a matching line within a search result
+type Snippet struct {
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// searchIndex is an in-process inverted index used for BM25 ranking
+type searchIndex struct {
+	postings  map[string]map[string]int // term -> path -> term frequency
+	docLength map[string]int            // path -> number of terms
+	totalLen  int
+}
+
+// newSearchIndex creates an empty inverted index
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		postings:  make(map[string]map[string]int),
+		docLength: make(map[string]int),
+	}
+}
+
+// addDocument adds the term frequencies of a file to the index
+func (si *searchIndex) addDocument(path string, terms map[string]int) {
+	length := 0
+	for term, tf := range terms {
+		if si.postings[term] == nil {
+			si.postings[term] = make(map[string]int)
+		}
+		si.postings[term][path] = tf
+		length += tf
+	}
+	si.docLength[path] = length
+	si.totalLen += length
+}
+
+// isSearchableFile checks if a file's content should be added to the search index
+func (idx *Index) isSearchableFile(path string) bool {
+	return idx.isCodeFile(path) || searchableTextExtensions[fsctx.GetFileExtension(path)]
+}
+
+// documentTerms returns the term frequencies used for search.
+// Tokens from the file path are included with extra weight.
+func documentTerms(relPath string, content []byte) map[string]int {
+	terms := make(map[string]int)
+
+	for _, token := range Tokenize(relPath) {
+		terms[token] += pathTermWeight
+	}
+	for _, token := range Tokenize(string(content)) {
+		terms[token]++
+	}
+	return terms
+}
+
+// Tokenize splits text into lowercase search terms.
+// Identifiers are split on camelCase and snake_case boundaries; compound
+// identifiers are also kept whole so that exact matches score higher.
+func Tokenize(text string) []string {
+	var tokens []string
+	for _, word := range splitWords(text) {
+		parts := SplitIdentifier(word)
+		if len(parts) > 1 {
+			if whole := normalizeTerm(strings.ToLower(strings.ReplaceAll(word, "_", ""))); whole != "" {
+				tokens = append(tokens, whole)
+			}
+		}
+		for _, part := range parts {
+			if term := normalizeTerm(part); term != "" {
+				tokens = append(tokens, term)
+			}
+		}
+	}
+	return tokens
+}
+
+// splitWords returns the identifier-like words (letters, digits, underscores) in text
+func splitWords(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_')
+	})
+}
+
+// SplitIdentifier splits an identifier into lowercase parts on camelCase,
+// PascalCase, acronym and snake_case boundaries.
+// For example "parseHTTPRequest_v2" becomes ["parse", "http", "request", "v2"].
+func SplitIdentifier(ident string) []string {
+	var parts []string
+	for _, chunk := range strings.Split(ident, "_") {
+		runes := []rune(chunk)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			prev, cur := runes[i-1], runes[i]
+			boundary := false
+			switch {
+			case unicode.IsLower(prev) && unicode.IsUpper(cur):
+				// fooBar
+				boundary = true
+			case unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(runes) && unicode.IsLower(runes[i+1]):
+				// HTTPServer -> HTTP | Server
+				boundary = true
+			}
+			if boundary {
+				parts = append(parts, strings.ToLower(string(runes[start:i])))
+				start = i
+			}
+		}
+		if start < len(runes) {
+			parts = append(parts, strings.ToLower(string(runes[start:])))
+		}
+	}
+	return parts
+}
+
+// normalizeTerm filters out noise terms and folds simple plurals
+func normalizeTerm(term string) string {
+	if len(term) < 2 || stopWords[term] {
+		return ""
+	}
+	if isNumeric(term) {
+		return ""
+	}
+	// Fold simple plurals ("routes" -> "route") but keep words like "class"
+	if len(term) > 3 && strings.HasSuffix(term, "s") && !strings.HasSuffix(term, "ss") {
+		term = term[:len(term)-1]
+	}
+	return term
+}
+
+// isNumeric checks if a term consists of digits only
+func isNumeric(term string) bool {
+	for _, r := range term {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Search ranks indexed files against a natural-language or identifier query using BM25.
+// If pathPrefix is non-empty, only files under that path are considered.
+// Each result includes up to three matching lines.
+func (idx *Index) Search(query string, limit int, pathPrefix string) []SearchResult {
+	idx.mu.RLock()
+	si := idx.search
+	idx.mu.RUnlock()
+
+	if si == nil || len(si.docLength) == 0 {
+		return nil
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+	pathPrefix = strings.Trim(pathPrefix, "/")
+
+	queryTerms := uniqueTerms(Tokenize(query))
+	if len(queryTerms) == 0 {
+		return nil
+	}
+
+	docCount := float64(len(si.docLength))
+	avgLen := float64(si.totalLen) / docCount
+	scores := make(map[string]float64)
+
+	for _, term := range queryTerms {
+		postings := si.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+		df := float64(len(postings))
+		idf := math.Log(1 + (docCount-df+0.5)/(df+0.5))
+		for path, tf := range postings {
+			if pathPrefix != "" && path != pathPrefix && !strings.HasPrefix(path, pathPrefix+"/") {
+				continue
+			}
+			freq := float64(tf)
+			norm := 1 - bm25B + bm25B*float64(si.docLength[path])/avgLen
+			scores[path] += idf * freq * (bm25K1 + 1) / (freq + bm25K1*norm)
+		}
+	}
+
+	results := make([]SearchResult, 0, len(scores))
+	for path, score := range scores {
+		results = append(results, SearchResult{Path: path, Score: math.Round(score*1000) / 1000})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Path < results[j].Path
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	for i := range results {
+		results[i].Snippets = findSnippets(filepath.Join(idx.projectRoot, results[i].Path), queryTerms)
+	}
+	return results
+}
+
+// uniqueTerms removes duplicate terms while keeping their order
+func uniqueTerms(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	unique := terms[:0:0]
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			unique = append(unique, term)
+		}
+	}
+	return unique
+}
+
+// findSnippets returns the lines of a file that match the most query terms
+func findSnippets(fullPath string, queryTerms []string) []Snippet {
+	file, err := os.Open(fullPath)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	wanted := make(map[string]bool, len(queryTerms))
+	for _, term := range queryTerms {
+		wanted[term] = true
+	}
+
+	type scoredLine struct {
+		snippet Snippet
+		hits    int
+	}
+	var candidates []scoredLine
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), fsctx.MaxFileSize)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		hits := 0
+		matched := make(map[string]bool)
+		for _, token := range Tokenize(line) {
+			if wanted[token] && !matched[token] {
+				matched[token] = true
+				hits++
+			}
+		}
+		if hits == 0 {
+			continue
+		}
+		text := strings.TrimSpace(line)
+		if len(text) > 200 {
+			// Cut on a rune boundary so the snippet stays valid UTF-8
+			cut := 200
+			for cut > 0 && !utf8.RuneStart(text[cut]) {
+				cut--
+			}
+			text = text[:cut] + "..."
+		}
+		candidates = append(candidates, scoredLine{snippet: Snippet{Line: lineNum, Text: text}, hits: hits})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].hits > candidates[j].hits
+	})
+	if len(candidates) > maxSnippetsPerFile {
+		candidates = candidates[:maxSnippetsPerFile]
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].snippet.Line < candidates[j].snippet.Line
+	})
+
+	snippets := make([]Snippet, 0, len(candidates))
+	for _, c := range candidates {
+		snippets = append(snippets, c.snippet)
+	}
+	return snippets
+}
@@ -0,0 +1,119 @@
+package indexer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplitIdentifier(t *testing.T) {
+	tests := []struct {
+		ident    string
+		expected []string
+	}{
+		{"rateLimiter", []string{"rate", "limiter"}},
+		{"RateLimiterMiddleware", []string{"rate", "limiter", "middleware"}},
+		{"parseHTTPRequest", []string{"parse", "http", "request"}},
+		{"snake_case_name", []string{"snake", "case", "name"}},
+		{"HTML", []string{"html"}},
+		{"v2", []string{"v2"}},
+	}
+
+	for _, tt := range tests {
+		result := SplitIdentifier(tt.ident)
+		if !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("SplitIdentifier(%q) = %v, expected %v", tt.ident, result, tt.expected)
+		}
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	result := Tokenize("func (l *RateLimiter) Allow() bool // the limiter")
+	expected := []string{"func", "ratelimiter", "rate", "limiter", "allow", "bool", "limiter"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Tokenize() = %v, expected %v", result, expected)
+	}
+
+	// Plurals fold to the singular form
+	if got := Tokenize("routes"); !reflect.DeepEqual(got, []string{"route"}) {
+		t.Errorf("Tokenize(routes) = %v, expected [route]", got)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	files := map[string]string{
+		"app/Http/Middleware/RateLimiter.php":     "<?php\nclass RateLimiter\n{\n    public function handle($request, $next)\n    {\n        // throttle requests per minute\n        return $next($request);\n    }\n}\n",
+		"app/Http/Controllers/UserController.php": "<?php\nclass UserController\n{\n    public function index() { return User::all(); }\n}\n",
+		"internal/limiter/limiter.go":             "package limiter\n\n// Limiter implements a token bucket rate limiter\ntype Limiter struct{}\n",
+		"docs/notes.md":                           "Middleware notes: nothing about limits here.\n",
+	}
+	root, cfg := newTestProject(t, files)
+
+	idx := NewIndex(root, cfg)
+	if err := idx.IndexProject(); err != nil {
+		t.Fatalf("IndexProject failed: %v", err)
+	}
+
+	results := idx.Search("rate limiter middleware", 10, "")
+	if len(results) == 0 {
+		t.Fatal("Expected search results")
+	}
+	if results[0].Path != "app/Http/Middleware/RateLimiter.php" {
+		t.Errorf("Expected RateLimiter.php to rank first, got %+v", results)
+	}
+	if len(results[0].Snippets) == 0 || results[0].Snippets[0].Line != 2 {
+		t.Errorf("Expected a snippet on the class line, got %+v", results[0].Snippets)
+	}
+
+	for _, r := range results {
+		if r.Path == "app/Http/Controllers/UserController.php" {
+			t.Errorf("UserController should not match, got %+v", r)
+		}
+	}
+
+	// Path prefix filter
+	results = idx.Search("limiter", 10, "internal")
+	if len(results) != 1 || results[0].Path != "internal/limiter/limiter.go" {
+		t.Errorf("Expected only internal/limiter/limiter.go, got %+v", results)
+	}
+	if results := idx.Search("limiter", 10, "intern"); len(results) != 0 {
+		t.Errorf("Expected the prefix to match whole directories, got %+v", results)
+	}
+	if results := idx.Search("limiter", 10, "internal/limiter/limiter.go/"); len(results) != 1 {
+		t.Errorf("Expected a file path to match the file, got %+v", results)
+	}
+
+	// Limit
+	results = idx.Search("limiter", 1, "")
+	if len(results) != 1 {
+		t.Errorf("Expected 1 result with limit, got %d", len(results))
+	}
+
+	if results := idx.Search("the of and", 10, ""); len(results) != 0 {
+		t.Errorf("Expected no results for a stop-word query, got %+v", results)
+	}
+}
+
+func TestSearchSnippetsKeepRunesWhole(t *testing.T) {
+	// The 200th byte falls in the middle of an "é"
+	line := "// résumé " + strings.Repeat("x", 187) + strings.Repeat("é", 20)
+	root, cfg := newTestProject(t, map[string]string{"cv.go": "package cv\n\n" + line + "\n"})
+
+	idx := NewIndex(root, cfg)
+	if err := idx.IndexProject(); err != nil {
+		t.Fatalf("IndexProject failed: %v", err)
+	}
+
+	results := idx.Search("résumé", 10, "")
+	if len(results) != 1 || len(results[0].Snippets) != 1 {
+		t.Fatalf("Expected one snippet, got %+v", results)
+	}
+	text := results[0].Snippets[0].Text
+	if !utf8.ValidString(text) {
+		t.Errorf("Snippet is not valid UTF-8: %q", text)
+	}
+	if !strings.HasSuffix(text, "x...") {
+		t.Errorf("Expected the snippet to stop before the cut rune, got %q", text)
+	}
+}
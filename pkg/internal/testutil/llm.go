@@ -0,0 +1,53 @@
+// Package testutil holds helpers shared by the tests of several packages.
+package testutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/axon/pkg/llm"
+)
+
+// FakeLLM serves an OpenAI-compatible chat endpoint that streams a
+// create_file tool call for path and content, then, once the tool result is
+// in the conversation, the answer one token per word. Each reply reports
+// its token usage: 10 prompt and 5 completion tokens for the tool call, 20
+// and 2 for the answer. The server is closed when the test ends.
+func FakeLLM(t *testing.T, path, content string, answer ...string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req llm.ChatCompletionRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "text/event-stream")
+		if req.Messages[len(req.Messages)-1].Role == "tool" {
+			for _, word := range answer {
+				writeChunk(w, map[string]interface{}{"choices": []interface{}{map[string]interface{}{"delta": map[string]string{"content": word}}}})
+			}
+			writeChunk(w, map[string]interface{}{"choices": []interface{}{map[string]interface{}{"delta": map[string]string{}, "finish_reason": "stop"}}})
+			writeChunk(w, map[string]interface{}{"choices": []interface{}{}, "usage": llm.Usage{PromptTokens: 20, CompletionTokens: 2, TotalTokens: 22}})
+			fmt.Fprint(w, "data: [DONE]\n\n")
+			return
+		}
+		arguments, _ := json.Marshal(map[string]string{"path": path, "content": content})
+		writeChunk(w, map[string]interface{}{"choices": []interface{}{map[string]interface{}{
+			"delta": map[string]interface{}{"tool_calls": []interface{}{map[string]interface{}{
+				"index": 0, "id": "call_1", "type": "function",
+				"function": map[string]string{"name": "create_file", "arguments": string(arguments)},
+			}}},
+			"finish_reason": "tool_calls",
+		}}})
+		writeChunk(w, map[string]interface{}{"choices": []interface{}{}, "usage": llm.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}})
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// writeChunk writes one server-sent event of the stream
+func writeChunk(w http.ResponseWriter, chunk interface{}) {
+	data, _ := json.Marshal(chunk)
+	fmt.Fprintf(w, "data: %s\n\n", data)
+}
@@ -0,0 +1,99 @@
+// Package interrupt routes Ctrl+C to whatever axon is running. A command or
+// turn that claims it with NotifyContext is cancelled on its own; only when
+// nothing holds a claim does the interrupt reach the process-wide handler
+// installed with Handle, which typically stops the LLM server and exits.
+package interrupt
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+	"syscall"
+)
+
+var (
+	mu       sync.Mutex
+	signals  chan os.Signal
+	claims   []*claim // Innermost last
+	fallback func(os.Signal)
+)
+
+// claim is a context waiting for Ctrl+C
+type claim struct {
+	cancel context.CancelFunc
+}
+
+// Handle installs fn as the handler of SIGINT and SIGTERM. An interrupt
+// while a claim is held cancels the innermost claim instead.
+func Handle(fn func(os.Signal)) {
+	mu.Lock()
+	defer mu.Unlock()
+	fallback = fn
+	listen()
+}
+
+// NotifyContext returns a copy of parent that is cancelled by the next
+// Ctrl+C, or when stop is called. Until then, Ctrl+C cancels it rather than
+// reaching the handler installed with Handle; claims nest, and each Ctrl+C
+// cancels the innermost one.
+func NotifyContext(parent context.Context) (ctx context.Context, stop context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	c := &claim{cancel: cancel}
+	mu.Lock()
+	claims = append(claims, c)
+	listen()
+	mu.Unlock()
+
+	return ctx, func() {
+		mu.Lock()
+		release(c)
+		mu.Unlock()
+		cancel()
+	}
+}
+
+// listen delivers the signals to dispatch while there is a handler or a
+// claim, and restores their default behaviour otherwise. The caller holds mu.
+func listen() {
+	if signals == nil {
+		signals = make(chan os.Signal, 1)
+		go dispatch()
+	}
+	switch {
+	case fallback != nil:
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	case len(claims) > 0:
+		signal.Notify(signals, os.Interrupt)
+	default:
+		signal.Stop(signals)
+	}
+}
+
+// release drops a claim if it is still held. The caller holds mu.
+func release(c *claim) {
+	if i := slices.Index(claims, c); i >= 0 {
+		claims = slices.Delete(claims, i, i+1)
+		listen()
+	}
+}
+
+// dispatch hands each signal to the innermost claim, or to the handler
+func dispatch() {
+	for sig := range signals {
+		mu.Lock()
+		if sig == os.Interrupt && len(claims) > 0 {
+			c := claims[len(claims)-1]
+			release(c)
+			mu.Unlock()
+			c.cancel()
+			continue
+		}
+		fn := fallback
+		mu.Unlock()
+		if fn != nil {
+			fn(sig)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package interrupt
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// interruptSelf sends Ctrl+C to the test process
+func interruptSelf(t *testing.T) {
+	t.Helper()
+	if err := syscall.Kill(os.Getpid(), syscall.SIGINT); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClaimsComeBeforeTheHandler(t *testing.T) {
+	handled := make(chan os.Signal, 1)
+	Handle(func(sig os.Signal) { handled <- sig })
+	t.Cleanup(func() { Handle(nil) })
+
+	turn, stopTurn := NotifyContext(context.Background())
+	defer stopTurn()
+	command, stopCommand := NotifyContext(turn)
+	defer stopCommand()
+
+	// The first Ctrl+C stops the command only, the second the turn
+	interruptSelf(t)
+	select {
+	case <-command.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("command not cancelled")
+	}
+	if turn.Err() != nil {
+		t.Error("turn cancelled with the command")
+	}
+	interruptSelf(t)
+	select {
+	case <-turn.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("turn not cancelled")
+	}
+	select {
+	case sig := <-handled:
+		t.Fatalf("handler got %v while a claim was held", sig)
+	default:
+	}
+
+	// Once nothing claims it, Ctrl+C reaches the handler
+	interruptSelf(t)
+	select {
+	case <-handled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler not called")
+	}
+}
+
+func TestStopReleasesTheClaim(t *testing.T) {
+	handled := make(chan os.Signal, 1)
+	Handle(func(sig os.Signal) { handled <- sig })
+	t.Cleanup(func() { Handle(nil) })
+
+	ctx, stop := NotifyContext(context.Background())
+	stop()
+	if ctx.Err() == nil {
+		t.Error("stop did not cancel the context")
+	}
+	interruptSelf(t)
+	select {
+	case <-handled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler not called after the claim was released")
+	}
+}
@@ -0,0 +1,251 @@
+// Package jsonl drives a chat session with newline-delimited JSON over a
+// pair of streams, so that editor extensions can run axon as a subprocess.
+//
+// Each line read is a Request; each line written is an Output. The server
+// first announces itself with a ready line carrying the protocol version,
+// then reports every event of a message's turn tagged with the message's ID.
+// The protocol only grows compatibly within a version: clients must ignore
+// fields and event types they do not know.
+package jsonl
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/axon/pkg/chat"
+)
+
+// Protocol is the version of the protocol, announced in the ready line
+const Protocol = 1
+
+// Request types
+const (
+	RequestMessage  = "message"  // Send a user message
+	RequestApproval = "approval" // Answer a confirmation
+	RequestCancel   = "cancel"   // Stop the running turn
+)
+
+// EventReady is the type of the first line the server writes
+const EventReady = "ready"
+
+// maxLine is the largest request the server reads
+const maxLine = 16 * 1024 * 1024
+
+// Request is a line sent by the client
+type Request struct {
+	Type         string `json:"type"`
+	ID           string `json:"id,omitempty"`           // Message: tags its events; one is assigned if empty
+	Content      string `json:"content,omitempty"`      // Message: the text
+	Confirmation string `json:"confirmation,omitempty"` // Approval: the confirmation's ID
+	Decision     string `json:"decision,omitempty"`     // Approval: yes, no or always
+}
+
+// Output is a line written by the server: a chat event, tagged with the
+// message it belongs to. Errors about requests themselves carry the
+// request's ID, if any.
+type Output struct {
+	chat.Event
+	ID string `json:"id,omitempty"`
+}
+
+// ready is the first line written
+type ready struct {
+	Type     string `json:"type"`
+	Protocol int    `json:"protocol"`
+}
+
+// Server answers requests for one chat session
+type Server struct {
+	session *chat.Session
+
+	writeMu sync.Mutex
+	w       io.Writer
+
+	mu       sync.Mutex
+	running  string             // ID of the message being answered
+	cancel   context.CancelFunc // Cancels the running turn
+	pending  map[string]chan string
+	messages int  // Messages received, for assigning IDs
+	closed   bool // No more requests will come
+}
+
+// NewServer creates a server for a session; it becomes the session's
+// Approver, so confirmations are asked of the client
+func NewServer(session *chat.Session) *Server {
+	s := &Server{session: session, pending: make(map[string]chan string)}
+	session.SetApprover(s)
+	return s
+}
+
+// Serve reads requests from r and writes events to w until r ends or ctx is
+// cancelled. When r ends, a running turn is finished with its confirmations
+// answered no; when ctx is cancelled, it is cancelled. Serve waits for it
+// either way.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	s.w = w
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), maxLine)
+		for scanner.Scan() {
+			line := append([]byte(nil), scanner.Bytes()...)
+			select {
+			case lines <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+		readErr <- scanner.Err()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	s.write(ready{Type: EventReady, Protocol: Protocol})
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-readErr:
+			s.closeInput()
+			return err
+		case line := <-lines:
+			if len(strings.TrimSpace(string(line))) == 0 {
+				continue
+			}
+			var req Request
+			if err := json.Unmarshal(line, &req); err != nil {
+				s.fail("", fmt.Sprintf("invalid request: %v", err))
+				continue
+			}
+			s.handle(ctx, &wg, &req)
+		}
+	}
+}
+
+// handle acts on one request; messages are answered in the background
+func (s *Server) handle(ctx context.Context, wg *sync.WaitGroup, req *Request) {
+	switch req.Type {
+	case RequestMessage:
+		s.mu.Lock()
+		s.messages++
+		if req.ID == "" {
+			req.ID = strconv.Itoa(s.messages)
+		}
+		if running := s.running; running != "" {
+			s.mu.Unlock()
+			s.fail(req.ID, fmt.Sprintf("message %s is still being answered", running))
+			return
+		}
+		turnCtx, cancel := context.WithCancel(ctx)
+		s.running, s.cancel = req.ID, cancel
+		s.mu.Unlock()
+
+		wg.Add(1)
+		go func(id, content string) {
+			defer wg.Done()
+			defer cancel()
+			// The final event is held back until the session is ready for
+			// the next message; failures are reported as error events
+			var last chat.Event
+			s.session.Send(turnCtx, content, func(e chat.Event) {
+				if e.Type == chat.EventDone || e.Type == chat.EventError {
+					last = e
+					return
+				}
+				s.write(Output{Event: e, ID: id})
+			})
+			s.mu.Lock()
+			s.running, s.cancel = "", nil
+			s.mu.Unlock()
+			s.write(Output{Event: last, ID: id})
+		}(req.ID, req.Content)
+	case RequestApproval:
+		switch req.Decision {
+		case chat.DecisionYes, chat.DecisionNo, chat.DecisionAlways:
+		default:
+			s.fail(req.ID, fmt.Sprintf("invalid decision %q: expected yes, no or always", req.Decision))
+			return
+		}
+		s.mu.Lock()
+		answer := s.pending[req.Confirmation]
+		delete(s.pending, req.Confirmation)
+		s.mu.Unlock()
+		if answer == nil {
+			s.fail(req.ID, fmt.Sprintf("no pending confirmation %q", req.Confirmation))
+			return
+		}
+		answer <- req.Decision
+	case RequestCancel:
+		s.mu.Lock()
+		// A turn that has just finished needs no cancelling
+		if s.cancel != nil && (req.ID == "" || req.ID == s.running) {
+			s.cancel()
+		}
+		s.mu.Unlock()
+	default:
+		s.fail(req.ID, fmt.Sprintf("unknown request type %q", req.Type))
+	}
+}
+
+// Approve waits for the client to answer a confirmation, which the event
+// stream has announced. A cancelled turn answers no, and so does a client
+// that has closed its input.
+func (s *Server) Approve(ctx context.Context, c chat.Confirmation) (string, error) {
+	answer := make(chan string, 1)
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return chat.DecisionNo, nil
+	}
+	s.pending[c.ID] = answer
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.pending, c.ID)
+		s.mu.Unlock()
+	}()
+	select {
+	case decision := <-answer:
+		return decision, nil
+	case <-ctx.Done():
+		return chat.DecisionNo, nil
+	}
+}
+
+// closeInput answers the pending confirmations no, as will be the later ones
+func (s *Server) closeInput() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for id, answer := range s.pending {
+		answer <- chat.DecisionNo
+		delete(s.pending, id)
+	}
+}
+
+// fail reports a request that could not be handled
+func (s *Server) fail(id, message string) {
+	s.write(Output{Event: chat.Event{Type: chat.EventError, Error: message}, ID: id})
+}
+
+// write sends one line
+func (s *Server) write(v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		body, _ = json.Marshal(Output{Event: chat.Event{Type: chat.EventError, Error: fmt.Sprintf("failed to encode event: %v", err)}})
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.w.Write(append(body, '\n'))
+}
@@ -0,0 +1,148 @@
+package jsonl
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/axon/pkg/chat"
+	"github.com/axon/pkg/internal/testutil"
+	"github.com/axon/pkg/llm"
+	"github.com/axon/pkg/project"
+)
+
+// start serves a session for a temporary project; requests are written to
+// the returned writer and the lines written back are read from the channel
+func start(t *testing.T) (io.WriteCloser, <-chan Output, string) {
+	model := testutil.FakeLLM(t, "a.txt", "x", "Done.")
+	root := t.TempDir()
+	cfg, err := project.LoadConfig(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := chat.NewSession(llm.NewClient(model.URL, "test", 0), root, cfg, false, nil)
+	t.Cleanup(session.Close)
+
+	in, requests := io.Pipe()
+	events, out := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- NewServer(session).Serve(context.Background(), in, out)
+		out.Close()
+	}()
+	t.Cleanup(func() {
+		requests.Close()
+		if err := <-done; err != nil {
+			t.Errorf("Serve: %v", err)
+		}
+	})
+
+	outputs := make(chan Output)
+	go func() {
+		defer close(outputs)
+		scanner := bufio.NewScanner(events)
+		for scanner.Scan() {
+			var o Output
+			if err := json.Unmarshal(scanner.Bytes(), &o); err != nil {
+				t.Errorf("bad line %s: %v", scanner.Text(), err)
+			}
+			if o.Type == EventReady && !strings.Contains(scanner.Text(), `"protocol":1`) {
+				t.Errorf("unexpected ready line %s", scanner.Text())
+			}
+			outputs <- o
+		}
+	}()
+	if o := <-outputs; o.Type != EventReady {
+		t.Fatalf("expected the ready line first, got %+v", o)
+	}
+	return requests, outputs, root
+}
+
+func send(t *testing.T, w io.Writer, req Request) {
+	line, _ := json.Marshal(req)
+	if _, err := w.Write(append(line, '\n')); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServe(t *testing.T) {
+	requests, outputs, root := start(t)
+	send(t, requests, Request{Type: RequestMessage, ID: "m1", Content: "Create a.txt"})
+
+	var types []string
+	var usage *llm.Usage
+	for o := range outputs {
+		if o.ID != "m1" {
+			t.Errorf("event %+v not tagged with the message", o)
+		}
+		types = append(types, o.Type)
+		switch o.Type {
+		case chat.EventConfirmation:
+			send(t, requests, Request{Type: RequestMessage, ID: "m2", Content: "x"})
+			if busy := <-outputs; busy.Type != chat.EventError || busy.ID != "m2" {
+				t.Errorf("expected m2 to be refused, got %+v", busy)
+			}
+			send(t, requests, Request{Type: RequestApproval, Confirmation: o.Confirmation.ID, Decision: chat.DecisionYes})
+		case chat.EventUsage:
+			usage = o.Usage
+		case chat.EventError:
+			t.Fatalf("unexpected error: %s", o.Error)
+		}
+		if o.Type == chat.EventDone {
+			if o.Text != "Done." {
+				t.Errorf("answer = %q", o.Text)
+			}
+			break
+		}
+	}
+
+	if got, want := strings.Join(types, ","), "tool_call,confirmation,tool_result,token,usage,done"; got != want {
+		t.Errorf("events = %s, want %s", got, want)
+	}
+	if usage == nil || *usage != (llm.Usage{PromptTokens: 30, CompletionTokens: 7, TotalTokens: 37}) {
+		t.Errorf("usage = %+v", usage)
+	}
+	if _, err := os.Stat(filepath.Join(root, "a.txt")); err != nil {
+		t.Errorf("file not created: %v", err)
+	}
+}
+
+func TestInvalidRequests(t *testing.T) {
+	requests, outputs, _ := start(t)
+	for _, line := range []string{
+		`not json`,
+		`{"type":"unknown","id":"r1"}`,
+		`{"type":"approval","id":"r2","confirmation":"9","decision":"yes"}`,
+		`{"type":"approval","id":"r3","confirmation":"1","decision":"maybe"}`,
+	} {
+		io.WriteString(requests, line+"\n")
+		if o := <-outputs; o.Type != chat.EventError || o.Error == "" {
+			t.Errorf("%s: expected an error, got %+v", line, o)
+		}
+	}
+}
+
+func TestClosedInputRejectsConfirmations(t *testing.T) {
+	requests, outputs, root := start(t)
+	send(t, requests, Request{Type: RequestMessage, Content: "Create a.txt"})
+	requests.Close()
+
+	var last Output
+	for o := range outputs {
+		if o.ID != "1" {
+			t.Errorf("event %+v not tagged with the assigned ID", o)
+		}
+		last = o
+	}
+	if last.Type != chat.EventDone {
+		t.Errorf("expected the turn to finish, got %+v", last)
+	}
+	if _, err := os.Stat(filepath.Join(root, "a.txt")); err == nil {
+		t.Error("file created without approval")
+	}
+}
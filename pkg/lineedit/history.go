@@ -0,0 +1,113 @@
+package lineedit
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MaxHistory is the number of entries a history keeps
+const MaxHistory = 1000
+
+// History is a list of submitted inputs, oldest first, optionally persisted
+// to a file with one entry per line
+type History struct {
+	path    string
+	entries []string
+}
+
+// OpenHistory loads the history stored at path; a missing file is an empty
+// history. Entries added later are appended to the file, so sessions
+// running side by side do not overwrite each other's history.
+func OpenHistory(path string) *History {
+	h := &History{path: path}
+	file, err := os.Open(path)
+	if err != nil {
+		return h
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxInput)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			h.entries = append(h.entries, decodeEntry(line))
+		}
+	}
+	if len(h.entries) > MaxHistory {
+		h.entries = h.entries[len(h.entries)-MaxHistory:]
+		h.rewrite()
+	}
+	return h
+}
+
+// Entries returns the entries, oldest first
+func (h *History) Entries() []string {
+	return h.entries
+}
+
+// Add appends an entry unless it is empty or repeats the latest one
+func (h *History) Add(entry string) {
+	if strings.TrimSpace(entry) == "" || (len(h.entries) > 0 && h.entries[len(h.entries)-1] == entry) {
+		return
+	}
+	h.entries = append(h.entries, entry)
+	if len(h.entries) > MaxHistory {
+		h.entries = h.entries[len(h.entries)-MaxHistory:]
+	}
+	if h.path == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o755); err != nil {
+		return
+	}
+	file, err := os.OpenFile(h.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	file.WriteString(encodeEntry(entry) + "\n")
+}
+
+// rewrite replaces the file with the entries kept
+func (h *History) rewrite() {
+	var b strings.Builder
+	for _, entry := range h.entries {
+		b.WriteString(encodeEntry(entry) + "\n")
+	}
+	tmp := h.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(b.String()), 0o600); err != nil {
+		return
+	}
+	if err := os.Rename(tmp, h.path); err != nil {
+		os.Remove(tmp)
+	}
+}
+
+// encodeEntry escapes backslashes and line breaks, so that a multiline
+// entry takes a single line of the file
+func encodeEntry(entry string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`).Replace(entry)
+}
+
+// decodeEntry reverses encodeEntry
+func decodeEntry(line string) string {
+	var b strings.Builder
+	for i := 0; i < len(line); i++ {
+		if line[i] != '\\' || i+1 == len(line) {
+			b.WriteByte(line[i])
+			continue
+		}
+		i++
+		switch line[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		default:
+			b.WriteByte(line[i])
+		}
+	}
+	return b.String()
+}
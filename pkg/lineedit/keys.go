@@ -0,0 +1,207 @@
+package lineedit
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Kinds of keys the editor handles
+const (
+	keyUnknown = iota
+	keyRune
+	keyEnter
+	keyNewline // Ctrl+J or Alt+Enter: a line break inside the input
+	keyTab
+	keyBackspace
+	keyDelete
+	keyLeft
+	keyRight
+	keyUp
+	keyDown
+	keyHome
+	keyEnd
+	keyWordLeft
+	keyWordRight
+	keyDeleteWord // Deletes the word before the cursor
+	keyKillEnd    // Deletes up to the end of the line
+	keyKillStart  // Deletes up to the start of the line
+	keyInterrupt  // Ctrl+C
+	keyEOF        // Ctrl+D
+	keySearch     // Ctrl+R
+	keyCancel     // Ctrl+G
+	keyClear      // Ctrl+L
+	keyPaste      // A bracketed paste, delivered whole
+)
+
+// Bracketed paste markers: terminals put pasted text between them once
+// enablePaste is written
+const (
+	enablePaste  = "\x1b[?2004h"
+	disablePaste = "\x1b[?2004l"
+	pasteStart   = "200~"
+	pasteEnd     = "\x1b[201~"
+)
+
+// key is a key press, or a whole paste
+type key struct {
+	kind int
+	r    rune   // keyRune
+	text string // keyPaste
+}
+
+// controlKeys are the keys sent as a single control byte
+var controlKeys = map[byte]int{
+	0x01: keyHome, 0x02: keyLeft, 0x03: keyInterrupt, 0x04: keyEOF, 0x05: keyEnd,
+	0x06: keyRight, 0x07: keyCancel, 0x08: keyBackspace, 0x09: keyTab, 0x0a: keyNewline,
+	0x0b: keyKillEnd, 0x0c: keyClear, 0x0d: keyEnter, 0x0e: keyDown, 0x10: keyUp,
+	0x12: keySearch, 0x15: keyKillStart, 0x17: keyDeleteWord, 0x7f: keyBackspace,
+}
+
+// escapeKeys are the escape sequences of keys, without the leading ESC
+var escapeKeys = map[string]int{
+	"[A": keyUp, "[B": keyDown, "[C": keyRight, "[D": keyLeft,
+	"[H": keyHome, "[F": keyEnd, "OA": keyUp, "OB": keyDown, "OC": keyRight, "OD": keyLeft,
+	"OH": keyHome, "OF": keyEnd, "[1~": keyHome, "[7~": keyHome, "[4~": keyEnd, "[8~": keyEnd,
+	"[3~": keyDelete, "[1;5C": keyWordRight, "[1;3C": keyWordRight, "[1;5D": keyWordLeft,
+	"[1;3D": keyWordLeft, "b": keyWordLeft, "f": keyWordRight, "\x7f": keyDeleteWord,
+	"\r": keyNewline, "\n": keyNewline,
+}
+
+// keyReader decodes the bytes read from a terminal into keys
+type keyReader struct {
+	in  io.Reader
+	buf []byte // Bytes read but not decoded yet
+}
+
+// pending reports whether input arrived that is not decoded yet, such as the
+// rest of a paste the terminal does not bracket
+func (k *keyReader) pending() bool {
+	return len(k.buf) > 0
+}
+
+// fill reads more input
+func (k *keyReader) fill() error {
+	chunk := make([]byte, 4096)
+	n, err := k.in.Read(chunk)
+	k.buf = append(k.buf, chunk[:n]...)
+	if n > 0 {
+		return nil
+	}
+	if err == nil {
+		err = io.ErrNoProgress
+	}
+	return err
+}
+
+// Read reads input up to the end of the next line, so that a bufio.Scanner
+// reading through it never holds input beyond the line it returns
+func (k *keyReader) Read(p []byte) (int, error) {
+	if len(k.buf) == 0 {
+		if err := k.fill(); err != nil {
+			return 0, err
+		}
+	}
+	line := k.buf
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i+1]
+	}
+	n := copy(p, line)
+	k.buf = k.buf[n:]
+	return n, nil
+}
+
+// next returns the next key
+func (k *keyReader) next() (key, error) {
+	if len(k.buf) == 0 {
+		if err := k.fill(); err != nil {
+			return key{}, err
+		}
+	}
+	b := k.buf[0]
+	if b == 0x1b {
+		return k.escape()
+	}
+	if kind, ok := controlKeys[b]; ok {
+		k.buf = k.buf[1:]
+		return key{kind: kind}, nil
+	}
+	if b < 0x20 {
+		k.buf = k.buf[1:]
+		return key{kind: keyUnknown}, nil
+	}
+	for !utf8.FullRune(k.buf) {
+		if err := k.fill(); err != nil {
+			return key{}, err
+		}
+	}
+	r, size := utf8.DecodeRune(k.buf)
+	k.buf = k.buf[size:]
+	return key{kind: keyRune, r: r}, nil
+}
+
+// escape decodes an escape sequence. A lone ESC waits for the next byte, as
+// there is no telling it from the start of a sequence without a timeout.
+func (k *keyReader) escape() (key, error) {
+	for len(k.buf) < 2 {
+		if err := k.fill(); err != nil {
+			return key{}, err
+		}
+	}
+	seq := string(k.buf[1:2])
+	end := 2
+	switch k.buf[1] {
+	case '[', 'O':
+		// Parameters, then a final byte in 0x40–0x7e
+		for {
+			for len(k.buf) <= end {
+				if err := k.fill(); err != nil {
+					return key{}, err
+				}
+			}
+			c := k.buf[end]
+			end++
+			if c >= 0x40 && c <= 0x7e {
+				break
+			}
+		}
+		seq = string(k.buf[1:end])
+	}
+	k.buf = k.buf[end:]
+
+	if seq == "["+pasteStart {
+		return k.paste()
+	}
+	if kind, ok := escapeKeys[seq]; ok {
+		return key{kind: kind}, nil
+	}
+	return key{kind: keyUnknown}, nil
+}
+
+// paste reads a bracketed paste up to its end marker
+func (k *keyReader) paste() (key, error) {
+	for {
+		if i := bytes.Index(k.buf, []byte(pasteEnd)); i >= 0 {
+			text := string(k.buf[:i])
+			k.buf = k.buf[i+len(pasteEnd):]
+			return key{kind: keyPaste, text: normalizePaste(text)}, nil
+		}
+		if err := k.fill(); err != nil {
+			return key{}, err
+		}
+	}
+}
+
+// normalizePaste turns the carriage returns terminals send for line breaks
+// into newlines and drops other control characters but tabs
+func normalizePaste(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 && r != '\n' && r != '\t' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, text)
+}
@@ -0,0 +1,575 @@
+// Package lineedit reads the input of the plain chat REPL: a line editor
+// with history, reverse search, tab completion and multiline input for
+// terminals, and plain line reading for pipes.
+//
+// On a terminal, pastes arrive as one input even when they span lines:
+// terminals that support bracketed paste mark them, and for the others a
+// line break followed by input that has already arrived is taken as part
+// of a paste. Ctrl+J or Alt+Enter insert a line break, and a line holding
+// only """ starts or ends a block of lines sent together, which also works
+// when reading from a pipe.
+package lineedit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/term"
+)
+
+// HistoryFile is where the chat history of a project is kept, relative to
+// the project root
+const HistoryFile = ".axon/history"
+
+// BlockDelimiter on a line of its own starts and ends a multiline block
+const BlockDelimiter = `"""`
+
+// maxInput is the longest input line read
+const maxInput = 16 << 20
+
+// continuation prompts the lines after the first of an input
+const continuation = "... "
+
+// ErrInterrupt is returned when Ctrl+C is pressed on an empty input
+var ErrInterrupt = errors.New("interrupted")
+
+// Completer completes the text before the cursor. It returns where the
+// completed word starts, as a byte offset in head, and the candidates to
+// replace the word with.
+type Completer func(head string) (start int, candidates []string)
+
+// Editor reads input lines
+type Editor struct {
+	out       io.Writer
+	terminal  bool       // The line editor is used rather than plain line reading
+	fd        int        // Descriptor put in raw mode while editing; -1 for none
+	width     func() int // Width of the terminal
+	keys      *keyReader // Terminal input
+	scanner   *bufio.Scanner
+	history   *History
+	completer Completer
+}
+
+// New creates an editor reading from in and echoing to out. The line editor
+// is used when both are terminals.
+func New(in, out *os.File) *Editor {
+	e := newEditor(in, out)
+	if term.IsTerminal(int(in.Fd())) && term.IsTerminal(int(out.Fd())) {
+		e.terminal, e.fd = true, int(in.Fd())
+		e.width = func() int {
+			if width, _, err := term.GetSize(int(out.Fd())); err == nil && width > 0 {
+				return width
+			}
+			return 80
+		}
+	}
+	return e
+}
+
+func newEditor(in io.Reader, out io.Writer) *Editor {
+	keys := &keyReader{in: in}
+	scanner := bufio.NewScanner(keys)
+	scanner.Buffer(make([]byte, 64*1024), maxInput)
+	return &Editor{
+		out:     out,
+		fd:      -1,
+		width:   func() int { return 80 },
+		keys:    keys,
+		scanner: scanner,
+		history: &History{},
+	}
+}
+
+// Scanner returns the scanner for reading answers to questions, such as
+// confirmations, from the editor's input. It reads through the editor's key
+// buffer a line at a time, so input typed ahead is left to whichever reads
+// next; reading the input through anything else would lose it.
+func (e *Editor) Scanner() *bufio.Scanner {
+	return e.scanner
+}
+
+// SetHistory sets the history browsed with the arrow keys and Ctrl+R, and
+// added to by ReadLine
+func (e *Editor) SetHistory(h *History) {
+	e.history = h
+}
+
+// SetCompleter sets what Tab completes with
+func (e *Editor) SetCompleter(c Completer) {
+	e.completer = c
+}
+
+// ReadLine reads an input, which may span several lines. It returns io.EOF
+// at the end of the input or on Ctrl+D, and ErrInterrupt on Ctrl+C.
+func (e *Editor) ReadLine(prompt string) (string, error) {
+	input, err := e.readLine(prompt)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(input) == BlockDelimiter {
+		var lines []string
+		for {
+			line, err := e.readLine(continuation)
+			if err != nil {
+				return "", err
+			}
+			if strings.TrimSpace(line) == BlockDelimiter {
+				break
+			}
+			lines = append(lines, line)
+		}
+		input = strings.Join(lines, "\n")
+	}
+	e.history.Add(input)
+	return input, nil
+}
+
+// readLine reads a line, or an edited input on a terminal
+func (e *Editor) readLine(prompt string) (string, error) {
+	if e.terminal {
+		return e.edit(prompt)
+	}
+	fmt.Fprint(e.out, prompt)
+	if !e.scanner.Scan() {
+		if err := e.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return e.scanner.Text(), nil
+}
+
+// state is an input being edited
+type state struct {
+	prompt string
+	buf    []rune
+	pos    int // Cursor position in buf
+	row    int // Screen row of the cursor, relative to the prompt's
+	endRow int // Screen row after the input
+
+	histPos int    // Entry being shown; len(entries) for the new input
+	draft   []rune // The new input while browsing history
+	lastTab bool   // The previous key was Tab
+
+	searching bool
+	query     []rune
+	match     int    // Entry matching the query; -1 for none
+	failed    bool   // No entry matches the query
+	saved     []rune // Input before the search, restored on cancel
+}
+
+// edit reads an input in raw mode
+func (e *Editor) edit(prompt string) (string, error) {
+	if e.fd >= 0 {
+		saved, err := term.MakeRaw(e.fd)
+		if err != nil {
+			return "", err
+		}
+		defer term.Restore(e.fd, saved)
+	}
+	fmt.Fprint(e.out, enablePaste)
+	defer fmt.Fprint(e.out, disablePaste)
+
+	s := &state{prompt: prompt, histPos: len(e.history.Entries())}
+	e.refresh(s)
+	for {
+		k, err := e.keys.next()
+		if err != nil {
+			e.moveToEnd(s)
+			return "", err
+		}
+		if s.searching && e.search(s, k) {
+			continue
+		}
+
+		lastTab := s.lastTab
+		s.lastTab = false
+		switch k.kind {
+		case keyEnter:
+			// Input arriving with the line break is a paste the terminal did
+			// not bracket
+			if e.keys.pending() {
+				s.insert([]rune{'\n'})
+				break
+			}
+			e.moveToEnd(s)
+			return string(s.buf), nil
+		case keyInterrupt:
+			empty := len(s.buf) == 0
+			s.pos = len(s.buf)
+			e.refresh(s)
+			fmt.Fprint(e.out, "^C")
+			e.moveToEnd(s)
+			if empty {
+				return "", ErrInterrupt
+			}
+			s.buf, s.pos, s.histPos = nil, 0, len(e.history.Entries())
+		case keyEOF:
+			if len(s.buf) == 0 {
+				e.moveToEnd(s)
+				return "", io.EOF
+			}
+			s.delete(s.pos, s.pos+1)
+		case keyRune:
+			s.insert([]rune{k.r})
+		case keyPaste:
+			s.insert([]rune(k.text))
+		case keyNewline:
+			s.insert([]rune{'\n'})
+		case keyTab:
+			e.complete(s, lastTab)
+			s.lastTab = true
+		case keyBackspace:
+			if s.pos > 0 {
+				s.delete(s.pos-1, s.pos)
+			}
+		case keyDelete:
+			s.delete(s.pos, s.pos+1)
+		case keyLeft:
+			s.pos = max(0, s.pos-1)
+		case keyRight:
+			s.pos = min(len(s.buf), s.pos+1)
+		case keyHome:
+			s.pos = s.lineStart()
+		case keyEnd:
+			s.pos = s.lineEnd()
+		case keyWordLeft:
+			s.pos = s.wordStart()
+		case keyWordRight:
+			s.pos = s.wordEnd()
+		case keyDeleteWord:
+			s.delete(s.wordStart(), s.pos)
+		case keyKillEnd:
+			s.delete(s.pos, s.lineEnd())
+		case keyKillStart:
+			s.delete(s.lineStart(), s.pos)
+		case keyUp:
+			if !s.moveLine(-1) {
+				e.browse(s, -1)
+			}
+		case keyDown:
+			if !s.moveLine(1) {
+				e.browse(s, 1)
+			}
+		case keySearch:
+			s.searching, s.failed, s.query, s.match, s.saved = true, false, nil, -1, s.buf
+		case keyClear:
+			fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+			s.row = 0
+		}
+		e.refresh(s)
+	}
+}
+
+// search handles a key during a reverse search; it reports whether the key
+// was consumed, or ended the search and is to be handled as usual
+func (e *Editor) search(s *state, k key) bool {
+	entries := e.history.Entries()
+	switch k.kind {
+	case keyRune:
+		s.query = append(s.query, k.r)
+		from := s.match
+		if from < 0 {
+			from = len(entries) - 1
+		}
+		e.find(s, from)
+	case keyBackspace:
+		if len(s.query) > 0 {
+			s.query = s.query[:len(s.query)-1]
+		}
+		e.find(s, len(entries)-1)
+	case keySearch:
+		if s.match > 0 {
+			e.find(s, s.match-1)
+		}
+	case keyCancel, keyInterrupt:
+		s.searching = false
+		s.buf, s.pos = s.saved, len(s.saved)
+	default:
+		s.searching = false
+		if s.match >= 0 {
+			s.histPos = s.match
+		}
+		return false
+	}
+	e.refresh(s)
+	return true
+}
+
+// find shows the latest entry matching the query, starting at an entry and
+// going back
+func (e *Editor) find(s *state, from int) {
+	entries := e.history.Entries()
+	query := string(s.query)
+	for i := min(from, len(entries)-1); i >= 0; i-- {
+		if at := strings.Index(entries[i], query); at >= 0 {
+			s.match, s.failed = i, false
+			s.buf = []rune(entries[i])
+			s.pos = utf8.RuneCountInString(entries[i][:at])
+			return
+		}
+	}
+	s.failed = true
+}
+
+// browse replaces the input with an earlier or later history entry
+func (e *Editor) browse(s *state, step int) {
+	entries := e.history.Entries()
+	pos := s.histPos + step
+	if pos < 0 || pos > len(entries) {
+		return
+	}
+	if s.histPos == len(entries) {
+		s.draft = s.buf
+	}
+	s.histPos = pos
+	if pos == len(entries) {
+		s.buf = s.draft
+	} else {
+		s.buf = []rune(entries[pos])
+	}
+	s.pos = len(s.buf)
+}
+
+// complete completes the word before the cursor. When the candidates share
+// no more of the word, pressing Tab twice lists them.
+func (e *Editor) complete(s *state, again bool) {
+	if e.completer == nil {
+		return
+	}
+	head := string(s.buf[:s.pos])
+	start, candidates := e.completer(head)
+	if len(candidates) == 0 || start < 0 || start > len(head) {
+		fmt.Fprint(e.out, "\a")
+		return
+	}
+	word := head[start:]
+	replacement := commonPrefix(candidates)
+	if len(candidates) == 1 && !strings.HasSuffix(replacement, "/") {
+		replacement += " "
+	}
+	if replacement != word && strings.HasPrefix(replacement, word) {
+		s.delete(utf8.RuneCountInString(head[:start]), s.pos)
+		s.insert([]rune(replacement))
+		return
+	}
+	if !again {
+		fmt.Fprint(e.out, "\a")
+		return
+	}
+
+	e.moveToEnd(s)
+	fmt.Fprint(e.out, strings.ReplaceAll(columns(candidates, e.width()), "\n", "\r\n"))
+}
+
+// refresh redraws the input and places the cursor
+func (e *Editor) refresh(s *state) {
+	width := max(1, e.width())
+	var b strings.Builder
+	if s.row > 0 {
+		fmt.Fprintf(&b, "\x1b[%dA", s.row)
+	}
+	b.WriteString("\r\x1b[J")
+
+	prompt := s.prompt
+	if s.searching {
+		failed := ""
+		if s.failed {
+			failed = "failed "
+		}
+		prompt = fmt.Sprintf("(%sreverse-i-search)`%s': ", failed, string(s.query))
+	}
+	b.WriteString(prompt)
+	row, col := 0, visibleWidth(prompt)
+	row, col = row+col/width, col%width
+
+	cursorRow, cursorCol := -1, 0
+	for i, r := range s.buf {
+		if r == '\n' {
+			if i == s.pos {
+				cursorRow, cursorCol = row, col
+			}
+			b.WriteString("\r\n" + continuation)
+			row, col = row+1, len(continuation)
+			continue
+		}
+		glyph, w := display(r)
+		if col+w > width {
+			b.WriteString("\r\n")
+			row, col = row+1, 0
+		}
+		if i == s.pos {
+			cursorRow, cursorCol = row, col
+		}
+		b.WriteString(glyph)
+		col += w
+	}
+	// A full last line leaves the terminal's cursor at its end until
+	// something is written; move it to the next line to know where it is
+	if col >= width {
+		b.WriteString("\r\n")
+		row, col = row+1, 0
+	}
+	if cursorRow < 0 {
+		cursorRow, cursorCol = row, col
+	}
+	if row > cursorRow {
+		fmt.Fprintf(&b, "\x1b[%dA", row-cursorRow)
+	}
+	b.WriteString("\r")
+	if cursorCol > 0 {
+		fmt.Fprintf(&b, "\x1b[%dC", cursorCol)
+	}
+	s.row, s.endRow = cursorRow, row
+	fmt.Fprint(e.out, b.String())
+}
+
+// moveToEnd moves the cursor to a new line below the input
+func (e *Editor) moveToEnd(s *state) {
+	if s.endRow > s.row {
+		fmt.Fprintf(e.out, "\x1b[%dB", s.endRow-s.row)
+	}
+	fmt.Fprint(e.out, "\r\n")
+	s.row = 0
+}
+
+// insert inserts text at the cursor
+func (s *state) insert(text []rune) {
+	buf := make([]rune, 0, len(s.buf)+len(text))
+	buf = append(buf, s.buf[:s.pos]...)
+	buf = append(buf, text...)
+	s.buf = append(buf, s.buf[s.pos:]...)
+	s.pos += len(text)
+}
+
+// delete removes the text between two positions
+func (s *state) delete(from, to int) {
+	from, to = max(0, from), min(len(s.buf), to)
+	if from >= to {
+		return
+	}
+	buf := make([]rune, 0, len(s.buf)-(to-from))
+	buf = append(buf, s.buf[:from]...)
+	s.buf = append(buf, s.buf[to:]...)
+	s.pos = from
+}
+
+// lineStart is the start of the cursor's line
+func (s *state) lineStart() int {
+	i := s.pos
+	for i > 0 && s.buf[i-1] != '\n' {
+		i--
+	}
+	return i
+}
+
+// lineEnd is the end of the cursor's line
+func (s *state) lineEnd() int {
+	i := s.pos
+	for i < len(s.buf) && s.buf[i] != '\n' {
+		i++
+	}
+	return i
+}
+
+// wordStart is the start of the word before the cursor
+func (s *state) wordStart() int {
+	i := s.pos
+	for i > 0 && unicode.IsSpace(s.buf[i-1]) {
+		i--
+	}
+	for i > 0 && !unicode.IsSpace(s.buf[i-1]) {
+		i--
+	}
+	return i
+}
+
+// wordEnd is the end of the word after the cursor
+func (s *state) wordEnd() int {
+	i := s.pos
+	for i < len(s.buf) && unicode.IsSpace(s.buf[i]) {
+		i++
+	}
+	for i < len(s.buf) && !unicode.IsSpace(s.buf[i]) {
+		i++
+	}
+	return i
+}
+
+// moveLine moves the cursor to the line above or below in a multiline
+// input; it reports whether there was one
+func (s *state) moveLine(step int) bool {
+	start, end := s.lineStart(), s.lineEnd()
+	column := s.pos - start
+	switch {
+	case step < 0 && start > 0:
+		s.pos = start - 1
+		s.pos = min(s.lineStart()+column, start-1)
+	case step > 0 && end < len(s.buf):
+		s.pos = end + 1
+		s.pos = min(s.pos+column, s.lineEnd())
+	default:
+		return false
+	}
+	return true
+}
+
+// display is how a rune is shown, and its width
+func display(r rune) (string, int) {
+	switch {
+	case r == '\t':
+		return "    ", 4
+	case r < 0x20 || r == 0x7f:
+		return "^" + string(r^0x40), 2
+	}
+	return string(r), max(1, runewidth.RuneWidth(r))
+}
+
+// ansi matches the escape sequences coloring a prompt
+var ansi = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+// visibleWidth is the width of a prompt on screen
+func visibleWidth(s string) int {
+	return runewidth.StringWidth(ansi.ReplaceAllString(s, ""))
+}
+
+// commonPrefix is the longest prefix of all candidates
+func commonPrefix(candidates []string) string {
+	prefix := candidates[0]
+	for _, c := range candidates[1:] {
+		for !strings.HasPrefix(c, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	return prefix
+}
+
+// columns lays out candidates in columns fitting a width
+func columns(candidates []string, width int) string {
+	widest := 0
+	for _, c := range candidates {
+		widest = max(widest, runewidth.StringWidth(c))
+	}
+	perRow := max(1, width/(widest+2))
+	rows := (len(candidates) + perRow - 1) / perRow
+	var b strings.Builder
+	for row := 0; row < rows; row++ {
+		var line strings.Builder
+		for col := 0; col < perRow; col++ {
+			if i := col*rows + row; i < len(candidates) {
+				line.WriteString(runewidth.FillRight(candidates[i], widest+2))
+			}
+		}
+		b.WriteString(strings.TrimRight(line.String(), " ") + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,258 @@
+package lineedit
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// newTestEditor creates an editor that edits like on a terminal, reading
+// input a byte at a time as if it were typed
+func newTestEditor(input string, history ...string) (*Editor, *bytes.Buffer) {
+	var out bytes.Buffer
+	e := newEditor(iotest.OneByteReader(strings.NewReader(input)), &out)
+	e.terminal = true
+	for _, entry := range history {
+		e.history.Add(entry)
+	}
+	return e, &out
+}
+
+func TestEditing(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"plain", "hello\r", "hello"},
+		{"backspace", "helo\x7f\x7fllo\r", "hello"},
+		{"cursor", "hllo\x1b[D\x1b[D\x1b[De\r", "hello"},
+		{"home and end", "ello\x01h\x05!\r", "hello!"},
+		{"delete word", "hello world\x17there\r", "hello there"},
+		{"kill to end", "hello world\x1b[1;5D\x0b\r", "hello "},
+		{"kill to start", "hello world\x1bb\x15\r", "world"},
+		{"delete", "hello!\x1b[D\x1b[3~\r", "hello"},
+		{"newline", "one\x1b\rtwo\nthree\r", "one\ntwo\nthree"},
+		{"line up", "one\ntwo\x1b[Ax\r", "onex\ntwo"},
+		{"unicode", "héllo wörld\x17\r", "héllo "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, _ := newTestEditor(tt.input)
+			got, err := e.ReadLine("> ")
+			if err != nil || got != tt.want {
+				t.Errorf("ReadLine = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaste(t *testing.T) {
+	// A bracketed paste is one input, whatever it holds
+	e, out := newTestEditor("fix this: \x1b[200~panic: boom\r\n\tmain.go:3\r\n\x1b[201~\r")
+	got, err := e.ReadLine("> ")
+	if want := "fix this: panic: boom\n\tmain.go:3\n"; err != nil || got != want {
+		t.Errorf("ReadLine = %q, %v; want %q", got, err, want)
+	}
+	if !strings.Contains(out.String(), enablePaste) || !strings.Contains(out.String(), disablePaste) {
+		t.Error("bracketed paste not enabled")
+	}
+
+	// Without brackets, lines arriving together are a paste too
+	var output bytes.Buffer
+	e = newEditor(strings.NewReader("line one\rline two\r"), &output)
+	e.terminal = true
+	if got, err := e.ReadLine("> "); err != nil || got != "line one\nline two" {
+		t.Errorf("unbracketed paste = %q, %v", got, err)
+	}
+}
+
+func TestBlock(t *testing.T) {
+	e, _ := newTestEditor("\"\"\"\rfirst\rsecond\r\"\"\"\r")
+	got, err := e.ReadLine("> ")
+	if err != nil || got != "first\nsecond" {
+		t.Errorf("ReadLine = %q, %v", got, err)
+	}
+
+	// Blocks work when reading from a pipe too, as do long lines
+	long := strings.Repeat("x", 100*1024)
+	e = newEditor(strings.NewReader("\"\"\"\na\nb\n\"\"\"\n"+long+"\n"), io.Discard)
+	if got, err := e.ReadLine("> "); err != nil || got != "a\nb" {
+		t.Errorf("piped block = %q, %v", got, err)
+	}
+	if got, err := e.ReadLine("> "); err != nil || got != long {
+		t.Errorf("long line: %d bytes, %v", len(got), err)
+	}
+	if _, err := e.ReadLine("> "); err != io.EOF {
+		t.Errorf("end of input: %v", err)
+	}
+}
+
+// chunks is a reader returning each of its strings from a separate Read, as
+// a terminal delivers what is typed between reads
+type chunks []string
+
+func (c *chunks) Read(p []byte) (int, error) {
+	if len(*c) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, (*c)[0])
+	(*c)[0] = (*c)[0][n:]
+	if (*c)[0] == "" {
+		*c = (*c)[1:]
+	}
+	return n, nil
+}
+
+func TestScannerSharesInput(t *testing.T) {
+	// An answer and the next input typed ahead together
+	input := chunks{"hello\r", "yes\nnext\r"}
+	e := newEditor(&input, io.Discard)
+	e.terminal = true
+	if got, err := e.ReadLine("> "); err != nil || got != "hello" {
+		t.Fatalf("ReadLine = %q, %v", got, err)
+	}
+	if !e.Scanner().Scan() || e.Scanner().Text() != "yes" {
+		t.Fatalf("answer = %q, %v", e.Scanner().Text(), e.Scanner().Err())
+	}
+	if got, err := e.ReadLine("> "); err != nil || got != "next" {
+		t.Errorf("typed ahead = %q, %v", got, err)
+	}
+
+}
+
+func TestHistory(t *testing.T) {
+	e, _ := newTestEditor("\x1b[A\x1b[A\r\x1b[A\x1b[B\x1b[Bdraft\x1b[A\x1b[B\r", "first", "second")
+	if got, _ := e.ReadLine("> "); got != "first" {
+		t.Errorf("up twice = %q", got)
+	}
+	if got, _ := e.ReadLine("> "); got != "draft" {
+		t.Errorf("back to the draft = %q", got)
+	}
+	if entries := e.history.Entries(); strings.Join(entries, ",") != "first,second,first,draft" {
+		t.Errorf("history = %q", entries)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	history := []string{"explain main.go", "run the tests", "explain parser.go"}
+
+	e, _ := newTestEditor("\x12expl\x12\r", history...)
+	if got, _ := e.ReadLine("> "); got != "explain main.go" {
+		t.Errorf("second match = %q", got)
+	}
+
+	// Other keys accept the match for editing
+	e, _ = newTestEditor("\x12tests\x05 again\r", history...)
+	if got, _ := e.ReadLine("> "); got != "run the tests again" {
+		t.Errorf("edited match = %q", got)
+	}
+
+	// Ctrl+G restores the input
+	e, out := newTestEditor("draft\x12zzz\x07\r", history...)
+	if got, _ := e.ReadLine("> "); got != "draft" {
+		t.Errorf("cancelled search = %q", got)
+	}
+	if !strings.Contains(out.String(), "(failed reverse-i-search)`zzz'") {
+		t.Errorf("no failed search shown: %q", out.String())
+	}
+}
+
+func TestComplete(t *testing.T) {
+	completer := func(head string) (int, []string) {
+		start := strings.LastIndex(head, " ") + 1
+		var matches []string
+		for _, c := range []string{"/help", "/history", "pkg/", "pkg/chat/", "main.go"} {
+			if strings.HasPrefix(c, head[start:]) {
+				matches = append(matches, c)
+			}
+		}
+		return start, matches
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"explain ma\t\r", "explain main.go "},
+		{"/he\t\r", "/help "},
+		{"/h\t\r", "/h"},
+		{"look in pkg/c\t\r", "look in pkg/chat/"},
+	}
+	for _, tt := range tests {
+		e, _ := newTestEditor(tt.input)
+		e.SetCompleter(completer)
+		if got, _ := e.ReadLine("> "); got != tt.want {
+			t.Errorf("%q: ReadLine = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	// A second Tab lists the candidates
+	e, out := newTestEditor("/h\t\t\r")
+	e.SetCompleter(completer)
+	e.ReadLine("> ")
+	if !strings.Contains(out.String(), "/help     /history\r\n") {
+		t.Errorf("candidates not listed: %q", out.String())
+	}
+}
+
+func TestInterrupt(t *testing.T) {
+	e, _ := newTestEditor("half typed\x03done\r\x03")
+	if got, err := e.ReadLine("> "); err != nil || got != "done" {
+		t.Errorf("Ctrl+C clears the input: %q, %v", got, err)
+	}
+	if _, err := e.ReadLine("> "); !errors.Is(err, ErrInterrupt) {
+		t.Errorf("Ctrl+C on an empty input: %v", err)
+	}
+
+	e, _ = newTestEditor("\x04")
+	if _, err := e.ReadLine("> "); err != io.EOF {
+		t.Errorf("Ctrl+D on an empty input: %v", err)
+	}
+}
+
+func TestRefreshWraps(t *testing.T) {
+	e, out := newTestEditor("")
+	e.width = func() int { return 10 }
+	s := &state{prompt: "\x1b[1mYou:\x1b[0m ", buf: []rune("0123456789abc"), pos: 2}
+	e.refresh(s)
+	// The prompt takes 5 columns, so the input wraps onto a second row; the
+	// cursor is on the first
+	if s.endRow != 1 || s.row != 0 {
+		t.Errorf("rows: cursor %d, end %d", s.row, s.endRow)
+	}
+	if !strings.HasSuffix(out.String(), "\x1b[1A\r\x1b[7C") {
+		t.Errorf("cursor not placed: %q", out.String())
+	}
+}
+
+func TestHistoryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".axon", "history")
+	h := OpenHistory(path)
+	h.Add("first")
+	h.Add("first")
+	h.Add("  ")
+	h.Add("two\nlines with a \\n")
+
+	entries := OpenHistory(path).Entries()
+	if len(entries) != 2 || entries[1] != "two\nlines with a \\n" {
+		t.Errorf("entries = %q", entries)
+	}
+
+	var lines []string
+	for i := 0; i < MaxHistory+10; i++ {
+		lines = append(lines, "entry")
+	}
+	os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600)
+	if n := len(OpenHistory(path).Entries()); n != MaxHistory {
+		t.Errorf("kept %d entries", n)
+	}
+	if body, _ := os.ReadFile(path); strings.Count(string(body), "\n") != MaxHistory {
+		t.Error("file not trimmed")
+	}
+}
@@ -0,0 +1,86 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	"github.com/axon/pkg/logger"
+)
+
+// EmbeddingRequest represents a request to the OpenAI-compatible embeddings API
+type EmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// EmbeddingResponse represents the response from the embeddings API
+type EmbeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+	Error *struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+	} `json:"error,omitempty"`
+}
+
+// Embed returns one embedding vector per input text.
+// It calls the /v1/embeddings endpoint, which llama-server exposes when started with --embedding.
+func (c *Client) Embed(ctx context.Context, inputs []string) ([][]float32, error) {
+	if len(inputs) == 0 {
+		return nil, nil
+	}
+
+	jsonData, err := json.Marshal(EmbeddingRequest{Model: c.Model, Input: inputs})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/v1/embeddings", c.BaseURL)
+	logger.Logf(">>> REQUEST: POST %s (%d inputs)\n", url, len(inputs))
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		logger.LogResponse(resp.StatusCode, string(body))
+		return nil, fmt.Errorf("embeddings API returned status %d: %s (is llama-server running with --embedding?)", resp.StatusCode, string(body))
+	}
+
+	var embResp EmbeddingResponse
+	if err := json.Unmarshal(body, &embResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if embResp.Error != nil {
+		return nil, fmt.Errorf("embeddings API error: %s", embResp.Error.Message)
+	}
+	if len(embResp.Data) != len(inputs) {
+		return nil, fmt.Errorf("embeddings API returned %d vectors for %d inputs", len(embResp.Data), len(inputs))
+	}
+
+	sort.Slice(embResp.Data, func(i, j int) bool {
+		return embResp.Data[i].Index < embResp.Data[j].Index
+	})
+
+	vectors := make([][]float32, len(embResp.Data))
+	for i, d := range embResp.Data {
+		vectors[i] = d.Embedding
+	}
+	return vectors, nil
+}
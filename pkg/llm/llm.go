@@ -2,8 +2,10 @@ package llm
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -46,6 +48,43 @@ type ChatCompletionRequest struct {
 	ToolChoice  string    `json:"tool_choice,omitempty"` // "auto", "none", or specific tool
 	Stream      bool      `json:"stream,omitempty"`      // Enable streaming
 	MaxTokens   *int      `json:"max_tokens,omitempty"`
+	// Asks streaming responses to end with the token usage
+	StreamOptions *StreamOptions `json:"stream_options,omitempty"`
+	// Constrains the response to JSON matching a schema
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+}
+
+// StreamOptions configures a streaming response
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// Usage counts the tokens of one or more requests
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// Add adds the tokens of another request
+func (u *Usage) Add(other Usage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.TotalTokens += other.TotalTokens
+}
+
+// ResponseFormat asks the server for JSON output; llama-server turns the
+// schema into a grammar, so the model cannot produce anything else
+type ResponseFormat struct {
+	Type       string      `json:"type"` // "json_schema"
+	JSONSchema *JSONSchema `json:"json_schema,omitempty"`
+}
+
+// JSONSchema names the schema of a structured response
+type JSONSchema struct {
+	Name   string                 `json:"name"`
+	Strict bool                   `json:"strict"`
+	Schema map[string]interface{} `json:"schema"`
 }
 
 // ChatCompletionResponse represents the response from the LLM API
@@ -66,6 +105,15 @@ func GetSystemPrompt() string {
 		"You specialize in PHP (Laravel), Go, JavaScript/TypeScript, shell, Docker, and Linux tooling.\n" +
 		"You have access to tools that let you read files, list directories, search code, and modify files.\n" +
 		"When you need to examine code, use the available tools instead of asking the user.\n" +
+		"To find code by concept (e.g. \"rate limiter middleware\"), use 'search_code'; use 'grep' only for exact patterns.\n" +
+		"Before changing a shared file, use 'impact_of_change' to see which files depend on it.\n" +
+		"In Laravel projects, use 'list_routes', 'find_route_handler' and 'describe_model' to understand routes, models and the database schema; run other artisan commands with 'execute' (php artisan ...).\n" +
+		"Use 'describe_schema' to look up tables and columns before writing SQL or migrations; use 'sql_query' to inspect data in local SQLite databases (read-only).\n" +
+		"To run tests, use 'run_tests' rather than 'execute'; after a fix, re-run only the failing package, file or test with 'target' and 'name'.\n" +
+		"'execute' commands are killed after a timeout: pass a larger 'timeout' for slow installs or builds. Start servers and watchers with 'start_process' instead, read their logs with 'process_output' and stop them with 'stop_process'.\n" +
+		"To debug an endpoint end to end, start the server with 'start_process', call it with 'http_request' and read the server log with 'process_output'.\n" +
+		"To find out why code is the way it is, use 'git_blame' on the lines and 'git_show' on the commit; use 'git_log' with 'path' or 'symbol' for a file's or function's history, and 'git_diff' to see uncommitted or branch changes.\n" +
+		"When a write result includes 'diagnostics', the file does not compile or has warnings: fix them before continuing.\n" +
 		"IMPORTANT: All write operations (write_file, create_file, update_file, string_replace, create_directory) require interactive user confirmation. The user will be prompted before any file or directory modification occurs.\n" +
 		"IMPORTANT: There is NO 'cd' tool. To list directory contents, use 'list_directory' with the 'path' parameter. Example: list_directory({\"path\": \"test\"}) to list contents of the 'test' directory. Use empty path or omit it to list the project root.\n" +
 		"You always respond with high-quality, concise code examples and short, focused explanations.\n" +
@@ -103,3 +151,48 @@ func (c *Client) Chat(ctx context.Context, messages []Message) (string, error) {
 
 	return content, nil
 }
+
+// ChatJSON sends a chat completion request whose response is constrained to
+// JSON matching schema, and decodes it into v
+func (c *Client) ChatJSON(ctx context.Context, messages []Message, name string, schema map[string]interface{}, v interface{}) error {
+	reqBody := ChatCompletionRequest{
+		Model:       c.Model,
+		Temperature: c.Temperature,
+		Messages:    messages,
+		ResponseFormat: &ResponseFormat{
+			Type:       "json_schema",
+			JSONSchema: &JSONSchema{Name: name, Strict: true, Schema: schema},
+		},
+	}
+	if c.MaxTokens > 0 {
+		reqBody.MaxTokens = &c.MaxTokens
+	}
+
+	completionResp, err := c.chatCompletion(ctx, reqBody)
+	if err != nil {
+		return err
+	}
+	if len(completionResp.Choices) == 0 {
+		return fmt.Errorf("no choices in LLM response")
+	}
+
+	content := extractJSON(completionResp.Choices[0].Message.Content)
+	if content == "" {
+		return fmt.Errorf("empty response from LLM")
+	}
+	if err := json.Unmarshal([]byte(content), v); err != nil {
+		return fmt.Errorf("invalid JSON in LLM response: %w", err)
+	}
+	return nil
+}
+
+// extractJSON returns the JSON object in a response, dropping code fences or
+// prose from servers that ignore the response format
+func extractJSON(content string) string {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return strings.TrimSpace(content)
+	}
+	return content[start : end+1]
+}
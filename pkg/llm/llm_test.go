@@ -104,3 +104,63 @@ func TestGetSystemPrompt(t *testing.T) {
 		t.Errorf("Expected system prompt to be substantial, got length %d", len(prompt))
 	}
 }
+
+func TestClient_Embed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" {
+			t.Errorf("Expected /v1/embeddings, got %s", r.URL.Path)
+		}
+
+		var req EmbeddingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+
+		// Return vectors out of order to verify sorting by index
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-model", 0)
+	vectors, err := client.Embed(context.Background(), []string{"first", "second"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if len(vectors) != 2 || vectors[0][0] != 1 || vectors[1][1] != 1 {
+		t.Errorf("Unexpected vectors: %v", vectors)
+	}
+}
+
+func TestClient_ChatJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		if req.ResponseFormat == nil || req.ResponseFormat.Type != "json_schema" || req.ResponseFormat.JSONSchema.Schema["type"] != "object" {
+			t.Errorf("Expected a json_schema response format, got %+v", req.ResponseFormat)
+		}
+
+		// Servers that ignore the format may wrap the JSON in a code fence
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"` + "```json\\n{\\\"subject\\\": \\\"add tests\\\"}\\n```" + `"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-model", 0)
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"subject": map[string]interface{}{"type": "string"}},
+		"required":   []string{"subject"},
+	}
+	var out struct {
+		Subject string `json:"subject"`
+	}
+	if err := client.ChatJSON(context.Background(), []Message{{Role: "user", Content: "Hi"}}, "message", schema, &out); err != nil {
+		t.Fatalf("ChatJSON failed: %v", err)
+	}
+	if out.Subject != "add tests" {
+		t.Errorf("Expected subject 'add tests', got %q", out.Subject)
+	}
+}
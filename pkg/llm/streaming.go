@@ -17,30 +17,34 @@ import (
 type ChatStreamCallback func(chunk string) error
 
 // ChatWithToolsStream sends a chat completion request with tools support and streaming
-// It handles tool calls automatically and streams the final response
-func (c *Client) ChatWithToolsStream(ctx context.Context, messages []Message, tools []Tool, executeTool func(name string, args map[string]interface{}) (string, error), callback ChatStreamCallback) (string, error) {
+// It handles tool calls automatically and streams the final response, and
+// returns the tokens used by all requests when the server reports them
+func (c *Client) ChatWithToolsStream(ctx context.Context, messages []Message, tools []Tool, executeTool func(name string, args map[string]interface{}) (string, error), callback ChatStreamCallback) (string, Usage, error) {
 	maxIterations := 10 // Prevent infinite loops
 	iteration := 0
+	var usage Usage
 
 	for iteration < maxIterations {
 		// Create request with tools and streaming
 		reqBody := ChatCompletionRequest{
-			Model:       c.Model,
-			Temperature: c.Temperature,
-			Messages:    messages,
-			Tools:       tools,
-			ToolChoice:  "auto",
-			Stream:      true, // Enable streaming
+			Model:         c.Model,
+			Temperature:   c.Temperature,
+			Messages:      messages,
+			Tools:         tools,
+			ToolChoice:    "auto",
+			Stream:        true, // Enable streaming
+			StreamOptions: &StreamOptions{IncludeUsage: true},
 		}
 		if c.MaxTokens > 0 {
 			reqBody.MaxTokens = &c.MaxTokens
 		}
 
 		// Make streaming API call
-		fullResponse, toolCalls, err := c.chatCompletionStream(ctx, reqBody, callback)
+		fullResponse, toolCalls, requestUsage, err := c.chatCompletionStream(ctx, reqBody, callback)
 		if err != nil {
-			return "", err
+			return "", usage, err
 		}
+		usage.Add(requestUsage)
 
 		// Add assistant message to history
 		assistantMsg := Message{
@@ -64,7 +68,7 @@ func (c *Client) ChatWithToolsStream(ctx context.Context, messages []Message, to
 				var args map[string]interface{}
 				if err := json.Unmarshal([]byte(toolCall.Function.Arguments), &args); err != nil {
 					logger.Logf("   ❌ PARSE ERROR: %v\n", err)
-					return "", fmt.Errorf("failed to parse tool arguments: %w (raw: %q)", err, toolCall.Function.Arguments)
+					return "", usage, fmt.Errorf("failed to parse tool arguments: %w (raw: %q)", err, toolCall.Function.Arguments)
 				}
 
 				logger.Logf("   Parsed Arguments: %+v\n", args)
@@ -94,17 +98,17 @@ func (c *Client) ChatWithToolsStream(ctx context.Context, messages []Message, to
 
 		// Model returned a regular response (not a tool call)
 		messages = append(messages, assistantMsg)
-		return fullResponse, nil
+		return fullResponse, usage, nil
 	}
 
-	return "", fmt.Errorf("maximum iterations reached - possible infinite tool call loop")
+	return "", usage, fmt.Errorf("maximum iterations reached - possible infinite tool call loop")
 }
 
 // chatCompletionStream makes a streaming request to the LLM API
-func (c *Client) chatCompletionStream(ctx context.Context, reqBody ChatCompletionRequest, callback ChatStreamCallback) (string, []ToolCall, error) {
+func (c *Client) chatCompletionStream(ctx context.Context, reqBody ChatCompletionRequest, callback ChatStreamCallback) (string, []ToolCall, Usage, error) {
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to marshal request: %w", err)
+		return "", nil, Usage{}, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	url := fmt.Sprintf("%s/v1/chat/completions", c.BaseURL)
@@ -112,7 +116,7 @@ func (c *Client) chatCompletionStream(ctx context.Context, reqBody ChatCompletio
 
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to create request: %w", err)
+		return "", nil, Usage{}, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -120,20 +124,21 @@ func (c *Client) chatCompletionStream(ctx context.Context, reqBody ChatCompletio
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to send request: %w", err)
+		return "", nil, Usage{}, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		logger.LogResponse(resp.StatusCode, string(body))
-		return "", nil, fmt.Errorf("LLM API returned status %d: %s", resp.StatusCode, string(body))
+		return "", nil, Usage{}, fmt.Errorf("LLM API returned status %d: %s", resp.StatusCode, string(body))
 	}
 
 	// Parse Server-Sent Events (SSE) stream
 	var fullResponse strings.Builder
 	var toolCalls []ToolCall
 	var finishReason string
+	var usage Usage
 	scanner := bufio.NewScanner(resp.Body)
 
 	for scanner.Scan() {
@@ -161,6 +166,7 @@ func (c *Client) chatCompletionStream(ctx context.Context, reqBody ChatCompletio
 				} `json:"delta"`
 				FinishReason string `json:"finish_reason"`
 			} `json:"choices"`
+			Usage *Usage `json:"usage"`
 		}
 
 		if err := json.Unmarshal([]byte(data), &streamResp); err != nil {
@@ -169,6 +175,10 @@ func (c *Client) chatCompletionStream(ctx context.Context, reqBody ChatCompletio
 			continue
 		}
 
+		// The usage comes with the last chunk, which may have no choices
+		if streamResp.Usage != nil {
+			usage = *streamResp.Usage
+		}
 		if len(streamResp.Choices) == 0 {
 			continue
 		}
@@ -184,7 +194,7 @@ func (c *Client) chatCompletionStream(ctx context.Context, reqBody ChatCompletio
 			// Call callback for streaming display
 			if callback != nil {
 				if err := callback(choice.Delta.Content); err != nil {
-					return "", nil, err
+					return "", nil, Usage{}, err
 				}
 			}
 		}
@@ -273,7 +283,7 @@ func (c *Client) chatCompletionStream(ctx context.Context, reqBody ChatCompletio
 	}
 
 	if err := scanner.Err(); err != nil {
-		return "", nil, fmt.Errorf("error reading stream: %w", err)
+		return "", nil, Usage{}, fmt.Errorf("error reading stream: %w", err)
 	}
 
 	// Filter out incomplete tool calls before returning
@@ -291,7 +301,7 @@ func (c *Client) chatCompletionStream(ctx context.Context, reqBody ChatCompletio
 		if len(responseText) > 0 {
 			logger.Logf("📝 FULL RESPONSE (before tool calls):\n%s\n", responseText)
 		}
-		return responseText, completeToolCalls, nil
+		return responseText, completeToolCalls, usage, nil
 	}
 
 	// Log final response summary for regular responses
@@ -300,5 +310,5 @@ func (c *Client) chatCompletionStream(ctx context.Context, reqBody ChatCompletio
 		logger.Logf("✅ STREAMING COMPLETE: finish_reason=%s, response_length=%d\n", finishReason, len(responseText))
 		logger.Logf("📝 FULL RESPONSE:\n%s\n", responseText)
 	}
-	return responseText, nil, nil
+	return responseText, nil, usage, nil
 }
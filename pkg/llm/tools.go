@@ -388,6 +388,52 @@ func GetAvailableTools() []Tool {
 				},
 			},
 		},
+		{
+			Type: "function",
+			Function: ToolFunction{
+				Name:        "search_code",
+				Description: "Full-text code search ranked by relevance (BM25). Accepts natural-language or identifier queries such as 'rate limiter middleware' and returns the best matching files with matching lines. Identifiers are split on camelCase and snake_case, so 'user repository' also finds UserRepository.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"query": map[string]interface{}{
+							"type":        "string",
+							"description": "Search query (words or identifiers)",
+						},
+						"limit": map[string]interface{}{
+							"type":        "integer",
+							"description": "Maximum number of files to return (default 10)",
+						},
+						"path": map[string]interface{}{
+							"type":        "string",
+							"description": "Only search files under this path (relative to project root, empty string for project root)",
+						},
+					},
+					"required": []string{"query"},
+				},
+			},
+		},
+		{
+			Type: "function",
+			Function: ToolFunction{
+				Name:        "semantic_search",
+				Description: "Find code by meaning using embeddings, e.g. 'where do we validate coupons?'. Works without knowing identifier names. Returns the most similar functions/classes with file and line range.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"query": map[string]interface{}{
+							"type":        "string",
+							"description": "Natural-language description of the code to find",
+						},
+						"limit": map[string]interface{}{
+							"type":        "integer",
+							"description": "Maximum number of results (default 8)",
+						},
+					},
+					"required": []string{"query"},
+				},
+			},
+		},
 		{
 			Type: "function",
 			Function: ToolFunction{
@@ -432,8 +478,266 @@ func GetAvailableTools() []Tool {
 		{
 			Type: "function",
 			Function: ToolFunction{
-				Name:        "git_status",
-				Description: "Get git repository status. Returns modified, added, deleted, and untracked files. Works only if project is a git repository.",
+				Name:        "get_imports",
+				Description: "List the project files a file imports (Go packages via go.mod, PHP classes via composer PSR-4, JS/TS modules incl. tsconfig paths, Python modules), plus its external package imports.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"path": map[string]interface{}{
+							"type":        "string",
+							"description": "Path to the file relative to project root",
+						},
+					},
+					"required": []string{"path"},
+				},
+			},
+		},
+		{
+			Type: "function",
+			Function: ToolFunction{
+				Name:        "get_dependents",
+				Description: "List the project files that import a file directly.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"path": map[string]interface{}{
+							"type":        "string",
+							"description": "Path to the file relative to project root",
+						},
+					},
+					"required": []string{"path"},
+				},
+			},
+		},
+		{
+			Type: "function",
+			Function: ToolFunction{
+				Name:        "impact_of_change",
+				Description: "List every file that directly or transitively depends on a file, with its distance. Use before changing a widely used file to see what may break.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"path": map[string]interface{}{
+							"type":        "string",
+							"description": "Path to the file relative to project root",
+						},
+						"max_depth": map[string]interface{}{
+							"type":        "integer",
+							"description": "Maximum number of import hops to follow (default: unlimited)",
+						},
+					},
+					"required": []string{"path"},
+				},
+			},
+		},
+		{
+			Type: "function",
+			Function: ToolFunction{
+				Name:        "list_routes",
+				Description: "List the routes of a Laravel project (method, URI, name, controller@action, middleware), parsed from routes/*.php.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"filter": map[string]interface{}{
+							"type":        "string",
+							"description": "Only list routes whose URI, name, action or middleware contains this text (case-insensitive)",
+						},
+						"method": map[string]interface{}{
+							"type":        "string",
+							"description": "Only list routes answering this HTTP method, e.g. 'POST'",
+						},
+					},
+				},
+			},
+		},
+		{
+			Type: "function",
+			Function: ToolFunction{
+				Name:        "find_route_handler",
+				Description: "Find the controller method that handles a URL or named route in a Laravel project. Concrete URLs such as '/users/42/edit' are matched against route parameters.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"uri": map[string]interface{}{
+							"type":        "string",
+							"description": "Request path or full URL, e.g. '/api/posts/5'",
+						},
+						"name": map[string]interface{}{
+							"type":        "string",
+							"description": "Route name, e.g. 'posts.show' (alternative to uri)",
+						},
+						"method": map[string]interface{}{
+							"type":        "string",
+							"description": "HTTP method to match with uri (default: any)",
+						},
+					},
+				},
+			},
+		},
+		{
+			Type: "function",
+			Function: ToolFunction{
+				Name:        "describe_model",
+				Description: "Describe a Laravel Eloquent model: table, fillable/hidden attributes, casts, relations, and the table's columns, indexes and foreign keys reconstructed from the migrations.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"name": map[string]interface{}{
+							"type":        "string",
+							"description": "Model class name (e.g. 'User'), fully qualified class, or table name",
+						},
+					},
+					"required": []string{"name"},
+				},
+			},
+		},
+		{
+			Type: "function",
+			Function: ToolFunction{
+				Name:        "describe_schema",
+				Description: "Describe the database schema reconstructed from SQL migrations, goose Go migrations and local SQLite databases. Without 'table', lists every table with its columns; with 'table', returns its columns, types, primary key, indexes and foreign keys.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"table": map[string]interface{}{
+							"type":        "string",
+							"description": "Table name to describe in full (optional)",
+						},
+						"database": map[string]interface{}{
+							"type":        "string",
+							"description": "Schema source: 'migrations' or a SQLite file path (optional, defaults to all)",
+						},
+					},
+					"required": []string{},
+				},
+			},
+		},
+		{
+			Type: "function",
+			Function: ToolFunction{
+				Name:        "sql_query",
+				Description: "Run a read-only query (SELECT, WITH, VALUES, EXPLAIN or PRAGMA) against a SQLite database configured in .axon.yml. Requires user confirmation. Results are capped at the configured row limit.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"query": map[string]interface{}{
+							"type":        "string",
+							"description": "A single read-only SQL statement",
+						},
+						"database": map[string]interface{}{
+							"type":        "string",
+							"description": "SQLite file path (optional when only one is configured)",
+						},
+						"limit": map[string]interface{}{
+							"type":        "integer",
+							"description": "Maximum number of rows to return (default and maximum: database.max_rows, 50)",
+						},
+					},
+					"required": []string{"query"},
+				},
+			},
+		},
+		{
+			Type: "function",
+			Function: ToolFunction{
+				Name:        "run_tests",
+				Description: "Run the project's tests with the detected runner (go test, phpunit, pest, jest, vitest or pytest) and return pass/fail counts and each failure with its message and file:line. Requires user confirmation. Prefer this over 'execute' for running tests.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"target": map[string]interface{}{
+							"type":        "string",
+							"description": "Package directory or test file to run (e.g. 'pkg/chat', 'tests/Feature/UserTest.php'). Omit to run all tests.",
+						},
+						"name": map[string]interface{}{
+							"type":        "string",
+							"description": "Test name or pattern to run (go test -run, phpunit --filter, jest -t, pytest -k)",
+						},
+						"runner": map[string]interface{}{
+							"type":        "string",
+							"description": "Runner to use when the project has several: go, phpunit, pest, jest, vitest or pytest (optional)",
+						},
+					},
+					"required": []string{},
+				},
+			},
+		},
+		{
+			Type: "function",
+			Function: ToolFunction{
+				Name:        "start_process",
+				Description: "Start a long-running command in the background, such as a dev server ('php artisan serve', 'go run ./cmd/api', 'npm run dev'), and return its first output. Use 'execute' for commands that finish. The process runs until stop_process or the end of the session. Requires user confirmation.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"command": map[string]interface{}{
+							"type":        "string",
+							"description": "Shell command to run in the project root",
+						},
+						"name": map[string]interface{}{
+							"type":        "string",
+							"description": "Short name to refer to the process (default: the command's first word)",
+						},
+						"ready": map[string]interface{}{
+							"type":        "string",
+							"description": "Text to wait for in the output before returning, e.g. 'Server running' or 'listening on'",
+						},
+						"timeout": map[string]interface{}{
+							"type":        "integer",
+							"description": "Seconds to wait for the 'ready' text (default 30)",
+						},
+					},
+					"required": []string{"command"},
+				},
+			},
+		},
+		{
+			Type: "function",
+			Function: ToolFunction{
+				Name:        "process_output",
+				Description: "Read the output of a background process. Without 'since' it returns the last lines; pass the returned next_offset as 'since' to get only newer output, e.g. the server log of a request you just made.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"id": map[string]interface{}{
+							"type":        "string",
+							"description": "Process ID or name from start_process",
+						},
+						"since": map[string]interface{}{
+							"type":        "integer",
+							"description": "Output offset to read from (next_offset of the previous call)",
+						},
+						"lines": map[string]interface{}{
+							"type":        "integer",
+							"description": "Number of last lines to return when 'since' is omitted (default 50)",
+						},
+					},
+					"required": []string{"id"},
+				},
+			},
+		},
+		{
+			Type: "function",
+			Function: ToolFunction{
+				Name:        "stop_process",
+				Description: "Stop a background process and every process it started, and return its last output.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"id": map[string]interface{}{
+							"type":        "string",
+							"description": "Process ID or name from start_process",
+						},
+					},
+					"required": []string{"id"},
+				},
+			},
+		},
+		{
+			Type: "function",
+			Function: ToolFunction{
+				Name:        "list_processes",
+				Description: "List the background processes started in this session with their status, uptime and exit code.",
 				Parameters: map[string]interface{}{
 					"type":       "object",
 					"properties": map[string]interface{}{},
@@ -441,17 +745,78 @@ func GetAvailableTools() []Tool {
 				},
 			},
 		},
+		{
+			Type: "function",
+			Function: ToolFunction{
+				Name:        "http_request",
+				Description: "Send an HTTP request to a service running locally (localhost by default, see http.allowed_hosts), e.g. a server started with start_process, and return the status, headers and body (JSON pretty-printed, long bodies truncated). POST, PUT, PATCH and DELETE require user confirmation.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"url": map[string]interface{}{
+							"type":        "string",
+							"description": "URL such as 'http://localhost:8000/api/users' ('http://' may be omitted)",
+						},
+						"method": map[string]interface{}{
+							"type":        "string",
+							"description": "HTTP method (default GET)",
+						},
+						"headers": map[string]interface{}{
+							"type":        "object",
+							"description": "Request headers, e.g. {\"Authorization\": \"Bearer ...\"}. Accept defaults to application/json",
+						},
+						"body": map[string]interface{}{
+							"description": "Request body: a JSON object or array (sent as application/json) or a string",
+						},
+						"timeout": map[string]interface{}{
+							"type":        "integer",
+							"description": "Seconds to wait for the response (default 30)",
+						},
+					},
+					"required": []string{"url"},
+				},
+			},
+		},
+		{
+			Type: "function",
+			Function: ToolFunction{
+				Name:        "git_status",
+				Description: "Get git repository status: branch, upstream with ahead/behind counts, and each changed file with its staged (index) and unstaged (worktree) state, untracked and conflicted files. Works only if project is a git repository.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"path": map[string]interface{}{
+							"type":        "string",
+							"description": "File or directory relative to project root (empty for the entire repository)",
+						},
+					},
+					"required": []string{},
+				},
+			},
+		},
 		{
 			Type: "function",
 			Function: ToolFunction{
 				Name:        "git_diff",
-				Description: "Get git diff for a file or directory. Returns changes made. Works only if project is a git repository.",
+				Description: "Get git changes with per-file added/deleted line counts. Shows unstaged changes by default, staged changes with staged=true, or the changes of a revision range such as main..HEAD or HEAD~3. Works only if project is a git repository.",
 				Parameters: map[string]interface{}{
 					"type": "object",
 					"properties": map[string]interface{}{
 						"path": map[string]interface{}{
 							"type":        "string",
-							"description": "Path to the file or directory relative to project root (empty string for entire repository)",
+							"description": "File or directory relative to project root (empty for the entire repository)",
+						},
+						"staged": map[string]interface{}{
+							"type":        "boolean",
+							"description": "Show changes staged for commit instead of unstaged changes",
+						},
+						"rev": map[string]interface{}{
+							"type":        "string",
+							"description": "Revision or range to diff, e.g. HEAD~1, main..HEAD or a1b2c3d..e4f5a6b",
+						},
+						"stat": map[string]interface{}{
+							"type":        "boolean",
+							"description": "Return only the changed files and line counts, without the patch",
 						},
 					},
 					"required": []string{},
@@ -461,29 +826,307 @@ func GetAvailableTools() []Tool {
 		{
 			Type: "function",
 			Function: ToolFunction{
-				Name:        "find_symbol_references",
-				Description: "Find all places where a symbol (class, function, variable) is used or referenced in the project. Searches code for occurrences of the symbol name.",
+				Name:        "git_log",
+				Description: "List commits, newest first, with hash, author, date and subject. Filter by file or directory (renames are followed), by a symbol whose number of occurrences changed (git log -S), by author or by date.",
 				Parameters: map[string]interface{}{
 					"type": "object",
 					"properties": map[string]interface{}{
+						"path": map[string]interface{}{
+							"type":        "string",
+							"description": "File or directory relative to project root (empty for the entire repository)",
+						},
 						"symbol": map[string]interface{}{
 							"type":        "string",
-							"description": "Symbol name to search for",
+							"description": "Only commits that added or removed this string, e.g. a function name",
 						},
+						"rev": map[string]interface{}{
+							"type":        "string",
+							"description": "Revision or range to list, e.g. main..HEAD (default: HEAD)",
+						},
+						"author": map[string]interface{}{
+							"type":        "string",
+							"description": "Only commits whose author matches this name or email",
+						},
+						"since": map[string]interface{}{
+							"type":        "string",
+							"description": "Only commits after this date, e.g. 2024-01-01 or '2 weeks ago'",
+						},
+						"limit": map[string]interface{}{
+							"type":        "integer",
+							"description": "Maximum number of commits (default: 20, max: 200)",
+						},
+					},
+					"required": []string{},
+				},
+			},
+		},
+		{
+			Type: "function",
+			Function: ToolFunction{
+				Name:        "git_blame",
+				Description: "Show which commit last changed each line of a file in a line range, with the commits' authors, dates and subjects. Use git_show on a commit to see why a line changed.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
 						"path": map[string]interface{}{
 							"type":        "string",
-							"description": "Path to search from (relative to project root, empty string for project root)",
+							"description": "File path relative to project root",
+						},
+						"start_line": map[string]interface{}{
+							"type":        "integer",
+							"description": "First line (1-based, default: 1)",
+						},
+						"end_line": map[string]interface{}{
+							"type":        "integer",
+							"description": "Last line (default: 100 lines from start_line, at most 500 lines)",
+						},
+						"rev": map[string]interface{}{
+							"type":        "string",
+							"description": "Revision to blame (default: the working tree)",
+						},
+					},
+					"required": []string{"path"},
+				},
+			},
+		},
+		{
+			Type: "function",
+			Function: ToolFunction{
+				Name:        "git_show",
+				Description: "Show a commit: hash, author, date, full message, parents, changed files with line counts, and the patch.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"rev": map[string]interface{}{
+							"type":        "string",
+							"description": "Commit, branch or tag, e.g. HEAD, a1b2c3d or v1.2.0",
+						},
+						"path": map[string]interface{}{
+							"type":        "string",
+							"description": "Limit the patch to this file or directory",
+						},
+						"stat": map[string]interface{}{
+							"type":        "boolean",
+							"description": "Return only the changed files and line counts, without the patch",
+						},
+					},
+					"required": []string{"rev"},
+				},
+			},
+		},
+		{
+			Type: "function",
+			Function: ToolFunction{
+				Name:        "git_branches",
+				Description: "List branches with their latest commit, the current branch, upstream and ahead/behind counts.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"remote": map[string]interface{}{
+							"type":        "boolean",
+							"description": "Also list remote-tracking branches",
+						},
+					},
+					"required": []string{},
+				},
+			},
+		},
+		{
+			Type: "function",
+			Function: ToolFunction{
+				Name:        "find_references",
+				Description: "Find all references to a symbol (type, function, method, field, variable). In Go projects the symbol is resolved with the type checker, so only true references are returned; other languages fall back to a whole-word text search.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"symbol": map[string]interface{}{
+							"type":        "string",
+							"description": "Symbol name, optionally qualified: Name, Type.Method or pkg.Name",
+						},
+						"path": map[string]interface{}{
+							"type":        "string",
+							"description": "File declaring or using the symbol (with 'line'), or a directory to limit results to",
+						},
+						"line": map[string]interface{}{
+							"type":        "integer",
+							"description": "Line of the symbol in 'path', to pick one of several symbols with the same name",
+						},
+					},
+					"required": []string{"symbol"},
+				},
+			},
+		},
+		{
+			Type: "function",
+			Function: ToolFunction{
+				Name:        "find_implementations",
+				Description: "Find the types implementing an interface, or the interfaces a type implements. Precise for Go; other languages search for 'implements'/'extends' declarations.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"symbol": map[string]interface{}{
+							"type":        "string",
+							"description": "Symbol name, optionally qualified: Name, Type.Method or pkg.Name",
+						},
+						"path": map[string]interface{}{
+							"type":        "string",
+							"description": "File declaring or using the symbol (with 'line'), or a directory to limit results to",
+						},
+						"line": map[string]interface{}{
+							"type":        "integer",
+							"description": "Line of the symbol in 'path', to pick one of several symbols with the same name",
 						},
 					},
 					"required": []string{"symbol"},
 				},
 			},
 		},
+		{
+			Type: "function",
+			Function: ToolFunction{
+				Name:        "call_hierarchy",
+				Description: "List the callers (incoming) and callees (outgoing) of a function or method. Precise for Go, including calls from other packages; other languages only find call sites by name.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"symbol": map[string]interface{}{
+							"type":        "string",
+							"description": "Symbol name, optionally qualified: Name, Type.Method or pkg.Name",
+						},
+						"path": map[string]interface{}{
+							"type":        "string",
+							"description": "File declaring or using the symbol (with 'line'), or a directory to limit results to",
+						},
+						"line": map[string]interface{}{
+							"type":        "integer",
+							"description": "Line of the symbol in 'path', to pick one of several symbols with the same name",
+						},
+						"direction": map[string]interface{}{
+							"type":        "string",
+							"description": "'incoming', 'outgoing' or 'both' (default: both)",
+						},
+						"depth": map[string]interface{}{
+							"type":        "integer",
+							"description": "Levels of calls to follow (default: 1, max: 5)",
+						},
+					},
+					"required": []string{"symbol"},
+				},
+			},
+		},
+		{
+			Type: "function",
+			Function: ToolFunction{
+				Name:        "goto_definition",
+				Description: "Jump to the definition of the symbol at a position, using the file type's language server (gopls, intelephense, typescript-language-server, ...).",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"path": map[string]interface{}{
+							"type":        "string",
+							"description": "Path to the file relative to project root",
+						},
+						"line": map[string]interface{}{
+							"type":        "integer",
+							"description": "Line number (1-based)",
+						},
+						"symbol": map[string]interface{}{
+							"type":        "string",
+							"description": "Identifier on the line to point at (first occurrence)",
+						},
+						"column": map[string]interface{}{
+							"type":        "integer",
+							"description": "Column (1-based), used when symbol is not given",
+						},
+					},
+					"required": []string{"path", "line"},
+				},
+			},
+		},
+		{
+			Type: "function",
+			Function: ToolFunction{
+				Name:        "hover",
+				Description: "Show the type, signature and documentation of the symbol at a position, using the language server.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"path": map[string]interface{}{
+							"type":        "string",
+							"description": "Path to the file relative to project root",
+						},
+						"line": map[string]interface{}{
+							"type":        "integer",
+							"description": "Line number (1-based)",
+						},
+						"symbol": map[string]interface{}{
+							"type":        "string",
+							"description": "Identifier on the line to point at (first occurrence)",
+						},
+						"column": map[string]interface{}{
+							"type":        "integer",
+							"description": "Column (1-based), used when symbol is not given",
+						},
+					},
+					"required": []string{"path", "line"},
+				},
+			},
+		},
+		{
+			Type: "function",
+			Function: ToolFunction{
+				Name:        "diagnostics",
+				Description: "Get compiler errors and warnings for a file from its language server. Files written with write tools already include fresh diagnostics in the result.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"path": map[string]interface{}{
+							"type":        "string",
+							"description": "Path to the file relative to project root",
+						},
+					},
+					"required": []string{"path"},
+				},
+			},
+		},
+		{
+			Type: "function",
+			Function: ToolFunction{
+				Name:        "rename_symbol",
+				Description: "Rename the symbol at a position everywhere it is used, using the language server. Shows the affected files and requires user confirmation.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"path": map[string]interface{}{
+							"type":        "string",
+							"description": "Path to the file relative to project root",
+						},
+						"line": map[string]interface{}{
+							"type":        "integer",
+							"description": "Line number (1-based)",
+						},
+						"symbol": map[string]interface{}{
+							"type":        "string",
+							"description": "Identifier on the line to point at (first occurrence)",
+						},
+						"column": map[string]interface{}{
+							"type":        "integer",
+							"description": "Column (1-based), used when symbol is not given",
+						},
+						"new_name": map[string]interface{}{
+							"type":        "string",
+							"description": "New name for the symbol",
+						},
+					},
+					"required": []string{"path", "line", "new_name"},
+				},
+			},
+		},
 		{
 			Type: "function",
 			Function: ToolFunction{
 				Name:        "execute",
-				Description: "Execute a shell command in the project root directory. Returns stdout, stderr, and exit code. Requires user confirmation.",
+				Description: "Execute a shell command in the project root directory. Returns the combined output (head and tail kept when long) and exit code. The command is killed after the timeout; it may run in a sandbox without network access that can only write inside the project. Requires user confirmation.",
 				Parameters: map[string]interface{}{
 					"type": "object",
 					"properties": map[string]interface{}{
@@ -495,6 +1138,10 @@ func GetAvailableTools() []Tool {
 							"type":        "string",
 							"description": "Optional description of what this command does (for confirmation prompt)",
 						},
+						"timeout": map[string]interface{}{
+							"type":        "integer",
+							"description": "Seconds before the command is killed (default from config, usually 120; max 1800). Raise it for slow installs or builds",
+						},
 					},
 					"required": []string{"command"},
 				},
@@ -0,0 +1,236 @@
+// Package localhttp sends HTTP requests to services running on the
+// developer's machine, refusing any host outside an allowlist (loopback by
+// default), so the assistant can exercise APIs under development.
+package localhttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultHosts are the hosts allowed when none are configured
+var DefaultHosts = []string{"localhost", "127.0.0.1", "::1"}
+
+// Defaults used when Request leaves a limit unset
+const (
+	DefaultTimeout = 30 * time.Second
+	DefaultMaxBody = 20000
+)
+
+// Request is a request to send
+type Request struct {
+	Method  string
+	URL     string // http://localhost:8000/api/users, or localhost:8000/api/users
+	Headers map[string]string
+	Body    []byte
+	Timeout time.Duration
+	MaxBody int // Bytes of the response body returned
+}
+
+// Response is the outcome of a request
+type Response struct {
+	URL         string            `json:"url"` // Final URL after redirects
+	Status      string            `json:"status"`
+	StatusCode  int               `json:"status_code"`
+	Headers     map[string]string `json:"headers"`
+	ContentType string            `json:"content_type,omitempty"`
+	Body        string            `json:"body"` // Pretty-printed when JSON
+	Size        int64             `json:"size"`
+	Truncated   bool              `json:"truncated,omitempty"`
+	Duration    time.Duration     `json:"-"`
+}
+
+// Client sends requests to allowed hosts only
+type Client struct {
+	hosts []string // host or host:port entries
+}
+
+// NewClient creates a client allowing the given hosts, each "host" (any
+// port) or "host:port"; DefaultHosts are used when hosts is empty
+func NewClient(hosts []string) *Client {
+	if len(hosts) == 0 {
+		hosts = DefaultHosts
+	}
+	return &Client{hosts: hosts}
+}
+
+// ParseURL normalizes a request URL, adding http:// when the scheme is missing
+func ParseURL(raw string) (*url.URL, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, errors.New("url is required")
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme %q (use http or https)", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("url %q has no host", raw)
+	}
+	return u, nil
+}
+
+// Check returns an error unless the URL's host and port are allowed
+func (c *Client) Check(u *url.URL) error {
+	host, port := strings.ToLower(u.Hostname()), u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	for _, entry := range c.hosts {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		allowedHost, allowedPort, err := net.SplitHostPort(entry)
+		if err != nil {
+			allowedHost, allowedPort = strings.Trim(entry, "[]"), ""
+		}
+		if allowedHost == host && (allowedPort == "" || allowedPort == port) {
+			return nil
+		}
+	}
+	return fmt.Errorf("host %s is not allowed; http_request only reaches %s (configure http.allowed_hosts)", u.Host, strings.Join(c.hosts, ", "))
+}
+
+// Do sends a request and reads the response, following redirects only to
+// allowed hosts
+func (c *Client) Do(ctx context.Context, req Request) (*Response, error) {
+	u, err := ParseURL(req.URL)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Check(u); err != nil {
+		return nil, err
+	}
+	if req.Timeout <= 0 {
+		req.Timeout = DefaultTimeout
+	}
+	if req.MaxBody <= 0 {
+		req.MaxBody = DefaultMaxBody
+	}
+	method := strings.ToUpper(strings.TrimSpace(req.Method))
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, req.Timeout)
+	defer cancel()
+	httpReq, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(req.Body))
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range req.Headers {
+		httpReq.Header.Set(name, value)
+	}
+	if httpReq.Header.Get("Accept") == "" {
+		// Frameworks such as Laravel answer errors with JSON instead of HTML pages
+		httpReq.Header.Set("Accept", "application/json, */*;q=0.8")
+	}
+	if len(req.Body) > 0 && httpReq.Header.Get("Content-Type") == "" && json.Valid(req.Body) {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+
+	client := &http.Client{
+		// Proxies are for reaching the outside world, not local services
+		Transport: &http.Transport{Proxy: nil, ForceAttemptHTTP2: true},
+		CheckRedirect: func(next *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			return c.Check(next.URL)
+		},
+	}
+	start := time.Now()
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("request timed out after %s", req.Timeout)
+		}
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	// Read a little past the limit: pretty-printing JSON needs the whole
+	// document, and anything bigger is cut anyway
+	data, err := io.ReadAll(io.LimitReader(httpResp.Body, int64(req.MaxBody)*4))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	size := int64(len(data))
+	if extra, _ := io.Copy(io.Discard, httpResp.Body); extra > 0 {
+		size += extra
+	}
+
+	resp := &Response{
+		URL:         httpResp.Request.URL.String(),
+		Status:      httpResp.Status,
+		StatusCode:  httpResp.StatusCode,
+		Headers:     flattenHeaders(httpResp.Header),
+		ContentType: httpResp.Header.Get("Content-Type"),
+		Size:        size,
+		Duration:    time.Since(start),
+	}
+	resp.Body, resp.Truncated = formatBody(data, resp.ContentType, req.MaxBody)
+	if size > int64(len(data)) {
+		resp.Truncated = true
+	}
+	return resp, nil
+}
+
+// formatBody pretty-prints JSON, describes binary content and truncates
+// the result to max bytes
+func formatBody(data []byte, contentType string, max int) (string, bool) {
+	if len(data) == 0 {
+		return "", false
+	}
+	if strings.Contains(contentType, "json") || json.Valid(data) {
+		var pretty bytes.Buffer
+		if json.Indent(&pretty, data, "", "  ") == nil {
+			data = pretty.Bytes()
+		}
+	} else if !utf8.Valid(data[:min(len(data), 1024)]) {
+		return fmt.Sprintf("[binary content, %d bytes, %s]", len(data), contentType), false
+	}
+	if len(data) <= max {
+		return string(data), false
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(data[cut]) {
+		cut--
+	}
+	return string(data[:cut]) + "\n... [truncated]", true
+}
+
+// flattenHeaders joins the values of repeated headers
+func flattenHeaders(header http.Header) map[string]string {
+	flat := make(map[string]string, len(header))
+	for name, values := range header {
+		flat[name] = strings.Join(values, ", ")
+	}
+	return flat
+}
+
+// Mutating reports whether a method changes state on the server
+func Mutating(method string) bool {
+	switch strings.ToUpper(strings.TrimSpace(method)) {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
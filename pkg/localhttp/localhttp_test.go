@@ -0,0 +1,99 @@
+package localhttp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	c := NewClient([]string{"localhost", "127.0.0.1:8000", "[::1]:9000", "app.test"})
+	tests := map[string]bool{
+		"http://localhost:3000/x":   true,
+		"http://127.0.0.1:8000/api": true,
+		"http://127.0.0.1:8001/api": false,
+		"http://[::1]:9000/":        true,
+		"http://[::1]:9001/":        false,
+		"http://APP.test/users":     true,
+		"https://example.com/":      false,
+		"http://localhost.evil.com": false,
+	}
+	for raw, want := range tests {
+		u, err := url.Parse(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := c.Check(u) == nil; got != want {
+			t.Errorf("Check(%s) allowed = %v, want %v", raw, got, want)
+		}
+	}
+}
+
+func TestParseURL(t *testing.T) {
+	u, err := ParseURL("localhost:8000/api/users?page=2")
+	if err != nil || u.String() != "http://localhost:8000/api/users?page=2" {
+		t.Errorf("got %v, %v", u, err)
+	}
+	if _, err := ParseURL("ftp://localhost/x"); err == nil {
+		t.Error("expected an error for ftp")
+	}
+}
+
+func TestDo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users":
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("X-Seen-Type", r.Header.Get("Content-Type"))
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"method":"` + r.Method + `","body":` + string(body) + `}`))
+		case "/away":
+			http.Redirect(w, r, "https://example.com/", http.StatusFound)
+		case "/big":
+			w.Write([]byte(strings.Repeat("x", 5000)))
+		}
+	}))
+	defer server.Close()
+	c := NewClient(nil)
+
+	resp, err := c.Do(context.Background(), Request{Method: "post", URL: server.URL + "/users", Body: []byte(`{"name":"ada"}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != 201 || resp.Headers["X-Seen-Type"] != "application/json" {
+		t.Errorf("got %d, headers %v", resp.StatusCode, resp.Headers)
+	}
+	want := "{\n  \"method\": \"POST\",\n  \"body\": {\n    \"name\": \"ada\"\n  }\n}"
+	if resp.Body != want {
+		t.Errorf("body not pretty-printed:\n%s", resp.Body)
+	}
+
+	if _, err := c.Do(context.Background(), Request{URL: server.URL + "/away"}); err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Errorf("expected the redirect to be refused, got %v", err)
+	}
+
+	resp, err = c.Do(context.Background(), Request{URL: server.URL + "/big", MaxBody: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Truncated || resp.Size != 5000 || len(resp.Body) > 120 {
+		t.Errorf("truncated %v, size %d, body length %d", resp.Truncated, resp.Size, len(resp.Body))
+	}
+
+	if _, err := c.Do(context.Background(), Request{URL: "http://example.com/"}); err == nil {
+		t.Error("expected a remote host to be refused")
+	}
+}
+
+func TestMutating(t *testing.T) {
+	for method, want := range map[string]bool{"GET": false, "": false, "head": false, "POST": true, "delete": true, "PATCH": true} {
+		if Mutating(method) != want {
+			t.Errorf("Mutating(%q) = %v", method, !want)
+		}
+	}
+}
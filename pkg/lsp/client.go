@@ -0,0 +1,388 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// shutdownTimeout bounds the shutdown handshake before the server is killed
+const shutdownTimeout = 2 * time.Second
+
+// Client talks to one language server
+type Client struct {
+	name string
+	root string
+	conn *Conn
+	cmd  *exec.Cmd // nil when connected to an existing stream
+
+	mu       sync.Mutex
+	versions map[string]int // URI -> version of open documents
+	diags    map[string]*documentDiagnostics
+}
+
+// documentDiagnostics holds the latest diagnostics published for a document
+type documentDiagnostics struct {
+	seq         int // Incremented on every publish
+	version     int // Document version the server reported, 0 if unknown
+	diagnostics []Diagnostic
+	updated     chan struct{} // Closed and replaced on every publish
+}
+
+// Start spawns a language server and performs the initialize handshake
+func Start(ctx context.Context, name string, command []string, root string) (*Client, error) {
+	if len(command) == 0 {
+		return nil, fmt.Errorf("no command configured for language server %s", name)
+	}
+
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Dir = root
+	cmd.Stderr = io.Discard
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdin pipe: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", name, err)
+	}
+
+	client, err := NewClient(ctx, name, &processStream{stdin, stdout}, root)
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return nil, err
+	}
+	client.cmd = cmd
+	return client, nil
+}
+
+// NewClient performs the initialize handshake over an existing stream
+func NewClient(ctx context.Context, name string, stream io.ReadWriteCloser, root string) (*Client, error) {
+	c := &Client{
+		name:     name,
+		root:     root,
+		versions: make(map[string]int),
+		diags:    make(map[string]*documentDiagnostics),
+	}
+	c.conn = NewConn(stream, c)
+
+	rootURI := PathToURI(root)
+	params := map[string]interface{}{
+		"processId": os.Getpid(),
+		"clientInfo": map[string]string{
+			"name": "axon",
+		},
+		"rootUri":  rootURI,
+		"rootPath": root,
+		"workspaceFolders": []map[string]string{
+			{"uri": rootURI, "name": filepath.Base(root)},
+		},
+		"capabilities": map[string]interface{}{
+			"textDocument": map[string]interface{}{
+				"synchronization":    map[string]interface{}{"didSave": true},
+				"publishDiagnostics": map[string]interface{}{"versionSupport": true},
+				"hover":              map[string]interface{}{"contentFormat": []string{"plaintext", "markdown"}},
+				"definition":         map[string]interface{}{"linkSupport": true},
+				"rename":             map[string]interface{}{},
+			},
+			"workspace": map[string]interface{}{
+				"workspaceEdit":    map[string]interface{}{"documentChanges": true},
+				"workspaceFolders": true,
+				"configuration":    true,
+			},
+		},
+	}
+
+	if err := c.conn.Call(ctx, "initialize", params, nil); err != nil {
+		c.conn.Close()
+		return nil, fmt.Errorf("failed to initialize %s: %w", name, err)
+	}
+	if err := c.conn.Notify("initialized", map[string]interface{}{}); err != nil {
+		c.conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Name returns the configured server name
+func (c *Client) Name() string {
+	return c.name
+}
+
+// HandleRequest answers the requests servers commonly send during startup
+func (c *Client) HandleRequest(method string, params json.RawMessage) (interface{}, error) {
+	switch method {
+	case "workspace/configuration":
+		var req struct {
+			Items []json.RawMessage `json:"items"`
+		}
+		json.Unmarshal(params, &req)
+		return make([]interface{}, len(req.Items)), nil
+	case "client/registerCapability", "client/unregisterCapability", "window/workDoneProgress/create":
+		return nil, nil
+	case "workspace/workspaceFolders":
+		return []map[string]string{{"uri": PathToURI(c.root), "name": filepath.Base(c.root)}}, nil
+	case "workspace/applyEdit":
+		// Edits are applied by the caller after user confirmation, never by the server
+		return map[string]interface{}{"applied": false}, nil
+	}
+	return nil, &ResponseError{Code: codeMethodNotFound, Message: "method not supported: " + method}
+}
+
+// HandleNotification records published diagnostics
+func (c *Client) HandleNotification(method string, params json.RawMessage) {
+	if method != "textDocument/publishDiagnostics" {
+		return
+	}
+	var p publishDiagnosticsParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	d := c.documentDiagnostics(p.URI)
+	d.seq++
+	d.version = 0
+	if p.Version != nil {
+		d.version = *p.Version
+	}
+	d.diagnostics = p.Diagnostics
+	close(d.updated)
+	d.updated = make(chan struct{})
+}
+
+// documentDiagnostics returns the diagnostics entry for a URI. Must be called with c.mu held.
+func (c *Client) documentDiagnostics(uri string) *documentDiagnostics {
+	d, ok := c.diags[uri]
+	if !ok {
+		d = &documentDiagnostics{updated: make(chan struct{})}
+		c.diags[uri] = d
+	}
+	return d
+}
+
+// Sync sends the current content of a file to the server (didOpen the first
+// time, a full didChange afterwards). It returns a token for WaitDiagnostics.
+func (c *Client) Sync(path, content string) (SyncToken, error) {
+	uri := PathToURI(path)
+
+	c.mu.Lock()
+	version, open := c.versions[uri]
+	version++
+	c.versions[uri] = version
+	seq := c.documentDiagnostics(uri).seq
+	c.mu.Unlock()
+
+	var err error
+	if !open {
+		err = c.conn.Notify("textDocument/didOpen", map[string]interface{}{
+			"textDocument": map[string]interface{}{
+				"uri":        uri,
+				"languageId": LanguageID(path),
+				"version":    version,
+				"text":       content,
+			},
+		})
+	} else {
+		err = c.conn.Notify("textDocument/didChange", map[string]interface{}{
+			"textDocument":   map[string]interface{}{"uri": uri, "version": version},
+			"contentChanges": []map[string]string{{"text": content}},
+		})
+		if err == nil {
+			err = c.conn.Notify("textDocument/didSave", map[string]interface{}{
+				"textDocument": map[string]string{"uri": uri},
+			})
+		}
+	}
+	return SyncToken{uri: uri, version: version, seq: seq}, err
+}
+
+// SyncToken identifies a document state sent with Sync
+type SyncToken struct {
+	uri     string
+	version int
+	seq     int
+}
+
+// WaitDiagnostics waits until the server publishes diagnostics for the synced
+// document state, or until the context ends. ok is false on timeout.
+func (c *Client) WaitDiagnostics(ctx context.Context, token SyncToken) (diagnostics []Diagnostic, ok bool) {
+	for {
+		c.mu.Lock()
+		d := c.documentDiagnostics(token.uri)
+		// Servers that report versions tell us exactly which state was checked;
+		// otherwise any publish after the sync is taken as the answer
+		fresh := d.seq > token.seq && (d.version == 0 || d.version >= token.version)
+		diagnostics, updated := d.diagnostics, d.updated
+		c.mu.Unlock()
+
+		if fresh {
+			return diagnostics, true
+		}
+		select {
+		case <-updated:
+		case <-ctx.Done():
+			return diagnostics, false
+		case <-c.conn.Done():
+			return diagnostics, false
+		}
+	}
+}
+
+// Diagnostics returns the latest diagnostics published for a file
+func (c *Client) Diagnostics(path string) []Diagnostic {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if d, ok := c.diags[PathToURI(path)]; ok {
+		return d.diagnostics
+	}
+	return nil
+}
+
+// Definition returns the locations where the symbol at a position is defined
+func (c *Client) Definition(ctx context.Context, path string, pos Position) ([]Location, error) {
+	var raw json.RawMessage
+	if err := c.conn.Call(ctx, "textDocument/definition", positionParams(path, pos), &raw); err != nil {
+		return nil, err
+	}
+	return decodeLocations(raw), nil
+}
+
+// Hover returns the hover text (type, signature, docs) for a position
+func (c *Client) Hover(ctx context.Context, path string, pos Position) (string, error) {
+	var result *hoverResult
+	if err := c.conn.Call(ctx, "textDocument/hover", positionParams(path, pos), &result); err != nil {
+		return "", err
+	}
+	if result == nil {
+		return "", nil
+	}
+	return hoverText(result.Contents), nil
+}
+
+// Rename asks the server for the edits that rename the symbol at a position.
+// The edits are returned per file path and are not applied.
+func (c *Client) Rename(ctx context.Context, path string, pos Position, newName string) (map[string][]TextEdit, error) {
+	params := positionParams(path, pos)
+	params["newName"] = newName
+
+	var edit *workspaceEdit
+	if err := c.conn.Call(ctx, "textDocument/rename", params, &edit); err != nil {
+		return nil, err
+	}
+	if edit == nil {
+		return nil, nil
+	}
+
+	changes := make(map[string][]TextEdit)
+	for uri, edits := range edit.Changes {
+		p, err := URIToPath(uri)
+		if err != nil {
+			return nil, err
+		}
+		changes[p] = append(changes[p], edits...)
+	}
+	for _, raw := range edit.DocumentChanges {
+		var docEdit textDocumentEdit
+		if err := json.Unmarshal(raw, &docEdit); err != nil {
+			return nil, fmt.Errorf("failed to decode rename edit: %w", err)
+		}
+		if docEdit.Kind != "" {
+			return nil, fmt.Errorf("rename requires a file %s operation, which is not supported", docEdit.Kind)
+		}
+		p, err := URIToPath(docEdit.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		changes[p] = append(changes[p], docEdit.Edits...)
+	}
+	return changes, nil
+}
+
+// Close shuts the server down, killing it if it does not exit in time
+func (c *Client) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := c.conn.Call(ctx, "shutdown", nil, nil); err == nil {
+		c.conn.Notify("exit", nil)
+	}
+	c.conn.Close()
+
+	if c.cmd == nil {
+		return nil
+	}
+	exited := make(chan struct{})
+	go func() {
+		c.cmd.Wait()
+		close(exited)
+	}()
+	select {
+	case <-exited:
+	case <-time.After(shutdownTimeout):
+		c.cmd.Process.Kill()
+		<-exited
+	}
+	return nil
+}
+
+// positionParams builds TextDocumentPositionParams
+func positionParams(path string, pos Position) map[string]interface{} {
+	return map[string]interface{}{
+		"textDocument": map[string]string{"uri": PathToURI(path)},
+		"position":     pos,
+	}
+}
+
+// LanguageID returns the LSP language identifier for a file
+func LanguageID(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	switch ext {
+	case ".go":
+		return "go"
+	case ".php":
+		return "php"
+	case ".ts":
+		return "typescript"
+	case ".tsx":
+		return "typescriptreact"
+	case ".js", ".mjs", ".cjs":
+		return "javascript"
+	case ".jsx":
+		return "javascriptreact"
+	case ".py":
+		return "python"
+	case ".rs":
+		return "rust"
+	case ".vue":
+		return "vue"
+	case ".sh", ".bash":
+		return "shellscript"
+	}
+	return strings.TrimPrefix(ext, ".")
+}
+
+// processStream joins a child process's stdin and stdout into one stream
+type processStream struct {
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+}
+
+func (p *processStream) Read(b []byte) (int, error)  { return p.stdout.Read(b) }
+func (p *processStream) Write(b []byte) (int, error) { return p.stdin.Write(b) }
+func (p *processStream) Close() error {
+	p.stdin.Close()
+	return p.stdout.Close()
+}
@@ -0,0 +1,234 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// JSON-RPC error codes used by the client
+const (
+	codeMethodNotFound = -32601
+)
+
+// ResponseError is a JSON-RPC error returned by the server
+type ResponseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+// message is any incoming JSON-RPC message: request, notification or response
+type message struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *ResponseError  `json:"error,omitempty"`
+}
+
+// outgoingRequest is a request or notification sent to the server
+type outgoingRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      *int64      `json:"id,omitempty"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// outgoingResponse answers a request the server sent to the client
+type outgoingResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result"`
+	Error   *ResponseError  `json:"error,omitempty"`
+}
+
+// Handler answers requests from the server. Notifications have no reply.
+type Handler interface {
+	HandleRequest(method string, params json.RawMessage) (interface{}, error)
+	HandleNotification(method string, params json.RawMessage)
+}
+
+// Conn is a JSON-RPC 2.0 connection using the LSP base protocol framing
+// (Content-Length headers followed by a JSON body)
+type Conn struct {
+	rwc     io.ReadWriteCloser
+	handler Handler
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	nextID  int64
+	pending map[int64]chan *message
+
+	done chan struct{}
+	err  error // Why the read loop stopped, valid after done is closed
+}
+
+// NewConn starts reading messages from rwc and dispatching them to handler
+func NewConn(rwc io.ReadWriteCloser, handler Handler) *Conn {
+	c := &Conn{
+		rwc:     rwc,
+		handler: handler,
+		pending: make(map[int64]chan *message),
+		done:    make(chan struct{}),
+	}
+	go c.readLoop()
+	return c
+}
+
+// Call sends a request and decodes the response into result (which may be nil)
+func (c *Conn) Call(ctx context.Context, method string, params, result interface{}) error {
+	c.mu.Lock()
+	c.nextID++
+	id := c.nextID
+	ch := make(chan *message, 1)
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	if err := c.write(outgoingRequest{JSONRPC: "2.0", ID: &id, Method: method, Params: params}); err != nil {
+		return err
+	}
+
+	select {
+	case resp := <-ch:
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result != nil && len(resp.Result) > 0 {
+			if err := json.Unmarshal(resp.Result, result); err != nil {
+				return fmt.Errorf("failed to decode %s response: %w", method, err)
+			}
+		}
+		return nil
+	case <-ctx.Done():
+		c.Notify("$/cancelRequest", map[string]interface{}{"id": id})
+		return ctx.Err()
+	case <-c.done:
+		return fmt.Errorf("connection closed: %w", c.err)
+	}
+}
+
+// Notify sends a notification
+func (c *Conn) Notify(method string, params interface{}) error {
+	return c.write(outgoingRequest{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// Close closes the underlying stream
+func (c *Conn) Close() error {
+	return c.rwc.Close()
+}
+
+// Done is closed when the connection stops reading
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+// write frames and sends a message
+func (c *Conn) write(v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if _, err := fmt.Fprintf(c.rwc, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if _, err := c.rwc.Write(body); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	return nil
+}
+
+// readLoop reads framed messages until the stream ends
+func (c *Conn) readLoop() {
+	reader := bufio.NewReader(c.rwc)
+	var err error
+	for {
+		var body []byte
+		body, err = readMessage(reader)
+		if err != nil {
+			break
+		}
+
+		var msg message
+		if jsonErr := json.Unmarshal(body, &msg); jsonErr != nil {
+			continue // Skip malformed messages
+		}
+
+		switch {
+		case msg.Method != "" && len(msg.ID) > 0:
+			go c.reply(&msg)
+		case msg.Method != "":
+			if c.handler != nil {
+				c.handler.HandleNotification(msg.Method, msg.Params)
+			}
+		default:
+			id, convErr := strconv.ParseInt(strings.Trim(string(msg.ID), `"`), 10, 64)
+			if convErr != nil {
+				continue
+			}
+			c.mu.Lock()
+			ch := c.pending[id]
+			c.mu.Unlock()
+			if ch != nil {
+				ch <- &msg
+			}
+		}
+	}
+
+	c.err = err
+	close(c.done)
+}
+
+// reply answers a request from the server
+func (c *Conn) reply(msg *message) {
+	resp := outgoingResponse{JSONRPC: "2.0", ID: msg.ID}
+	if c.handler == nil {
+		resp.Error = &ResponseError{Code: codeMethodNotFound, Message: "method not found: " + msg.Method}
+	} else if result, err := c.handler.HandleRequest(msg.Method, msg.Params); err != nil {
+		if respErr, ok := err.(*ResponseError); ok {
+			resp.Error = respErr
+		} else {
+			resp.Error = &ResponseError{Code: -32603, Message: err.Error()}
+		}
+	} else {
+		resp.Result = result
+	}
+	c.write(resp)
+}
+
+// readMessage reads one Content-Length framed message body
+func readMessage(r *bufio.Reader) ([]byte, error) {
+	headers, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(headers.Get("Content-Length"))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length header: %q", headers.Get("Content-Length"))
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
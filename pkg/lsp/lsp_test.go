@@ -0,0 +1,232 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeServer is a tiny language server: it reports a diagnostic on every line
+// containing "undefined", and answers definition, hover and rename requests
+type fakeServer struct {
+	t    *testing.T
+	conn net.Conn
+
+	mu          sync.Mutex
+	configAsked bool
+}
+
+func (f *fakeServer) send(v interface{}) {
+	body, _ := json.Marshal(v)
+	fmt.Fprintf(f.conn, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+func (f *fakeServer) serve() {
+	reader := bufio.NewReader(f.conn)
+	for {
+		body, err := readMessage(reader)
+		if err != nil {
+			return
+		}
+		var msg struct {
+			ID     *int64          `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+			Result json.RawMessage `json:"result"`
+		}
+		json.Unmarshal(body, &msg)
+
+		reply := func(result interface{}) {
+			f.send(map[string]interface{}{"jsonrpc": "2.0", "id": *msg.ID, "result": result})
+		}
+
+		switch msg.Method {
+		case "":
+			// Response to our workspace/configuration request
+			f.mu.Lock()
+			f.configAsked = string(msg.Result) == "[null]"
+			f.mu.Unlock()
+		case "initialize":
+			f.send(map[string]interface{}{"jsonrpc": "2.0", "id": 99, "method": "workspace/configuration",
+				"params": map[string]interface{}{"items": []map[string]string{{"section": "fake"}}}})
+			reply(map[string]interface{}{"capabilities": map[string]interface{}{}})
+		case "textDocument/didOpen", "textDocument/didChange":
+			var p struct {
+				TextDocument struct {
+					URI     string `json:"uri"`
+					Version int    `json:"version"`
+					Text    string `json:"text"`
+				} `json:"textDocument"`
+				ContentChanges []struct {
+					Text string `json:"text"`
+				} `json:"contentChanges"`
+			}
+			json.Unmarshal(msg.Params, &p)
+			text := p.TextDocument.Text
+			if len(p.ContentChanges) > 0 {
+				text = p.ContentChanges[0].Text
+			}
+			diagnostics := []Diagnostic{}
+			for i, line := range strings.Split(text, "\n") {
+				if col := strings.Index(line, "undefined"); col >= 0 {
+					diagnostics = append(diagnostics, Diagnostic{
+						Range:    Range{Start: Position{i, col}, End: Position{i, col + 9}},
+						Severity: SeverityError,
+						Message:  "undefined: x",
+					})
+				}
+			}
+			f.send(map[string]interface{}{"jsonrpc": "2.0", "method": "textDocument/publishDiagnostics",
+				"params": map[string]interface{}{"uri": p.TextDocument.URI, "version": p.TextDocument.Version, "diagnostics": diagnostics}})
+		case "textDocument/definition":
+			var p struct {
+				TextDocument struct {
+					URI string `json:"uri"`
+				} `json:"textDocument"`
+			}
+			json.Unmarshal(msg.Params, &p)
+			reply([]map[string]interface{}{{
+				"targetUri":            p.TextDocument.URI,
+				"targetRange":          Range{Start: Position{0, 0}, End: Position{2, 1}},
+				"targetSelectionRange": Range{Start: Position{0, 5}, End: Position{0, 8}},
+			}})
+		case "textDocument/hover":
+			reply(map[string]interface{}{"contents": map[string]string{"kind": "markdown", "value": "func Add(a, b int) int"}})
+		case "textDocument/rename":
+			var p struct {
+				TextDocument struct {
+					URI string `json:"uri"`
+				} `json:"textDocument"`
+				NewName string `json:"newName"`
+			}
+			json.Unmarshal(msg.Params, &p)
+			reply(map[string]interface{}{"documentChanges": []map[string]interface{}{{
+				"textDocument": map[string]interface{}{"uri": p.TextDocument.URI, "version": 1},
+				"edits": []TextEdit{
+					{Range: Range{Start: Position{0, 5}, End: Position{0, 8}}, NewText: p.NewName},
+					{Range: Range{Start: Position{3, 8}, End: Position{3, 11}}, NewText: p.NewName},
+				},
+			}}})
+		case "shutdown":
+			reply(nil)
+		case "exit":
+			f.conn.Close()
+			return
+		}
+	}
+}
+
+func newFakeClient(t *testing.T) (*Client, *fakeServer) {
+	t.Helper()
+	clientEnd, serverEnd := net.Pipe()
+	server := &fakeServer{t: t, conn: serverEnd}
+	go server.serve()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client, err := NewClient(ctx, "fake", clientEnd, t.TempDir())
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client, server
+}
+
+func TestClient_Diagnostics(t *testing.T) {
+	client, server := newFakeClient(t)
+	path := filepath.Join(client.root, "main.go")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	token, err := client.Sync(path, "package main\n\nfunc main() { undefined }\n")
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	diagnostics, ok := client.WaitDiagnostics(ctx, token)
+	if !ok {
+		t.Fatal("Timed out waiting for diagnostics")
+	}
+	if len(diagnostics) != 1 || diagnostics[0].Range.Start.Line != 2 || diagnostics[0].SeverityName() != "error" {
+		t.Errorf("Unexpected diagnostics: %+v", diagnostics)
+	}
+
+	// A fixed file publishes an empty list for the new version
+	token, _ = client.Sync(path, "package main\n\nfunc main() {}\n")
+	diagnostics, ok = client.WaitDiagnostics(ctx, token)
+	if !ok || len(diagnostics) != 0 {
+		t.Errorf("Expected no diagnostics after the fix, got %+v (ok=%v)", diagnostics, ok)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if !server.configAsked {
+		t.Error("Expected the client to answer workspace/configuration")
+	}
+}
+
+func TestClient_Navigation(t *testing.T) {
+	client, _ := newFakeClient(t)
+	path := filepath.Join(client.root, "math.go")
+	content := "func Add(a, b int) int {\n\treturn a + b\n}\nvar x = Add(1, 2)\n"
+	ctx := context.Background()
+
+	if _, err := client.Sync(path, content); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	locations, err := client.Definition(ctx, path, Position{3, 9})
+	if err != nil {
+		t.Fatalf("Definition failed: %v", err)
+	}
+	if len(locations) != 1 || locations[0].Range.Start != (Position{0, 5}) {
+		t.Errorf("Unexpected definition: %+v", locations)
+	}
+	if p, _ := URIToPath(locations[0].URI); p != path {
+		t.Errorf("Expected definition in %s, got %s", path, p)
+	}
+
+	hover, err := client.Hover(ctx, path, Position{3, 9})
+	if err != nil || hover != "func Add(a, b int) int" {
+		t.Errorf("Unexpected hover %q (err %v)", hover, err)
+	}
+
+	changes, err := client.Rename(ctx, path, Position{3, 9}, "Sum")
+	if err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	renamed, err := ApplyEdits(content, changes[path])
+	if err != nil {
+		t.Fatalf("ApplyEdits failed: %v", err)
+	}
+	if want := "func Sum(a, b int) int {\n\treturn a + b\n}\nvar x = Sum(1, 2)\n"; renamed != want {
+		t.Errorf("Unexpected rename result:\n%s", renamed)
+	}
+}
+
+func TestApplyEdits_UTF16(t *testing.T) {
+	// "é" is one UTF-16 unit but two bytes; "😀" is two UTF-16 units and four bytes
+	content := "s := \"é😀\" + old\n"
+	start := UTF16Offset(content, strings.Index(content, "old"))
+	if start != 13 {
+		t.Fatalf("Expected UTF-16 offset 13, got %d", start)
+	}
+	got, err := ApplyEdits(content, []TextEdit{{Range: Range{Start: Position{0, start}, End: Position{0, start + 3}}, NewText: "new"}})
+	if err != nil {
+		t.Fatalf("ApplyEdits failed: %v", err)
+	}
+	if got != "s := \"é😀\" + new\n" {
+		t.Errorf("Unexpected result %q", got)
+	}
+
+	if _, err := ApplyEdits("a\n", []TextEdit{{Range: Range{Start: Position{5, 0}, End: Position{5, 1}}}}); err == nil {
+		t.Error("Expected error for an edit past the end of the document")
+	}
+}
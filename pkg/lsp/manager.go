@@ -0,0 +1,121 @@
+package lsp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/axon/pkg/project"
+)
+
+// ErrNoServer is returned when no language server is configured or installed for a file
+var ErrNoServer = errors.New("no language server available for this file type")
+
+// startTimeout bounds starting and initializing a server; some servers index
+// the workspace before answering initialize
+const startTimeout = 30 * time.Second
+
+// Manager starts configured language servers on demand, one per server entry
+type Manager struct {
+	root    string
+	servers []project.LSPServer
+
+	mu      sync.Mutex
+	clients map[string]*Client // Server name -> running client
+	failed  map[string]error   // Server name -> start error, so broken servers are not retried every call
+}
+
+// NewManager creates a manager for the servers configured in .axon.yml
+func NewManager(root string, servers []project.LSPServer) *Manager {
+	return &Manager{
+		root:    root,
+		servers: servers,
+		clients: make(map[string]*Client),
+		failed:  make(map[string]error),
+	}
+}
+
+// serverFor finds the configured server for a file extension
+func (m *Manager) serverFor(path string) (project.LSPServer, bool) {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, server := range m.servers {
+		for _, e := range server.Extensions {
+			if strings.ToLower(e) == ext {
+				return server, true
+			}
+		}
+	}
+	return project.LSPServer{}, false
+}
+
+// Handles reports whether a server is configured and installed for a file
+func (m *Manager) Handles(path string) bool {
+	server, ok := m.serverFor(path)
+	if !ok || len(server.Command) == 0 {
+		return false
+	}
+	_, err := exec.LookPath(server.Command[0])
+	return err == nil
+}
+
+// ClientFor returns the running client for a file, starting its server if needed
+func (m *Manager) ClientFor(path string) (*Client, error) {
+	server, ok := m.serverFor(path)
+	if !ok || len(server.Command) == 0 {
+		return nil, ErrNoServer
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if client, ok := m.clients[server.Name]; ok {
+		select {
+		case <-client.conn.Done():
+			delete(m.clients, server.Name) // Server exited; start a new one
+		default:
+			return client, nil
+		}
+	}
+	if err, ok := m.failed[server.Name]; ok {
+		return nil, err
+	}
+
+	if _, err := exec.LookPath(server.Command[0]); err != nil {
+		err = fmt.Errorf("%w: %s is not installed", ErrNoServer, server.Command[0])
+		m.failed[server.Name] = err
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), startTimeout)
+	defer cancel()
+	client, err := Start(ctx, server.Name, server.Command, m.root)
+	if err != nil {
+		m.failed[server.Name] = err
+		return nil, err
+	}
+	m.clients[server.Name] = client
+	return client, nil
+}
+
+// Close shuts down all running servers
+func (m *Manager) Close() {
+	m.mu.Lock()
+	clients := m.clients
+	m.clients = make(map[string]*Client)
+	m.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, client := range clients {
+		wg.Add(1)
+		go func(c *Client) {
+			defer wg.Done()
+			c.Close()
+		}(client)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,271 @@
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Position is a zero-based line and UTF-16 character offset
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is a span between two positions
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Location is a range in a document
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+// locationLink is the alternative definition result some servers return
+type locationLink struct {
+	TargetURI            string `json:"targetUri"`
+	TargetRange          Range  `json:"targetRange"`
+	TargetSelectionRange Range  `json:"targetSelectionRange"`
+}
+
+// Diagnostic severities
+const (
+	SeverityError       = 1
+	SeverityWarning     = 2
+	SeverityInformation = 3
+	SeverityHint        = 4
+)
+
+// Diagnostic is a compiler error, warning or hint reported by a server
+type Diagnostic struct {
+	Range    Range           `json:"range"`
+	Severity int             `json:"severity,omitempty"`
+	Code     json.RawMessage `json:"code,omitempty"`
+	Source   string          `json:"source,omitempty"`
+	Message  string          `json:"message"`
+}
+
+// SeverityName returns a readable severity
+func (d Diagnostic) SeverityName() string {
+	switch d.Severity {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityInformation:
+		return "info"
+	case SeverityHint:
+		return "hint"
+	}
+	return "error" // Servers may omit severity; treat it as an error
+}
+
+// publishDiagnosticsParams is the payload of textDocument/publishDiagnostics
+type publishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Version     *int         `json:"version,omitempty"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// TextEdit replaces a range of a document
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+// workspaceEdit is the result of textDocument/rename
+type workspaceEdit struct {
+	Changes         map[string][]TextEdit `json:"changes,omitempty"`
+	DocumentChanges []json.RawMessage     `json:"documentChanges,omitempty"`
+}
+
+// textDocumentEdit is one entry of workspaceEdit.documentChanges
+type textDocumentEdit struct {
+	TextDocument struct {
+		URI string `json:"uri"`
+	} `json:"textDocument"`
+	Edits []TextEdit `json:"edits"`
+	Kind  string     `json:"kind,omitempty"` // Set for create/rename/delete file operations
+}
+
+// hoverResult is the result of textDocument/hover
+type hoverResult struct {
+	Contents json.RawMessage `json:"contents"`
+}
+
+// PathToURI converts an absolute file path into a file:// URI
+func PathToURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+// URIToPath converts a file:// URI into a file path
+func URIToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("invalid URI %q: %w", uri, err)
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported URI scheme: %s", uri)
+	}
+	return filepath.FromSlash(u.Path), nil
+}
+
+// UTF16Offset converts a byte offset within a line into a UTF-16 character offset
+func UTF16Offset(line string, byteOffset int) int {
+	if byteOffset > len(line) {
+		byteOffset = len(line)
+	}
+	n := 0
+	for _, r := range line[:byteOffset] {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
+
+// ByteOffset converts a UTF-16 character offset within a line into a byte offset
+func ByteOffset(line string, character int) int {
+	n := 0
+	for i, r := range line {
+		if n >= character {
+			return i
+		}
+		n += utf16.RuneLen(r)
+	}
+	return len(line)
+}
+
+// ApplyEdits applies text edits to a document. Edits must not overlap.
+func ApplyEdits(content string, edits []TextEdit) (string, error) {
+	lineStarts := []int{0}
+	for i := 0; i < len(content); i++ {
+		if content[i] == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	offset := func(p Position) (int, error) {
+		if p.Line >= len(lineStarts) {
+			if p.Line == len(lineStarts) && p.Character == 0 {
+				return len(content), nil
+			}
+			return 0, fmt.Errorf("edit position %d:%d is past the end of the document", p.Line+1, p.Character+1)
+		}
+		start := lineStarts[p.Line]
+		end := len(content)
+		if p.Line+1 < len(lineStarts) {
+			end = lineStarts[p.Line+1]
+		}
+		return start + ByteOffset(strings.TrimRight(content[start:end], "\r\n"), p.Character), nil
+	}
+
+	type span struct {
+		start, end int
+		text       string
+	}
+	spans := make([]span, 0, len(edits))
+	for _, e := range edits {
+		start, err := offset(e.Range.Start)
+		if err != nil {
+			return "", err
+		}
+		end, err := offset(e.Range.End)
+		if err != nil {
+			return "", err
+		}
+		if end < start {
+			return "", fmt.Errorf("invalid edit range")
+		}
+		spans = append(spans, span{start, end, e.NewText})
+	}
+
+	// Apply from the end so earlier offsets stay valid
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].start > spans[j].start })
+	for i := 1; i < len(spans); i++ {
+		if spans[i].end > spans[i-1].start {
+			return "", fmt.Errorf("overlapping edits")
+		}
+	}
+	for _, s := range spans {
+		content = content[:s.start] + s.text + content[s.end:]
+	}
+	if !utf8.ValidString(content) {
+		return "", fmt.Errorf("edits produced invalid UTF-8")
+	}
+	return content, nil
+}
+
+// hoverText flattens the several shapes of hover contents into plain text
+func hoverText(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+
+	// MarkupContent or MarkedString with a language
+	var markup struct {
+		Kind     string `json:"kind"`
+		Language string `json:"language"`
+		Value    string `json:"value"`
+	}
+	if json.Unmarshal(raw, &markup) == nil && markup.Value != "" {
+		if markup.Language != "" {
+			return "```" + markup.Language + "\n" + markup.Value + "\n```"
+		}
+		return markup.Value
+	}
+
+	// Plain MarkedString
+	var text string
+	if json.Unmarshal(raw, &text) == nil {
+		return text
+	}
+
+	// Array of MarkedStrings
+	var parts []json.RawMessage
+	if json.Unmarshal(raw, &parts) == nil {
+		var texts []string
+		for _, part := range parts {
+			if t := hoverText(part); t != "" {
+				texts = append(texts, t)
+			}
+		}
+		return strings.Join(texts, "\n\n")
+	}
+	return ""
+}
+
+// decodeLocations decodes a definition result: Location, []Location or []LocationLink
+func decodeLocations(raw json.RawMessage) []Location {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+
+	var single Location
+	if json.Unmarshal(raw, &single) == nil && single.URI != "" {
+		return []Location{single}
+	}
+
+	var items []json.RawMessage
+	if json.Unmarshal(raw, &items) != nil {
+		return nil
+	}
+	var locations []Location
+	for _, item := range items {
+		var link locationLink
+		if json.Unmarshal(item, &link) == nil && link.TargetURI != "" {
+			locations = append(locations, Location{URI: link.TargetURI, Range: link.TargetSelectionRange})
+			continue
+		}
+		var loc Location
+		if json.Unmarshal(item, &loc) == nil && loc.URI != "" {
+			locations = append(locations, loc)
+		}
+	}
+	return locations
+}
@@ -0,0 +1,200 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// httpTransport speaks the Streamable HTTP transport: every message is a
+// POST, answered with JSON or with a stream of server-sent events that ends
+// with the response
+type httpTransport struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+
+	mu              sync.Mutex
+	nextID          int64
+	sessionID       string // Mcp-Session-Id assigned by the server
+	protocolVersion string // Negotiated in initialize
+}
+
+// newHTTPTransport connects to a server URL; headers are sent with every
+// request, e.g. for authorization
+func newHTTPTransport(url string, headers map[string]string) *httpTransport {
+	return &httpTransport{
+		url:     url,
+		headers: headers,
+		// Local servers only; never go through a proxy
+		client: &http.Client{Transport: &http.Transport{Proxy: nil}},
+	}
+}
+
+func (t *httpTransport) call(ctx context.Context, req request) (*message, error) {
+	t.mu.Lock()
+	t.nextID++
+	id := t.nextID
+	t.mu.Unlock()
+	req.ID = &id
+
+	resp, err := t.post(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var msg *message
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		msg = &message{}
+		if err := json.NewDecoder(resp.Body).Decode(msg); err != nil {
+			return nil, fmt.Errorf("failed to decode %s response: %w", req.Method, err)
+		}
+	case "text/event-stream":
+		if msg, err = t.readEvents(ctx, resp.Body, id); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unexpected content type %q in %s response", mediaType, req.Method)
+	}
+
+	if req.Method == "initialize" && msg.Error == nil {
+		var result struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		json.Unmarshal(msg.Result, &result)
+		t.mu.Lock()
+		if sessionID := resp.Header.Get("Mcp-Session-Id"); sessionID != "" {
+			t.sessionID = sessionID
+		}
+		t.protocolVersion = result.ProtocolVersion
+		t.mu.Unlock()
+	}
+	return msg, nil
+}
+
+func (t *httpTransport) notify(ctx context.Context, req request) error {
+	resp, err := t.post(ctx, req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// close ends the session; servers that do not support it answer 405
+func (t *httpTransport) close() error {
+	t.mu.Lock()
+	sessionID := t.sessionID
+	t.mu.Unlock()
+	if sessionID == "" {
+		return nil
+	}
+	req, err := http.NewRequest(http.MethodDelete, t.url, nil)
+	if err != nil {
+		return err
+	}
+	t.setHeaders(req)
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// post sends a message and checks the status
+func (t *httpTransport) post(ctx context.Context, v interface{}) (*http.Response, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode message: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	t.setHeaders(req)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		if resp.StatusCode == http.StatusNotFound && t.hasSession() {
+			return nil, fmt.Errorf("the server ended the session (404)")
+		}
+		return nil, fmt.Errorf("server returned %s: %s", resp.Status, strings.TrimSpace(string(text)))
+	}
+	return resp, nil
+}
+
+// setHeaders adds the configured headers and the session headers
+func (t *httpTransport) setHeaders(req *http.Request) {
+	for name, value := range t.headers {
+		req.Header.Set(name, value)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.sessionID != "" {
+		req.Header.Set("Mcp-Session-Id", t.sessionID)
+	}
+	if t.protocolVersion != "" {
+		req.Header.Set("MCP-Protocol-Version", t.protocolVersion)
+	}
+}
+
+// hasSession reports whether the server assigned a session
+func (t *httpTransport) hasSession() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.sessionID != ""
+}
+
+// readEvents reads server-sent events until the response to id arrives,
+// answering requests the server makes in the meantime
+func (t *httpTransport) readEvents(ctx context.Context, body io.Reader, id int64) (*message, error) {
+	reader := bufio.NewReader(body)
+	var data strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		if strings.HasPrefix(line, "data:") {
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+		// A blank line, or the end of the stream, ends an event
+		if (line == "" || err != nil) && data.Len() > 0 {
+			var msg message
+			if json.Unmarshal([]byte(data.String()), &msg) == nil {
+				switch {
+				case msg.Method != "" && len(msg.ID) > 0:
+					if resp, err := t.post(ctx, handleServerRequest(&msg)); err == nil {
+						resp.Body.Close()
+					}
+				case msg.Method == "" && strings.Trim(string(msg.ID), `"`) == strconv.FormatInt(id, 10):
+					return &msg, nil
+				}
+			}
+			data.Reset()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("event stream ended before the response: %w", err)
+		}
+	}
+}
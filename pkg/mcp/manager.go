@@ -0,0 +1,214 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/axon/pkg/project"
+)
+
+// maxToolName is the longest tool name the chat completions API accepts
+const maxToolName = 64
+
+// ServerTool is a tool offered to the model under a name that is unique
+// across servers
+type ServerTool struct {
+	Tool
+	Server        string // Server name from the configuration
+	QualifiedName string // mcp_<server>_<tool>
+}
+
+// Manager runs the configured servers and routes tool calls to them
+type Manager struct {
+	root    string
+	servers []project.MCPServer
+	timeout time.Duration
+
+	mu      sync.Mutex
+	clients map[string]*Client
+	tools   map[string]ServerTool // Qualified name -> tool
+	failed  map[string]error      // Server name -> why it is unavailable
+}
+
+// NewManager creates a manager for the servers configured in .axon.yml;
+// timeout bounds starting a server and each call
+func NewManager(root string, servers []project.MCPServer, timeout time.Duration) *Manager {
+	return &Manager{
+		root:    root,
+		servers: servers,
+		timeout: timeout,
+		clients: make(map[string]*Client),
+		tools:   make(map[string]ServerTool),
+		failed:  make(map[string]error),
+	}
+}
+
+// Start connects to every enabled server in parallel and lists its tools.
+// Servers that fail are left out and reported by Failed.
+func (m *Manager) Start(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, server := range m.servers {
+		if server.Disabled {
+			continue
+		}
+		wg.Add(1)
+		go func(server project.MCPServer) {
+			defer wg.Done()
+			client, tools, err := m.connect(ctx, server)
+
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			if err != nil {
+				m.failed[server.Name] = err
+				return
+			}
+			m.clients[server.Name] = client
+			for _, tool := range tools {
+				name := qualifiedName(server.Name, tool.Name)
+				if _, taken := m.tools[name]; taken {
+					continue
+				}
+				m.tools[name] = ServerTool{Tool: tool, Server: server.Name, QualifiedName: name}
+			}
+		}(server)
+	}
+	wg.Wait()
+}
+
+// connect starts one server, initializes it and lists its tools
+func (m *Manager) connect(ctx context.Context, server project.MCPServer) (*Client, []Tool, error) {
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	var t transport
+	switch {
+	case server.URL != "" && len(server.Command) > 0:
+		return nil, nil, fmt.Errorf("set either command or url, not both")
+	case server.URL != "":
+		headers := make(map[string]string, len(server.Headers))
+		for name, value := range server.Headers {
+			headers[name] = os.ExpandEnv(value)
+		}
+		t = newHTTPTransport(server.URL, headers)
+	case len(server.Command) > 0:
+		env := os.Environ()
+		for name, value := range server.Env {
+			env = append(env, name+"="+os.ExpandEnv(value))
+		}
+		stdio, err := startStdio(server.Command, m.root, env)
+		if err != nil {
+			return nil, nil, err
+		}
+		t = stdio
+	default:
+		return nil, nil, fmt.Errorf("no command or url configured")
+	}
+
+	client, err := newClient(ctx, server.Name, t)
+	if err != nil {
+		t.close()
+		return nil, nil, err
+	}
+	tools, err := client.ListTools(ctx)
+	if err != nil {
+		client.Close()
+		return nil, nil, fmt.Errorf("tools/list failed: %w", err)
+	}
+	return client, tools, nil
+}
+
+// Tools returns the tools of all running servers, sorted by name
+func (m *Manager) Tools() []ServerTool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	tools := make([]ServerTool, 0, len(m.tools))
+	for _, tool := range m.tools {
+		tools = append(tools, tool)
+	}
+	sort.Slice(tools, func(i, j int) bool { return tools[i].QualifiedName < tools[j].QualifiedName })
+	return tools
+}
+
+// Lookup finds a tool by its qualified name
+func (m *Manager) Lookup(name string) (ServerTool, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	tool, ok := m.tools[name]
+	return tool, ok
+}
+
+// Servers returns the running servers' names with their tool counts
+func (m *Manager) Servers() map[string]int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	counts := make(map[string]int, len(m.clients))
+	for name := range m.clients {
+		counts[name] = 0
+	}
+	for _, tool := range m.tools {
+		counts[tool.Server]++
+	}
+	return counts
+}
+
+// Failed returns the servers that could not be started and why
+func (m *Manager) Failed() map[string]error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	failed := make(map[string]error, len(m.failed))
+	for name, err := range m.failed {
+		failed[name] = err
+	}
+	return failed
+}
+
+// Call calls a tool by its qualified name
+func (m *Manager) Call(ctx context.Context, name string, args map[string]interface{}) (*CallResult, error) {
+	m.mu.Lock()
+	tool, ok := m.tools[name]
+	client := m.clients[tool.Server]
+	m.mu.Unlock()
+	if !ok || client == nil {
+		return nil, fmt.Errorf("unknown MCP tool %s", name)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+	result, err := client.CallTool(ctx, tool.Name, args)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, fmt.Errorf("%s did not answer within %s", tool.Server, m.timeout)
+	}
+	return result, err
+}
+
+// Close stops every server
+func (m *Manager) Close() {
+	m.mu.Lock()
+	clients := m.clients
+	m.clients = make(map[string]*Client)
+	m.tools = make(map[string]ServerTool)
+	m.mu.Unlock()
+	for _, client := range clients {
+		client.Close()
+	}
+}
+
+// invalidNameChars matches characters not allowed in tool names
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// qualifiedName names a server's tool for the model: mcp_<server>_<tool>,
+// limited to the characters and length function names allow
+func qualifiedName(server, tool string) string {
+	name := "mcp_" + invalidNameChars.ReplaceAllString(server, "_") + "_" + invalidNameChars.ReplaceAllString(tool, "_")
+	if len(name) > maxToolName {
+		name = name[:maxToolName]
+	}
+	return strings.TrimRight(name, "_")
+}
@@ -0,0 +1,254 @@
+// Package mcp speaks the Model Context Protocol. As a client it starts the
+// MCP servers configured in .axon.yml, over stdio or HTTP, performs the
+// initialize handshake, lists their tools and calls them on the model's
+// behalf. As a server it offers axon's own tools to other MCP clients over
+// stdio.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ProtocolVersion is the MCP revision the client speaks; servers answer with
+// the revision they chose
+const ProtocolVersion = "2025-06-18"
+
+// Version is the axon version reported to MCP servers and clients
+const Version = "0.1.0"
+
+// ResponseError is a JSON-RPC error returned by a server
+type ResponseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+// message is any incoming JSON-RPC message: request, notification or response
+type message struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *ResponseError  `json:"error,omitempty"`
+}
+
+// request is a request or notification sent to a server
+type request struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      *int64      `json:"id,omitempty"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// response answers a request a server sent to the client
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *ResponseError  `json:"error,omitempty"`
+}
+
+// transport carries JSON-RPC messages to one server
+type transport interface {
+	// call sends a request and waits for its response
+	call(ctx context.Context, req request) (*message, error)
+	// notify sends a notification
+	notify(ctx context.Context, req request) error
+	close() error
+}
+
+// Tool is a tool a server offers
+type Tool struct {
+	Name        string                 `json:"name"`
+	Title       string                 `json:"title,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+	Annotations *ToolAnnotations       `json:"annotations,omitempty"`
+}
+
+// ToolAnnotations are the server's hints about a tool. They are not trusted
+// for permissions.
+type ToolAnnotations struct {
+	ReadOnlyHint    bool `json:"readOnlyHint,omitempty"`
+	DestructiveHint bool `json:"destructiveHint,omitempty"`
+}
+
+// Content is one item of a tool result
+type Content struct {
+	Type     string    `json:"type"` // text, image, audio, resource or resource_link
+	Text     string    `json:"text,omitempty"`
+	Data     string    `json:"data,omitempty"` // Base64, for images and audio
+	MimeType string    `json:"mimeType,omitempty"`
+	URI      string    `json:"uri,omitempty"` // For resource links
+	Name     string    `json:"name,omitempty"`
+	Resource *Resource `json:"resource,omitempty"` // For embedded resources
+}
+
+// Resource is an embedded resource in a tool result
+type Resource struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+}
+
+// CallResult is the result of a tool call
+type CallResult struct {
+	Content           []Content       `json:"content"`
+	StructuredContent json.RawMessage `json:"structuredContent,omitempty"`
+	IsError           bool            `json:"isError,omitempty"`
+}
+
+// Text renders the result for the model: text content as is, other content
+// as short placeholders, and structured content as JSON when there is no
+// text
+func (r *CallResult) Text() string {
+	var parts []string
+	for _, c := range r.Content {
+		switch c.Type {
+		case "text":
+			parts = append(parts, c.Text)
+		case "image", "audio":
+			parts = append(parts, fmt.Sprintf("[%s %s, %d bytes base64]", c.Type, c.MimeType, len(c.Data)))
+		case "resource_link":
+			parts = append(parts, fmt.Sprintf("[resource %s %s]", c.Name, c.URI))
+		case "resource":
+			if c.Resource != nil && c.Resource.Text != "" {
+				parts = append(parts, c.Resource.Text)
+			} else if c.Resource != nil {
+				parts = append(parts, fmt.Sprintf("[resource %s %s]", c.Resource.URI, c.Resource.MimeType))
+			}
+		}
+	}
+	if len(parts) == 0 && len(r.StructuredContent) > 0 {
+		return string(r.StructuredContent)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// ServerInfo identifies a server after initialization
+type ServerInfo struct {
+	Name            string `json:"name"`
+	Version         string `json:"version"`
+	ProtocolVersion string `json:"-"`
+	Instructions    string `json:"-"`
+}
+
+// Client is an initialized connection to one server
+type Client struct {
+	name string
+	t    transport
+	info ServerInfo
+}
+
+// newClient performs the initialize handshake over a transport
+func newClient(ctx context.Context, name string, t transport) (*Client, error) {
+	c := &Client{name: name, t: t}
+	var result struct {
+		ProtocolVersion string     `json:"protocolVersion"`
+		ServerInfo      ServerInfo `json:"serverInfo"`
+		Instructions    string     `json:"instructions"`
+	}
+	params := map[string]interface{}{
+		"protocolVersion": ProtocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo":      map[string]interface{}{"name": "axon", "version": Version},
+	}
+	if err := c.call(ctx, "initialize", params, &result); err != nil {
+		return nil, fmt.Errorf("initialize failed: %w", err)
+	}
+	c.info = result.ServerInfo
+	c.info.ProtocolVersion = result.ProtocolVersion
+	c.info.Instructions = result.Instructions
+	if err := t.notify(ctx, request{JSONRPC: "2.0", Method: "notifications/initialized"}); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Name returns the server name from the configuration
+func (c *Client) Name() string {
+	return c.name
+}
+
+// Info returns what the server reported about itself
+func (c *Client) Info() ServerInfo {
+	return c.info
+}
+
+// ListTools lists the server's tools, following pagination
+func (c *Client) ListTools(ctx context.Context) ([]Tool, error) {
+	var tools []Tool
+	cursor := ""
+	for {
+		params := map[string]interface{}{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+		var result struct {
+			Tools      []Tool `json:"tools"`
+			NextCursor string `json:"nextCursor"`
+		}
+		if err := c.call(ctx, "tools/list", params, &result); err != nil {
+			return nil, err
+		}
+		tools = append(tools, result.Tools...)
+		if result.NextCursor == "" || result.NextCursor == cursor {
+			return tools, nil
+		}
+		cursor = result.NextCursor
+	}
+}
+
+// CallTool calls a tool. A tool that fails reports it in the result
+// (IsError); the error is for protocol and transport failures.
+func (c *Client) CallTool(ctx context.Context, name string, args map[string]interface{}) (*CallResult, error) {
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	var result CallResult
+	if err := c.call(ctx, "tools/call", map[string]interface{}{"name": name, "arguments": args}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Close shuts the connection down and stops the server process
+func (c *Client) Close() error {
+	return c.t.close()
+}
+
+// call sends a request and decodes the result
+func (c *Client) call(ctx context.Context, method string, params, result interface{}) error {
+	resp, err := c.t.call(ctx, request{JSONRPC: "2.0", Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if result != nil && len(resp.Result) > 0 {
+		if err := json.Unmarshal(resp.Result, result); err != nil {
+			return fmt.Errorf("failed to decode %s response: %w", method, err)
+		}
+	}
+	return nil
+}
+
+// handleServerRequest answers a request a server sent to the client. Only
+// ping is supported; the client declares no other capabilities.
+func handleServerRequest(msg *message) response {
+	resp := response{JSONRPC: "2.0", ID: msg.ID}
+	if msg.Method == "ping" {
+		resp.Result = map[string]interface{}{}
+	} else {
+		resp.Error = &ResponseError{Code: -32601, Message: "method not found: " + msg.Method}
+	}
+	return resp
+}
@@ -0,0 +1,299 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/axon/pkg/project"
+)
+
+// TestMain runs the fake server when the tests start themselves as one
+func TestMain(m *testing.M) {
+	if os.Getenv("AXON_MCP_TEST_SERVER") == "1" {
+		serveStdio(os.Stdin, os.Stdout)
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// fakeResult answers a request like a small ticketing server: two pages of
+// tools and an echo tool that fails on "boom"
+func fakeResult(method string, params json.RawMessage) (interface{}, *ResponseError) {
+	switch method {
+	case "initialize":
+		return map[string]interface{}{
+			"protocolVersion": ProtocolVersion,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]interface{}{"name": "tickets", "version": "1.0"},
+		}, nil
+	case "tools/list":
+		var p struct {
+			Cursor string `json:"cursor"`
+		}
+		json.Unmarshal(params, &p)
+		if p.Cursor == "" {
+			return map[string]interface{}{
+				"tools":      []interface{}{map[string]interface{}{"name": "get_ticket", "description": "Get a ticket", "inputSchema": map[string]interface{}{"type": "object"}}},
+				"nextCursor": "2",
+			}, nil
+		}
+		return map[string]interface{}{
+			"tools": []interface{}{map[string]interface{}{"name": "echo", "inputSchema": map[string]interface{}{"type": "object"}}},
+		}, nil
+	case "tools/call":
+		var p struct {
+			Name      string                 `json:"name"`
+			Arguments map[string]interface{} `json:"arguments"`
+		}
+		json.Unmarshal(params, &p)
+		text := fmt.Sprint(p.Arguments["text"])
+		return map[string]interface{}{
+			"content": []interface{}{map[string]interface{}{"type": "text", "text": p.Name + ": " + text}},
+			"isError": text == "boom",
+		}, nil
+	}
+	return nil, &ResponseError{Code: -32601, Message: "method not found"}
+}
+
+// serveStdio is a fake stdio server. Before answering tools/call it pings
+// the client and writes a log line to stdout, as careless servers do.
+func serveStdio(in io.Reader, out io.Writer) {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		var msg message
+		if json.Unmarshal(scanner.Bytes(), &msg) != nil || len(msg.ID) == 0 {
+			continue
+		}
+		if msg.Method == "" {
+			continue // The client's answer to our ping
+		}
+		if msg.Method == "tools/call" {
+			fmt.Fprintln(out, "calling tool...")
+			fmt.Fprintln(out, `{"jsonrpc":"2.0","id":"ping-1","method":"ping"}`)
+		}
+		result, rpcErr := fakeResult(msg.Method, msg.Params)
+		resp, _ := json.Marshal(response{JSONRPC: "2.0", ID: msg.ID, Result: result, Error: rpcErr})
+		fmt.Fprintf(out, "%s\n", resp)
+	}
+}
+
+func TestStdioClient(t *testing.T) {
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	go serveStdio(serverIn, serverOut)
+
+	ctx := context.Background()
+	client, err := newClient(ctx, "tickets", newStdioTransport(clientIn, clientOut))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if client.Info().Name != "tickets" || client.Info().ProtocolVersion != ProtocolVersion {
+		t.Errorf("unexpected server info %+v", client.Info())
+	}
+
+	tools, err := client.ListTools(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tools) != 2 || tools[0].Name != "get_ticket" || tools[1].Name != "echo" {
+		t.Fatalf("expected both pages of tools, got %+v", tools)
+	}
+
+	result, err := client.CallTool(ctx, "echo", map[string]interface{}{"text": "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.IsError || result.Text() != "echo: hi" {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if result, err = client.CallTool(ctx, "echo", map[string]interface{}{"text": "boom"}); err != nil || !result.IsError {
+		t.Errorf("expected a tool error, got %+v, %v", result, err)
+	}
+}
+
+func TestManagerStdio(t *testing.T) {
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager(t.TempDir(), []project.MCPServer{
+		{Name: "tickets", Command: []string{executable}, Env: map[string]string{"AXON_MCP_TEST_SERVER": "1"}},
+		{Name: "broken", Command: []string{"/nonexistent/mcp-server"}},
+		{Name: "off", Command: []string{"/nonexistent/mcp-server"}, Disabled: true},
+	}, 10*time.Second)
+	m.Start(context.Background())
+	defer m.Close()
+
+	if failed := m.Failed(); len(failed) != 1 || failed["broken"] == nil {
+		t.Errorf("expected only broken to fail, got %v", failed)
+	}
+	tools := m.Tools()
+	if len(tools) != 2 || tools[0].QualifiedName != "mcp_tickets_echo" || tools[1].QualifiedName != "mcp_tickets_get_ticket" {
+		t.Fatalf("unexpected tools %+v", tools)
+	}
+	if servers := m.Servers(); servers["tickets"] != 2 {
+		t.Errorf("unexpected servers %v", servers)
+	}
+
+	result, err := m.Call(context.Background(), "mcp_tickets_get_ticket", map[string]interface{}{"text": "T-1"})
+	if err != nil || result.Text() != "get_ticket: T-1" {
+		t.Errorf("unexpected call result %+v, %v", result, err)
+	}
+	if _, err := m.Call(context.Background(), "mcp_tickets_missing", nil); err == nil {
+		t.Error("expected an error for an unknown tool")
+	}
+}
+
+func TestHTTPClient(t *testing.T) {
+	var sessionHeaders []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var msg message
+		json.NewDecoder(r.Body).Decode(&msg)
+		sessionHeaders = append(sessionHeaders, r.Header.Get("Mcp-Session-Id"))
+		if len(msg.ID) == 0 {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		result, rpcErr := fakeResult(msg.Method, msg.Params)
+		resp, _ := json.Marshal(response{JSONRPC: "2.0", ID: msg.ID, Result: result, Error: rpcErr})
+		switch msg.Method {
+		case "initialize":
+			w.Header().Set("Mcp-Session-Id", "abc")
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(resp)
+		default:
+			// Stream a progress notification before the response
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", `{"jsonrpc":"2.0","method":"notifications/progress","params":{}}`)
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", resp)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("TICKETS_TOKEN", "secret")
+	m := NewManager(t.TempDir(), []project.MCPServer{
+		{Name: "tickets mirror", URL: server.URL, Headers: map[string]string{"Authorization": "Bearer ${TICKETS_TOKEN}"}},
+	}, 10*time.Second)
+	m.Start(context.Background())
+	defer m.Close()
+	if failed := m.Failed(); len(failed) > 0 {
+		t.Fatalf("unexpected failures %v", failed)
+	}
+
+	result, err := m.Call(context.Background(), "mcp_tickets_mirror_echo", map[string]interface{}{"text": "hi"})
+	if err != nil || result.Text() != "echo: hi" {
+		t.Fatalf("unexpected call result %+v, %v", result, err)
+	}
+	// Every request after initialize carries the session
+	if got := strings.Join(sessionHeaders, ","); got != ",abc,abc,abc,abc" {
+		t.Errorf("unexpected session headers %q", got)
+	}
+}
+
+func TestQualifiedName(t *testing.T) {
+	if got := qualifiedName("db.prod", "run query"); got != "mcp_db_prod_run_query" {
+		t.Errorf("got %q", got)
+	}
+	if got := qualifiedName("tickets", strings.Repeat("x", 100)); len(got) != maxToolName {
+		t.Errorf("name not shortened: %q", got)
+	}
+}
+
+func TestCallResultText(t *testing.T) {
+	r := &CallResult{Content: []Content{
+		{Type: "text", Text: "first"},
+		{Type: "image", MimeType: "image/png", Data: "aGk="},
+		{Type: "resource", Resource: &Resource{URI: "file:///a", Text: "embedded"}},
+	}}
+	if got := r.Text(); got != "first\n[image image/png, 4 bytes base64]\nembedded" {
+		t.Errorf("got %q", got)
+	}
+	r = &CallResult{StructuredContent: json.RawMessage(`{"id":1}`)}
+	if got := r.Text(); got != `{"id":1}` {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestServer(t *testing.T) {
+	server := NewServer("axon", Version, "Use echo.")
+	server.AddTool(Tool{Name: "echo", Description: "Echo text"}, func(ctx context.Context, args map[string]interface{}) (string, error) {
+		if args["text"] == "boom" {
+			return "", fmt.Errorf("boom")
+		}
+		return fmt.Sprint(args["text"]), nil
+	})
+	server.AddTool(Tool{Name: "wait"}, func(ctx context.Context, args map[string]interface{}) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Serve(context.Background(), serverIn, serverOut)
+		serverOut.Close()
+	}()
+
+	ctx := context.Background()
+	client, err := newClient(ctx, "axon", newStdioTransport(clientIn, clientOut))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info := client.Info(); info.Name != "axon" || info.Instructions != "Use echo." {
+		t.Errorf("unexpected server info %+v", info)
+	}
+
+	tools, err := client.ListTools(ctx)
+	if err != nil || len(tools) != 2 || tools[0].Name != "echo" || tools[1].InputSchema["type"] != "object" {
+		t.Fatalf("unexpected tools %+v, %v", tools, err)
+	}
+	result, err := client.CallTool(ctx, "echo", map[string]interface{}{"text": "hi"})
+	if err != nil || result.IsError || result.Text() != "hi" {
+		t.Errorf("unexpected result %+v, %v", result, err)
+	}
+	if result, err = client.CallTool(ctx, "echo", map[string]interface{}{"text": "boom"}); err != nil || !result.IsError || result.Text() != "boom" {
+		t.Errorf("expected a tool error, got %+v, %v", result, err)
+	}
+	if _, err := client.CallTool(ctx, "missing", nil); err == nil {
+		t.Error("expected an error for an unknown tool")
+	}
+
+	// A cancelled call is abandoned by the client and stopped on the server
+	waitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.CallTool(waitCtx, "wait", nil); err == nil {
+		t.Error("expected the wait call to be cancelled")
+	}
+	if result, err = client.CallTool(ctx, "echo", map[string]interface{}{"text": "after"}); err != nil || result.Text() != "after" {
+		t.Errorf("server stuck after a cancelled call: %+v, %v", result, err)
+	}
+
+	clientOut.Close()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Serve returned %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return when its input ended")
+	}
+}
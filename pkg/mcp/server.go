@@ -0,0 +1,216 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// supportedVersions are the protocol revisions the server accepts from a
+// client; other requests are answered with ProtocolVersion
+var supportedVersions = []string{ProtocolVersion, "2025-03-26", "2024-11-05"}
+
+// maxMessage is the largest message the server reads
+const maxMessage = 16 * 1024 * 1024
+
+// Handler runs a tool call. An error is reported to the client as a tool
+// result with isError set, so the calling model can see it.
+type Handler func(ctx context.Context, args map[string]interface{}) (string, error)
+
+// Server offers tools to an MCP client over stdio
+type Server struct {
+	name         string
+	version      string
+	instructions string
+	tools        []Tool
+	handlers     map[string]Handler
+
+	writeMu sync.Mutex
+	w       io.Writer
+
+	callMu  sync.Mutex // Tool calls run one at a time
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc // Request ID -> cancels the running call
+}
+
+// NewServer creates a server that introduces itself with a name, a version
+// and instructions for the client's model
+func NewServer(name, version, instructions string) *Server {
+	return &Server{
+		name:         name,
+		version:      version,
+		instructions: instructions,
+		handlers:     make(map[string]Handler),
+		cancels:      make(map[string]context.CancelFunc),
+	}
+}
+
+// AddTool offers a tool; tools are listed in the order they were added
+func (s *Server) AddTool(tool Tool, handler Handler) {
+	if tool.InputSchema == nil {
+		tool.InputSchema = map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+	}
+	s.tools = append(s.tools, tool)
+	s.handlers[tool.Name] = handler
+}
+
+// Serve reads newline-delimited JSON-RPC messages from r and writes the
+// responses to w until r ends or ctx is cancelled. Tool calls run in the
+// background so that pings and cancellations are answered meanwhile.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	s.w = w
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), maxMessage)
+		for scanner.Scan() {
+			line := append([]byte(nil), scanner.Bytes()...)
+			select {
+			case lines <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+		readErr <- scanner.Err()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-readErr:
+			return err
+		case line := <-lines:
+			if len(strings.TrimSpace(string(line))) == 0 {
+				continue
+			}
+			var msg message
+			if err := json.Unmarshal(line, &msg); err != nil {
+				s.write(response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &ResponseError{Code: -32700, Message: "parse error"}})
+				continue
+			}
+			if msg.Method == "tools/call" && len(msg.ID) > 0 {
+				callCtx, cancelCall := context.WithCancel(ctx)
+				s.mu.Lock()
+				s.cancels[string(msg.ID)] = cancelCall
+				s.mu.Unlock()
+				wg.Add(1)
+				go func(msg message) {
+					defer wg.Done()
+					s.write(s.callTool(callCtx, &msg))
+					s.mu.Lock()
+					delete(s.cancels, string(msg.ID))
+					s.mu.Unlock()
+					cancelCall()
+				}(msg)
+				continue
+			}
+			if resp, ok := s.handle(&msg); ok {
+				s.write(resp)
+			}
+		}
+	}
+}
+
+// handle answers every message except tool calls; notifications and
+// responses get no answer
+func (s *Server) handle(msg *message) (response, bool) {
+	switch {
+	case msg.Method == "notifications/cancelled":
+		var params struct {
+			RequestID json.RawMessage `json:"requestId"`
+		}
+		json.Unmarshal(msg.Params, &params)
+		s.mu.Lock()
+		if cancel := s.cancels[string(params.RequestID)]; cancel != nil {
+			cancel()
+		}
+		s.mu.Unlock()
+		return response{}, false
+	case len(msg.ID) == 0 || msg.Method == "":
+		return response{}, false
+	}
+
+	resp := response{JSONRPC: "2.0", ID: msg.ID}
+	switch msg.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		json.Unmarshal(msg.Params, &params)
+		version := ProtocolVersion
+		for _, v := range supportedVersions {
+			if v == params.ProtocolVersion {
+				version = v
+			}
+		}
+		result := map[string]interface{}{
+			"protocolVersion": version,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]interface{}{"name": s.name, "version": s.version},
+		}
+		if s.instructions != "" {
+			result["instructions"] = s.instructions
+		}
+		resp.Result = result
+	case "ping":
+		resp.Result = map[string]interface{}{}
+	case "tools/list":
+		resp.Result = map[string]interface{}{"tools": s.tools}
+	default:
+		resp.Error = &ResponseError{Code: -32601, Message: "method not found: " + msg.Method}
+	}
+	return resp, true
+}
+
+// callTool runs a tools/call request
+func (s *Server) callTool(ctx context.Context, msg *message) response {
+	resp := response{JSONRPC: "2.0", ID: msg.ID}
+	var params struct {
+		Name      string                 `json:"name"`
+		Arguments map[string]interface{} `json:"arguments"`
+	}
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		resp.Error = &ResponseError{Code: -32602, Message: "invalid params: " + err.Error()}
+		return resp
+	}
+	handler := s.handlers[params.Name]
+	if handler == nil {
+		resp.Error = &ResponseError{Code: -32602, Message: "unknown tool: " + params.Name}
+		return resp
+	}
+	if params.Arguments == nil {
+		params.Arguments = map[string]interface{}{}
+	}
+
+	s.callMu.Lock()
+	text, err := handler(ctx, params.Arguments)
+	s.callMu.Unlock()
+	if err != nil {
+		resp.Result = CallResult{Content: []Content{{Type: "text", Text: err.Error()}}, IsError: true}
+		return resp
+	}
+	resp.Result = CallResult{Content: []Content{{Type: "text", Text: text}}}
+	return resp
+}
+
+// write sends one message on its own line
+func (s *Server) write(resp response) {
+	body, err := json.Marshal(resp)
+	if err != nil {
+		body, _ = json.Marshal(response{JSONRPC: "2.0", ID: resp.ID, Error: &ResponseError{Code: -32603, Message: fmt.Sprintf("failed to encode result: %v", err)}})
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.w.Write(append(body, '\n'))
+}
@@ -0,0 +1,232 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// shutdownTimeout is how long a server gets to exit after its stdin is
+// closed before it is killed
+const shutdownTimeout = 2 * time.Second
+
+// maxStderr is the number of bytes of server stderr kept for error messages
+const maxStderr = 4096
+
+// stdioTransport exchanges newline-delimited JSON-RPC messages with a server
+// process over its stdin and stdout
+type stdioTransport struct {
+	w       io.WriteCloser
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	nextID  int64
+	pending map[int64]chan *message
+
+	done chan struct{}
+	err  error // Why the read loop stopped, valid after done is closed
+
+	cmd    *exec.Cmd   // nil for streams not backed by a process
+	stderr *tailBuffer // The end of the server's stderr
+}
+
+// startStdio starts a server process and connects to its stdio
+func startStdio(command []string, dir string, env []string) (*stdioTransport, error) {
+	if len(command) == 0 {
+		return nil, fmt.Errorf("no command configured")
+	}
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Dir = dir
+	cmd.Env = env
+	stderr := &tailBuffer{max: maxStderr}
+	cmd.Stderr = stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdin pipe: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", command[0], err)
+	}
+	t := newStdioTransport(stdout, stdin)
+	t.cmd = cmd
+	t.stderr = stderr
+	return t, nil
+}
+
+// newStdioTransport starts reading messages from r; requests are written to w
+func newStdioTransport(r io.Reader, w io.WriteCloser) *stdioTransport {
+	t := &stdioTransport{
+		w:       w,
+		pending: make(map[int64]chan *message),
+		done:    make(chan struct{}),
+	}
+	go t.readLoop(r)
+	return t
+}
+
+func (t *stdioTransport) call(ctx context.Context, req request) (*message, error) {
+	t.mu.Lock()
+	t.nextID++
+	id := t.nextID
+	ch := make(chan *message, 1)
+	t.pending[id] = ch
+	t.mu.Unlock()
+
+	defer func() {
+		t.mu.Lock()
+		delete(t.pending, id)
+		t.mu.Unlock()
+	}()
+
+	req.ID = &id
+	if err := t.write(req); err != nil {
+		return nil, err
+	}
+
+	select {
+	case resp := <-ch:
+		return resp, nil
+	case <-ctx.Done():
+		t.write(request{JSONRPC: "2.0", Method: "notifications/cancelled", Params: map[string]interface{}{
+			"requestId": id,
+			"reason":    ctx.Err().Error(),
+		}})
+		return nil, ctx.Err()
+	case <-t.done:
+		return nil, t.exitError()
+	}
+}
+
+func (t *stdioTransport) notify(ctx context.Context, req request) error {
+	return t.write(req)
+}
+
+// close closes the server's stdin, which asks it to exit, and kills it if
+// it does not
+func (t *stdioTransport) close() error {
+	err := t.w.Close()
+	if t.cmd == nil {
+		return err
+	}
+	exited := make(chan struct{})
+	go func() {
+		t.cmd.Wait()
+		close(exited)
+	}()
+	select {
+	case <-exited:
+	case <-time.After(shutdownTimeout):
+		t.cmd.Process.Kill()
+		<-exited
+	}
+	return nil
+}
+
+// exitError explains why the connection closed, with the end of stderr
+func (t *stdioTransport) exitError() error {
+	err := t.err
+	if errors.Is(err, io.EOF) {
+		err = errors.New("server closed the connection")
+	}
+	if t.stderr != nil {
+		if tail := strings.TrimSpace(t.stderr.String()); tail != "" {
+			return fmt.Errorf("%w: %s", err, tail)
+		}
+	}
+	return err
+}
+
+// write sends one message on its own line
+func (t *stdioTransport) write(v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	if _, err := t.w.Write(append(body, '\n')); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	return nil
+}
+
+// readLoop reads messages until the stream ends. Lines that are not JSON,
+// such as log output a server wrongly writes to stdout, are skipped.
+func (t *stdioTransport) readLoop(r io.Reader) {
+	reader := bufio.NewReader(r)
+	var err error
+	for {
+		var line []byte
+		line, err = reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			t.dispatch(line)
+		}
+		if err != nil {
+			break
+		}
+	}
+	t.err = err
+	close(t.done)
+}
+
+// dispatch routes one incoming message
+func (t *stdioTransport) dispatch(line []byte) {
+	var msg message
+	if json.Unmarshal(line, &msg) != nil {
+		return
+	}
+	switch {
+	case msg.Method != "" && len(msg.ID) > 0:
+		go t.write(handleServerRequest(&msg))
+	case msg.Method != "":
+		// Notifications (logging, progress, list changes) are not used
+	default:
+		id, err := strconv.ParseInt(strings.Trim(string(msg.ID), `"`), 10, 64)
+		if err != nil {
+			return
+		}
+		t.mu.Lock()
+		ch := t.pending[id]
+		t.mu.Unlock()
+		if ch != nil {
+			ch <- &msg
+		}
+	}
+}
+
+// tailBuffer keeps the last max bytes written to it
+type tailBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+	max int
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > b.max {
+		b.buf = b.buf[len(b.buf)-b.max:]
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.buf)
+}
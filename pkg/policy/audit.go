@@ -0,0 +1,63 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// AuditFile is the audit log, relative to the project root
+const AuditFile = ".axon/audit.log"
+
+// Entry is one line of the audit log
+type Entry struct {
+	Time     time.Time `json:"time"`
+	Tool     string    `json:"tool"`
+	Command  string    `json:"command,omitempty"`
+	Paths    []string  `json:"paths,omitempty"`
+	Target   string    `json:"target,omitempty"`
+	Decision string    `json:"decision"` // allow or deny
+	By       string    `json:"by"`       // rule, user, always (the user added an allow rule) or client (an MCP client confirmed it)
+	Rule     string    `json:"rule,omitempty"`
+}
+
+// Audit appends decisions to a JSON lines file
+type Audit struct {
+	mu   sync.Mutex
+	path string
+}
+
+// OpenAudit returns the audit log of a project; the file is created on the
+// first record
+func OpenAudit(projectRoot string) *Audit {
+	return &Audit{path: filepath.Join(projectRoot, AuditFile)}
+}
+
+// Record appends an entry, stamping the time if unset
+func (a *Audit) Record(entry Entry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(a.path), 0755); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(a.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
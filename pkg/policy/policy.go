@@ -0,0 +1,428 @@
+// Package policy decides whether a tool call runs without asking, is refused
+// or needs the user's confirmation. Rules come from the permissions section
+// of .axon.yml and .axon.local.yml, e.g. "execute: go test *" or
+// "write: src/**", and every decision is recorded in an audit log.
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Actions of a rule or decision
+const (
+	Allow = "allow"
+	Deny  = "deny"
+	Ask   = "ask"
+)
+
+// Groups are tool names that stand for several tools in rules
+var Groups = map[string][]string{
+	"write": {
+		"write_file", "create_file", "update_file", "string_replace", "create_directory",
+		"delete_file", "delete_directory", "move_file", "copy_file", "rename_symbol",
+	},
+}
+
+// Rule matches tool calls by tool name and, optionally, by a pattern on the
+// command (execute), the project-relative paths (write tools) or the target
+// (http_request)
+type Rule struct {
+	Tool    string // Tool name, a group from Groups, or a glob such as * or mcp_tickets_*
+	Pattern string // Empty matches every call of the tool
+	Action  string
+}
+
+// ParseRule parses "tool" or "tool: pattern"
+func ParseRule(rule, action string) (Rule, error) {
+	tool, pattern, _ := strings.Cut(rule, ":")
+	tool, pattern = strings.TrimSpace(tool), strings.TrimSpace(pattern)
+	if tool == "" || strings.ContainsAny(tool, " \t") {
+		return Rule{}, fmt.Errorf("invalid %s rule %q: expected \"tool\" or \"tool: pattern\"", action, rule)
+	}
+	return Rule{Tool: tool, Pattern: pattern, Action: action}, nil
+}
+
+// String formats the rule the way it is written in the config
+func (r Rule) String() string {
+	if r.Pattern == "" {
+		return r.Tool
+	}
+	return r.Tool + ": " + r.Pattern
+}
+
+// Request describes a tool call to authorize
+type Request struct {
+	Tool    string
+	Command string   // Shell command, for execute
+	Paths   []string // Project-relative paths the call writes, for write tools
+	Target  string   // Other subject rules match, e.g. "POST http://localhost:8000/api/users"
+}
+
+// Decision is the outcome of evaluating a request
+type Decision struct {
+	Action string // Allow, Deny or Ask
+	Rule   string // Rule that decided, empty when nothing matched
+}
+
+// Policy holds the rules of a project. Deny rules win over ask rules, which
+// win over allow rules; calls no rule matches are asked about.
+type Policy struct {
+	rules []Rule
+}
+
+// New builds a policy from rule lists. Invalid rules are skipped and
+// reported in the returned error; the policy is usable either way.
+func New(allow, deny, ask []string) (*Policy, error) {
+	p := &Policy{}
+	var errs []error
+	for _, list := range []struct {
+		action string
+		rules  []string
+	}{{Deny, deny}, {Ask, ask}, {Allow, allow}} {
+		for _, text := range list.rules {
+			rule, err := ParseRule(text, list.action)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			p.rules = append(p.rules, rule)
+		}
+	}
+	return p, errors.Join(errs...)
+}
+
+// Add adds a rule, e.g. after the user chose "always allow"
+func (p *Policy) Add(rule Rule) {
+	p.rules = append(p.rules, rule)
+}
+
+// Rules returns the rules, deny rules first
+func (p *Policy) Rules() []Rule {
+	return p.rules
+}
+
+// Evaluate decides a request. A command is split into its simple commands
+// (at ;, &&, ||, | and &) and a call with several paths is split per path:
+// the call is denied if any part is denied and allowed only if every part
+// is allowed.
+func (p *Policy) Evaluate(req Request) Decision {
+	var subjects []string
+	switch {
+	case req.Command != "":
+		subjects = SplitCommand(req.Command)
+	case len(req.Paths) > 0:
+		subjects = req.Paths
+	case req.Target != "":
+		subjects = []string{req.Target}
+	}
+	if len(subjects) == 0 {
+		subjects = []string{""}
+	}
+
+	decision := Decision{Action: Allow}
+	var allowedBy []string
+	for _, subject := range subjects {
+		d := p.evaluateSubject(req, subject)
+		switch d.Action {
+		case Deny:
+			return d
+		case Ask:
+			if decision.Action != Ask {
+				decision = d
+			}
+		case Allow:
+			if !slices.Contains(allowedBy, d.Rule) {
+				allowedBy = append(allowedBy, d.Rule)
+			}
+		}
+	}
+	if decision.Action == Allow {
+		decision.Rule = strings.Join(allowedBy, ", ")
+	}
+	return decision
+}
+
+// evaluateSubject decides one simple command or path
+func (p *Policy) evaluateSubject(req Request, subject string) Decision {
+	for _, action := range []string{Deny, Ask, Allow} {
+		for _, rule := range p.rules {
+			if rule.Action != action || !matchTool(rule.Tool, req.Tool) {
+				continue
+			}
+			if rule.Pattern != "" {
+				// A pattern never auto-allows a command whose effect it
+				// cannot see: substitutions or redirections to files
+				if action == Allow && req.Command != "" && !plainCommand(subject) {
+					continue
+				}
+				pattern, subject := rule.Pattern, subject
+				// Rules that restrict commands also match their spelling
+				// variants
+				if action != Allow && req.Command != "" {
+					pattern, subject = canonicalCommand(pattern), canonicalCommand(subject)
+				}
+				if !matchSubject(pattern, subject, len(req.Paths) == 0) {
+					continue
+				}
+			}
+			return Decision{Action: action, Rule: rule.String()}
+		}
+	}
+	return Decision{Action: Ask}
+}
+
+// SuggestRule proposes the rule added when the user answers "always allow":
+// the command name and subcommand for execute ("go test *", or the whole
+// command when a prefix would be too broad), the directory
+// for write tools ("write_file: src/**"), the target up to its first path
+// segment ("POST http://localhost:8000/api/*"), or the tool alone otherwise
+func SuggestRule(req Request) []Rule {
+	var rules []Rule
+	add := func(pattern string) {
+		rule := Rule{Tool: req.Tool, Pattern: pattern, Action: Allow}
+		for _, r := range rules {
+			if r == rule {
+				return
+			}
+		}
+		rules = append(rules, rule)
+	}
+	switch {
+	case req.Command != "":
+		for _, cmd := range SplitCommand(req.Command) {
+			add(commandPrefix(cmd))
+		}
+	case len(req.Paths) > 0:
+		for _, p := range req.Paths {
+			if dir := path.Dir(p); dir != "." {
+				add(dir + "/**")
+			} else {
+				add(p)
+			}
+		}
+	case req.Target != "":
+		add(targetPrefix(req.Target))
+	default:
+		add("")
+	}
+	return rules
+}
+
+// subcommandPattern matches words like "test", "install" or "make:model"
+var subcommandPattern = regexp.MustCompile(`^[a-z][a-z0-9:_-]*$`)
+
+// runners are programs that run a command, script or code given as their
+// arguments: a rule for their name would allow anything
+var runners = []string{
+	"sh", "bash", "zsh", "dash", "ksh", "fish", "env", "sudo", "doas", "su", "xargs",
+	"nohup", "nice", "time", "timeout", "watch", "exec", "eval", "command", "ssh",
+	"python", "python3", "node", "deno", "bun", "bunx", "npx", "pnpx", "php", "ruby",
+	"perl", "lua", "java", "uv", "uvx", "pipx", "docker", "podman", "kubectl",
+}
+
+// commandPrefix keeps the command name and a subcommand-looking second word,
+// so "go test ./..." suggests "go test *". Commands run through a runner, run
+// by path, or whose second word is a flag or a path are kept whole, as any
+// prefix of them would also allow unrelated commands.
+func commandPrefix(cmd string) string {
+	fields := strings.Fields(cmd)
+	if len(fields) == 0 {
+		return "*"
+	}
+	if strings.Contains(fields[0], "/") || slices.Contains(runners, fields[0]) ||
+		len(fields) == 1 || !subcommandPattern.MatchString(fields[1]) {
+		return strings.Join(fields, " ")
+	}
+	prefix := fields[0] + " " + fields[1]
+	if len(fields) == 2 {
+		return prefix
+	}
+	return prefix + " *"
+}
+
+// canonicalCommand rewrites a command, or a command pattern, for the rules
+// that restrict commands: leading runners that pass the rest through
+// unchanged and variable assignments are dropped, the program loses its
+// directory, and runs of short flags are merged with their letters sorted,
+// so "sudo /bin/rm -r -f /" is seen as "rm -fr /" like "rm -rf /" is
+func canonicalCommand(cmd string) string {
+	fields := strings.Fields(cmd)
+	for len(fields) > 1 && (slices.Contains(passThrough, fields[0]) || assignmentPattern.MatchString(fields[0])) {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return ""
+	}
+	out := []string{path.Base(fields[0])}
+	var flags []byte
+	flush := func() {
+		if len(flags) > 0 {
+			slices.Sort(flags)
+			out = append(out, "-"+string(slices.Compact(flags)))
+			flags = nil
+		}
+	}
+	for _, field := range fields[1:] {
+		if shortFlagsPattern.MatchString(field) {
+			flags = append(flags, field[1:]...)
+			continue
+		}
+		flush()
+		out = append(out, field)
+	}
+	flush()
+	return strings.Join(out, " ")
+}
+
+// passThrough are runners that run the rest of the command line as it is
+var passThrough = []string{"sudo", "doas", "env", "nohup", "nice", "time", "command", "exec"}
+
+var (
+	assignmentPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+	shortFlagsPattern = regexp.MustCompile(`^-[A-Za-z]+$`)
+)
+
+// targetPrefix keeps a target up to the first path segment of its URL
+func targetPrefix(target string) string {
+	scheme, rest, ok := strings.Cut(target, "://")
+	if !ok {
+		return target
+	}
+	segments := strings.SplitN(rest, "/", 3)
+	if len(segments) < 3 {
+		return target
+	}
+	return scheme + "://" + segments[0] + "/" + segments[1] + "/*"
+}
+
+// SplitCommand splits a shell command into simple commands at ;, &&, ||, |,
+// & and newlines outside quotes, with whitespace collapsed
+func SplitCommand(command string) []string {
+	var parts []string
+	var current strings.Builder
+	flush := func() {
+		if part := strings.Join(strings.Fields(current.String()), " "); part != "" {
+			parts = append(parts, part)
+		}
+		current.Reset()
+	}
+	var quote rune
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else if r == '\\' && quote == '"' && i+1 < len(runes) {
+				current.WriteRune(r)
+				i++
+				r = runes[i]
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '\\' && i+1 < len(runes):
+			current.WriteRune(r)
+			i++
+			r = runes[i]
+		case r == ';' || r == '\n' || r == '|':
+			flush()
+			if i+1 < len(runes) && r == '|' && runes[i+1] == '|' {
+				i++
+			}
+			continue
+		case r == '&':
+			// Keep redirections such as 2>&1 and &> intact
+			if (i > 0 && runes[i-1] == '>') || (i+1 < len(runes) && runes[i+1] == '>') {
+				break
+			}
+			flush()
+			if i+1 < len(runes) && runes[i+1] == '&' {
+				i++
+			}
+			continue
+		}
+		current.WriteRune(r)
+	}
+	flush()
+	return parts
+}
+
+// redirectPattern matches output redirections, except to /dev/null or
+// another file descriptor
+var redirectPattern = regexp.MustCompile(`\d*>>?\s*(&\d|/dev/null\b)?`)
+
+// plainCommand reports whether a simple command has no command substitution
+// and no output redirection to a file
+func plainCommand(cmd string) bool {
+	if strings.Contains(cmd, "$(") || strings.Contains(cmd, "`") || strings.Contains(cmd, "<(") || strings.Contains(cmd, ">(") {
+		return false
+	}
+	for _, m := range redirectPattern.FindAllStringSubmatch(cmd, -1) {
+		if m[1] == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// matchTool reports whether a rule's tool covers a tool
+func matchTool(ruleTool, tool string) bool {
+	if ruleTool == "*" || ruleTool == tool {
+		return true
+	}
+	if strings.Contains(ruleTool, "*") {
+		matched, _ := path.Match(ruleTool, tool)
+		return matched
+	}
+	return slices.Contains(Groups[ruleTool], tool)
+}
+
+// matchSubject matches a command, target or path against a glob pattern. In
+// commands and targets * matches anything, and a trailing " *" also matches the bare prefix, so
+// "go test *" covers "go test". In paths * stops at / and ** does not.
+func matchSubject(pattern, subject string, command bool) bool {
+	if command {
+		pattern = strings.Join(strings.Fields(pattern), " ")
+		if prefix, ok := strings.CutSuffix(pattern, " *"); ok && subject == prefix {
+			return true
+		}
+	}
+	return globRegexp(pattern, command).MatchString(subject)
+}
+
+// globRegexp converts a glob pattern to an anchored regular expression
+func globRegexp(pattern string, command bool) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '*' && (command || (i+1 < len(pattern) && pattern[i+1] == '*')):
+			if !command {
+				i++
+				// "**/" also matches no directory at all
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					b.WriteString("(?:.*/)?")
+					i++
+					continue
+				}
+			}
+			b.WriteString(".*")
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?' && command:
+			b.WriteString(".")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
@@ -0,0 +1,182 @@
+package policy
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSplitCommand(t *testing.T) {
+	tests := map[string][]string{
+		"go test ./...":                         {"go test ./..."},
+		"go  build && go test ./... || echo no": {"go build", "go test ./...", "echo no"},
+		"go test 2>&1 | tail -n 20; ls &":       {"go test 2>&1", "tail -n 20", "ls"},
+		`grep -E 'a|b' x; echo "c && d"`:        {`grep -E 'a|b' x`, `echo "c && d"`},
+	}
+	for command, want := range tests {
+		if got := SplitCommand(command); !reflect.DeepEqual(got, want) {
+			t.Errorf("SplitCommand(%q) = %q, want %q", command, got, want)
+		}
+	}
+}
+
+func TestEvaluateCommands(t *testing.T) {
+	p, err := New(
+		[]string{"execute: go test *", "execute: git diff*", "execute: tail *"},
+		[]string{"execute: rm -rf *", "execute: curl *"},
+		[]string{"execute: go test -exec *"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		command string
+		action  string
+	}{
+		{"go test ./pkg/...", Allow},
+		{"go test", Allow},
+		{"git diff --stat", Allow},
+		{"go test ./... 2>&1 | tail -n 20", Allow},
+		{"go test ./... > /dev/null", Allow},
+		{"go test -exec sudo ./...", Ask},
+		{"go test ./... && rm -rf /", Deny},
+		{"curl https://example.com", Deny},
+		{"go test ./... > out.txt", Ask},
+		{"go test $(rm -rf x)", Ask},
+		{"go vet ./...", Ask},
+		{"go test ./... && make", Ask},
+		{"rm -fr /", Deny},
+		{"/bin/rm -rf /", Deny},
+		{"sudo rm -r -f /", Deny},
+		{"env FOO=1 curl http://example.com", Deny},
+		{"rm -r build", Ask},
+	}
+	for _, tt := range tests {
+		if got := p.Evaluate(Request{Tool: "execute", Command: tt.command}); got.Action != tt.action {
+			t.Errorf("%q: got %s (%s), want %s", tt.command, got.Action, got.Rule, tt.action)
+		}
+	}
+}
+
+func TestEvaluatePaths(t *testing.T) {
+	p, err := New(
+		[]string{"write: src/**", "create_file: docs/*.md", "sql_query", "http_request: POST http://localhost:8000/*", "mcp_tickets_get_*"},
+		[]string{"write: src/secrets/**", "http_request: DELETE *", "mcp_db_*"},
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		req    Request
+		action string
+	}{
+		{Request{Tool: "write_file", Paths: []string{"src/app/main.go"}}, Allow},
+		{Request{Tool: "delete_file", Paths: []string{"src/secrets/key.pem"}}, Deny},
+		{Request{Tool: "move_file", Paths: []string{"src/a.go", "lib/a.go"}}, Ask},
+		{Request{Tool: "create_file", Paths: []string{"docs/intro.md"}}, Allow},
+		{Request{Tool: "create_file", Paths: []string{"docs/api/intro.md"}}, Ask},
+		{Request{Tool: "sql_query"}, Allow},
+		{Request{Tool: "run_tests"}, Ask},
+		{Request{Tool: "execute", Command: "ls src/a"}, Ask},
+		{Request{Tool: "http_request", Target: "POST http://localhost:8000/api/users?a=1&b=2"}, Allow},
+		{Request{Tool: "http_request", Target: "DELETE http://localhost:8000/api/users/1"}, Deny},
+		{Request{Tool: "mcp_tickets_get_ticket"}, Allow},
+		{Request{Tool: "mcp_tickets_close_ticket"}, Ask},
+		{Request{Tool: "mcp_db_query"}, Deny},
+	}
+	for _, tt := range tests {
+		if got := p.Evaluate(tt.req); got.Action != tt.action {
+			t.Errorf("%+v: got %s (%s), want %s", tt.req, got.Action, got.Rule, tt.action)
+		}
+	}
+}
+
+func TestNewReportsInvalidRules(t *testing.T) {
+	p, err := New([]string{"go test *", "execute: ls"}, nil, nil)
+	if err == nil {
+		t.Fatal("expected an error for a rule without a tool")
+	}
+	if len(p.Rules()) != 1 {
+		t.Errorf("valid rules kept: %v", p.Rules())
+	}
+}
+
+func TestSuggestRule(t *testing.T) {
+	tests := []struct {
+		req  Request
+		want []string
+	}{
+		{Request{Tool: "execute", Command: "go test ./pkg/..."}, []string{"execute: go test *"}},
+		{Request{Tool: "execute", Command: "npm install && ls -la"}, []string{"execute: npm install", "execute: ls -la"}},
+		{Request{Tool: "execute", Command: "php artisan make:model Post"}, []string{"execute: php artisan make:model Post"}},
+		{Request{Tool: "execute", Command: "bash -c 'go test ./...'"}, []string{"execute: bash -c 'go test ./...'"}},
+		{Request{Tool: "execute", Command: "sh scripts/x.sh"}, []string{"execute: sh scripts/x.sh"}},
+		{Request{Tool: "execute", Command: "env FOO=1 go test"}, []string{"execute: env FOO=1 go test"}},
+		{Request{Tool: "execute", Command: "python manage.py migrate"}, []string{"execute: python manage.py migrate"}},
+		{Request{Tool: "execute", Command: "rm -rf build"}, []string{"execute: rm -rf build"}},
+		{Request{Tool: "execute", Command: "./gradlew build"}, []string{"execute: ./gradlew build"}},
+		{Request{Tool: "execute", Command: "cat ./go.mod"}, []string{"execute: cat ./go.mod"}},
+		{Request{Tool: "write_file", Paths: []string{"pkg/chat/chat.go"}}, []string{"write_file: pkg/chat/**"}},
+		{Request{Tool: "write_file", Paths: []string{"README.md"}}, []string{"write_file: README.md"}},
+		{Request{Tool: "run_tests"}, []string{"run_tests"}},
+		{Request{Tool: "http_request", Target: "POST http://localhost:8000/api/users/1?x=a&b"}, []string{"http_request: POST http://localhost:8000/api/*"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, rule := range SuggestRule(tt.req) {
+			got = append(got, rule.String())
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SuggestRule(%+v) = %q, want %q", tt.req, got, tt.want)
+		}
+	}
+}
+
+func TestSuggestedRuleOnlyAllowsTheCommand(t *testing.T) {
+	p, err := New(nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rule := range SuggestRule(Request{Tool: "execute", Command: "bash -c 'go test ./...'"}) {
+		p.Add(rule)
+	}
+	if d := p.Evaluate(Request{Tool: "execute", Command: "bash -c 'go test ./...'"}); d.Action != Allow {
+		t.Errorf("approved command: %s", d.Action)
+	}
+	if d := p.Evaluate(Request{Tool: "execute", Command: "bash -c 'rm -rf /'"}); d.Action != Ask {
+		t.Errorf("other bash command: %s (%s)", d.Action, d.Rule)
+	}
+}
+
+func TestAuditRecord(t *testing.T) {
+	root := t.TempDir()
+	audit := OpenAudit(root)
+	if err := audit.Record(Entry{Tool: "execute", Command: "ls", Decision: Allow, By: "rule", Rule: "execute: ls"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := audit.Record(Entry{Tool: "write_file", Paths: []string{"a.go"}, Decision: Deny, By: "user"}); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(filepath.Join(root, AuditFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 2 || entries[0].Rule != "execute: ls" || entries[1].Decision != Deny || entries[1].Time.IsZero() {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+}
@@ -0,0 +1,294 @@
+// Package procs runs long-lived commands such as development servers in the
+// background, keeps their recent output in ring buffers and stops them, with
+// everything they started, when asked or when the owning session ends.
+package procs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/axon/pkg/execx"
+)
+
+// DefaultBufferSize is the output kept per process
+const DefaultBufferSize = 256 * 1024
+
+// StopGrace is how long a process gets to exit after SIGTERM before it is
+// killed
+const StopGrace = 5 * time.Second
+
+// Process is a background command
+type Process struct {
+	ID      int
+	Name    string
+	Command string
+	Started time.Time
+	Sandbox string // none, bwrap or namespaces
+	Warning string // Sandbox warning, if any
+
+	cmd      *exec.Cmd
+	cancel   context.CancelFunc
+	output   *ring
+	done     chan struct{}
+	exitCode int
+	ended    time.Time
+}
+
+// Info describes a process for listings
+type Info struct {
+	ID       int       `json:"id"`
+	Name     string    `json:"name"`
+	Command  string    `json:"command"`
+	PID      int       `json:"pid"`
+	Running  bool      `json:"running"`
+	ExitCode *int      `json:"exit_code,omitempty"`
+	Started  time.Time `json:"started"`
+	Uptime   string    `json:"uptime"`
+	Sandbox  string    `json:"sandbox"`
+}
+
+// Manager owns the background processes of a session
+type Manager struct {
+	mu     sync.Mutex
+	procs  []*Process
+	nextID int
+}
+
+// NewManager creates an empty manager
+func NewManager() *Manager {
+	return &Manager{nextID: 1}
+}
+
+// Start starts a command in the background. name labels it in listings and
+// can be used instead of the ID; it defaults to the command's first word.
+func (m *Manager) Start(command, name string, opts execx.Options) (*Process, error) {
+	if name == "" {
+		name = defaultName(command)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, p := range m.procs {
+		if p.Name == name && p.Running() {
+			return nil, fmt.Errorf("a process named %q is already running (id %d); stop it first or pick another name", name, p.ID)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd, sandbox, warning, err := execx.Command(ctx, command, opts)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	setParentDeathSignal(cmd)
+	cmd.WaitDelay = 2 * time.Second
+	p := &Process{
+		ID:      m.nextID,
+		Name:    name,
+		Command: command,
+		Sandbox: sandbox,
+		Warning: warning,
+		cmd:     cmd,
+		cancel:  cancel,
+		output:  newRing(DefaultBufferSize),
+		done:    make(chan struct{}),
+	}
+	cmd.Stdout, cmd.Stderr = p.output, p.output
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to start %q: %w", command, err)
+	}
+	p.Started = time.Now()
+	m.nextID++
+	m.procs = append(m.procs, p)
+
+	go func() {
+		err := cmd.Wait()
+		p.exitCode = cmd.ProcessState.ExitCode()
+		if err != nil && p.exitCode == 0 {
+			p.exitCode = -1
+		}
+		p.ended = time.Now()
+		close(p.done)
+	}()
+	return p, nil
+}
+
+// Get finds a process by ID or name, preferring running processes when
+// names repeat
+func (m *Manager) Get(ref string) (*Process, error) {
+	ref = strings.TrimSpace(ref)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if id, err := strconv.Atoi(ref); err == nil {
+		for _, p := range m.procs {
+			if p.ID == id {
+				return p, nil
+			}
+		}
+	}
+	var found *Process
+	for _, p := range m.procs {
+		if p.Name == ref && (found == nil || p.Running()) {
+			found = p
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no background process %q; use list_processes to see them", ref)
+	}
+	return found, nil
+}
+
+// List describes every process started by the session, running ones first
+func (m *Manager) List() []Info {
+	m.mu.Lock()
+	infos := make([]Info, 0, len(m.procs))
+	for _, p := range m.procs {
+		infos = append(infos, p.Info())
+	}
+	m.mu.Unlock()
+	sort.SliceStable(infos, func(i, j int) bool { return infos[i].Running && !infos[j].Running })
+	return infos
+}
+
+// StopAll stops every running process, in parallel
+func (m *Manager) StopAll() {
+	m.mu.Lock()
+	procs := append([]*Process(nil), m.procs...)
+	m.mu.Unlock()
+	var wg sync.WaitGroup
+	for _, p := range procs {
+		if p.Running() {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				p.Stop(StopGrace)
+			}()
+		}
+	}
+	wg.Wait()
+}
+
+// Running reports whether the process has not exited
+func (p *Process) Running() bool {
+	select {
+	case <-p.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Stop sends SIGTERM to the process group, waits up to grace for it to exit
+// and then kills whatever is left of the group
+func (p *Process) Stop(grace time.Duration) {
+	if p.Running() {
+		execx.Terminate(p.cmd)
+		select {
+		case <-p.done:
+		case <-time.After(grace):
+		}
+	}
+	// Children may outlive the shell that started them
+	execx.Kill(p.cmd)
+	p.cancel()
+	<-p.done
+}
+
+// Wait waits up to timeout for the process to exit and reports whether it did
+func (p *Process) Wait(timeout time.Duration) bool {
+	select {
+	case <-p.done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// WaitForOutput waits up to timeout until the output contains text, e.g. a
+// server's "listening on" line. It returns false on timeout or exit.
+func (p *Process) WaitForOutput(text string, timeout time.Duration) bool {
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		data, _, _ := p.output.since(0)
+		if bytes.Contains(data, []byte(text)) {
+			return true
+		}
+		select {
+		case <-p.done:
+			data, _, _ := p.output.since(0)
+			return bytes.Contains(data, []byte(text))
+		case <-deadline:
+			return false
+		case <-ticker.C:
+		}
+	}
+}
+
+// Output returns the output from offset on, or the last lines when offset is
+// negative, with the offset to pass next time to get only newer output.
+// dropped is set when older output was discarded from the buffer.
+func (p *Process) Output(offset int64, lines int) (text string, next int64, dropped bool) {
+	if offset >= 0 {
+		data, end, dropped := p.output.since(offset)
+		return string(data), end, dropped
+	}
+	data, end, dropped := p.output.since(0)
+	text = string(data)
+	if lines > 0 {
+		all := strings.SplitAfter(text, "\n")
+		if all[len(all)-1] == "" {
+			all = all[:len(all)-1]
+		}
+		if len(all) > lines {
+			all, dropped = all[len(all)-lines:], true
+		}
+		text = strings.Join(all, "")
+	}
+	return text, end, dropped
+}
+
+// Info describes the process
+func (p *Process) Info() Info {
+	info := Info{
+		ID:      p.ID,
+		Name:    p.Name,
+		Command: p.Command,
+		PID:     p.cmd.Process.Pid,
+		Running: p.Running(),
+		Started: p.Started,
+		Sandbox: p.Sandbox,
+	}
+	end := time.Now()
+	if !info.Running {
+		code := p.exitCode
+		info.ExitCode = &code
+		end = p.ended
+	}
+	info.Uptime = end.Sub(p.Started).Round(time.Second).String()
+	return info
+}
+
+// defaultName names a process after its command, skipping wrappers and
+// variable assignments: "npm run dev" becomes "npm", "php artisan serve"
+// becomes "php"
+func defaultName(command string) string {
+	for _, field := range strings.Fields(command) {
+		if strings.Contains(field, "=") || field == "env" || field == "exec" || field == "nohup" {
+			continue
+		}
+		if i := strings.LastIndex(field, "/"); i >= 0 {
+			field = field[i+1:]
+		}
+		return field
+	}
+	return "process"
+}
@@ -0,0 +1,15 @@
+package procs
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setParentDeathSignal has the kernel terminate the process if axon dies
+// without stopping it, e.g. when it is killed
+func setParentDeathSignal(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Pdeathsig = syscall.SIGTERM
+}
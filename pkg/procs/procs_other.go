@@ -0,0 +1,9 @@
+//go:build !linux
+
+package procs
+
+import "os/exec"
+
+// setParentDeathSignal is Linux-only; elsewhere processes are only stopped
+// when the session closes
+func setParentDeathSignal(cmd *exec.Cmd) {}
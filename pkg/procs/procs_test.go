@@ -0,0 +1,135 @@
+package procs
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/axon/pkg/execx"
+)
+
+func TestRing(t *testing.T) {
+	r := newRing(8)
+	r.Write([]byte("abcd"))
+	data, end, dropped := r.since(0)
+	if string(data) != "abcd" || end != 4 || dropped {
+		t.Fatalf("got %q, %d, %v", data, end, dropped)
+	}
+	r.Write([]byte("efghij"))
+	data, end, dropped = r.since(0)
+	if string(data) != "cdefghij" || end != 10 || !dropped {
+		t.Errorf("got %q, %d, %v", data, end, dropped)
+	}
+	data, _, dropped = r.since(7)
+	if string(data) != "hij" || dropped {
+		t.Errorf("since 7: got %q, %v", data, dropped)
+	}
+	r.Write([]byte("0123456789"))
+	if data, _, _ := r.since(0); string(data) != "23456789" {
+		t.Errorf("large write: got %q", data)
+	}
+}
+
+func TestStartOutputStop(t *testing.T) {
+	m := NewManager()
+	dir := t.TempDir()
+	p, err := m.Start("echo ready; sleep 60", "", execx.Options{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "echo" {
+		t.Errorf("default name = %q", p.Name)
+	}
+	if !p.WaitForOutput("ready", 5*time.Second) {
+		t.Fatal("output never arrived")
+	}
+	text, next, _ := p.Output(-1, 10)
+	if text != "ready\n" || next != 6 {
+		t.Errorf("got %q, next %d", text, next)
+	}
+	if text, _, _ := p.Output(next, 0); text != "" {
+		t.Errorf("expected no new output, got %q", text)
+	}
+
+	if _, err := m.Start("sleep 1", "echo", execx.Options{Dir: dir}); err == nil {
+		t.Error("expected an error for a duplicate running name")
+	}
+	if got, err := m.Get("echo"); err != nil || got != p {
+		t.Errorf("Get by name: %v, %v", got, err)
+	}
+	if got, err := m.Get(strconv.Itoa(p.ID)); err != nil || got != p {
+		t.Errorf("Get by ID: %v, %v", got, err)
+	}
+
+	start := time.Now()
+	p.Stop(StopGrace)
+	if p.Running() || time.Since(start) > 3*time.Second {
+		t.Errorf("running %v after %v", p.Running(), time.Since(start))
+	}
+	if info := p.Info(); info.ExitCode == nil {
+		t.Error("expected an exit code once stopped")
+	}
+}
+
+func TestStopAllKillsChildren(t *testing.T) {
+	if _, err := os.Stat("/proc/self/stat"); err != nil {
+		t.Skip("needs /proc")
+	}
+	m := NewManager()
+	dir := t.TempDir()
+	pidFile := filepath.Join(dir, "child.pid")
+	// The child ignores SIGTERM, so only the final kill of the group stops it
+	if _, err := m.Start("sh -c 'trap \"\" TERM; echo $$ > child.pid; sleep 60' & wait", "server", execx.Options{Dir: dir}); err != nil {
+		t.Fatal(err)
+	}
+	var pid int
+	for i := 0; i < 50 && pid == 0; i++ {
+		time.Sleep(50 * time.Millisecond)
+		data, _ := os.ReadFile(pidFile)
+		pid, _ = strconv.Atoi(strings.TrimSpace(string(data)))
+	}
+	if pid == 0 {
+		t.Fatal("child never started")
+	}
+
+	m.StopAll()
+	if infos := m.List(); len(infos) != 1 || infos[0].Running {
+		t.Errorf("unexpected list %+v", infos)
+	}
+	for i := 0; i < 20; i++ {
+		if !alive(pid) {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Errorf("child %d survived StopAll", pid)
+}
+
+// alive reports whether a process exists and is not a zombie waiting to be
+// reaped by its new parent
+func alive(pid int) bool {
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return false
+	}
+	fields := strings.Fields(string(data[strings.LastIndexByte(string(data), ')')+1:]))
+	return len(fields) > 0 && fields[0] != "Z"
+}
+
+func TestDefaultName(t *testing.T) {
+	tests := map[string]string{
+		"npm run dev":                 "npm",
+		"php artisan serve":           "php",
+		"PORT=8081 go run ./cmd/api":  "go",
+		"./node_modules/.bin/vite":    "vite",
+		"env APP_ENV=local ./bin/api": "api",
+	}
+	for command, want := range tests {
+		if got := defaultName(command); got != want {
+			t.Errorf("defaultName(%q) = %q, want %q", command, got, want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package procs
+
+import "sync"
+
+// ring keeps the last bytes written to it and counts everything written, so
+// readers can ask for output since an offset and learn what was dropped
+type ring struct {
+	mu    sync.Mutex
+	buf   []byte
+	size  int
+	total int64
+}
+
+// newRing creates a ring keeping at most size bytes
+func newRing(size int) *ring {
+	return &ring{buf: make([]byte, 0, size), size: size}
+}
+
+// Write implements io.Writer; it never fails
+func (r *ring) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.total += int64(len(p))
+	if len(p) >= r.size {
+		r.buf = append(r.buf[:0], p[len(p)-r.size:]...)
+		return len(p), nil
+	}
+	if over := len(r.buf) + len(p) - r.size; over > 0 {
+		r.buf = append(r.buf[:0], r.buf[over:]...)
+	}
+	r.buf = append(r.buf, p...)
+	return len(p), nil
+}
+
+// since returns the output written from offset on and the offset of the
+// end. dropped is set when part of it was already overwritten.
+func (r *ring) since(offset int64) (data []byte, end int64, dropped bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	start := r.total - int64(len(r.buf))
+	if offset < start {
+		offset, dropped = start, true
+	}
+	if offset > r.total {
+		offset = r.total
+	}
+	return append([]byte(nil), r.buf[offset-start:]...), r.total, dropped
+}
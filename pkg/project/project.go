@@ -22,8 +22,88 @@ type Config struct {
 		Model      string `yaml:"model"` // Model for llama-server
 	} `yaml:"server"`
 	Context struct {
-		Ignore []string `yaml:"ignore"`
+		Ignore        []string `yaml:"ignore"`
+		RepoMapTokens int      `yaml:"repo_map_tokens"` // Token budget of the repository map in the system prompt (0 disables)
 	} `yaml:"context"`
+	Embeddings struct {
+		BaseURL string `yaml:"base_url"` // llama-server started with --embedding (defaults to llm.base_url)
+		Model   string `yaml:"model"`
+	} `yaml:"embeddings"`
+	LSP struct {
+		Servers            []LSPServer `yaml:"servers"`
+		DiagnosticsTimeout int         `yaml:"diagnostics_timeout"` // Seconds to wait for diagnostics after a write (0 disables)
+	} `yaml:"lsp"`
+	Database struct {
+		SQLite  []string `yaml:"sqlite"`   // SQLite files for describe_schema and sql_query; describe_schema detects them when empty
+		MaxRows int      `yaml:"max_rows"` // Row limit of sql_query results
+	} `yaml:"database"`
+	Tests struct {
+		Timeout int `yaml:"timeout"` // Seconds before run_tests stops the test runner
+	} `yaml:"tests"`
+	Fix struct {
+		MaxIterations int `yaml:"max_iterations"` // Edit/re-run rounds before /fix gives up
+		Timeout       int `yaml:"timeout"`        // Seconds before the checked command is stopped
+	} `yaml:"fix"`
+	Execute struct {
+		Timeout   int      `yaml:"timeout"`    // Default seconds before an execute command is killed
+		MaxOutput int      `yaml:"max_output"` // Bytes of combined output kept (head and tail)
+		Env       []string `yaml:"env"`        // Extra environment variables passed through ("*" for all)
+		Sandbox   string   `yaml:"sandbox"`    // off, auto, bwrap or namespaces
+		Network   bool     `yaml:"network"`    // Keep network access inside the sandbox
+		Writable  []string `yaml:"writable"`   // Extra paths writable inside the sandbox besides the project root
+	} `yaml:"execute"`
+	HTTP struct {
+		AllowedHosts []string `yaml:"allowed_hosts"` // Hosts http_request may reach, "host" or "host:port" (loopback when empty)
+		Timeout      int      `yaml:"timeout"`       // Seconds before a request is abandoned
+		MaxBody      int      `yaml:"max_body"`      // Bytes of response body returned
+	} `yaml:"http"`
+	MCP struct {
+		Servers []MCPServer `yaml:"servers"`
+		Timeout int         `yaml:"timeout"` // Seconds to wait for a server to start or a tool call to finish
+	} `yaml:"mcp"`
+	Permissions Permissions `yaml:"permissions"` // Rules deciding which tool calls run without confirmation
+}
+
+// Permissions lists rules like "execute: go test *" or "write: src/**". Deny
+// rules win over ask rules, which win over allow rules; anything else asks.
+type Permissions struct {
+	Allow []string `yaml:"allow"`
+	Deny  []string `yaml:"deny"`
+	Ask   []string `yaml:"ask"`
+}
+
+// LocalConfigFile holds personal permission rules, such as those added by
+// answering "always allow", and is meant to stay out of version control
+const LocalConfigFile = ".axon.local.yml"
+
+// LSPServer configures a language server started over stdio for some file types
+type LSPServer struct {
+	Name       string   `yaml:"name"`
+	Command    []string `yaml:"command"`
+	Extensions []string `yaml:"extensions"`
+}
+
+// MCPServer configures a Model Context Protocol server, either a command
+// speaking over stdio or the URL of a Streamable HTTP endpoint. Env and
+// header values may reference environment variables as ${VAR}.
+type MCPServer struct {
+	Name     string            `yaml:"name"`
+	Command  []string          `yaml:"command"`
+	Env      map[string]string `yaml:"env"` // Added to axon's environment
+	URL      string            `yaml:"url"`
+	Headers  map[string]string `yaml:"headers"`
+	Disabled bool              `yaml:"disabled"`
+}
+
+// defaultLSPServers are used when .axon.yml does not configure any; servers
+// that are not installed are skipped
+func defaultLSPServers() []LSPServer {
+	return []LSPServer{
+		{Name: "gopls", Command: []string{"gopls"}, Extensions: []string{".go"}},
+		{Name: "intelephense", Command: []string{"intelephense", "--stdio"}, Extensions: []string{".php"}},
+		{Name: "typescript-language-server", Command: []string{"typescript-language-server", "--stdio"}, Extensions: []string{".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs"}},
+		{Name: "pyright", Command: []string{"pyright-langserver", "--stdio"}, Extensions: []string{".py"}},
+	}
 }
 
 // FindProjectRoot walks upwards from startDir to find the project root.
@@ -80,6 +160,19 @@ func LoadConfig(projectRoot string) (*Config, error) {
 	cfg.Server.AutoStart = true                                     // Auto-start server by default
 	cfg.Server.ServerPath = ""                                      // Use llama-server from PATH
 	cfg.Server.Model = "Qwen/Qwen2.5-Coder-3B-Instruct-GGUF:Q4_K_M" // Default to 3B model
+	cfg.Context.RepoMapTokens = 1024
+	cfg.LSP.Servers = defaultLSPServers()
+	cfg.LSP.DiagnosticsTimeout = 5
+	cfg.Database.MaxRows = 50
+	cfg.Tests.Timeout = 600
+	cfg.Fix.MaxIterations = 5
+	cfg.Fix.Timeout = 600
+	cfg.Execute.Timeout = 120
+	cfg.Execute.MaxOutput = 30000
+	cfg.Execute.Sandbox = "off"
+	cfg.HTTP.Timeout = 30
+	cfg.HTTP.MaxBody = 20000
+	cfg.MCP.Timeout = 60
 
 	// Try to load .axon.yml first
 	axonYmlPath := filepath.Join(projectRoot, ".axon.yml")
@@ -97,6 +190,19 @@ func LoadConfig(projectRoot string) (*Config, error) {
 		}
 	}
 
+	// Personal rules are added to the project's
+	if data, err := os.ReadFile(filepath.Join(projectRoot, LocalConfigFile)); err == nil {
+		var local struct {
+			Permissions Permissions `yaml:"permissions"`
+		}
+		if err := yaml.Unmarshal(data, &local); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", LocalConfigFile, err)
+		}
+		cfg.Permissions.Allow = append(cfg.Permissions.Allow, local.Permissions.Allow...)
+		cfg.Permissions.Deny = append(cfg.Permissions.Deny, local.Permissions.Deny...)
+		cfg.Permissions.Ask = append(cfg.Permissions.Ask, local.Permissions.Ask...)
+	}
+
 	// Apply environment variable overrides
 	if baseURL := os.Getenv("AXON_LLM_BASE_URL"); baseURL != "" {
 		cfg.LLM.BaseURL = baseURL
@@ -120,6 +226,22 @@ func LoadConfig(projectRoot string) (*Config, error) {
 	if serverModel := os.Getenv("AXON_SERVER_MODEL"); serverModel != "" {
 		cfg.Server.Model = serverModel
 	}
+	// Embeddings configuration
+	if embURL := os.Getenv("AXON_EMBEDDINGS_BASE_URL"); embURL != "" {
+		cfg.Embeddings.BaseURL = embURL
+	}
+	if embModel := os.Getenv("AXON_EMBEDDINGS_MODEL"); embModel != "" {
+		cfg.Embeddings.Model = embModel
+	}
+	if sandbox := os.Getenv("AXON_EXECUTE_SANDBOX"); sandbox != "" {
+		cfg.Execute.Sandbox = sandbox
+	}
+	if cfg.Embeddings.BaseURL == "" {
+		cfg.Embeddings.BaseURL = cfg.LLM.BaseURL
+	}
+	if cfg.Embeddings.Model == "" {
+		cfg.Embeddings.Model = cfg.LLM.Model
+	}
 
 	// Ensure ignore list has default values if empty
 	if len(cfg.Context.Ignore) == 0 {
@@ -128,12 +250,68 @@ func LoadConfig(projectRoot string) (*Config, error) {
 			"node_modules/",
 			"storage/",
 			".git/",
+			".axon/",
 		}
 	}
 
 	return cfg, nil
 }
 
+// AddLocalPermission appends a rule to the allow, deny or ask list of
+// .axon.local.yml, creating the file if needed and keeping its other content
+func AddLocalPermission(projectRoot, list, rule string) error {
+	path := filepath.Join(projectRoot, LocalConfigFile)
+	var doc yaml.Node
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", LocalConfigFile, err)
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", LocalConfigFile, err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s must contain a mapping", LocalConfigFile)
+	}
+
+	rules := mappingValue(mappingValue(root, "permissions", yaml.MappingNode), list, yaml.SequenceNode)
+	for _, existing := range rules.Content {
+		if existing.Value == rule {
+			return nil
+		}
+	}
+	rules.Content = append(rules.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: rule, Style: yaml.DoubleQuotedStyle})
+
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, out, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", LocalConfigFile, err)
+	}
+	return nil
+}
+
+// mappingValue returns the value of key in a mapping node, adding an empty
+// node of the given kind when the key is missing or null
+func mappingValue(mapping *yaml.Node, key string, kind yaml.Kind) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			value := mapping.Content[i+1]
+			if value.Kind != kind {
+				*value = yaml.Node{Kind: kind}
+			}
+			return value
+		}
+	}
+	value := &yaml.Node{Kind: kind}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	return value
+}
+
 // ShouldIgnore checks if a path should be ignored based on the ignore patterns.
 // Patterns can be simple strings (prefix match) or glob patterns.
 func ShouldIgnore(path string, ignorePatterns []string) bool {
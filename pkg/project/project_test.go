@@ -3,6 +3,7 @@ package project
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -79,6 +80,11 @@ context:
   ignore:
     - "test/"
     - "temp/"
+lsp:
+  servers:
+    - name: gopls
+      command: ["gopls", "serve"]
+      extensions: [".go"]
 `
 	axonYmlPath := filepath.Join(tmpDir, ".axon.yml")
 	if err := os.WriteFile(axonYmlPath, []byte(configYaml), 0644); err != nil {
@@ -102,6 +108,9 @@ context:
 	if len(cfg.Context.Ignore) != 2 {
 		t.Errorf("Expected 2 ignore patterns, got %d", len(cfg.Context.Ignore))
 	}
+	if len(cfg.LSP.Servers) != 1 || len(cfg.LSP.Servers[0].Command) != 2 {
+		t.Errorf("Expected configured LSP servers to replace the defaults, got %+v", cfg.LSP.Servers)
+	}
 
 	// Test defaults when no config file
 	tmpDir2 := t.TempDir()
@@ -116,6 +125,55 @@ context:
 	if cfg2.LLM.Model != "qwen2.5-coder-3b" {
 		t.Errorf("Expected default model qwen2.5-coder-3b, got %s", cfg2.LLM.Model)
 	}
+	if len(cfg2.LSP.Servers) == 0 {
+		t.Error("Expected default LSP servers")
+	}
+}
+
+func TestLocalPermissions(t *testing.T) {
+	tmpDir := t.TempDir()
+	configYaml := `permissions:
+  allow:
+    - "execute: go test *"
+  deny:
+    - "execute: rm -rf *"
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, ".axon.yml"), []byte(configYaml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	localYaml := "# personal settings\nllm:\n  model: ignored\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, LocalConfigFile), []byte(localYaml), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, rule := range []string{"execute: git diff*", "write: src/**", "execute: git diff*"} {
+		if err := AddLocalPermission(tmpDir, "allow", rule); err != nil {
+			t.Fatalf("AddLocalPermission failed: %v", err)
+		}
+	}
+
+	cfg, err := LoadConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	want := []string{"execute: go test *", "execute: git diff*", "write: src/**"}
+	if strings.Join(cfg.Permissions.Allow, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected allow rules %q, got %q", want, cfg.Permissions.Allow)
+	}
+	if len(cfg.Permissions.Deny) != 1 {
+		t.Errorf("Expected 1 deny rule, got %q", cfg.Permissions.Deny)
+	}
+	if cfg.LLM.Model == "ignored" {
+		t.Error("Expected only permissions to be read from the local config")
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, LocalConfigFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "# personal settings") || !strings.Contains(string(data), "model: ignored") {
+		t.Errorf("Expected the local config to keep its content, got:\n%s", data)
+	}
 }
 
 func TestShouldIgnore(t *testing.T) {
@@ -0,0 +1,184 @@
+package review
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Output formats
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatSARIF = "sarif"
+)
+
+// ANSI color codes
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorYellow = "\033[33m"
+	colorCyan   = "\033[36m"
+	colorBold   = "\033[1m"
+	colorDim    = "\033[2m"
+)
+
+// Write renders a result in a format; color applies to text only
+func Write(w io.Writer, result *Result, format string, color bool) error {
+	switch format {
+	case FormatText, "":
+		return WriteText(w, result, color)
+	case FormatJSON:
+		return WriteJSON(w, result)
+	case FormatSARIF:
+		return WriteSARIF(w, result)
+	}
+	return fmt.Errorf("unknown format %q (text, json or sarif)", format)
+}
+
+// WriteText renders findings grouped by file for the terminal
+func WriteText(w io.Writer, result *Result, color bool) error {
+	paint := func(code, text string) string {
+		if !color {
+			return text
+		}
+		return code + text + colorReset
+	}
+	severityColor := map[string]string{Error: colorRed + colorBold, Warning: colorYellow, Info: colorCyan}
+
+	file := ""
+	for _, f := range result.Findings {
+		if f.File != file {
+			file = f.File
+			fmt.Fprintf(w, "\n%s\n", paint(colorBold, file))
+		}
+		fmt.Fprintf(w, "  %s %s %s\n", paint(colorDim, fmt.Sprintf("%5d", f.Line)), paint(severityColor[f.Severity], fmt.Sprintf("%-7s", f.Severity)), f.Message)
+		if f.Suggestion != "" {
+			for i, line := range strings.Split(f.Suggestion, "\n") {
+				prefix := "fix: "
+				if i > 0 {
+					prefix = "     "
+				}
+				fmt.Fprintf(w, "                %s\n", paint(colorDim, prefix+line))
+			}
+		}
+	}
+	for _, e := range result.Errors {
+		fmt.Fprintf(w, "%s\n", paint(colorYellow, "not reviewed: "+e))
+	}
+
+	summary := fmt.Sprintf("\n%d finding(s) in %d hunk(s): %d error(s), %d warning(s), %d info\n",
+		len(result.Findings), result.Hunks, result.Count(Error), result.Count(Warning), result.Count(Info))
+	if len(result.Findings) == 0 {
+		summary = fmt.Sprintf("\nNo findings in %d hunk(s).\n", result.Hunks)
+	}
+	_, err := io.WriteString(w, summary)
+	return err
+}
+
+// WriteJSON writes the result as indented JSON
+func WriteJSON(w io.Writer, result *Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(result)
+}
+
+// SARIF 2.1.0, the subset code scanning tools read
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string            `json:"ruleId"`
+	Level      string            `json:"level"`
+	Message    sarifMessage      `json:"message"`
+	Locations  []sarifLocation   `json:"locations"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// sarifLevels maps severities to SARIF levels
+var sarifLevels = map[string]string{Error: "error", Warning: "warning", Info: "note"}
+
+// WriteSARIF writes the findings as a SARIF 2.1.0 log with one rule per
+// category; paths are relative to the project root (%SRCROOT%)
+func WriteSARIF(w io.Writer, result *Result) error {
+	run := sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: "axon", Rules: []sarifRule{}}},
+		Results: []sarifResult{},
+	}
+	rules := map[string]bool{}
+	for _, f := range result.Findings {
+		if !rules[f.Category] {
+			rules[f.Category] = true
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:               f.Category,
+				ShortDescription: sarifMessage{Text: "axon review: " + f.Category},
+			})
+		}
+		r := sarifResult{
+			RuleID:  f.Category,
+			Level:   sarifLevels[f.Severity],
+			Message: sarifMessage{Text: f.Message},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: f.File, URIBaseID: "%SRCROOT%"},
+				Region:           sarifRegion{StartLine: max(f.Line, 1)},
+			}}},
+		}
+		if f.Suggestion != "" {
+			r.Properties = map[string]string{"suggestion": f.Suggestion}
+		}
+		run.Results = append(run.Results, r)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
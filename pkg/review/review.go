@@ -0,0 +1,259 @@
+// Package review reviews a diff hunk by hunk with the local model. Each hunk
+// is sent with the code around it, and the model answers with constrained
+// JSON findings (severity, line, message, suggested fix) that are rendered
+// for the terminal, as JSON or as SARIF.
+package review
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/axon/pkg/fsctx"
+	"github.com/axon/pkg/git"
+	"github.com/axon/pkg/llm"
+)
+
+// Severities of a finding, most severe first
+const (
+	Error   = "error"
+	Warning = "warning"
+	Info    = "info"
+)
+
+// Severities lists the severities, most severe first
+var Severities = []string{Error, Warning, Info}
+
+// Categories are the kinds of problems the model reports
+var Categories = []string{"bug", "security", "error-handling", "concurrency", "performance", "maintainability", "style"}
+
+// Limits on what one request contains
+const (
+	DefaultContext = 20   // Lines of code shown above and below a hunk
+	MaxHunk        = 8000 // Bytes of hunk text
+)
+
+// Finding is a problem the model found in a hunk
+type Finding struct {
+	Severity   string `json:"severity"`
+	Category   string `json:"category"`
+	File       string `json:"file"`
+	Line       int    `json:"line"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// Result is the outcome of a review
+type Result struct {
+	Findings []Finding `json:"findings"`
+	Hunks    int       `json:"hunks"`            // Hunks reviewed
+	Errors   []string  `json:"errors,omitempty"` // Hunks the model could not review
+}
+
+// Count returns the number of findings of a severity
+func (r *Result) Count(severity string) int {
+	n := 0
+	for _, f := range r.Findings {
+		if f.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// AtLeast reports whether a finding is at least as severe as severity
+func AtLeast(finding, severity string) bool {
+	return severityRank(finding) <= severityRank(severity)
+}
+
+// severityRank orders severities, 0 being the most severe
+func severityRank(severity string) int {
+	for i, s := range Severities {
+		if s == severity {
+			return i
+		}
+	}
+	return len(Severities)
+}
+
+// Model is the part of the LLM client Review uses
+type Model interface {
+	ChatJSON(ctx context.Context, messages []llm.Message, name string, schema map[string]interface{}, v interface{}) error
+}
+
+// Options configure a review
+type Options struct {
+	Context  int                               // Lines around each hunk (0 uses DefaultContext)
+	Progress func(done, total int, h git.Hunk) // Called before each hunk is reviewed
+}
+
+// Schema is the JSON schema the model's answer for a hunk is constrained to
+func Schema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"findings": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"severity": map[string]interface{}{
+							"type": "string",
+							"enum": Severities,
+						},
+						"category": map[string]interface{}{
+							"type": "string",
+							"enum": Categories,
+						},
+						"line": map[string]interface{}{
+							"type":        "integer",
+							"description": "Line number in the new version of the file",
+						},
+						"message": map[string]interface{}{
+							"type":        "string",
+							"description": "What is wrong and why it matters",
+						},
+						"suggestion": map[string]interface{}{
+							"type":        "string",
+							"description": "How to fix it, as a short explanation or replacement code; empty if obvious",
+						},
+					},
+					"required": []string{"severity", "category", "line", "message", "suggestion"},
+				},
+			},
+		},
+		"required": []string{"findings"},
+	}
+}
+
+// systemPrompt tells the model what to look for
+const systemPrompt = "You are a careful senior engineer reviewing one hunk of a change before it is pushed.\n" +
+	"Report only real problems the change introduces or exposes: bugs, security issues, missing error handling, races, resource leaks, performance traps and clear maintainability problems.\n" +
+	"Do not praise, summarize or restate the change, and do not flag style the surrounding code already follows.\n" +
+	"Use error for defects that will break or endanger something, warning for likely problems and info for minor suggestions.\n" +
+	"Give the line number from the numbered code in the new version of the file, on or near the changed lines.\n" +
+	"If the hunk looks correct, return an empty findings list."
+
+// Review reviews each hunk of the per-file patches. The code around a hunk is
+// read from the working tree. A hunk the model fails on is recorded in
+// Result.Errors and the review goes on; cancelling ctx stops it.
+func Review(ctx context.Context, model Model, projectRoot string, files []git.FilePatch, opts Options) (*Result, error) {
+	if opts.Context <= 0 {
+		opts.Context = DefaultContext
+	}
+	var hunks []git.Hunk
+	for _, f := range files {
+		if SkipFile(f.Path) {
+			continue
+		}
+		hunks = append(hunks, f.Hunks()...)
+	}
+
+	result := &Result{Findings: []Finding{}}
+	for i, h := range hunks {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if opts.Progress != nil {
+			opts.Progress(i, len(hunks), h)
+		}
+		findings, err := reviewHunk(ctx, model, projectRoot, h, opts.Context)
+		if err != nil {
+			if ctx.Err() != nil {
+				return result, ctx.Err()
+			}
+			result.Errors = append(result.Errors, fmt.Sprintf("%s:%d: %v", h.Path, h.NewStart, err))
+			continue
+		}
+		result.Hunks++
+		result.Findings = append(result.Findings, findings...)
+	}
+	sortFindings(result.Findings)
+	return result, nil
+}
+
+// reviewHunk asks the model about one hunk
+func reviewHunk(ctx context.Context, model Model, projectRoot string, h git.Hunk, contextLines int) ([]Finding, error) {
+	text := h.Text
+	if len(text) > MaxHunk {
+		text = text[:MaxHunk] + "\n... [truncated]\n"
+	}
+
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, "File: %s\n\n", h.Path)
+	start := max(h.NewStart-contextLines, 1)
+	if code, err := fsctx.ReadFileRange(projectRoot, h.Path, start, h.NewEnd()+contextLines); err == nil {
+		prompt.WriteString("Code around the change (new version, numbered):\n")
+		for i, line := range strings.Split(code, "\n") {
+			fmt.Fprintf(&prompt, "%5d| %s\n", start+i, line)
+		}
+		prompt.WriteString("\n")
+	}
+	prompt.WriteString("Change:\n")
+	prompt.WriteString(text)
+
+	messages := []llm.Message{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: prompt.String()},
+	}
+	var answer struct {
+		Findings []Finding `json:"findings"`
+	}
+	if err := model.ChatJSON(ctx, messages, "review_findings", Schema(), &answer); err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	for _, f := range answer.Findings {
+		f.Message = strings.TrimSpace(f.Message)
+		if f.Message == "" {
+			continue
+		}
+		f.File = h.Path
+		f.Severity = strings.ToLower(strings.TrimSpace(f.Severity))
+		if severityRank(f.Severity) == len(Severities) {
+			f.Severity = Warning
+		}
+		f.Category = strings.ToLower(strings.TrimSpace(f.Category))
+		if f.Category == "" {
+			f.Category = "bug"
+		}
+		// Findings belong to the changed lines, give or take a few
+		if f.Line < h.NewStart-3 || f.Line > h.NewEnd()+3 {
+			f.Line = h.NewStart
+		}
+		f.Suggestion = strings.TrimSpace(f.Suggestion)
+		findings = append(findings, f)
+	}
+	return findings, nil
+}
+
+// sortFindings orders findings by file and line, most severe first on a line
+func sortFindings(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return severityRank(a.Severity) < severityRank(b.Severity)
+	})
+}
+
+// lockFiles are generated files not worth reviewing
+var lockFiles = map[string]bool{
+	"go.sum": true, "package-lock.json": true, "yarn.lock": true, "pnpm-lock.yaml": true,
+	"composer.lock": true, "Cargo.lock": true, "poetry.lock": true, "Gemfile.lock": true,
+}
+
+// SkipFile reports whether a file is generated or minified rather than
+// written by hand
+func SkipFile(file string) bool {
+	base := path.Base(file)
+	return lockFiles[base] || strings.HasSuffix(base, ".min.js") || strings.HasSuffix(base, ".min.css") ||
+		strings.HasSuffix(base, ".pb.go") || strings.HasSuffix(base, "_gen.go")
+}
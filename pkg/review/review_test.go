@@ -0,0 +1,156 @@
+package review
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/axon/pkg/git"
+	"github.com/axon/pkg/llm"
+)
+
+const patch = `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -2,3 +2,3 @@ func main() {
+ 	f, _ := os.Open(name)
+-	defer f.Close()
++	data, _ := io.ReadAll(f)
+ 	use(data)
+diff --git a/go.sum b/go.sum
+--- a/go.sum
++++ b/go.sum
+@@ -1 +1 @@
+-a v1
++a v2
+diff --git a/util.go b/util.go
+--- a/util.go
++++ b/util.go
+@@ -10 +10 @@
+-x
++y
+`
+
+// fakeModel answers every hunk with fixed findings and records the prompts
+type fakeModel struct {
+	prompts []string
+	fail    string // Fail for prompts containing this
+}
+
+func (f *fakeModel) ChatJSON(ctx context.Context, messages []llm.Message, name string, schema map[string]interface{}, v interface{}) error {
+	prompt := messages[len(messages)-1].Content
+	f.prompts = append(f.prompts, prompt)
+	if f.fail != "" && strings.Contains(prompt, f.fail) {
+		return errors.New("model error")
+	}
+	return json.Unmarshal([]byte(`{"findings":[
+		{"severity":"error","category":"bug","line":3,"message":"f is never closed","suggestion":"defer f.Close()"},
+		{"severity":"critical","category":"","line":500,"message":"ignored error","suggestion":""},
+		{"severity":"info","category":"style","line":3,"message":"  ","suggestion":""}
+	]}`), v)
+}
+
+func TestReview(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "main.go"), []byte("func main() {\n\tf, _ := os.Open(name)\n\tdata, _ := io.ReadAll(f)\n\tuse(data)\n}\n"), 0644)
+
+	model := &fakeModel{fail: "util.go"}
+	var progress int
+	result, err := Review(context.Background(), model, root, git.SplitPatch(patch), Options{
+		Progress: func(done, total int, h git.Hunk) { progress++ },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// go.sum is skipped, util.go fails, main.go is reviewed
+	if progress != 2 || result.Hunks != 1 || len(result.Errors) != 1 || !strings.HasPrefix(result.Errors[0], "util.go:10:") {
+		t.Fatalf("unexpected result: progress %d, %+v", progress, result)
+	}
+	if !strings.Contains(model.prompts[0], "    3| \tdata, _ := io.ReadAll(f)") {
+		t.Errorf("prompt lacks numbered context:\n%s", model.prompts[0])
+	}
+
+	if len(result.Findings) != 2 {
+		t.Fatalf("expected 2 findings, got %+v", result.Findings)
+	}
+	first, second := result.Findings[0], result.Findings[1]
+	if first.File != "main.go" || first.Line != 2 || first.Severity != Warning || first.Category != "bug" {
+		t.Errorf("out-of-range finding was not normalized: %+v", first)
+	}
+	if second.Line != 3 || second.Severity != Error || second.Suggestion != "defer f.Close()" {
+		t.Errorf("unexpected finding: %+v", second)
+	}
+	if result.Count(Error) != 1 || !AtLeast(Error, Warning) || AtLeast(Info, Warning) {
+		t.Errorf("unexpected counts or ranking")
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	result := &Result{Findings: []Finding{
+		{Severity: Info, Category: "style", File: "a/b.go", Line: 7, Message: "long line", Suggestion: "wrap it"},
+	}, Hunks: 1}
+	var buf bytes.Buffer
+	if err := Write(&buf, result, FormatSARIF, false); err != nil {
+		t.Fatal(err)
+	}
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region struct {
+							StartLine int `json:"startLine"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("invalid SARIF: %v\n%s", err, buf.String())
+	}
+	run := log.Runs[0]
+	r := run.Results[0]
+	if log.Version != "2.1.0" || run.Tool.Driver.Rules[0].ID != "style" || r.RuleID != "style" || r.Level != "note" ||
+		r.Locations[0].PhysicalLocation.ArtifactLocation.URI != "a/b.go" || r.Locations[0].PhysicalLocation.Region.StartLine != 7 {
+		t.Errorf("unexpected SARIF:\n%s", buf.String())
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	result := &Result{Findings: []Finding{
+		{Severity: Error, Category: "bug", File: "main.go", Line: 3, Message: "f is never closed", Suggestion: "defer f.Close()"},
+	}, Hunks: 2}
+	var buf bytes.Buffer
+	if err := Write(&buf, result, FormatText, false); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"main.go\n", "    3 error   f is never closed", "fix: defer f.Close()", "1 finding(s) in 2 hunk(s): 1 error(s)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("text output lacks %q:\n%s", want, out)
+		}
+	}
+	if err := Write(&buf, result, "xml", false); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,801 @@
+package schema
+
+import (
+	"strings"
+)
+
+// tokenKind classifies SQL tokens
+type tokenKind int
+
+const (
+	tokenWord   tokenKind = iota // Keyword or unquoted identifier
+	tokenIdent                   // Quoted identifier ("x", `x` or [x])
+	tokenString                  // String literal, including dollar-quoted strings
+	tokenNumber                  // Numeric literal
+	tokenPunct                   // Punctuation and operators
+)
+
+// token is one lexical SQL token
+type token struct {
+	kind tokenKind
+	text string // Identifiers are unquoted; strings keep their quotes
+}
+
+// tokenize splits SQL into tokens, dropping whitespace and comments
+func tokenize(sql string) []token {
+	var tokens []token
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			i++
+		case c == '-' && strings.HasPrefix(sql[i:], "--"), c == '#':
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return tokens
+			}
+			i += end + 4
+		case c == '\'':
+			j := i + 1
+			for j < len(sql) {
+				if sql[j] == '\'' {
+					if j+1 < len(sql) && sql[j+1] == '\'' {
+						j += 2
+						continue
+					}
+					break
+				}
+				if sql[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			j = min(j+1, len(sql))
+			tokens = append(tokens, token{tokenString, sql[i:j]})
+			i = j
+		case c == '[' && (strings.HasPrefix(sql[i:], "[]") || i+1 < len(sql) && sql[i+1] >= '0' && sql[i+1] <= '9'):
+			// Array type such as int[] or int[3], not a bracket-quoted identifier
+			if sql[i+1] == ']' {
+				tokens = append(tokens, token{tokenPunct, "[]"})
+				i += 2
+			} else {
+				tokens = append(tokens, token{tokenPunct, "["})
+				i++
+			}
+		case c == '"' || c == '`' || c == '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			end := strings.IndexByte(sql[i+1:], closing)
+			if end < 0 {
+				end = len(sql) - i - 1
+			}
+			tokens = append(tokens, token{tokenIdent, sql[i+1 : i+1+end]})
+			i += end + 2
+		case c == '$' && dollarTag(sql[i:]) != "":
+			tag := dollarTag(sql[i:])
+			end := strings.Index(sql[i+len(tag):], tag)
+			j := len(sql)
+			if end >= 0 {
+				j = i + len(tag) + end + len(tag)
+			}
+			tokens = append(tokens, token{tokenString, sql[i:j]})
+			i = j
+		case isWordByte(c) && !(c >= '0' && c <= '9'):
+			j := i
+			for j < len(sql) && isWordByte(sql[j]) {
+				j++
+			}
+			tokens = append(tokens, token{tokenWord, sql[i:j]})
+			i = j
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(sql) && (isWordByte(sql[j]) || sql[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{tokenNumber, sql[i:j]})
+			i = j
+		case c == ':' && strings.HasPrefix(sql[i:], "::"):
+			tokens = append(tokens, token{tokenPunct, "::"})
+			i += 2
+		default:
+			tokens = append(tokens, token{tokenPunct, string(c)})
+			i++
+		}
+	}
+	return tokens
+}
+
+// dollarTag returns the $tag$ opening a PostgreSQL dollar-quoted string, or ""
+func dollarTag(s string) string {
+	for j := 1; j < len(s); j++ {
+		if s[j] == '$' {
+			return s[:j+1]
+		}
+		if !isWordByte(s[j]) || (j == 1 && s[j] >= '0' && s[j] <= '9') {
+			return ""
+		}
+	}
+	return ""
+}
+
+// isWordByte reports whether c can be part of an unquoted SQL word
+func isWordByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+// splitStatements splits tokens into statements at top-level semicolons.
+// Trigger bodies (BEGIN ... END) contain semicolons and are kept together.
+func splitStatements(tokens []token) [][]token {
+	var statements [][]token
+	start, depth, inTrigger := 0, 0, false
+	for i, t := range tokens {
+		switch {
+		case t.kind == tokenPunct && t.text == "(":
+			depth++
+		case t.kind == tokenPunct && t.text == ")":
+			depth--
+		case t.kind == tokenWord && strings.EqualFold(t.text, "BEGIN") && isTrigger(tokens[start:i]):
+			inTrigger = true
+		case t.kind == tokenWord && strings.EqualFold(t.text, "END") && inTrigger:
+			inTrigger = false
+		case t.kind == tokenPunct && t.text == ";" && depth <= 0 && !inTrigger:
+			if i > start {
+				statements = append(statements, tokens[start:i])
+			}
+			start, depth = i+1, 0
+		}
+	}
+	if start < len(tokens) {
+		statements = append(statements, tokens[start:])
+	}
+	return statements
+}
+
+// isTrigger reports whether a statement so far is a CREATE TRIGGER
+func isTrigger(tokens []token) bool {
+	for i := 0; i < len(tokens) && i < 4; i++ {
+		if tokens[i].kind == tokenWord && strings.EqualFold(tokens[i].text, "TRIGGER") {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply replays the DDL statements of a SQL script against the database.
+// Statements that do not change the schema are ignored.
+func (db *Database) Apply(sql, source string) {
+	for _, stmt := range splitStatements(tokenize(sql)) {
+		p := &parser{tokens: stmt}
+		p.statement(db, source)
+	}
+}
+
+// parser walks the tokens of one statement
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// peek returns the current token, or an empty token at the end
+func (p *parser) peek() token {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return token{kind: tokenPunct}
+}
+
+// done reports whether all tokens were consumed
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+// next consumes and returns the current token
+func (p *parser) next() token {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+// is reports whether the current token is one of the keywords
+func (p *parser) is(keywords ...string) bool {
+	t := p.peek()
+	if t.kind != tokenWord {
+		return false
+	}
+	for _, kw := range keywords {
+		if strings.EqualFold(t.text, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// accept consumes the keyword sequence if it is next
+func (p *parser) accept(keywords ...string) bool {
+	for i, kw := range keywords {
+		if p.pos+i >= len(p.tokens) {
+			return false
+		}
+		t := p.tokens[p.pos+i]
+		if t.kind != tokenWord || !strings.EqualFold(t.text, kw) {
+			return false
+		}
+	}
+	p.pos += len(keywords)
+	return true
+}
+
+// punct consumes a punctuation token if it is next
+func (p *parser) punct(text string) bool {
+	if t := p.peek(); t.kind == tokenPunct && t.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// name reads a possibly schema-qualified name and returns its last part
+func (p *parser) name() string {
+	t := p.next()
+	name := t.text
+	for p.punct(".") {
+		name = p.next().text
+	}
+	return name
+}
+
+// group consumes a parenthesized group and returns its inner tokens
+func (p *parser) group() []token {
+	if !p.punct("(") {
+		return nil
+	}
+	start, depth := p.pos, 1
+	for !p.done() {
+		t := p.next()
+		if t.kind == tokenPunct && t.text == "(" {
+			depth++
+		} else if t.kind == tokenPunct && t.text == ")" {
+			depth--
+			if depth == 0 {
+				return p.tokens[start : p.pos-1]
+			}
+		}
+	}
+	return p.tokens[start:]
+}
+
+// splitElements splits tokens at top-level commas
+func splitElements(tokens []token) [][]token {
+	var elements [][]token
+	start, depth := 0, 0
+	for i, t := range tokens {
+		if t.kind != tokenPunct {
+			continue
+		}
+		switch t.text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		case ",":
+			if depth == 0 {
+				elements = append(elements, tokens[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if start < len(tokens) {
+		elements = append(elements, tokens[start:])
+	}
+	return elements
+}
+
+// columnList reads the column names of a parenthesized list such as (a, b DESC)
+func columnList(tokens []token) []string {
+	var columns []string
+	for _, element := range splitElements(tokens) {
+		if len(element) == 0 {
+			continue
+		}
+		if len(element) > 1 && element[1].kind == tokenPunct && element[1].text == "(" {
+			columns = append(columns, joinTokens(element)) // Expression such as lower(email)
+			continue
+		}
+		columns = append(columns, element[0].text)
+	}
+	return columns
+}
+
+// joinTokens renders tokens back into compact SQL text, e.g. varchar(255)
+// or timestamp with time zone
+func joinTokens(tokens []token) string {
+	var sb strings.Builder
+	for i, t := range tokens {
+		if i > 0 && spaceBetween(tokens[i-1], t) {
+			sb.WriteByte(' ')
+		}
+		if t.kind == tokenIdent {
+			sb.WriteString(`"` + t.text + `"`)
+		} else {
+			sb.WriteString(t.text)
+		}
+	}
+	return sb.String()
+}
+
+// spaceBetween reports whether two adjacent tokens are separated by a space
+func spaceBetween(prev, t token) bool {
+	if prev.kind == tokenPunct {
+		switch prev.text {
+		case "(", ".", "::", "[":
+			return false
+		}
+	}
+	if t.kind == tokenPunct {
+		switch t.text {
+		case ")", ",", ".", "::", "[", "]", "[]":
+			return false
+		case "(":
+			// Keep a space in IN (...) and CHECK (...), not in varchar(255) or now()
+			return prev.kind == tokenWord && isOperatorKeyword(prev.text)
+		}
+	}
+	return true
+}
+
+// isOperatorKeyword reports whether a keyword takes a space before "("
+func isOperatorKeyword(word string) bool {
+	switch strings.ToUpper(word) {
+	case "IN", "AS", "AND", "OR", "NOT", "CHECK":
+		return true
+	}
+	return false
+}
+
+// statement applies one DDL statement
+func (p *parser) statement(db *Database, source string) {
+	switch {
+	case p.accept("CREATE"):
+		p.accept("OR", "REPLACE")
+		for p.is("TEMP", "TEMPORARY", "UNLOGGED", "GLOBAL", "LOCAL", "VIRTUAL") {
+			p.next()
+		}
+		switch {
+		case p.accept("TABLE"):
+			p.createTable(db, source)
+		case p.accept("UNIQUE", "INDEX"):
+			p.createIndex(db, source, true)
+		case p.accept("INDEX"):
+			p.createIndex(db, source, false)
+		}
+	case p.accept("ALTER", "TABLE"):
+		p.alterTable(db, source)
+	case p.accept("DROP", "TABLE"):
+		p.accept("IF", "EXISTS")
+		for !p.done() {
+			delete(db.Tables, tableKey(db, p.name()))
+			if !p.punct(",") {
+				break
+			}
+		}
+	case p.accept("DROP", "INDEX"):
+		p.accept("CONCURRENTLY")
+		p.accept("IF", "EXISTS")
+		name := p.name()
+		if p.accept("ON") {
+			// MySQL: DROP INDEX name ON table
+			if t, ok := db.Table(p.name()); ok {
+				t.dropConstraint(name)
+				t.addSource(source)
+			}
+			return
+		}
+		for _, t := range db.Tables {
+			if t.dropConstraint(name) {
+				t.addSource(source)
+			}
+		}
+	case p.accept("RENAME", "TABLE"):
+		// MySQL: RENAME TABLE a TO b, c TO d
+		for !p.done() {
+			from := p.name()
+			if !p.accept("TO") {
+				return
+			}
+			renameTable(db, from, p.name(), source)
+			if !p.punct(",") {
+				break
+			}
+		}
+	}
+}
+
+// tableKey returns the key of an existing table, or the name itself
+func tableKey(db *Database, name string) string {
+	for key := range db.Tables {
+		if strings.EqualFold(key, name) {
+			return key
+		}
+	}
+	return name
+}
+
+// renameTable moves a table to a new name and updates foreign keys pointing at it
+func renameTable(db *Database, from, to, source string) {
+	key := tableKey(db, from)
+	t, ok := db.Tables[key]
+	if !ok {
+		return
+	}
+	delete(db.Tables, key)
+	t.Name = to
+	t.addSource(source)
+	db.Tables[to] = t
+	for _, other := range db.Tables {
+		for i := range other.ForeignKeys {
+			if strings.EqualFold(other.ForeignKeys[i].RefTable, from) {
+				other.ForeignKeys[i].RefTable = to
+			}
+		}
+	}
+}
+
+// createTable parses CREATE TABLE [IF NOT EXISTS] name (...)
+func (p *parser) createTable(db *Database, source string) {
+	ifNotExists := p.accept("IF", "NOT", "EXISTS")
+	name := p.name()
+	if _, exists := db.Table(name); exists && ifNotExists {
+		return
+	}
+	body := p.group()
+	if body == nil {
+		return // CREATE TABLE ... AS SELECT: columns are not known
+	}
+
+	t := &Table{Name: name}
+	for _, element := range splitElements(body) {
+		ep := &parser{tokens: element}
+		if !ep.tableConstraint(t) {
+			if column, ok := ep.columnDefinition(t); ok {
+				t.setColumn(column)
+			}
+		}
+	}
+	t.addSource(source)
+	delete(db.Tables, tableKey(db, name))
+	db.Tables[name] = t
+}
+
+// tableConstraint parses a table-level constraint. It returns false if the
+// element is a column definition.
+func (p *parser) tableConstraint(t *Table) bool {
+	name := ""
+	if p.accept("CONSTRAINT") {
+		name = p.next().text
+	}
+	switch {
+	case p.accept("PRIMARY", "KEY"):
+		t.PrimaryKey = columnList(p.group())
+		for _, column := range t.PrimaryKey {
+			if c, ok := t.Column(column); ok {
+				c.NotNull = true
+			}
+		}
+	case p.is("UNIQUE"):
+		p.next()
+		if !p.accept("KEY") {
+			p.accept("INDEX")
+		}
+		if p.peek().kind != tokenPunct {
+			name = p.next().text
+		}
+		columns := columnList(p.group())
+		t.Indexes = append(t.Indexes, Index{Name: name, Columns: columns, Unique: true})
+		if len(columns) == 1 {
+			if c, ok := t.Column(columns[0]); ok {
+				c.Unique = true
+			}
+		}
+	case p.accept("FOREIGN", "KEY"):
+		fk := ForeignKey{Name: name, Columns: columnList(p.group())}
+		p.references(&fk)
+		t.ForeignKeys = append(t.ForeignKeys, fk)
+	case p.is("KEY", "INDEX", "FULLTEXT", "SPATIAL") && (p.pos+1 >= len(p.tokens) || p.tokens[p.pos+1].kind != tokenWord || !isTypeWord(p.tokens[p.pos+1].text)):
+		// MySQL inline index: KEY name (cols), FULLTEXT KEY name (cols)
+		for p.is("KEY", "INDEX", "FULLTEXT", "SPATIAL") {
+			p.next()
+		}
+		if p.peek().kind != tokenPunct {
+			name = p.next().text
+		}
+		t.Indexes = append(t.Indexes, Index{Name: name, Columns: columnList(p.group())})
+	case p.is("CHECK", "EXCLUDE"):
+	default:
+		return name != "" // A named constraint we do not model
+	}
+	return true
+}
+
+// isTypeWord reports whether a word after KEY/INDEX is a column type, meaning
+// the element is a column named "key" or "index"
+func isTypeWord(word string) bool {
+	switch strings.ToUpper(word) {
+	case "TEXT", "VARCHAR", "INT", "INTEGER", "BIGINT", "CHAR", "BOOLEAN", "BOOL", "UUID", "JSON", "JSONB":
+		return true
+	}
+	return false
+}
+
+// columnKeywords end the type of a column definition
+var columnKeywords = map[string]bool{
+	"NOT": true, "NULL": true, "DEFAULT": true, "PRIMARY": true, "UNIQUE": true, "REFERENCES": true,
+	"CHECK": true, "CONSTRAINT": true, "COLLATE": true, "GENERATED": true, "AUTO_INCREMENT": true,
+	"AUTOINCREMENT": true, "COMMENT": true, "ON": true, "IDENTITY": true, "AS": true, "CHARACTER": true,
+	"FIRST": true, "AFTER": true, "STORED": true, "VIRTUAL": true,
+}
+
+// columnDefinition parses name type [constraints...]
+func (p *parser) columnDefinition(t *Table) (Column, bool) {
+	if p.done() {
+		return Column{}, false
+	}
+	column := Column{Name: p.next().text}
+
+	typeStart := p.pos
+	for !p.done() {
+		tok := p.peek()
+		if tok.kind == tokenWord && columnKeywords[strings.ToUpper(tok.text)] && p.pos > typeStart {
+			break
+		}
+		if tok.kind == tokenPunct && tok.text == "(" {
+			p.group()
+			continue
+		}
+		p.next()
+	}
+	column.Type = joinTokens(p.tokens[typeStart:p.pos])
+	upperType := strings.ToUpper(column.Type)
+	if strings.Contains(upperType, "SERIAL") {
+		column.AutoIncrement, column.NotNull = true, true
+	}
+
+	for !p.done() {
+		switch {
+		case p.accept("NOT", "NULL"):
+			column.NotNull = true
+		case p.accept("NULL"):
+			column.NotNull = false
+		case p.accept("DEFAULT"):
+			column.Default = p.defaultValue()
+		case p.accept("PRIMARY", "KEY"):
+			t.PrimaryKey = []string{column.Name}
+			column.NotNull = true
+			p.accept("ASC")
+			p.accept("DESC")
+		case p.accept("UNIQUE"):
+			column.Unique = true
+			p.accept("KEY")
+		case p.is("AUTO_INCREMENT", "AUTOINCREMENT", "IDENTITY"):
+			p.next()
+			column.AutoIncrement = true
+			p.group()
+		case p.accept("GENERATED"):
+			// GENERATED {ALWAYS|BY DEFAULT} AS IDENTITY | AS (expr) STORED
+			for !p.done() && !p.is("AS") {
+				p.next()
+			}
+			p.accept("AS")
+			if p.accept("IDENTITY") {
+				column.AutoIncrement, column.NotNull = true, true
+			}
+			p.group()
+		case p.is("REFERENCES"):
+			fk := ForeignKey{Columns: []string{column.Name}}
+			p.references(&fk)
+			t.ForeignKeys = append(t.ForeignKeys, fk)
+		case p.accept("CONSTRAINT"):
+			p.next()
+		default:
+			p.next()
+			p.group()
+		}
+	}
+	return column, true
+}
+
+// defaultValue reads a DEFAULT expression
+func (p *parser) defaultValue() string {
+	start := p.pos
+	if p.peek().kind == tokenPunct && p.peek().text == "(" {
+		p.group()
+	} else {
+		p.punct("-") // Negative number
+		p.next()
+		if p.peek().kind == tokenPunct && p.peek().text == "(" {
+			p.group() // Function call such as now()
+		}
+	}
+	for p.punct("::") {
+		p.next() // PostgreSQL cast such as 'x'::text or '{}'::text[]
+		p.group()
+		p.punct("[]")
+	}
+	value := joinTokens(p.tokens[start:p.pos])
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		value = strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+	}
+	return value
+}
+
+// references parses REFERENCES table [(columns)] [ON DELETE action] [ON UPDATE action]
+func (p *parser) references(fk *ForeignKey) {
+	if !p.accept("REFERENCES") {
+		return
+	}
+	fk.RefTable = p.name()
+	if p.peek().kind == tokenPunct && p.peek().text == "(" {
+		fk.RefColumns = columnList(p.group())
+	}
+	for !p.done() {
+		switch {
+		case p.accept("ON", "DELETE"):
+			fk.OnDelete = p.referentialAction()
+		case p.accept("ON", "UPDATE"):
+			fk.OnUpdate = p.referentialAction()
+		case p.is("MATCH", "DEFERRABLE", "INITIALLY", "IMMEDIATE", "DEFERRED", "NOT"):
+			if p.accept("NOT", "NULL") {
+				p.pos -= 2 // A column constraint, not part of the reference
+				return
+			}
+			p.next()
+		default:
+			return
+		}
+	}
+}
+
+// referentialAction reads CASCADE, RESTRICT, SET NULL, SET DEFAULT or NO ACTION
+func (p *parser) referentialAction() string {
+	switch {
+	case p.accept("SET", "NULL"):
+		return "set null"
+	case p.accept("SET", "DEFAULT"):
+		return "set default"
+	case p.accept("NO", "ACTION"):
+		return "no action"
+	}
+	return strings.ToLower(p.next().text)
+}
+
+// createIndex parses CREATE [UNIQUE] INDEX [CONCURRENTLY] [IF NOT EXISTS] [name] ON table (...)
+func (p *parser) createIndex(db *Database, source string, unique bool) {
+	p.accept("CONCURRENTLY")
+	p.accept("IF", "NOT", "EXISTS")
+	name := ""
+	if !p.is("ON") {
+		name = p.name()
+	}
+	if !p.accept("ON") {
+		return
+	}
+	p.accept("ONLY")
+	t, ok := db.Table(p.name())
+	if !ok {
+		return
+	}
+	if p.accept("USING") {
+		p.next()
+	}
+	columns := columnList(p.group())
+	if len(columns) == 0 {
+		return
+	}
+	t.dropConstraint(name)
+	t.Indexes = append(t.Indexes, Index{Name: name, Columns: columns, Unique: unique})
+	if unique && len(columns) == 1 {
+		if c, ok := t.Column(columns[0]); ok {
+			c.Unique = true
+		}
+	}
+	t.addSource(source)
+}
+
+// alterTable parses ALTER TABLE name action[, action...]
+func (p *parser) alterTable(db *Database, source string) {
+	p.accept("IF", "EXISTS")
+	p.accept("ONLY")
+	t, ok := db.Table(p.name())
+	if !ok {
+		return
+	}
+	t.addSource(source)
+
+	for _, action := range splitElements(p.tokens[p.pos:]) {
+		ap := &parser{tokens: action}
+		ap.alterAction(db, t, source)
+	}
+}
+
+// alterAction applies one action of an ALTER TABLE statement
+func (p *parser) alterAction(db *Database, t *Table, source string) {
+	switch {
+	case p.accept("ADD"):
+		if p.accept("COLUMN") {
+			p.accept("IF", "NOT", "EXISTS")
+			if column, ok := p.columnDefinition(t); ok {
+				t.setColumn(column)
+			}
+			return
+		}
+		if !p.tableConstraint(t) {
+			if column, ok := p.columnDefinition(t); ok {
+				t.setColumn(column)
+			}
+		}
+	case p.accept("DROP"):
+		switch {
+		case p.accept("COLUMN"):
+			p.accept("IF", "EXISTS")
+			t.dropColumn(p.next().text)
+		case p.accept("CONSTRAINT"), p.accept("FOREIGN", "KEY"), p.accept("INDEX"), p.accept("KEY"):
+			p.accept("IF", "EXISTS")
+			t.dropConstraint(p.next().text)
+		case p.accept("PRIMARY", "KEY"):
+			t.PrimaryKey = nil
+		default:
+			t.dropColumn(p.next().text)
+		}
+	case p.accept("RENAME", "TO"), p.accept("RENAME", "AS"):
+		renameTable(db, t.Name, p.name(), source)
+	case p.accept("RENAME"):
+		p.accept("COLUMN")
+		from := p.next().text
+		if p.accept("TO") {
+			t.renameColumn(from, p.next().text)
+		}
+	case p.accept("ALTER"):
+		p.accept("COLUMN")
+		c, ok := t.Column(p.next().text)
+		if !ok {
+			return
+		}
+		switch {
+		case p.accept("TYPE"), p.accept("SET", "DATA", "TYPE"):
+			start := p.pos
+			for !p.done() && !p.is("USING", "COLLATE") {
+				if p.peek().kind == tokenPunct && p.peek().text == "(" {
+					p.group()
+					continue
+				}
+				p.next()
+			}
+			c.Type = joinTokens(p.tokens[start:p.pos])
+		case p.accept("SET", "NOT", "NULL"):
+			c.NotNull = true
+		case p.accept("DROP", "NOT", "NULL"):
+			c.NotNull = false
+		case p.accept("SET", "DEFAULT"):
+			c.Default = p.defaultValue()
+		case p.accept("DROP", "DEFAULT"):
+			c.Default = ""
+		}
+	case p.accept("MODIFY"):
+		p.accept("COLUMN")
+		if column, ok := p.columnDefinition(t); ok {
+			t.setColumn(column)
+		}
+	case p.accept("CHANGE"):
+		p.accept("COLUMN")
+		old := p.next().text
+		if column, ok := p.columnDefinition(t); ok {
+			t.renameColumn(old, column.Name)
+			t.setColumn(column)
+		}
+	}
+}
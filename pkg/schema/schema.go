@@ -0,0 +1,205 @@
+// Package schema builds a model of the project's database tables from SQL
+// migrations, goose Go migrations and local SQLite databases.
+package schema
+
+import (
+	"sort"
+	"strings"
+)
+
+// Database is one schema source: the project's migrations replayed in order,
+// or a SQLite database file
+type Database struct {
+	Name   string            `json:"name"`
+	Kind   string            `json:"kind"` // "migrations" or "sqlite"
+	Path   string            `json:"path,omitempty"`
+	Tables map[string]*Table `json:"tables"`
+	Error  string            `json:"error,omitempty"` // Why the source could not be read
+}
+
+// Table is a table definition
+type Table struct {
+	Name        string       `json:"name"`
+	Columns     []Column     `json:"columns"`
+	PrimaryKey  []string     `json:"primary_key,omitempty"`
+	Indexes     []Index      `json:"indexes,omitempty"`
+	ForeignKeys []ForeignKey `json:"foreign_keys,omitempty"`
+	Sources     []string     `json:"sources,omitempty"` // Files that created or altered the table, in order
+}
+
+// Column is a table column
+type Column struct {
+	Name          string `json:"name"`
+	Type          string `json:"type"`
+	NotNull       bool   `json:"not_null,omitempty"`
+	Default       string `json:"default,omitempty"`
+	Unique        bool   `json:"unique,omitempty"`
+	AutoIncrement bool   `json:"auto_increment,omitempty"`
+}
+
+// Index is a secondary index
+type Index struct {
+	Name    string   `json:"name,omitempty"`
+	Columns []string `json:"columns"`
+	Unique  bool     `json:"unique,omitempty"`
+}
+
+// ForeignKey is a foreign key constraint
+type ForeignKey struct {
+	Name       string   `json:"name,omitempty"`
+	Columns    []string `json:"columns"`
+	RefTable   string   `json:"ref_table"`
+	RefColumns []string `json:"ref_columns,omitempty"`
+	OnDelete   string   `json:"on_delete,omitempty"`
+	OnUpdate   string   `json:"on_update,omitempty"`
+}
+
+// newDatabase creates an empty database
+func newDatabase(name, kind, path string) *Database {
+	return &Database{Name: name, Kind: kind, Path: path, Tables: make(map[string]*Table)}
+}
+
+// TableNames returns the table names in alphabetical order
+func (db *Database) TableNames() []string {
+	names := make([]string, 0, len(db.Tables))
+	for name := range db.Tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Table finds a table by name, ignoring case
+func (db *Database) Table(name string) (*Table, bool) {
+	if t, ok := db.Tables[name]; ok {
+		return t, true
+	}
+	for key, t := range db.Tables {
+		if strings.EqualFold(key, name) {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// Column finds a column by name, ignoring case
+func (t *Table) Column(name string) (*Column, bool) {
+	for i := range t.Columns {
+		if strings.EqualFold(t.Columns[i].Name, name) {
+			return &t.Columns[i], true
+		}
+	}
+	return nil, false
+}
+
+// ColumnNames returns the column names in definition order
+func (t *Table) ColumnNames() []string {
+	names := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// addSource records a file as touching the table
+func (t *Table) addSource(source string) {
+	if source == "" {
+		return
+	}
+	if n := len(t.Sources); n == 0 || t.Sources[n-1] != source {
+		t.Sources = append(t.Sources, source)
+	}
+}
+
+// setColumn adds a column, or replaces the column with the same name
+func (t *Table) setColumn(column Column) {
+	if existing, ok := t.Column(column.Name); ok {
+		*existing = column
+		return
+	}
+	t.Columns = append(t.Columns, column)
+}
+
+// dropColumn removes a column and the indexes and keys that use it
+func (t *Table) dropColumn(name string) {
+	for i := range t.Columns {
+		if strings.EqualFold(t.Columns[i].Name, name) {
+			t.Columns = append(t.Columns[:i], t.Columns[i+1:]...)
+			break
+		}
+	}
+	t.PrimaryKey = without(t.PrimaryKey, name)
+	indexes := t.Indexes[:0]
+	for _, index := range t.Indexes {
+		if !containsFold(index.Columns, name) {
+			indexes = append(indexes, index)
+		}
+	}
+	t.Indexes = indexes
+	keys := t.ForeignKeys[:0]
+	for _, fk := range t.ForeignKeys {
+		if !containsFold(fk.Columns, name) {
+			keys = append(keys, fk)
+		}
+	}
+	t.ForeignKeys = keys
+}
+
+// renameColumn renames a column everywhere it is referenced in the table
+func (t *Table) renameColumn(from, to string) {
+	if c, ok := t.Column(from); ok {
+		c.Name = to
+	}
+	rename := func(names []string) {
+		for i := range names {
+			if strings.EqualFold(names[i], from) {
+				names[i] = to
+			}
+		}
+	}
+	rename(t.PrimaryKey)
+	for _, index := range t.Indexes {
+		rename(index.Columns)
+	}
+	for _, fk := range t.ForeignKeys {
+		rename(fk.Columns)
+	}
+}
+
+// dropConstraint removes a named index or foreign key
+func (t *Table) dropConstraint(name string) bool {
+	for i, index := range t.Indexes {
+		if strings.EqualFold(index.Name, name) {
+			t.Indexes = append(t.Indexes[:i], t.Indexes[i+1:]...)
+			return true
+		}
+	}
+	for i, fk := range t.ForeignKeys {
+		if strings.EqualFold(fk.Name, name) {
+			t.ForeignKeys = append(t.ForeignKeys[:i], t.ForeignKeys[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// without returns names without the entries equal (ignoring case) to name
+func without(names []string, name string) []string {
+	var kept []string
+	for _, n := range names {
+		if !strings.EqualFold(n, name) {
+			kept = append(kept, n)
+		}
+	}
+	return kept
+}
+
+// containsFold reports whether names contains name, ignoring case
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
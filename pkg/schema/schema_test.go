@@ -0,0 +1,249 @@
+package schema
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) (string, []string) {
+	t.Helper()
+	root := t.TempDir()
+	var paths []string
+	for name, content := range files {
+		full := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, name)
+	}
+	return root, paths
+}
+
+func columnSummary(table *Table) []string {
+	var summary []string
+	for _, c := range table.Columns {
+		s := c.Name + " " + c.Type
+		if c.NotNull {
+			s += " NOT NULL"
+		}
+		if c.Default != "" {
+			s += " DEFAULT " + c.Default
+		}
+		summary = append(summary, s)
+	}
+	return summary
+}
+
+func TestApply_DDL(t *testing.T) {
+	db := newDatabase("test", "migrations", "")
+	db.Apply(`
+-- PostgreSQL
+CREATE TABLE IF NOT EXISTS public.users (
+    id BIGSERIAL PRIMARY KEY,
+    email varchar(255) NOT NULL UNIQUE,
+    name text,
+    balance numeric(10, 2) DEFAULT 0,
+    tags text[] DEFAULT '{}'::text[],
+    created_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+CREATE TABLE "posts" (
+    id integer GENERATED ALWAYS AS IDENTITY,
+    user_id bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    title text NOT NULL, -- comment, with a comma
+    body text,
+    CONSTRAINT posts_pkey PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX posts_title_idx ON posts USING btree (user_id, lower(title));
+
+CREATE FUNCTION touch() RETURNS trigger AS $$
+BEGIN
+    NEW.updated_at = now(); RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE posts ADD COLUMN published boolean DEFAULT false, DROP COLUMN body;
+ALTER TABLE posts RENAME COLUMN title TO headline;
+ALTER TABLE users ALTER COLUMN name SET NOT NULL;
+ALTER TABLE users ALTER COLUMN name TYPE varchar(100);
+
+/* MySQL */
+CREATE TABLE `+"`orders`"+` (
+  `+"`id`"+` int unsigned NOT NULL AUTO_INCREMENT,
+  `+"`post_id`"+` bigint NOT NULL,
+  `+"`status`"+` enum('new','paid') NOT NULL DEFAULT 'new',
+  PRIMARY KEY (`+"`id`"+`),
+  KEY `+"`orders_post_id`"+` (`+"`post_id`"+`),
+  CONSTRAINT `+"`orders_post_fk`"+` FOREIGN KEY (`+"`post_id`"+`) REFERENCES `+"`posts`"+` (`+"`id`"+`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+ALTER TABLE orders DROP FOREIGN KEY orders_post_fk;
+CREATE TABLE tmp (x int);
+DROP TABLE IF EXISTS tmp;
+ALTER TABLE orders RENAME TO purchases;
+`, "schema.sql")
+
+	if got, want := db.TableNames(), []string{"posts", "purchases", "users"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("TableNames = %v, want %v", got, want)
+	}
+
+	users, _ := db.Table("users")
+	wantUsers := []string{
+		"id BIGSERIAL NOT NULL",
+		"email varchar(255) NOT NULL",
+		"name varchar(100) NOT NULL",
+		"balance numeric(10, 2) DEFAULT 0",
+		"tags text[] DEFAULT '{}'::text[]",
+		"created_at timestamp with time zone NOT NULL DEFAULT now()",
+	}
+	if got := columnSummary(users); !reflect.DeepEqual(got, wantUsers) {
+		t.Errorf("users columns:\n got %q\nwant %q", got, wantUsers)
+	}
+	if email, _ := users.Column("email"); !email.Unique {
+		t.Error("Expected email to be unique")
+	}
+	if !reflect.DeepEqual(users.PrimaryKey, []string{"id"}) {
+		t.Errorf("users primary key = %v", users.PrimaryKey)
+	}
+
+	posts, _ := db.Table("posts")
+	if got, want := posts.ColumnNames(), []string{"id", "user_id", "headline", "published"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("posts columns = %v, want %v", got, want)
+	}
+	wantFK := []ForeignKey{{Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}, OnDelete: "cascade"}}
+	if !reflect.DeepEqual(posts.ForeignKeys, wantFK) {
+		t.Errorf("posts foreign keys = %+v, want %+v", posts.ForeignKeys, wantFK)
+	}
+	wantIndexes := []Index{{Name: "posts_title_idx", Columns: []string{"user_id", "lower(title)"}, Unique: true}}
+	if !reflect.DeepEqual(posts.Indexes, wantIndexes) {
+		t.Errorf("posts indexes = %+v, want %+v", posts.Indexes, wantIndexes)
+	}
+	if id, _ := posts.Column("id"); !id.AutoIncrement {
+		t.Error("Expected identity column to be auto-increment")
+	}
+
+	purchases, _ := db.Table("purchases")
+	if status, _ := purchases.Column("status"); status == nil || status.Type != "enum('new', 'paid')" || status.Default != "new" {
+		t.Errorf("Unexpected status column: %+v", status)
+	}
+	if len(purchases.ForeignKeys) != 0 || len(purchases.Indexes) != 1 {
+		t.Errorf("Unexpected purchases constraints: %+v %+v", purchases.ForeignKeys, purchases.Indexes)
+	}
+}
+
+func TestBuild_Migrations(t *testing.T) {
+	root, files := writeFiles(t, map[string]string{
+		// golang-migrate: versions sort numerically, down files are skipped
+		"db/migrations/2_add_name.up.sql":   "ALTER TABLE accounts ADD COLUMN name text;",
+		"db/migrations/2_add_name.down.sql": "ALTER TABLE accounts DROP COLUMN name;",
+		"db/migrations/10_add_age.up.sql":   "ALTER TABLE accounts ADD COLUMN age int;",
+		"db/migrations/1_init.up.sql":       "CREATE TABLE accounts (id serial PRIMARY KEY);",
+		// goose SQL with an inline down section, and a goose Go migration
+		"goose/00001_items.sql": "-- +goose Up\nCREATE TABLE items (id int);\n-- +goose Down\nDROP TABLE items;\n",
+		"goose/00002_items_sku.go": `package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigration(upItemsSku, downItemsSku)
+}
+
+func upItemsSku(tx *sql.Tx) error {
+	_, err := tx.Exec(` + "`ALTER TABLE items ADD COLUMN sku varchar(32) NOT NULL`" + `)
+	return err
+}
+
+func downItemsSku(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE items DROP COLUMN sku")
+	return err
+}
+`,
+		"main.go": "package main\n",
+	})
+
+	migrations := MigrationFiles(root, files)
+	want := []string{"db/migrations/1_init.up.sql", "db/migrations/2_add_name.up.sql", "db/migrations/10_add_age.up.sql", "goose/00001_items.sql", "goose/00002_items_sku.go"}
+	if !reflect.DeepEqual(migrations, want) {
+		t.Fatalf("MigrationFiles = %v, want %v", migrations, want)
+	}
+
+	databases := Build(root, files, nil)
+	if len(databases) != 1 || databases[0].Name != MigrationsDatabase {
+		t.Fatalf("Unexpected databases: %+v", databases)
+	}
+	db := databases[0]
+	accounts, _ := db.Table("accounts")
+	if got := accounts.ColumnNames(); !reflect.DeepEqual(got, []string{"id", "name", "age"}) {
+		t.Errorf("accounts columns = %v", got)
+	}
+	items, _ := db.Table("items")
+	if items == nil || !reflect.DeepEqual(items.ColumnNames(), []string{"id", "sku"}) {
+		t.Errorf("Unexpected items table: %+v", items)
+	}
+	if !reflect.DeepEqual(items.Sources, []string{"goose/00001_items.sql", "goose/00002_items_sku.go"}) {
+		t.Errorf("items sources = %v", items.Sources)
+	}
+}
+
+func TestCheckReadOnlyQuery(t *testing.T) {
+	allowed := []string{"SELECT * FROM users;", "with x as (select 1) select * from x", "PRAGMA table_info(users)", "EXPLAIN QUERY PLAN SELECT 1"}
+	for _, q := range allowed {
+		if _, err := checkReadOnlyQuery(q); err != nil {
+			t.Errorf("checkReadOnlyQuery(%q) failed: %v", q, err)
+		}
+	}
+	rejected := []string{"DELETE FROM users", "SELECT 1; DROP TABLE users", ".shell ls", "PRAGMA journal_mode = WAL", "", "ATTACH 'x.db' AS x"}
+	for _, q := range rejected {
+		if _, err := checkReadOnlyQuery(q); err == nil {
+			t.Errorf("checkReadOnlyQuery(%q) should fail", q)
+		}
+	}
+}
+
+func TestSQLite(t *testing.T) {
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		t.Skip("sqlite3 not installed")
+	}
+	root := t.TempDir()
+	dbPath := filepath.Join(root, "app.db")
+	setup := "CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT NOT NULL UNIQUE);" +
+		"CREATE INDEX users_email ON users (email);" +
+		"INSERT INTO users (email) VALUES ('a@x'), ('b@x'), ('c@x');"
+	if out, err := exec.Command("sqlite3", dbPath, setup).CombinedOutput(); err != nil {
+		t.Fatalf("Failed to create database: %v: %s", err, out)
+	}
+
+	databases := Build(root, nil, []string{"app.db"})
+	if len(databases) != 1 || databases[0].Error != "" {
+		t.Fatalf("Unexpected databases: %+v", databases[0])
+	}
+	users, ok := databases[0].Table("users")
+	if !ok || !reflect.DeepEqual(users.ColumnNames(), []string{"id", "email"}) || len(users.Indexes) != 1 {
+		t.Fatalf("Unexpected users table: %+v", users)
+	}
+
+	result, err := Query(context.Background(), dbPath, "SELECT id, email FROM users ORDER BY id", 2)
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if !reflect.DeepEqual(result.Columns, []string{"id", "email"}) || len(result.Rows) != 2 || !result.Truncated || result.Rows[1][1] != "b@x" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	// The database is opened read-only even if validation were bypassed
+	if _, err := runSQLite(context.Background(), dbPath, "DELETE FROM users"); err == nil {
+		t.Error("Expected a write to fail on a read-only database")
+	}
+}
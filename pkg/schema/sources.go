@@ -0,0 +1,190 @@
+package schema
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	gotoken "go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MigrationsDatabase is the name of the schema replayed from migration files
+const MigrationsDatabase = "migrations"
+
+// Build replays the project's SQL and goose Go migrations into one database
+// and reads each SQLite file. files are project-relative paths (e.g. from the
+// index); sqliteFiles are project-relative SQLite database paths.
+func Build(projectRoot string, files []string, sqliteFiles []string) []*Database {
+	var databases []*Database
+
+	if migrations := MigrationFiles(projectRoot, files); len(migrations) > 0 {
+		db := newDatabase(MigrationsDatabase, "migrations", "")
+		for _, file := range migrations {
+			for _, sql := range migrationSQL(projectRoot, file) {
+				db.Apply(sql, file)
+			}
+		}
+		databases = append(databases, db)
+	}
+
+	for _, file := range sqliteFiles {
+		db, err := ReadSQLite(filepath.Join(projectRoot, file))
+		if err != nil {
+			db = newDatabase(file, "sqlite", file)
+			db.Error = err.Error()
+		}
+		db.Name, db.Path = file, file
+		databases = append(databases, db)
+	}
+	return databases
+}
+
+// MigrationFiles returns the schema files among the project files in the order
+// they apply: .sql files (skipping golang-migrate .down.sql files) and goose Go
+// migrations, ordered by directory and then by numeric version prefix
+func MigrationFiles(projectRoot string, files []string) []string {
+	var migrations []string
+	for _, file := range files {
+		switch {
+		case strings.HasSuffix(file, ".down.sql"):
+		case strings.HasSuffix(file, ".sql"):
+			migrations = append(migrations, file)
+		case strings.HasSuffix(file, ".go") && !strings.HasSuffix(file, "_test.go") && migrationVersion(path.Base(file)) >= 0:
+			if data, err := os.ReadFile(filepath.Join(projectRoot, file)); err == nil && strings.Contains(string(data), "goose.AddMigration") {
+				migrations = append(migrations, file)
+			}
+		}
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		a, b := migrations[i], migrations[j]
+		if path.Dir(a) != path.Dir(b) {
+			return path.Dir(a) < path.Dir(b)
+		}
+		va, vb := migrationVersion(path.Base(a)), migrationVersion(path.Base(b))
+		if va != vb {
+			return va < vb
+		}
+		return a < b
+	})
+	return migrations
+}
+
+// migrationVersion returns the numeric prefix of a migration file name
+// (1_init.up.sql, 00002_users.sql, 20240101120000_posts.go), or -1
+func migrationVersion(name string) int64 {
+	end := 0
+	for end < len(name) && name[end] >= '0' && name[end] <= '9' {
+		end++
+	}
+	if end == 0 {
+		return -1
+	}
+	version, err := strconv.ParseInt(name[:end], 10, 64)
+	if err != nil {
+		return -1
+	}
+	return version
+}
+
+// migrationSQL returns the SQL a migration file applies when migrating up
+func migrationSQL(projectRoot, file string) []string {
+	data, err := os.ReadFile(filepath.Join(projectRoot, file))
+	if err != nil {
+		return nil
+	}
+	if strings.HasSuffix(file, ".go") {
+		return gooseGoSQL(data)
+	}
+	return []string{upSection(string(data))}
+}
+
+// upSection strips the down part of goose (-- +goose Down) and dbmate
+// (-- migrate:down) migrations
+func upSection(sql string) string {
+	lines := strings.Split(sql, "\n")
+	up := true
+	var kept []string
+	for _, line := range lines {
+		marker := strings.ToLower(strings.Join(strings.Fields(line), " "))
+		switch {
+		case strings.HasPrefix(marker, "-- +goose up"), strings.HasPrefix(marker, "-- migrate:up"):
+			up = true
+		case strings.HasPrefix(marker, "-- +goose down"), strings.HasPrefix(marker, "-- migrate:down"):
+			up = false
+		case up:
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// gooseGoSQL extracts the SQL string literals executed by the up function of
+// a goose Go migration (registered with goose.AddMigration and friends)
+func gooseGoSQL(data []byte) []string {
+	fset := gotoken.NewFileSet()
+	file, err := goparser.ParseFile(fset, "", data, 0)
+	if err != nil {
+		return nil
+	}
+
+	funcs := make(map[string]*ast.FuncDecl)
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil {
+			funcs[fn.Name.Name] = fn
+		}
+	}
+
+	var upBodies []ast.Node
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || !strings.HasPrefix(sel.Sel.Name, "AddMigration") && !strings.HasPrefix(sel.Sel.Name, "AddNamedMigration") {
+			return true
+		}
+		up := call.Args[0]
+		if strings.HasPrefix(sel.Sel.Name, "AddNamedMigration") && len(call.Args) > 1 {
+			up = call.Args[1]
+		}
+		switch fn := up.(type) {
+		case *ast.Ident:
+			if decl, ok := funcs[fn.Name]; ok && decl.Body != nil {
+				upBodies = append(upBodies, decl.Body)
+			}
+		case *ast.FuncLit:
+			upBodies = append(upBodies, fn.Body)
+		}
+		return true
+	})
+
+	var statements []string
+	for _, body := range upBodies {
+		ast.Inspect(body, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || (sel.Sel.Name != "Exec" && sel.Sel.Name != "ExecContext") {
+				return true
+			}
+			for _, arg := range call.Args {
+				if lit, ok := arg.(*ast.BasicLit); ok && lit.Kind == gotoken.STRING {
+					if sql, err := strconv.Unquote(lit.Value); err == nil {
+						statements = append(statements, sql)
+					}
+					break
+				}
+			}
+			return true
+		})
+	}
+	return statements
+}
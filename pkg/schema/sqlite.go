@@ -0,0 +1,161 @@
+package schema
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// ErrNoSQLite is returned when the sqlite3 command-line shell is not installed
+var ErrNoSQLite = errors.New("sqlite3 command not found; install the SQLite command-line shell to read SQLite databases")
+
+// sqliteTimeout bounds every sqlite3 invocation
+const sqliteTimeout = 30 * time.Second
+
+// sqliteHeader is the magic string at the start of every SQLite database file
+const sqliteHeader = "SQLite format 3\x00"
+
+// QueryResult holds the rows returned by a query
+type QueryResult struct {
+	Columns   []string   `json:"columns"`
+	Rows      [][]string `json:"rows"`
+	Truncated bool       `json:"truncated,omitempty"` // More rows matched than the limit
+}
+
+// IsSQLiteFile reports whether a file is a SQLite database
+func IsSQLiteFile(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	header := make([]byte, len(sqliteHeader))
+	if _, err := io.ReadFull(f, header); err != nil {
+		return false
+	}
+	return string(header) == sqliteHeader
+}
+
+// ReadSQLite reads the schema of a SQLite database
+func ReadSQLite(path string) (*Database, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), sqliteTimeout)
+	defer cancel()
+
+	result, err := runSQLite(ctx, path, "SELECT sql FROM sqlite_master WHERE sql IS NOT NULL AND name NOT LIKE 'sqlite_%' ORDER BY CASE type WHEN 'table' THEN 0 ELSE 1 END, rowid")
+	if err != nil {
+		return nil, err
+	}
+	db := newDatabase(path, "sqlite", path)
+	for _, row := range result.Rows {
+		if len(row) > 0 {
+			db.Apply(row[0], "")
+		}
+	}
+	return db, nil
+}
+
+// Query runs a read-only query against a SQLite database and returns at most
+// limit rows. Only single SELECT, WITH, VALUES, EXPLAIN and read-only PRAGMA
+// statements are accepted, and the database is opened read-only.
+func Query(ctx context.Context, path, query string, limit int) (*QueryResult, error) {
+	query, err := checkReadOnlyQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	keyword := strings.ToUpper(tokenize(query)[0].text)
+	if limit > 0 && (keyword == "SELECT" || keyword == "WITH" || keyword == "VALUES") {
+		// Ask for one extra row to tell whether the result was truncated
+		query = fmt.Sprintf("SELECT * FROM (%s) LIMIT %d", query, limit+1)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sqliteTimeout)
+	defer cancel()
+	result, err := runSQLite(ctx, path, query)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(result.Rows) > limit {
+		result.Rows = result.Rows[:limit]
+		result.Truncated = true
+	}
+	return result, nil
+}
+
+// checkReadOnlyQuery validates a query for Query and returns it without the
+// trailing semicolon
+func checkReadOnlyQuery(query string) (string, error) {
+	query = strings.TrimSpace(query)
+	if strings.HasPrefix(query, ".") {
+		return "", fmt.Errorf("sqlite3 dot-commands are not allowed")
+	}
+	statements := splitStatements(tokenize(query))
+	if len(statements) == 0 {
+		return "", fmt.Errorf("query is empty")
+	}
+	if len(statements) > 1 {
+		return "", fmt.Errorf("only a single statement is allowed")
+	}
+	stmt := statements[0]
+	first := stmt[0]
+	if first.kind != tokenWord {
+		return "", fmt.Errorf("query must start with SELECT, WITH, VALUES, EXPLAIN or PRAGMA")
+	}
+	switch strings.ToUpper(first.text) {
+	case "SELECT", "WITH", "VALUES", "EXPLAIN":
+	case "PRAGMA":
+		for _, t := range stmt {
+			if t.kind == tokenPunct && t.text == "=" {
+				return "", fmt.Errorf("PRAGMA assignments are not allowed")
+			}
+		}
+	default:
+		return "", fmt.Errorf("only read-only queries are allowed (SELECT, WITH, VALUES, EXPLAIN, PRAGMA), got %s", strings.ToUpper(first.text))
+	}
+	return strings.TrimRight(query, "; \t\n"), nil
+}
+
+// runSQLite runs one statement with the sqlite3 shell. The database is opened
+// read-only and -safe disables ATTACH, extension loading and shell escapes.
+func runSQLite(ctx context.Context, path, sql string) (*QueryResult, error) {
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		return nil, ErrNoSQLite
+	}
+	if !IsSQLiteFile(path) {
+		return nil, fmt.Errorf("not a SQLite database: %s", path)
+	}
+
+	cmd := exec.CommandContext(ctx, "sqlite3", "-readonly", "-safe", "-bail", "-batch", "-csv", "-header", "-nullvalue", "NULL", path, sql)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	cmd.Stdin = strings.NewReader("")
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("query timed out")
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("sqlite3: %s", msg)
+	}
+
+	reader := csv.NewReader(&stdout)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse sqlite3 output: %w", err)
+	}
+	result := &QueryResult{Rows: [][]string{}}
+	if len(records) > 0 {
+		result.Columns, result.Rows = records[0], records[1:]
+	}
+	return result, nil
+}
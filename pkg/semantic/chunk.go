@@ -0,0 +1,115 @@
+package semantic
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/axon/pkg/indexer"
+)
+
+const (
+	// maxChunkLines is the maximum number of lines in a single chunk
+	maxChunkLines = 80
+
+	// windowLines is the chunk size used for files without symbols
+	windowLines = 60
+
+	// maxEmbedChars limits the text sent to the embedder per chunk
+	maxEmbedChars = 2000
+)
+
+// Chunk is a contiguous region of a file, usually one symbol, that is embedded as a unit
+type Chunk struct {
+	Path    string `json:"path"`
+	Symbol  string `json:"symbol,omitempty"`
+	Line    int    `json:"line"`
+	EndLine int    `json:"end_line"`
+	Text    string `json:"-"`
+	Hash    string `json:"-"`
+}
+
+// key uniquely identifies a chunk within the store
+func (c Chunk) key() string {
+	return c.Path + "#" + c.Symbol + "#" + strconv.Itoa(c.Line)
+}
+
+// embedText is the text sent to the embedder: location header plus (capped) content
+func (c Chunk) embedText() string {
+	header := c.Path
+	if c.Symbol != "" {
+		header += " " + c.Symbol
+	}
+	text := c.Text
+	if len(text) > maxEmbedChars {
+		text = text[:maxEmbedChars]
+	}
+	return header + "\n" + text
+}
+
+// ChunkFile splits file content into chunks at symbol boundaries.
+// Content before the first symbol becomes its own chunk, long symbols are split
+// into pieces of at most maxChunkLines, and files without symbols are split into
+// fixed windows.
+func ChunkFile(path, content string, symbols []indexer.Symbol) []Chunk {
+	lines := strings.Split(content, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	// Symbol start lines, sorted and de-duplicated
+	sorted := make([]indexer.Symbol, 0, len(symbols))
+	seen := make(map[int]bool)
+	for _, sym := range symbols {
+		if sym.Line < 1 || sym.Line > len(lines) || seen[sym.Line] {
+			continue
+		}
+		seen[sym.Line] = true
+		sorted = append(sorted, sym)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Line < sorted[j].Line })
+
+	var chunks []Chunk
+	if len(sorted) == 0 {
+		for start := 1; start <= len(lines); start += windowLines {
+			chunks = appendChunk(chunks, path, "", lines, start, min(start+windowLines-1, len(lines)))
+		}
+		return chunks
+	}
+
+	if sorted[0].Line > 1 {
+		chunks = appendChunk(chunks, path, "", lines, 1, sorted[0].Line-1)
+	}
+	for i, sym := range sorted {
+		end := len(lines)
+		if i+1 < len(sorted) {
+			end = sorted[i+1].Line - 1
+		}
+		for start := sym.Line; start <= end; start += maxChunkLines {
+			chunks = appendChunk(chunks, path, sym.Name, lines, start, min(start+maxChunkLines-1, end))
+		}
+	}
+	return chunks
+}
+
+// appendChunk adds the chunk for lines[start..end] (1-based, inclusive) unless it is blank
+func appendChunk(chunks []Chunk, path, symbol string, lines []string, start, end int) []Chunk {
+	text := strings.Join(lines[start-1:end], "\n")
+	if strings.TrimSpace(text) == "" {
+		return chunks
+	}
+	sum := sha256.Sum256([]byte(path + "\x00" + symbol + "\x00" + text))
+	return append(chunks, Chunk{
+		Path:    path,
+		Symbol:  symbol,
+		Line:    start,
+		EndLine: end,
+		Text:    text,
+		Hash:    hex.EncodeToString(sum[:]),
+	})
+}
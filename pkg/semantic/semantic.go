@@ -0,0 +1,224 @@
+package semantic
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/axon/pkg/fsctx"
+	"github.com/axon/pkg/indexer"
+)
+
+const (
+	// StoreFile is the vector store location relative to the project root
+	StoreFile = ".axon/vectors.gob"
+
+	// embedBatchSize is the number of chunks sent per embeddings request
+	embedBatchSize = 32
+)
+
+// Embedder turns texts into vectors.
+// *llm.Client implements it via the /v1/embeddings endpoint.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Result is a chunk matched by a semantic search
+type Result struct {
+	Path    string  `json:"file"`
+	Symbol  string  `json:"symbol,omitempty"`
+	Line    int     `json:"line"`
+	EndLine int     `json:"end_line"`
+	Score   float64 `json:"score"`
+	Preview string  `json:"preview"`
+}
+
+// UpdateStats reports what an Update call did
+type UpdateStats struct {
+	Chunks   int // Chunks currently in the store
+	Embedded int // Chunks that were (re-)embedded
+	Removed  int // Stale chunks dropped from the store
+}
+
+// Index keeps embeddings of project chunks in sync with the project index
+type Index struct {
+	projectRoot string
+	index       *indexer.Index
+	embedder    Embedder
+	storePath   string
+	model       string
+
+	mu    sync.Mutex
+	store *Store
+}
+
+// NewIndex creates a semantic index for a project.
+// model identifies the embedding model; vectors from a different model are discarded.
+func NewIndex(projectRoot string, projectIndex *indexer.Index, embedder Embedder, model string) *Index {
+	return &Index{
+		projectRoot: projectRoot,
+		index:       projectIndex,
+		embedder:    embedder,
+		storePath:   filepath.Join(projectRoot, StoreFile),
+		model:       model,
+	}
+}
+
+// Update chunks all indexed code files and embeds chunks whose content changed
+// since the last run. Unchanged chunks keep their stored vectors.
+func (si *Index) Update(ctx context.Context) (UpdateStats, error) {
+	si.mu.Lock()
+	defer si.mu.Unlock()
+
+	var stats UpdateStats
+	if si.index == nil {
+		return stats, fmt.Errorf("project index not available")
+	}
+
+	if si.store == nil {
+		store, err := OpenStore(si.storePath, si.model)
+		if err != nil {
+			return stats, err
+		}
+		si.store = store
+	}
+
+	current := make(map[string]Chunk)
+	var pending []Chunk
+	for _, path := range si.index.GetAllFilePaths() {
+		info, ok := si.index.GetFileInfo(path)
+		if !ok || info.IsDir || len(info.Symbols) == 0 && !isTextDocument(path) {
+			continue
+		}
+		if info.Size > fsctx.MaxFileSize {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(si.projectRoot, path))
+		if err != nil {
+			continue
+		}
+		for _, chunk := range ChunkFile(path, string(data), info.Symbols) {
+			key := chunk.key()
+			current[key] = chunk
+			if entry, ok := si.store.Entries[key]; !ok || entry.Hash != chunk.Hash {
+				pending = append(pending, chunk)
+			}
+		}
+	}
+
+	// Drop chunks that no longer exist
+	for key := range si.store.Entries {
+		if _, ok := current[key]; !ok {
+			delete(si.store.Entries, key)
+			stats.Removed++
+		}
+	}
+
+	// Embed new and changed chunks in batches
+	sort.Slice(pending, func(i, j int) bool { return pending[i].key() < pending[j].key() })
+	for start := 0; start < len(pending); start += embedBatchSize {
+		batch := pending[start:min(start+embedBatchSize, len(pending))]
+		texts := make([]string, len(batch))
+		for i, chunk := range batch {
+			texts[i] = chunk.embedText()
+		}
+
+		vectors, err := si.embedder.Embed(ctx, texts)
+		if err != nil {
+			// Keep the progress made so far
+			si.store.Save()
+			return stats, fmt.Errorf("failed to embed chunks: %w", err)
+		}
+		for i, chunk := range batch {
+			si.store.Entries[chunk.key()] = &Entry{
+				Path:    chunk.Path,
+				Symbol:  chunk.Symbol,
+				Line:    chunk.Line,
+				EndLine: chunk.EndLine,
+				Hash:    chunk.Hash,
+				Vector:  vectors[i],
+			}
+		}
+		stats.Embedded += len(batch)
+	}
+
+	stats.Chunks = len(si.store.Entries)
+	if stats.Embedded > 0 || stats.Removed > 0 {
+		if err := si.store.Save(); err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+// Search embeds the query and returns the most similar chunks
+func (si *Index) Search(ctx context.Context, query string, limit int) ([]Result, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, fmt.Errorf("query is empty")
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	vectors, err := si.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("embedder returned %d vectors for the query", len(vectors))
+	}
+	queryVector := vectors[0]
+
+	si.mu.Lock()
+	var results []Result
+	if si.store != nil {
+		for _, entry := range si.store.Entries {
+			results = append(results, Result{
+				Path:    entry.Path,
+				Symbol:  entry.Symbol,
+				Line:    entry.Line,
+				EndLine: entry.EndLine,
+				Score:   cosine(queryVector, entry.Vector),
+			})
+		}
+	}
+	si.mu.Unlock()
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Path < results[j].Path
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	for i := range results {
+		results[i].Score = float64(int(results[i].Score*10000)) / 10000
+		results[i].Preview = si.preview(results[i])
+	}
+	return results, nil
+}
+
+// preview returns the first few non-empty lines of a result's chunk
+func (si *Index) preview(r Result) string {
+	content, err := fsctx.ReadFileRange(si.projectRoot, r.Path, r.Line, min(r.EndLine, r.Line+4))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(content)
+}
+
+// isTextDocument checks if a file without symbols is still worth embedding
+func isTextDocument(path string) bool {
+	switch fsctx.GetFileExtension(path) {
+	case ".md", ".txt", ".sql", ".yml", ".yaml":
+		return true
+	}
+	return false
+}
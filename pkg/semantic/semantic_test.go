@@ -0,0 +1,127 @@
+package semantic
+
+import (
+	"context"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/axon/pkg/indexer"
+	"github.com/axon/pkg/project"
+)
+
+// fakeEmbedder is a deterministic bag-of-words embedder based on feature hashing
+type fakeEmbedder struct {
+	calls  int
+	inputs int
+}
+
+func (f *fakeEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	f.calls++
+	f.inputs += len(texts)
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vec := make([]float32, 64)
+		for _, token := range indexer.Tokenize(text) {
+			h := fnv.New32a()
+			h.Write([]byte(token))
+			vec[h.Sum32()%64]++
+		}
+		vectors[i] = vec
+	}
+	return vectors, nil
+}
+
+func TestChunkFile(t *testing.T) {
+	content := "package coupons\n\nimport \"errors\"\n\nfunc Validate(code string) error {\n\treturn nil\n}\n\nfunc Apply() {}\n"
+	symbols := []indexer.Symbol{
+		{Name: "Validate", Type: "function", Line: 5},
+		{Name: "Apply", Type: "function", Line: 9},
+	}
+
+	chunks := ChunkFile("coupons.go", content, symbols)
+	if len(chunks) != 3 {
+		t.Fatalf("Expected 3 chunks (header + 2 symbols), got %d: %+v", len(chunks), chunks)
+	}
+	if chunks[0].Symbol != "" || chunks[0].Line != 1 || chunks[0].EndLine != 4 {
+		t.Errorf("Unexpected header chunk: %+v", chunks[0])
+	}
+	if chunks[1].Symbol != "Validate" || chunks[1].Line != 5 || chunks[1].EndLine != 8 {
+		t.Errorf("Unexpected Validate chunk: %+v", chunks[1])
+	}
+	if chunks[2].Symbol != "Apply" || chunks[2].EndLine != 9 {
+		t.Errorf("Unexpected Apply chunk: %+v", chunks[2])
+	}
+
+	// Long files without symbols are split into windows
+	long := ""
+	for i := 0; i < 150; i++ {
+		long += "line\n"
+	}
+	if chunks := ChunkFile("notes.md", long, nil); len(chunks) != 3 {
+		t.Errorf("Expected 3 windows, got %d", len(chunks))
+	}
+}
+
+func TestIndex_UpdateAndSearch(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"app/Services/CouponService.php": "<?php\nclass CouponService\n{\n    public function validateCoupon($coupon)\n    {\n        // check coupon expiry and usage limit\n    }\n}\n",
+		"app/Services/MailService.php":   "<?php\nclass MailService\n{\n    public function sendWelcomeMail($user)\n    {\n    }\n}\n",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(root, path)
+		os.MkdirAll(filepath.Dir(fullPath), 0755)
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	cfg := &project.Config{}
+	cfg.Context.Ignore = []string{".axon/"}
+
+	idx := indexer.NewIndex(root, cfg)
+	if err := idx.IndexProject(); err != nil {
+		t.Fatalf("IndexProject failed: %v", err)
+	}
+
+	embedder := &fakeEmbedder{}
+	si := NewIndex(root, idx, embedder, "fake")
+
+	stats, err := si.Update(context.Background())
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if stats.Embedded == 0 || stats.Chunks != stats.Embedded {
+		t.Errorf("Unexpected stats on first update: %+v", stats)
+	}
+
+	results, err := si.Search(context.Background(), "where do we validate coupons?", 3)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) == 0 || results[0].Path != "app/Services/CouponService.php" || results[0].Symbol != "validateCoupon" {
+		t.Errorf("Expected validateCoupon to rank first, got %+v", results)
+	}
+
+	// The store persists across instances; unchanged chunks are not re-embedded
+	embedder2 := &fakeEmbedder{}
+	si2 := NewIndex(root, idx, embedder2, "fake")
+	stats, err = si2.Update(context.Background())
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if stats.Embedded != 0 || embedder2.inputs != 0 {
+		t.Errorf("Expected no re-embedding, got %+v (%d inputs)", stats, embedder2.inputs)
+	}
+
+	// A different model invalidates the store
+	si3 := NewIndex(root, idx, &fakeEmbedder{}, "other-model")
+	stats, err = si3.Update(context.Background())
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if stats.Embedded != stats.Chunks {
+		t.Errorf("Expected full re-embedding for a new model, got %+v", stats)
+	}
+}
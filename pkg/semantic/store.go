@@ -0,0 +1,98 @@
+package semantic
+
+import (
+	"encoding/gob"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// storeVersion is bumped whenever the on-disk format changes
+const storeVersion = 1
+
+// Entry is a stored chunk vector
+type Entry struct {
+	Path    string
+	Symbol  string
+	Line    int
+	EndLine int
+	Hash    string
+	Vector  []float32
+}
+
+// Store is a file-backed vector store
+type Store struct {
+	path    string
+	Version int
+	Model   string
+	Entries map[string]*Entry // chunk key -> entry
+}
+
+// OpenStore loads the store at path, or returns an empty store if the file
+// does not exist, was written by another model, or uses an older format.
+func OpenStore(path, model string) (*Store, error) {
+	store := &Store{path: path, Version: storeVersion, Model: model, Entries: make(map[string]*Entry)}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open vector store: %w", err)
+	}
+	defer file.Close()
+
+	var loaded Store
+	if err := gob.NewDecoder(file).Decode(&loaded); err != nil {
+		// A corrupt store is rebuilt from scratch
+		return store, nil
+	}
+	if loaded.Version != storeVersion || loaded.Model != model || loaded.Entries == nil {
+		return store, nil
+	}
+	loaded.path = path
+	return &loaded, nil
+}
+
+// Save writes the store to disk atomically
+func (st *Store) Save() error {
+	if err := os.MkdirAll(filepath.Dir(st.path), 0755); err != nil {
+		return fmt.Errorf("failed to create store directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(st.path), ".vectors-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := gob.NewEncoder(tmp).Encode(st); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to encode vector store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write vector store: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), st.path); err != nil {
+		return fmt.Errorf("failed to save vector store: %w", err)
+	}
+	return nil
+}
+
+// cosine returns the cosine similarity of two vectors
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -7,9 +7,10 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
-	"os/signal"
 	"syscall"
 	"time"
+
+	"github.com/axon/pkg/interrupt"
 )
 
 // ANSI color codes
@@ -199,16 +200,13 @@ func (s *Server) isRunning() bool {
 	return resp.StatusCode < 500
 }
 
-// SetupSignalHandling sets up signal handlers to stop the server on exit
+// SetupSignalHandling sets up signal handlers to stop the server on exit.
+// Ctrl+C while a command or answer has claimed it stops only that.
 func (s *Server) SetupSignalHandling() {
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-
-	go func() {
-		<-sigChan
+	interrupt.Handle(func(os.Signal) {
 		s.Stop()
 		os.Exit(0)
-	}()
+	})
 }
 
 // CheckRunning checks if a server is already running at the given URL
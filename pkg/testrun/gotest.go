@@ -0,0 +1,226 @@
+package testrun
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// goTestEvent is one line of go test -json output (see go doc test2json)
+type goTestEvent struct {
+	Action      string
+	Package     string
+	Test        string
+	Elapsed     float64
+	Output      string
+	ImportPath  string // build-output and build-fail events
+	FailedBuild string // Set on a package's fail event when its build failed
+}
+
+// goTestState accumulates the events of one test or package
+type goTestState struct {
+	test        TestCase
+	output      strings.Builder
+	done        bool
+	buildFailed bool // The package failed because of a build error reported separately
+}
+
+// parseGoTestJSON parses go test -json output into test cases. Build and
+// package failures without a failing test are reported as a failed
+// "(package)" test. Lines that are not JSON events are returned as-is.
+func parseGoTestJSON(data []byte, projectRoot string) ([]TestCase, string) {
+	module := goModulePath(projectRoot)
+	tests := make(map[string]*goTestState)
+	packages := make(map[string]*goTestState)
+	var order []string
+	var rest strings.Builder
+
+	state := func(states map[string]*goTestState, key string, tc TestCase) *goTestState {
+		st, ok := states[key]
+		if !ok {
+			st = &goTestState{test: tc}
+			states[key] = st
+			order = append(order, key)
+		}
+		return st
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		var ev goTestEvent
+		if len(line) == 0 || line[0] != '{' || json.Unmarshal(line, &ev) != nil {
+			rest.Write(line)
+			rest.WriteByte('\n')
+			continue
+		}
+
+		switch {
+		case ev.Action == "build-output" || ev.Action == "build-fail":
+			// The import path of a test build reads "pkg [pkg.test]"
+			pkg, _, _ := strings.Cut(ev.ImportPath, " ")
+			st := state(packages, "build "+pkg, TestCase{Name: "(build)", Suite: pkg})
+			st.output.WriteString(ev.Output)
+			if ev.Action == "build-fail" {
+				st.test.Status, st.done = StatusFailed, true
+			}
+		case ev.Test != "":
+			st := state(tests, ev.Package+" "+ev.Test, TestCase{Name: ev.Test, Suite: ev.Package})
+			switch ev.Action {
+			case "output":
+				st.output.WriteString(ev.Output)
+			case "pass", "fail", "skip":
+				st.test.Status, st.test.Duration, st.done = goStatus(ev.Action), ev.Elapsed, true
+			}
+		default:
+			st := state(packages, ev.Package, TestCase{Name: "(package)", Suite: ev.Package})
+			switch ev.Action {
+			case "output":
+				st.output.WriteString(ev.Output)
+			case "pass", "fail", "skip":
+				st.test.Status, st.test.Duration, st.done = goStatus(ev.Action), ev.Elapsed, true
+				st.buildFailed = ev.FailedBuild != ""
+			}
+		}
+	}
+
+	failedIn := make(map[string]bool) // Packages with a failing test
+	for _, st := range tests {
+		if st.done && st.test.Status == StatusFailed {
+			failedIn[st.test.Suite] = true
+		}
+	}
+
+	var cases []TestCase
+	for _, key := range order {
+		if st, ok := tests[key]; ok {
+			if !st.done {
+				// Still running when the binary exited: panic or timeout
+				st.test.Status = StatusFailed
+			}
+			if st.test.Status != StatusPassed && hasFailedSubtest(tests, st.test) {
+				continue // The subtests carry the failure
+			}
+			st.test.Message = goTestMessage(st.output.String())
+			if st.test.Status == StatusFailed {
+				dir := goPackageDir(module, st.test.Suite)
+				st.test.File, st.test.Line = locate(st.test.Message, projectRoot, dir)
+				if st.test.File == "" {
+					st.test.File, st.test.Line = locate(st.output.String(), projectRoot, dir)
+				}
+			} else if st.test.Status == StatusPassed {
+				st.test.Message = ""
+			}
+			cases = append(cases, st.test)
+			continue
+		}
+
+		st := packages[key]
+		if st.test.Status != StatusFailed || st.buildFailed || failedIn[st.test.Suite] {
+			continue
+		}
+		// A package that failed without a failing test: build error, panic
+		// in init or TestMain, or a timeout
+		st.test.Message = truncate(goTestMessage(st.output.String()), maxMessageLength)
+		st.test.File, st.test.Line = locate(st.test.Message, projectRoot, goPackageDir(module, st.test.Suite))
+		cases = append(cases, st.test)
+	}
+
+	for i := range cases {
+		cases[i].Message = truncate(cases[i].Message, maxMessageLength)
+	}
+	sort.SliceStable(cases, func(i, j int) bool { return cases[i].Suite < cases[j].Suite })
+	return cases, rest.String()
+}
+
+// goStatus maps a go test action to a status
+func goStatus(action string) string {
+	switch action {
+	case "pass":
+		return StatusPassed
+	case "skip":
+		return StatusSkipped
+	}
+	return StatusFailed
+}
+
+// hasFailedSubtest reports whether a failed test has a failed subtest
+func hasFailedSubtest(tests map[string]*goTestState, parent TestCase) bool {
+	prefix := parent.Name + "/"
+	for _, st := range tests {
+		if st.test.Suite == parent.Suite && strings.HasPrefix(st.test.Name, prefix) && st.test.Status != StatusPassed {
+			return true
+		}
+	}
+	return false
+}
+
+// goTestMessage removes the framing lines (=== RUN, --- FAIL, FAIL, ok ...)
+// from a test's output
+func goTestMessage(output string) string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "",
+			strings.HasPrefix(trimmed, "=== "),
+			strings.HasPrefix(trimmed, "--- PASS"),
+			strings.HasPrefix(trimmed, "--- FAIL"),
+			strings.HasPrefix(trimmed, "--- SKIP"),
+			trimmed == "PASS", trimmed == "FAIL",
+			strings.HasPrefix(trimmed, "FAIL\t"), strings.HasPrefix(trimmed, "ok  \t"),
+			strings.HasPrefix(trimmed, "coverage:"):
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t"))
+	}
+	return dedent(lines)
+}
+
+// dedent removes the indentation common to all lines
+func dedent(lines []string) string {
+	indent := -1
+	for _, line := range lines {
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i := range lines {
+		lines[i] = lines[i][max(indent, 0):]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// goModulePath reads the module path from go.mod
+func goModulePath(projectRoot string) string {
+	data, err := os.ReadFile(filepath.Join(projectRoot, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if fields := strings.Fields(line); len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`)
+		}
+	}
+	return ""
+}
+
+// goPackageDir returns the project-relative directory of a package
+func goPackageDir(module, pkg string) string {
+	if module == "" {
+		return "."
+	}
+	if pkg == module {
+		return "."
+	}
+	if rel, ok := strings.CutPrefix(pkg, module+"/"); ok {
+		return rel
+	}
+	return "."
+}
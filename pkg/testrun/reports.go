@@ -0,0 +1,211 @@
+package testrun
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// junitSuite is a <testsuite> (or the <testsuites> root) of a JUnit XML
+// report. PHPUnit nests suites per class and data provider.
+type junitSuite struct {
+	Name   string       `xml:"name,attr"`
+	File   string       `xml:"file,attr"`
+	Suites []junitSuite `xml:"testsuite"`
+	Cases  []junitCase  `xml:"testcase"`
+}
+
+// junitCase is a <testcase>
+type junitCase struct {
+	Name      string         `xml:"name,attr"`
+	Classname string         `xml:"classname,attr"`
+	Class     string         `xml:"class,attr"` // PHPUnit
+	File      string         `xml:"file,attr"`
+	Line      string         `xml:"line,attr"`
+	Time      string         `xml:"time,attr"`
+	Failures  []junitFailure `xml:"failure"`
+	Errors    []junitFailure `xml:"error"`
+	Skipped   *junitFailure  `xml:"skipped"`
+}
+
+// junitFailure is a <failure>, <error> or <skipped> element
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// parseJUnit parses a JUnit XML report (PHPUnit, Pest, Vitest, pytest)
+func parseJUnit(data []byte, projectRoot string) ([]TestCase, error) {
+	var root junitSuite
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("invalid JUnit XML: %w", err)
+	}
+	cases := []TestCase{}
+	collectJUnit(root, "", projectRoot, &cases)
+	return cases, nil
+}
+
+// collectJUnit appends the test cases of a suite and its nested suites
+func collectJUnit(suite junitSuite, file, projectRoot string, cases *[]TestCase) {
+	if suite.File != "" {
+		file = suite.File
+	}
+	for _, c := range suite.Cases {
+		tc := TestCase{
+			Name:   c.Name,
+			Suite:  firstNonEmpty(c.Classname, c.Class, suite.Name),
+			Status: StatusPassed,
+		}
+		tc.Duration, _ = strconv.ParseFloat(c.Time, 64)
+
+		testFile := firstNonEmpty(c.File, file)
+		if testFile != "" {
+			tc.File = projectFile(testFile, projectRoot)
+			if tc.File != "" {
+				tc.Line, _ = strconv.Atoi(c.Line)
+			}
+		}
+
+		var problems []string
+		for _, f := range append(c.Failures, c.Errors...) {
+			problems = append(problems, failureText(f))
+		}
+		switch {
+		case len(problems) > 0:
+			tc.Status = StatusFailed
+			tc.Message = truncate(strings.Join(problems, "\n\n"), maxMessageLength)
+			var base []string
+			if tc.File != "" {
+				base = append(base, filepath.Dir(tc.File))
+			}
+			// Prefer the assertion's location over the test declaration
+			if file, line := locate(tc.Message, projectRoot, base...); file != "" {
+				tc.File, tc.Line = file, line
+			}
+		case c.Skipped != nil:
+			tc.Status = StatusSkipped
+			tc.Message = truncate(failureText(*c.Skipped), maxMessageLength)
+		}
+		*cases = append(*cases, tc)
+	}
+	for _, nested := range suite.Suites {
+		collectJUnit(nested, file, projectRoot, cases)
+	}
+}
+
+// failureText combines the message attribute and the body of a failure,
+// which often repeats the message
+func failureText(f junitFailure) string {
+	message := strings.TrimSpace(f.Message)
+	text := strings.TrimSpace(f.Text)
+	switch {
+	case text == "":
+		return message
+	case message == "" || strings.Contains(text, message):
+		return text
+	}
+	return message + "\n" + text
+}
+
+// firstNonEmpty returns the first non-empty string
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// jestReport is the report written by jest --json
+type jestReport struct {
+	TestResults []struct {
+		Name             string `json:"name"`
+		Status           string `json:"status"`
+		Message          string `json:"message"`
+		AssertionResults []struct {
+			FullName        string   `json:"fullName"`
+			Title           string   `json:"title"`
+			Status          string   `json:"status"`
+			Duration        float64  `json:"duration"` // Milliseconds
+			FailureMessages []string `json:"failureMessages"`
+			Location        *struct {
+				Line int `json:"line"`
+			} `json:"location"`
+		} `json:"assertionResults"`
+	} `json:"testResults"`
+}
+
+// parseJestJSON parses a jest --json report. A test file that failed to run
+// (syntax error, missing module) is reported as one failed test.
+func parseJestJSON(data []byte, projectRoot string) ([]TestCase, error) {
+	var report jestReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("invalid Jest report: %w", err)
+	}
+
+	cases := []TestCase{}
+	for _, file := range report.TestResults {
+		suite := projectFile(file.Name, projectRoot)
+		if suite == "" {
+			suite = file.Name
+		}
+		if len(file.AssertionResults) == 0 && file.Status == "failed" {
+			tc := TestCase{Name: "(test file)", Suite: suite, Status: StatusFailed, File: suite}
+			tc.Message = truncate(stripANSI(file.Message), maxMessageLength)
+			if f, line := locate(tc.Message, projectRoot); f != "" {
+				tc.File, tc.Line = f, line
+			}
+			cases = append(cases, tc)
+			continue
+		}
+
+		for _, a := range file.AssertionResults {
+			tc := TestCase{
+				Name:     firstNonEmpty(a.FullName, a.Title),
+				Suite:    suite,
+				Duration: a.Duration / 1000,
+				File:     suite,
+			}
+			if a.Location != nil {
+				tc.Line = a.Location.Line
+			}
+			switch a.Status {
+			case "passed":
+				tc.Status = StatusPassed
+			case "failed":
+				tc.Status = StatusFailed
+				tc.Message = truncate(stripANSI(strings.Join(a.FailureMessages, "\n\n")), maxMessageLength)
+				if f, line := locate(tc.Message, projectRoot); f != "" {
+					tc.File, tc.Line = f, line
+				}
+			default: // pending, skipped, todo, disabled
+				tc.Status = StatusSkipped
+			}
+			cases = append(cases, tc)
+		}
+	}
+	return cases, nil
+}
+
+// stripANSI removes terminal color codes, which Jest keeps in failure
+// messages even with colors disabled
+func stripANSI(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == 0x1b && i+1 < len(s) && s[i+1] == '[' {
+			j := i + 2
+			for j < len(s) && (s[j] < 0x40 || s[j] > 0x7e) {
+				j++
+			}
+			i = j
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,490 @@
+// Package testrun detects a project's test runner, runs its tests and parses
+// the results (go test -json, JUnit XML and Jest JSON reports) into
+// structured pass/fail records.
+package testrun
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/axon/pkg/execx"
+)
+
+// Test statuses
+const (
+	StatusPassed  = "passed"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// Output limits keep results small enough for the model's context
+const (
+	maxMessageLength = 2000
+	maxOutputLength  = 8000
+	maxReportLength  = 16 << 20 // Runner output parsed for results
+)
+
+// Runner is a test runner detected in the project
+type Runner struct {
+	Name     string `json:"name"`     // go, phpunit, pest, jest, vitest or pytest
+	Language string `json:"language"` // go, php, javascript or python
+}
+
+// Options selects the tests to run
+type Options struct {
+	Target  string        // Project-relative package directory or test file (empty for all tests)
+	Name    string        // Test name or filter pattern
+	Timeout time.Duration // Zero means no timeout
+	Exec    execx.Options // Sandbox, environment and output limit of the run; its Dir and Timeout are ignored
+}
+
+// TestCase is the outcome of one test
+type TestCase struct {
+	Name     string  `json:"name"`
+	Suite    string  `json:"suite,omitempty"` // Package, class or file the test belongs to
+	Status   string  `json:"status"`
+	Duration float64 `json:"duration,omitempty"` // Seconds
+	Message  string  `json:"message,omitempty"`  // Failure or skip message
+	File     string  `json:"file,omitempty"`     // Project-relative location of the failure, or of the test
+	Line     int     `json:"line,omitempty"`
+}
+
+// Result is the outcome of a test run
+type Result struct {
+	Runner   string     `json:"runner"`
+	Command  string     `json:"command"`
+	ExitCode int        `json:"exit_code"`
+	TimedOut bool       `json:"timed_out,omitempty"`
+	Duration float64    `json:"duration"` // Seconds
+	Passed   int        `json:"passed"`
+	Failed   int        `json:"failed"`
+	Skipped  int        `json:"skipped"`
+	Tests    []TestCase `json:"tests"`
+	Output   string     `json:"output,omitempty"` // Tail of the runner's own output (build errors, crashes)
+}
+
+// Failures returns the failed tests
+func (r *Result) Failures() []TestCase {
+	var failures []TestCase
+	for _, t := range r.Tests {
+		if t.Status == StatusFailed {
+			failures = append(failures, t)
+		}
+	}
+	return failures
+}
+
+// tally counts the tests by status
+func (r *Result) tally() {
+	r.Passed, r.Failed, r.Skipped = 0, 0, 0
+	for _, t := range r.Tests {
+		switch t.Status {
+		case StatusPassed:
+			r.Passed++
+		case StatusFailed:
+			r.Failed++
+		case StatusSkipped:
+			r.Skipped++
+		}
+	}
+}
+
+// Detect returns the test runners configured in the project root, in the
+// order go, PHP, JavaScript, Python
+func Detect(projectRoot string) []Runner {
+	var runners []Runner
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(projectRoot, name))
+		return err == nil
+	}
+
+	if exists("go.mod") {
+		runners = append(runners, Runner{Name: "go", Language: "go"})
+	}
+
+	if deps := packageDependencies(filepath.Join(projectRoot, "composer.json"), "require", "require-dev"); deps != nil {
+		switch {
+		case deps["pestphp/pest"] || exists("vendor/bin/pest"):
+			runners = append(runners, Runner{Name: "pest", Language: "php"})
+		case deps["phpunit/phpunit"] || exists("vendor/bin/phpunit") || exists("phpunit.xml") || exists("phpunit.xml.dist"):
+			runners = append(runners, Runner{Name: "phpunit", Language: "php"})
+		}
+	}
+
+	if deps := packageDependencies(filepath.Join(projectRoot, "package.json"), "dependencies", "devDependencies"); deps != nil {
+		switch {
+		case deps["vitest"]:
+			runners = append(runners, Runner{Name: "vitest", Language: "javascript"})
+		case deps["jest"]:
+			runners = append(runners, Runner{Name: "jest", Language: "javascript"})
+		}
+	}
+
+	if isPytestProject(projectRoot) {
+		runners = append(runners, Runner{Name: "pytest", Language: "python"})
+	}
+	return runners
+}
+
+// packageDependencies reads the dependency names from sections of a
+// composer.json or package.json file, or returns nil if it does not exist
+func packageDependencies(path string, sections ...string) map[string]bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var manifest map[string]json.RawMessage
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return map[string]bool{}
+	}
+	deps := make(map[string]bool)
+	for _, section := range sections {
+		var names map[string]interface{}
+		if err := json.Unmarshal(manifest[section], &names); err == nil {
+			for name := range names {
+				deps[name] = true
+			}
+		}
+	}
+	return deps
+}
+
+// isPytestProject looks for pytest configuration or a pytest requirement
+func isPytestProject(projectRoot string) bool {
+	markers := map[string]string{
+		"pytest.ini":           "",
+		"conftest.py":          "",
+		"tox.ini":              "[pytest]",
+		"setup.cfg":            "[tool:pytest]",
+		"pyproject.toml":       "pytest",
+		"requirements.txt":     "pytest",
+		"requirements-dev.txt": "pytest",
+	}
+	for name, needle := range markers {
+		data, err := os.ReadFile(filepath.Join(projectRoot, name))
+		if err == nil && strings.Contains(string(data), needle) {
+			return true
+		}
+	}
+	return false
+}
+
+// Select picks the runner by name, or by the language of the target file, or
+// the first detected runner
+func Select(runners []Runner, name, target string) (Runner, error) {
+	if len(runners) == 0 {
+		return Runner{}, fmt.Errorf("no test runner detected (looked for go.mod, composer.json with phpunit/pest, package.json with jest/vitest, and pytest configuration)")
+	}
+	if name != "" {
+		for _, r := range runners {
+			if r.Name == name {
+				return r, nil
+			}
+		}
+		return Runner{}, fmt.Errorf("test runner %q not detected (available: %s)", name, runnerNames(runners))
+	}
+	if language := targetLanguage(target); language != "" {
+		for _, r := range runners {
+			if r.Language == language {
+				return r, nil
+			}
+		}
+	}
+	return runners[0], nil
+}
+
+// runnerNames returns the runner names as a comma-separated list
+func runnerNames(runners []Runner) string {
+	names := make([]string, len(runners))
+	for i, r := range runners {
+		names[i] = r.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// targetLanguage returns the language of a test file target
+func targetLanguage(target string) string {
+	switch strings.ToLower(filepath.Ext(target)) {
+	case ".go":
+		return "go"
+	case ".php":
+		return "php"
+	case ".js", ".jsx", ".ts", ".tsx", ".mjs", ".cjs", ".mts", ".cts":
+		return "javascript"
+	case ".py":
+		return "python"
+	}
+	return ""
+}
+
+// Run runs the selected tests in the project root and parses the results
+func Run(ctx context.Context, projectRoot string, runner Runner, opts Options) (*Result, error) {
+	reportDir, err := os.MkdirTemp("", "axon-tests-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create report directory: %w", err)
+	}
+	defer os.RemoveAll(reportDir)
+
+	args, report, err := command(projectRoot, runner, opts, reportDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = execx.Quote(arg)
+	}
+	execOpts := opts.Exec
+	execOpts.Dir = projectRoot
+	execOpts.Writable = append(append([]string{projectRoot}, execOpts.Writable...), reportDir)
+	cmd, _, _, err := execx.Command(ctx, strings.Join(quoted, " "), execOpts)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Env = append(cmd.Env, "CI=true", "NO_COLOR=1")
+	cmd.WaitDelay = 5 * time.Second // Don't wait forever on children holding the pipes
+	// go test -json reports every test on stdout, so it keeps more than the
+	// output shown to the model
+	stdout := execx.NewOutputBuffer(maxReportLength)
+	stderr := execx.NewOutputBuffer(max(opts.Exec.MaxOutput, execx.DefaultMaxOutput))
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+
+	start := time.Now()
+	runErr := cmd.Run()
+	result := &Result{
+		Runner:   runner.Name,
+		Command:  strings.Join(args, " "),
+		Duration: time.Since(start).Round(time.Millisecond).Seconds(),
+		Tests:    []TestCase{},
+	}
+	if runErr != nil {
+		var exitErr *exec.ExitError
+		switch {
+		case ctx.Err() == context.DeadlineExceeded:
+			result.TimedOut = true
+			result.ExitCode = -1
+		case ctx.Err() == context.Canceled:
+			return nil, fmt.Errorf("test run stopped")
+		case errors.As(runErr, &exitErr):
+			result.ExitCode = exitErr.ExitCode()
+		case errors.Is(runErr, exec.ErrWaitDelay):
+			// Exited, but a background child still held the output open
+		default:
+			return nil, fmt.Errorf("failed to run %s: %w", args[0], runErr)
+		}
+	}
+
+	var output string
+	switch {
+	case runner.Name == "go":
+		var rest string
+		result.Tests, rest = parseGoTestJSON([]byte(stdout.String()), projectRoot)
+		output = rest + stderr.String()
+	default:
+		output = stdout.String() + stderr.String()
+		if data, err := os.ReadFile(report); err == nil {
+			if runner.Name == "jest" {
+				result.Tests, err = parseJestJSON(data, projectRoot)
+			} else {
+				result.Tests, err = parseJUnit(data, projectRoot)
+			}
+			if err != nil {
+				output += fmt.Sprintf("\n[axon: failed to parse test report: %v]", err)
+			}
+		}
+	}
+	result.tally()
+	result.Output = tail(strings.TrimSpace(output), maxOutputLength)
+	return result, nil
+}
+
+// command builds the command line of a runner and returns the report file it
+// writes (empty for go, which reports on stdout)
+func command(projectRoot string, runner Runner, opts Options, reportDir string) ([]string, string, error) {
+	target := filepath.ToSlash(filepath.Clean(opts.Target))
+	if opts.Target == "" || target == "." {
+		target = ""
+	}
+	if strings.HasPrefix(target, "../") || filepath.IsAbs(target) {
+		return nil, "", fmt.Errorf("target must be inside the project: %s", opts.Target)
+	}
+
+	switch runner.Name {
+	case "go":
+		args := []string{"go", "test", "-json"}
+		if opts.Name != "" {
+			args = append(args, "-run", opts.Name)
+		}
+		return append(args, goPackagePattern(projectRoot, target)), "", nil
+
+	case "phpunit", "pest":
+		report := filepath.Join(reportDir, "junit.xml")
+		args := append(localBinary(projectRoot, "vendor/bin/"+runner.Name, runner.Name), "--colors=never", "--log-junit", report)
+		if opts.Name != "" {
+			args = append(args, "--filter", opts.Name)
+		}
+		if target != "" {
+			args = append(args, target)
+		}
+		return args, report, nil
+
+	case "jest":
+		report := filepath.Join(reportDir, "jest.json")
+		args := append(localBinary(projectRoot, "node_modules/.bin/jest", "npx", "jest"), "--ci", "--json", "--testLocationInResults", "--outputFile", report)
+		if opts.Name != "" {
+			args = append(args, "-t", opts.Name)
+		}
+		if target != "" {
+			args = append(args, target)
+		}
+		return args, report, nil
+
+	case "vitest":
+		report := filepath.Join(reportDir, "junit.xml")
+		args := append(localBinary(projectRoot, "node_modules/.bin/vitest", "npx", "vitest"), "run", "--reporter=junit", "--outputFile="+report)
+		if opts.Name != "" {
+			args = append(args, "-t", opts.Name)
+		}
+		if target != "" {
+			args = append(args, target)
+		}
+		return args, report, nil
+
+	case "pytest":
+		report := filepath.Join(reportDir, "junit.xml")
+		args := []string{"python3", "-m", "pytest"}
+		if _, err := exec.LookPath("pytest"); err == nil {
+			args = []string{"pytest"}
+		}
+		// xunit1 reports include the file and line of each test
+		args = append(args, "-q", "-o", "junit_family=xunit1", "--junitxml="+report)
+		if opts.Name != "" {
+			args = append(args, "-k", opts.Name)
+		}
+		if target != "" {
+			args = append(args, target)
+		}
+		return args, report, nil
+	}
+	return nil, "", fmt.Errorf("unsupported test runner: %s", runner.Name)
+}
+
+// localBinary returns the project-local binary if installed, or the fallback
+// command
+func localBinary(projectRoot, path string, fallback ...string) []string {
+	if _, err := os.Stat(filepath.Join(projectRoot, path)); err == nil {
+		return []string{"./" + path}
+	}
+	return fallback
+}
+
+// goPackagePattern turns a target into a go test package pattern: a test
+// file runs its package, a directory its package, and no target everything
+func goPackagePattern(projectRoot, target string) string {
+	if target == "" {
+		return "./..."
+	}
+	if strings.HasSuffix(target, "...") {
+		return "./" + strings.TrimPrefix(target, "./")
+	}
+	if info, err := os.Stat(filepath.Join(projectRoot, target)); err == nil && !info.IsDir() {
+		target = filepath.ToSlash(filepath.Dir(target))
+	}
+	if target == "." {
+		return "."
+	}
+	return "./" + strings.TrimPrefix(target, "./")
+}
+
+// locationPattern matches file:line references such as foo_test.go:12,
+// /app/tests/UserTest.php:34 or (src/app.test.ts:5:10)
+var locationPattern = regexp.MustCompile(`([A-Za-z0-9_@.\-/\\]+\.[A-Za-z0-9]+):(\d+)`)
+
+// locate finds the first file:line reference in text that points to a
+// project file outside vendor/ and node_modules/, trying relative paths
+// against each base directory (project-relative). It returns a
+// project-relative path.
+func locate(text, projectRoot string, baseDirs ...string) (string, int) {
+	for _, m := range locationPattern.FindAllStringSubmatch(text, -1) {
+		line, err := strconv.Atoi(m[2])
+		if err != nil || line == 0 {
+			continue
+		}
+		if file := projectFile(m[1], projectRoot, baseDirs...); file != "" {
+			return file, line
+		}
+	}
+	return "", 0
+}
+
+// FileReferences returns the project files referenced as file:line in test or
+// build output, in order of first mention, skipping vendor/ and node_modules/
+func FileReferences(output, projectRoot string) []string {
+	var files []string
+	seen := make(map[string]bool)
+	for _, m := range locationPattern.FindAllStringSubmatch(output, -1) {
+		if file := projectFile(m[1], projectRoot); file != "" && !seen[file] {
+			seen[file] = true
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// projectFile resolves a path from test output to an existing project file,
+// or returns "" for files outside the project or in dependencies
+func projectFile(path, projectRoot string, baseDirs ...string) string {
+	var candidates []string
+	if filepath.IsAbs(path) {
+		candidates = append(candidates, path)
+	} else {
+		for _, dir := range append(baseDirs, ".") {
+			candidates = append(candidates, filepath.Join(projectRoot, dir, path))
+		}
+	}
+	for _, candidate := range candidates {
+		rel, err := filepath.Rel(projectRoot, candidate)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		if strings.HasPrefix(rel, "vendor/") || strings.Contains(rel, "node_modules/") {
+			continue
+		}
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return rel
+		}
+	}
+	return ""
+}
+
+// truncate caps a message, keeping its beginning
+func truncate(s string, max int) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "\n... (truncated)"
+}
+
+// tail caps output, keeping its end where runners print summaries and errors
+func tail(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return "(truncated) ...\n" + s[len(s)-max:]
+}
@@ -0,0 +1,282 @@
+package testrun
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		full := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDetectAndSelect(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod":         "module example.com/app\n",
+		"composer.json":  `{"require-dev": {"phpunit/phpunit": "^10"}}`,
+		"package.json":   `{"devDependencies": {"vitest": "^1"}}`,
+		"pyproject.toml": "[tool.pytest.ini_options]\n",
+	})
+
+	runners := Detect(root)
+	var names []string
+	for _, r := range runners {
+		names = append(names, r.Name)
+	}
+	if want := []string{"go", "phpunit", "vitest", "pytest"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("Detect = %v, want %v", names, want)
+	}
+
+	tests := []struct {
+		name, target, want string
+	}{
+		{"", "", "go"},
+		{"", "tests/Feature/UserTest.php", "phpunit"},
+		{"", "src/app.test.ts", "vitest"},
+		{"", "tests/test_app.py", "pytest"},
+		{"pytest", "", "pytest"},
+	}
+	for _, tt := range tests {
+		r, err := Select(runners, tt.name, tt.target)
+		if err != nil || r.Name != tt.want {
+			t.Errorf("Select(%q, %q) = %v, %v; want %s", tt.name, tt.target, r.Name, err, tt.want)
+		}
+	}
+	if _, err := Select(runners, "jest", ""); err == nil {
+		t.Error("Expected an error for a runner that was not detected")
+	}
+	if _, err := Select(nil, "", ""); err == nil {
+		t.Error("Expected an error when no runner is detected")
+	}
+}
+
+func TestGoPackagePattern(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"pkg/foo/foo_test.go": "package foo\n"})
+	tests := map[string]string{
+		"":                    "./...",
+		"pkg/foo":             "./pkg/foo",
+		"pkg/foo/foo_test.go": "./pkg/foo",
+		"./pkg/...":           "./pkg/...",
+	}
+	for target, want := range tests {
+		if got := goPackagePattern(root, target); got != want {
+			t.Errorf("goPackagePattern(%q) = %q, want %q", target, got, want)
+		}
+	}
+}
+
+func TestRun_Go(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go test")
+	}
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.21\n",
+		"calc/calc.go": `package calc
+
+func Add(a, b int) int { return a + b }
+`,
+		"calc/calc_test.go": `package calc
+
+import "testing"
+
+func TestAdd(t *testing.T) {
+	if Add(1, 2) != 3 {
+		t.Fatal("wrong sum")
+	}
+}
+
+func TestBroken(t *testing.T) {
+	got := Add(2, 2)
+	t.Errorf("Add(2, 2) = %d, want 5", got)
+}
+
+func TestTable(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {})
+	t.Run("bad", func(t *testing.T) { t.Error("sub failure") })
+}
+
+func TestSkipped(t *testing.T) {
+	t.Skip("not ready")
+}
+`,
+		"broken/broken_test.go": `package broken
+
+import "testing"
+
+func TestX(t *testing.T) { undefinedCall() }
+`,
+	})
+
+	result, err := Run(context.Background(), root, Runner{Name: "go", Language: "go"}, Options{Timeout: 2 * time.Minute})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.ExitCode == 0 {
+		t.Error("Expected a non-zero exit code")
+	}
+	if result.Passed != 2 || result.Failed != 3 || result.Skipped != 1 {
+		t.Errorf("Unexpected counts: passed=%d failed=%d skipped=%d\n%+v", result.Passed, result.Failed, result.Skipped, result.Tests)
+	}
+
+	failures := make(map[string]TestCase)
+	for _, f := range result.Failures() {
+		failures[f.Name] = f
+	}
+	broken := failures["TestBroken"]
+	if broken.File != "calc/calc_test.go" || broken.Line != 13 || !strings.Contains(broken.Message, "want 5") {
+		t.Errorf("Unexpected TestBroken failure: %+v", broken)
+	}
+	if _, ok := failures["TestTable"]; ok {
+		t.Error("Parent of a failing subtest should not be reported")
+	}
+	if sub := failures["TestTable/bad"]; sub.Line != 18 {
+		t.Errorf("Unexpected TestTable/bad failure: %+v", sub)
+	}
+	build, ok := failures["(build)"]
+	if !ok {
+		// Older toolchains print build errors on stderr
+		if !strings.Contains(result.Output, "undefinedCall") {
+			t.Errorf("Build failure not reported: %+v\n%s", result.Tests, result.Output)
+		}
+	} else if build.File != "broken/broken_test.go" || build.Line != 5 {
+		t.Errorf("Unexpected build failure: %+v", build)
+	}
+
+	// Narrow to one test
+	result, err = Run(context.Background(), root, Runner{Name: "go"}, Options{Target: "calc/calc_test.go", Name: "TestAdd"})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.ExitCode != 0 || result.Passed != 1 || result.Failed != 0 || len(result.Tests) != 1 {
+		t.Errorf("Unexpected narrowed result: %+v", result)
+	}
+}
+
+func TestParseJUnit(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"tests/Feature/UserTest.php": "<?php\n",
+		"tests/test_app.py":          "\n",
+	})
+
+	// PHPUnit: nested suites, absolute paths, the assertion location in the body
+	phpunit := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="Feature" tests="3">
+    <testsuite name="Tests\Feature\UserTest" file="` + root + `/tests/Feature/UserTest.php" tests="3">
+      <testcase name="test_create" class="Tests\Feature\UserTest" file="` + root + `/tests/Feature/UserTest.php" line="10" time="0.05"/>
+      <testcase name="test_update" class="Tests\Feature\UserTest" file="` + root + `/tests/Feature/UserTest.php" line="20" time="0.02">
+        <failure type="PHPUnit\Framework\ExpectationFailedException">Tests\Feature\UserTest::test_update
+Failed asserting that 404 matches expected 200.
+
+` + root + `/vendor/laravel/framework/src/Testing/TestResponse.php:120
+` + root + `/tests/Feature/UserTest.php:24</failure>
+      </testcase>
+      <testcase name="test_delete" class="Tests\Feature\UserTest" file="` + root + `/tests/Feature/UserTest.php" line="30">
+        <skipped/>
+      </testcase>
+    </testsuite>
+  </testsuite>
+</testsuites>`
+	cases, err := parseJUnit([]byte(phpunit), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TestCase{
+		{Name: "test_create", Suite: `Tests\Feature\UserTest`, Status: StatusPassed, Duration: 0.05, File: "tests/Feature/UserTest.php", Line: 10},
+		{Name: "test_update", Suite: `Tests\Feature\UserTest`, Status: StatusFailed, Duration: 0.02, File: "tests/Feature/UserTest.php", Line: 24},
+		{Name: "test_delete", Suite: `Tests\Feature\UserTest`, Status: StatusSkipped, File: "tests/Feature/UserTest.php", Line: 30},
+	}
+	for i := range cases {
+		cases[i].Message = ""
+	}
+	if !reflect.DeepEqual(cases, want) {
+		t.Errorf("PHPUnit cases:\n got %+v\nwant %+v", cases, want)
+	}
+
+	// pytest (xunit1): relative paths and the location in the failure body
+	pytest := `<?xml version="1.0" encoding="utf-8"?><testsuites><testsuite name="pytest" errors="0" failures="1" tests="1">
+<testcase classname="tests.test_app" name="test_total" file="tests/test_app.py" line="3" time="0.001"><failure message="assert 3 == 4">def test_total():
+&gt;       assert total([1, 2]) == 4
+E       assert 3 == 4
+
+tests/test_app.py:5: AssertionError</failure></testcase></testsuite></testsuites>`
+	cases, err = parseJUnit([]byte(pytest), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) != 1 || cases[0].Status != StatusFailed || cases[0].File != "tests/test_app.py" || cases[0].Line != 5 || !strings.Contains(cases[0].Message, "assert 3 == 4") {
+		t.Errorf("Unexpected pytest cases: %+v", cases)
+	}
+
+	if _, err := parseJUnit([]byte("not xml"), root); err == nil {
+		t.Error("Expected an error for an invalid report")
+	}
+}
+
+func TestParseJestJSON(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"src/sum.test.js":    "\n",
+		"src/broken.test.js": "\n",
+	})
+	report := `{"testResults": [
+  {"name": "` + root + `/src/sum.test.js", "status": "failed", "assertionResults": [
+    {"fullName": "sum adds", "title": "adds", "status": "passed", "duration": 3, "location": {"line": 3, "column": 1}},
+    {"fullName": "sum handles negatives", "title": "handles negatives", "status": "failed", "duration": 5, "location": {"line": 7, "column": 1},
+     "failureMessages": ["Error: \u001b[2mexpect(\u001b[22mreceived\u001b[2m).\u001b[22mtoBe(expected)\n\nExpected: -1\nReceived: 1\n    at Object.<anonymous> (` + root + `/src/sum.test.js:8:20)\n    at Promise.then.completed (` + root + `/node_modules/jest-circus/build/utils.js:298:28)"]},
+    {"fullName": "sum later", "title": "later", "status": "todo"}
+  ]},
+  {"name": "` + root + `/src/broken.test.js", "status": "failed", "message": "SyntaxError: Unexpected token (2:1)", "assertionResults": []}
+]}`
+	cases, err := parseJestJSON([]byte(report), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) != 4 {
+		t.Fatalf("Expected 4 cases, got %+v", cases)
+	}
+	failed := cases[1]
+	if failed.Status != StatusFailed || failed.File != "src/sum.test.js" || failed.Line != 8 || strings.Contains(failed.Message, "\u001b") || failed.Duration != 0.005 {
+		t.Errorf("Unexpected failed case: %+v", failed)
+	}
+	if cases[0].Status != StatusPassed || cases[0].Line != 3 || cases[2].Status != StatusSkipped {
+		t.Errorf("Unexpected cases: %+v", cases)
+	}
+	if cases[3].Name != "(test file)" || cases[3].Status != StatusFailed || cases[3].File != "src/broken.test.js" {
+		t.Errorf("Unexpected suite failure: %+v", cases[3])
+	}
+}
+
+func TestFileReferences(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"pkg/a/a.go":         "",
+		"pkg/a/a_test.go":    "",
+		"vendor/x/x.go":      "",
+		"tests/UserTest.php": "",
+	})
+	output := "# example.com/app/pkg/a\npkg/a/a.go:12:3: undefined: foo\n" +
+		"pkg/a/a_test.go:7: wrong\npkg/a/a.go:20:1: again\n" +
+		root + "/vendor/x/x.go:1\n" + root + "/tests/UserTest.php:9\nmissing.go:3: nope\n"
+	want := []string{"pkg/a/a.go", "pkg/a/a_test.go", "tests/UserTest.php"}
+	if got := FileReferences(output, root); !reflect.DeepEqual(got, want) {
+		t.Errorf("FileReferences = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,142 @@
+package tui
+
+import (
+	"context"
+	"strings"
+
+	"github.com/axon/pkg/chat"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	modalStyle   = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("3")).Padding(0, 1)
+	titleStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("3")).Bold(true)
+	hunkStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("6"))
+	removedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+)
+
+// approver asks for confirmations in the approval modal
+type approver struct {
+	send func(tea.Msg)
+}
+
+// Approve shows the modal and waits for the user's decision; a cancelled
+// turn answers no
+func (a approver) Approve(ctx context.Context, c chat.Confirmation) (string, error) {
+	answer := make(chan string, 1)
+	a.send(confirmMsg{confirmation: c, answer: answer})
+	select {
+	case decision := <-answer:
+		return decision, nil
+	case <-ctx.Done():
+		return chat.DecisionNo, nil
+	}
+}
+
+// approval is the modal showing a confirmation and the diff of a write
+type approval struct {
+	confirmation chat.Confirmation
+	answer       chan string
+	body         viewport.Model
+}
+
+func newApproval(c chat.Confirmation, answer chan string) *approval {
+	body := viewport.New(0, 0)
+	body.KeyMap = viewport.KeyMap{}
+	return &approval{confirmation: c, answer: answer, body: body}
+}
+
+// resize fits the modal, border included, into a width and height
+func (a *approval) resize(width, height int) {
+	// Border and padding take two lines and four columns; the title, the
+	// keys and a blank line three more lines
+	a.body.Width = max(1, width-4)
+	a.body.Height = max(1, height-5)
+	a.body.SetContent(a.content(a.body.Width))
+}
+
+// content is what the user is asked to approve
+func (a *approval) content(width int) string {
+	c := a.confirmation
+	wrap := lipgloss.NewStyle().Width(width)
+	lines := []string{wrap.Render(c.Description)}
+	if c.Diff != "" {
+		lines = append(lines, "")
+		for _, line := range strings.Split(strings.TrimRight(c.Diff, "\n"), "\n") {
+			switch {
+			case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+				line = dimStyle.Render(line)
+			case strings.HasPrefix(line, "+"):
+				line = allowStyle.Render(line)
+			case strings.HasPrefix(line, "-"):
+				line = removedStyle.Render(line)
+			case strings.HasPrefix(line, "@@"):
+				line = hunkStyle.Render(line)
+			}
+			lines = append(lines, line)
+		}
+	}
+	if len(c.Rules) > 0 {
+		lines = append(lines, "", wrap.Render(dimStyle.Render("Always adds "+strings.Join(c.Rules, ", ")+" to .axon.local.yml")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// update handles a key while the modal is shown
+func (a *approval) update(msg tea.KeyMsg, m *model) tea.Cmd {
+	decision := ""
+	switch msg.String() {
+	case "y":
+		decision = chat.DecisionYes
+	case "n", "esc":
+		decision = chat.DecisionNo
+	case "a":
+		if len(a.confirmation.Rules) > 0 {
+			decision = chat.DecisionAlways
+		}
+	case "ctrl+c":
+		decision = chat.DecisionNo
+		if m.cancel != nil {
+			m.cancel()
+		}
+	case "up", "k":
+		a.body.ScrollUp(1)
+	case "down", "j":
+		a.body.ScrollDown(1)
+	case "pgup":
+		a.body.HalfPageUp()
+	case "pgdown", " ":
+		a.body.HalfPageDown()
+	}
+	if decision == "" {
+		return nil
+	}
+
+	a.answer <- decision
+	m.approval = nil
+	text := "Rejected: " + a.confirmation.Action
+	switch decision {
+	case chat.DecisionYes:
+		text = "Approved: " + a.confirmation.Action
+	case chat.DecisionAlways:
+		text = "Always allowed: " + strings.Join(a.confirmation.Rules, ", ")
+	}
+	m.add(&entry{kind: entryNotice, text: text})
+	m.layout()
+	return nil
+}
+
+// view renders the modal
+func (a *approval) view() string {
+	keys := "[y]es  [n]o"
+	if len(a.confirmation.Rules) > 0 {
+		keys += "  [a]lways"
+	}
+	if !a.body.AtTop() || !a.body.AtBottom() {
+		keys += dimStyle.Render("   ↑/↓ pgup/pgdown scroll")
+	}
+	title := titleStyle.Render("⚠  " + a.confirmation.Action)
+	return modalStyle.Render(lipgloss.JoinVertical(lipgloss.Left, title, a.body.View(), "", keys))
+}
@@ -0,0 +1,189 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/axon/pkg/chat"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Lines of arguments and of results shown per call in the expanded panel
+const (
+	maxArgLines    = 10
+	maxResultLines = 12
+)
+
+// collapsedCalls is the number of calls the collapsed panel lists
+const collapsedCalls = 3
+
+// toolCall is a tool call of the conversation
+type toolCall struct {
+	name   string
+	args   map[string]interface{}
+	result string
+	failed bool
+	done   bool
+}
+
+// toolPanel lists the tool calls: the latest ones on a line each, or all of
+// them with their arguments and results when expanded
+type toolPanel struct {
+	calls     []*toolCall
+	expanded  bool
+	width     int
+	maxHeight int // Height of the expanded panel
+	offset    int // First line shown when expanded; -1 follows the end
+}
+
+// call adds a call that has started
+func (p *toolPanel) call(name string, args map[string]interface{}) {
+	p.calls = append(p.calls, &toolCall{name: name, args: args})
+	p.offset = -1
+}
+
+// result completes the latest running call of the event's tool
+func (p *toolPanel) result(e chat.Event) {
+	for i := len(p.calls) - 1; i >= 0; i-- {
+		c := p.calls[i]
+		if c.name != e.Tool || c.done {
+			continue
+		}
+		c.done = true
+		if e.Error != "" {
+			c.failed, c.result = true, e.Error
+			return
+		}
+		c.result = string(e.Result)
+		var pretty interface{}
+		if json.Unmarshal(e.Result, &pretty) == nil {
+			if s, ok := pretty.(string); ok {
+				c.result = s
+			} else if body, err := json.MarshalIndent(pretty, "", "  "); err == nil {
+				c.result = string(body)
+			}
+		}
+		return
+	}
+}
+
+// resize fits the panel to the width and the height it shares with the
+// transcript
+func (p *toolPanel) resize(width, available int) {
+	p.width = width
+	p.maxHeight = max(3, available*2/5)
+}
+
+// scroll moves the expanded panel by a few lines
+func (p *toolPanel) scroll(step int) {
+	if !p.expanded {
+		return
+	}
+	lines := p.lines()
+	body := p.height() - 1
+	offset := p.offset
+	if offset < 0 {
+		offset = max(0, len(lines)-body)
+	}
+	offset += step * 3
+	if offset >= len(lines)-body {
+		offset = -1
+	} else if offset < 0 {
+		offset = 0
+	}
+	p.offset = offset
+}
+
+// height is the number of lines the panel takes, with its header
+func (p *toolPanel) height() int {
+	if len(p.calls) == 0 {
+		return 0
+	}
+	if !p.expanded {
+		return 1 + min(collapsedCalls, len(p.calls))
+	}
+	return min(1+len(p.lines()), p.maxHeight)
+}
+
+// view renders the panel
+func (p *toolPanel) view() string {
+	if len(p.calls) == 0 {
+		return ""
+	}
+	toggle := "ctrl+t expands"
+	var body []string
+	if p.expanded {
+		toggle = "ctrl+t collapses, ctrl+up/down scroll"
+		lines := p.lines()
+		n := p.height() - 1
+		start := p.offset
+		if start < 0 || start > len(lines)-n {
+			start = max(0, len(lines)-n)
+		}
+		body = lines[start : start+n]
+	} else {
+		for _, c := range p.calls[max(0, len(p.calls)-collapsedCalls):] {
+			args, _ := json.Marshal(c.args)
+			body = append(body, c.status()+" "+c.name+" "+dimStyle.Render(string(args)))
+		}
+	}
+
+	header := fmt.Sprintf("── Tool calls (%d) · %s ", len(p.calls), toggle)
+	header += strings.Repeat("─", max(0, p.width-lipgloss.Width(header)))
+	clip := lipgloss.NewStyle().MaxWidth(p.width)
+	out := []string{dimStyle.Render(clip.Render(header))}
+	for _, line := range body {
+		out = append(out, clip.Render(line))
+	}
+	return strings.Join(out, "\n")
+}
+
+// lines are the calls with their arguments and results
+func (p *toolPanel) lines() []string {
+	var lines []string
+	for _, c := range p.calls {
+		lines = append(lines, c.status()+" "+c.name)
+		args, _ := json.MarshalIndent(c.args, "", "  ")
+		lines = append(lines, indent("args: ", string(args), maxArgLines)...)
+		if c.done {
+			style := dimStyle
+			if c.failed {
+				style = errorStyle
+			}
+			for _, line := range indent("result: ", c.result, maxResultLines) {
+				lines = append(lines, style.Render(line))
+			}
+		}
+	}
+	return lines
+}
+
+// status marks a call running, done or failed
+func (c *toolCall) status() string {
+	switch {
+	case !c.done:
+		return noticeStyle.Render("…")
+	case c.failed:
+		return errorStyle.Render("✗")
+	}
+	return allowStyle.Render("✓")
+}
+
+// indent labels text and indents it under a call, keeping at most limit
+// lines
+func indent(label, text string, limit int) []string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(lines) > limit {
+		lines = append(lines[:limit], fmt.Sprintf("… %d more lines", len(lines)-limit))
+	}
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		prefix := "    "
+		if i == 0 {
+			prefix = "  " + label
+		}
+		out[i] = prefix + line
+	}
+	return out
+}
@@ -0,0 +1,88 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/axon/pkg/policy"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Styles shared by the parts of the screen
+var (
+	userStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("6")).Bold(true)
+	assistantStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("5")).Bold(true)
+	noticeStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
+	errorStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	allowStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	dimStyle       = lipgloss.NewStyle().Faint(true)
+	statusStyle    = lipgloss.NewStyle().Reverse(true)
+)
+
+// Kinds of transcript entries
+const (
+	entryUser       = "user"
+	entryAssistant  = "assistant"
+	entryPermission = "permission"
+	entryNotice     = "notice"
+	entryError      = "error"
+	entryOutput     = "output" // What a chat command prints, colors included
+)
+
+// entry is one item of the transcript
+type entry struct {
+	kind      string
+	text      string
+	decision  string // Permission entries: allow or deny
+	rule      string // Permission entries: the deciding rule
+	streaming bool   // Answers still streaming are shown as plain text
+
+	rendered string // Cached rendering; cleared when the width changes
+}
+
+// render returns the entry as it is shown at a width
+func (e *entry) render(width int, style string) string {
+	if e.rendered != "" && !e.streaming {
+		return e.rendered
+	}
+	wrap := lipgloss.NewStyle().Width(max(1, width-2)).PaddingLeft(1)
+	var out string
+	switch e.kind {
+	case entryUser:
+		out = "\n" + userStyle.Render(" You") + "\n" + wrap.Render(e.text)
+	case entryAssistant:
+		body := wrap.Render(e.text)
+		if !e.streaming {
+			body = renderMarkdown(e.text, width, style)
+		}
+		out = "\n" + assistantStyle.Render(" AXON") + "\n" + body
+	case entryPermission:
+		if e.decision == policy.Allow {
+			out = wrap.Render(allowStyle.Render("✓ " + e.text + " (allowed by " + e.rule + ")"))
+		} else {
+			out = wrap.Render(errorStyle.Render("⛔ " + e.text + " denied by " + e.rule))
+		}
+	case entryOutput:
+		out = wrap.Render(strings.Trim(e.text, "\n"))
+	case entryError:
+		out = wrap.Render(errorStyle.Render("Error: " + e.text))
+	default:
+		out = wrap.Render(noticeStyle.Render(e.text))
+	}
+	e.rendered = out
+	return out
+}
+
+// renderMarkdown renders an answer, falling back to plain text
+func renderMarkdown(markdown string, width int, style string) string {
+	renderer, err := glamour.NewTermRenderer(
+		glamour.WithStandardStyle(style),
+		glamour.WithWordWrap(max(20, width-4)),
+	)
+	if err == nil {
+		if out, err := renderer.Render(markdown); err == nil {
+			return strings.Trim(out, "\n")
+		}
+	}
+	return lipgloss.NewStyle().Width(max(1, width-2)).PaddingLeft(1).Render(markdown)
+}
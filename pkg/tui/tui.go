@@ -0,0 +1,555 @@
+// Package tui is a full-screen terminal interface for chat sessions: a
+// scrollable transcript, a panel with the tool calls of the conversation,
+// a modal for approving writes with their diff, a status bar and a
+// multiline input with history. It drives chat.Session through its event
+// stream and Approver, like the HTTP API and the JSON-lines mode do.
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/axon/pkg/chat"
+	"github.com/axon/pkg/indexer"
+	"github.com/axon/pkg/lineedit"
+	"github.com/axon/pkg/llm"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Options configure the interface
+type Options struct {
+	Model string         // Shown in the status bar
+	Index *indexer.Index // Indexed in the background on start; nil if there is none
+	// History holds the messages browsed with up and down, shared with the
+	// plain REPL; nil keeps them for the run only
+	History *lineedit.History
+}
+
+// maxInputHeight is the number of lines the input grows to
+const maxInputHeight = 6
+
+// Messages from the running turn and the indexer
+type (
+	eventMsg    chat.Event
+	turnDoneMsg struct {
+		answer string
+		err    error
+	}
+	commandDoneMsg struct {
+		err error
+	}
+	confirmMsg struct {
+		confirmation chat.Confirmation
+		answer       chan string
+	}
+	indexMsg     indexer.IndexProgress
+	indexDoneMsg struct {
+		progress indexer.IndexProgress
+		err      error
+	}
+)
+
+// model is the state of the interface
+type model struct {
+	session *chat.Session
+	opts    Options
+	send    func(tea.Msg) // Delivers messages from other goroutines
+	style   string        // Glamour style, detected before the screen is taken
+
+	width, height int
+	transcript    viewport.Model
+	entries       []*entry
+	streaming     *entry // The answer being streamed, if any
+	output        *entry // What the running command prints, if any
+	tools         toolPanel
+	input         textarea.Model
+	history       []string
+	historyPos    int    // Position while browsing history; len(history) when not
+	draft         string // The input before browsing history
+	approval      *approval
+
+	busy     bool
+	cancel   context.CancelFunc // Cancels the running turn
+	usage    llm.Usage
+	indexing bool
+	index    string // Index state for the status bar
+}
+
+// Run shows the interface for a session until the user quits. The session
+// asks for confirmations through the interface from then on.
+func Run(session *chat.Session, opts Options) error {
+	m := newModel(session, opts)
+	// Asking the terminal for its background once the program reads the
+	// input would swallow keys
+	if !lipgloss.HasDarkBackground() {
+		m.style = "light"
+	}
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	m.send = p.Send
+	session.SetApprover(approver{send: p.Send})
+	_, err := p.Run()
+	if m.cancel != nil {
+		m.cancel()
+	}
+	return err
+}
+
+func newModel(session *chat.Session, opts Options) *model {
+	input := textarea.New()
+	input.Placeholder = "Ask about the project, or /help"
+	input.ShowLineNumbers = false
+	input.Prompt = "> "
+	input.CharLimit = 0
+	input.FocusedStyle.CursorLine = lipgloss.NewStyle()
+	input.KeyMap.InsertNewline = key.NewBinding(key.WithKeys("alt+enter", "ctrl+j"))
+	input.SetHeight(1)
+	input.Focus()
+
+	m := &model{
+		session:    session,
+		opts:       opts,
+		send:       func(tea.Msg) {},
+		style:      "dark",
+		transcript: viewport.New(0, 0),
+		input:      input,
+		index:      "no index",
+	}
+	m.transcript.KeyMap = viewport.KeyMap{} // Keys go to the input; scrolling is handled in Update
+	if opts.History != nil {
+		m.history = slices.Clone(opts.History.Entries())
+		m.historyPos = len(m.history)
+	}
+	m.add(&entry{kind: entryNotice, text: "Type a question, or /help for commands. Ctrl+C quits."})
+	return m
+}
+
+func (m *model) Init() tea.Cmd {
+	return tea.Batch(textarea.Blink, m.reindex())
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		for _, e := range m.entries {
+			e.rendered = "" // Markdown is wrapped to the width
+		}
+		m.layout()
+		m.refresh()
+		return m, nil
+	case tea.KeyMsg:
+		if m.approval != nil {
+			return m, m.approval.update(msg, m)
+		}
+		return m.key(msg)
+	case eventMsg:
+		m.event(chat.Event(msg))
+		return m, nil
+	case turnDoneMsg:
+		m.finishTurn(msg.answer, msg.err)
+		return m, nil
+	case commandDoneMsg:
+		m.finishCommand(msg.err)
+		return m, nil
+	case confirmMsg:
+		m.approval = newApproval(msg.confirmation, msg.answer)
+		m.layout()
+		return m, nil
+	case indexMsg:
+		m.index = "indexing " + indexer.IndexProgress(msg).String()
+		return m, nil
+	case indexDoneMsg:
+		m.indexing = false
+		if msg.err != nil {
+			m.index = "index failed"
+			m.add(&entry{kind: entryError, text: fmt.Sprintf("Failed to index the project: %v", msg.err)})
+		} else {
+			m.index = fmt.Sprintf("%d files indexed", msg.progress.Processed)
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// key handles a key press outside the approval modal
+func (m *model) key(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		if m.busy {
+			m.cancel()
+			return m, nil
+		}
+		return m, tea.Quit
+	case "esc":
+		if m.busy {
+			m.cancel()
+		}
+		return m, nil
+	case "ctrl+d":
+		if m.input.Value() == "" && !m.busy {
+			return m, tea.Quit
+		}
+	case "enter":
+		return m, m.submit()
+	case "pgup":
+		m.transcript.HalfPageUp()
+		return m, nil
+	case "pgdown":
+		m.transcript.HalfPageDown()
+		return m, nil
+	case "ctrl+t":
+		m.tools.expanded = !m.tools.expanded
+		m.layout()
+		return m, nil
+	case "ctrl+up":
+		m.tools.scroll(-1)
+		return m, nil
+	case "ctrl+down":
+		m.tools.scroll(1)
+		return m, nil
+	case "up":
+		if m.input.Line() == 0 && m.browseHistory(-1) {
+			return m, nil
+		}
+	case "down":
+		if m.input.Line() == m.input.LineCount()-1 && m.browseHistory(1) {
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	m.layout()
+	return m, cmd
+}
+
+// browseHistory replaces the input with an earlier or later message; it
+// reports whether there was one
+func (m *model) browseHistory(step int) bool {
+	pos := m.historyPos + step
+	if pos < 0 || pos > len(m.history) || len(m.history) == 0 {
+		return false
+	}
+	if m.historyPos == len(m.history) {
+		m.draft = m.input.Value()
+	}
+	m.historyPos = pos
+	if pos == len(m.history) {
+		m.input.SetValue(m.draft)
+	} else {
+		m.input.SetValue(m.history[pos])
+	}
+	m.layout()
+	return true
+}
+
+// submit sends the input as a message or runs it as a command
+func (m *model) submit() tea.Cmd {
+	input := strings.TrimSpace(m.input.Value())
+	if input == "" || m.busy {
+		return nil
+	}
+	if len(m.history) == 0 || m.history[len(m.history)-1] != input {
+		m.history = append(m.history, input)
+	}
+	if m.opts.History != nil {
+		m.opts.History.Add(input)
+	}
+	m.historyPos = len(m.history)
+	m.input.Reset()
+	m.layout()
+
+	if strings.HasPrefix(input, "/") {
+		if cmd, ok := m.command(input); ok {
+			return cmd
+		}
+	}
+
+	m.add(&entry{kind: entryUser, text: input})
+	ctx, cancel := context.WithCancel(context.Background())
+	m.busy, m.cancel = true, cancel
+	session, send := m.session, m.send
+	return func() tea.Msg {
+		answer, err := session.Send(ctx, input, func(e chat.Event) {
+			send(eventMsg(e))
+		})
+		return turnDoneMsg{answer: answer, err: err}
+	}
+}
+
+// command runs a chat command; unknown commands are sent as messages
+func (m *model) command(input string) (tea.Cmd, bool) {
+	name := strings.Fields(input)[0]
+	switch name {
+	case "/exit", "/quit", "/q":
+		return tea.Quit, true
+	case "/clear", "/reset":
+		m.session.Reset()
+		m.entries, m.streaming, m.tools.calls = nil, nil, nil
+		m.add(&entry{kind: entryNotice, text: "Conversation history cleared."})
+		m.layout()
+		return nil, true
+	case "/reindex":
+		if m.indexing {
+			m.add(&entry{kind: entryNotice, text: "The project is being indexed already."})
+			return nil, true
+		}
+		return m.reindex(), true
+	case "/help", "/h":
+		m.add(&entry{kind: entryNotice, text: helpText})
+		return nil, true
+	}
+	if chat.IsCommand(input) {
+		return m.runCommand(input), true
+	}
+	return nil, false
+}
+
+// runCommand runs a chat command such as /fix or /diff through the session,
+// showing what it prints and the answers it streams
+func (m *model) runCommand(input string) tea.Cmd {
+	m.add(&entry{kind: entryUser, text: input})
+	ctx, cancel := context.WithCancel(context.Background())
+	m.busy, m.cancel = true, cancel
+	session, send := m.session, m.send
+	return func() tea.Msg {
+		err := session.RunCommand(ctx, input, func(e chat.Event) {
+			send(eventMsg(e))
+		})
+		return commandDoneMsg{err: err}
+	}
+}
+
+const helpText = `Keys: enter sends, alt+enter or ctrl+j adds a line, up/down browse earlier messages,
+pgup/pgdown scroll the conversation, ctrl+t expands the tool calls and ctrl+up/ctrl+down
+scroll them, esc stops the answer or command, ctrl+c quits.
+Commands: /clear starts over, /reindex rebuilds the project index, /exit quits.
+/file <path> shows a file, /explain <path> [start:end] explains it, /find <question> searches by meaning.
+/fix <command> edits until the command passes; /checkpoints, /diff [checkpoint] and /rollback <checkpoint>
+review or undo its changes. /commit commits the staged changes once you approve the message.`
+
+// reindex indexes the project in the background, reporting progress
+func (m *model) reindex() tea.Cmd {
+	idx := m.opts.Index
+	if idx == nil {
+		return nil
+	}
+	m.indexing = true
+	m.index = "indexing"
+	send := m.send
+	return func() tea.Msg {
+		var final indexer.IndexProgress
+		idx.SetProgressFunc(func(p indexer.IndexProgress) {
+			if p.Done {
+				final = p
+				return
+			}
+			send(indexMsg(p))
+		})
+		defer idx.SetProgressFunc(nil)
+		err := idx.IndexProject()
+		return indexDoneMsg{progress: final, err: err}
+	}
+}
+
+// event shows an event of the running turn
+func (m *model) event(e chat.Event) {
+	switch e.Type {
+	case chat.EventOutput:
+		m.endStreaming()
+		if m.output == nil {
+			m.output = &entry{kind: entryOutput, streaming: true}
+			m.entries = append(m.entries, m.output)
+		}
+		m.output.text += e.Text
+		m.refresh()
+	case chat.EventToken:
+		m.endOutput()
+		if m.streaming == nil {
+			m.streaming = &entry{kind: entryAssistant, streaming: true}
+			m.entries = append(m.entries, m.streaming)
+		}
+		m.streaming.text += e.Text
+		m.refresh()
+	case chat.EventToolCall:
+		// Text streamed before a tool call is not part of the answer
+		m.endStreaming()
+		m.endOutput()
+		m.tools.call(e.Tool, e.Args)
+		m.layout()
+	case chat.EventToolResult:
+		m.tools.result(e)
+	case chat.EventPermission:
+		m.add(&entry{kind: entryPermission, text: e.Text, decision: e.Decision, rule: e.Rule})
+	case chat.EventNotice:
+		m.add(&entry{kind: entryNotice, text: e.Text})
+	case chat.EventUsage:
+		m.usage.Add(*e.Usage)
+	}
+	// Errors and the answer come with turnDoneMsg; confirmations with
+	// confirmMsg
+}
+
+// finishTurn shows the answer or why there is none
+func (m *model) finishTurn(answer string, err error) {
+	m.busy = false
+	m.cancel()
+	m.cancel = nil
+	if m.approval != nil {
+		// The turn no longer waits for it
+		m.approval = nil
+		m.layout()
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		m.endStreaming()
+		m.add(&entry{kind: entryNotice, text: "Stopped."})
+	case err != nil:
+		m.endStreaming()
+		m.add(&entry{kind: entryError, text: err.Error()})
+	case m.streaming != nil:
+		m.streaming.text = answer
+		m.endStreaming()
+		m.refresh()
+	case answer != "":
+		m.add(&entry{kind: entryAssistant, text: answer})
+	}
+}
+
+// finishCommand ends a command run by runCommand
+func (m *model) finishCommand(err error) {
+	m.busy = false
+	m.cancel()
+	m.cancel = nil
+	if m.approval != nil {
+		m.approval = nil
+		m.layout()
+	}
+	m.endStreaming()
+	m.endOutput()
+	switch {
+	case errors.Is(err, context.Canceled):
+		m.add(&entry{kind: entryNotice, text: "Stopped."})
+	case err != nil:
+		m.add(&entry{kind: entryError, text: err.Error()})
+	}
+	m.refresh()
+}
+
+// endOutput stops adding to the output of the command, so that what it
+// prints next starts a new entry
+func (m *model) endOutput() {
+	if m.output != nil {
+		m.output.streaming = false
+		m.output.rendered = ""
+		m.output = nil
+	}
+}
+
+// endStreaming stops adding tokens to the current answer
+func (m *model) endStreaming() {
+	if m.streaming != nil {
+		m.streaming.streaming = false
+		m.streaming.rendered = ""
+		m.streaming = nil
+	}
+}
+
+// add appends an entry to the transcript
+func (m *model) add(e *entry) {
+	m.entries = append(m.entries, e)
+	m.refresh()
+}
+
+// refresh re-renders the transcript, following the end of the
+// conversation unless the user scrolled away from it
+func (m *model) refresh() {
+	if m.width == 0 {
+		return
+	}
+	atBottom := m.transcript.AtBottom()
+	var parts []string
+	for _, e := range m.entries {
+		parts = append(parts, e.render(m.width, m.style))
+	}
+	m.transcript.SetContent(strings.Join(parts, "\n"))
+	if atBottom {
+		m.transcript.GotoBottom()
+	}
+}
+
+// layout sizes the parts of the screen
+func (m *model) layout() {
+	if m.width == 0 {
+		return
+	}
+	m.input.SetWidth(m.width)
+	m.input.SetHeight(max(1, min(m.input.LineCount(), maxInputHeight)))
+
+	// Status bar, input separator and input
+	rest := m.height - 2 - m.input.Height()
+	m.tools.resize(m.width, rest)
+	rest -= m.tools.height()
+	if m.approval != nil {
+		m.approval.resize(m.width, rest+m.tools.height())
+	}
+	m.transcript.Width = m.width
+	m.transcript.Height = max(1, rest)
+	m.refresh()
+}
+
+func (m *model) View() string {
+	if m.width == 0 {
+		return ""
+	}
+	var main string
+	if m.approval != nil {
+		main = m.approval.view()
+	} else {
+		main = m.transcript.View()
+		if panel := m.tools.view(); panel != "" {
+			main += "\n" + panel
+		}
+	}
+	separator := dimStyle.Render(strings.Repeat("─", m.width))
+	return lipgloss.JoinVertical(lipgloss.Left, main, separator, m.input.View(), m.status())
+}
+
+// status renders the status bar
+func (m *model) status() string {
+	state := "ready"
+	switch {
+	case m.approval != nil:
+		state = "waiting for approval"
+	case m.busy:
+		state = "thinking… (esc stops)"
+	}
+	parts := []string{m.opts.Model}
+	if m.usage.TotalTokens > 0 {
+		parts = append(parts, fmt.Sprintf("%s in / %s out tokens", count(m.usage.PromptTokens), count(m.usage.CompletionTokens)))
+	}
+	parts = append(parts, m.index, state)
+	line := " " + strings.Join(parts, " │ ")
+	if hint := "ctrl+t tools · /help "; lipgloss.Width(line)+lipgloss.Width(hint)+1 < m.width {
+		line += strings.Repeat(" ", m.width-lipgloss.Width(line)-lipgloss.Width(hint)) + hint
+	}
+	return statusStyle.Width(m.width).MaxWidth(m.width).Render(line)
+}
+
+// count formats a token count compactly
+func count(n int) string {
+	if n < 1000 {
+		return fmt.Sprint(n)
+	}
+	return fmt.Sprintf("%.1fk", float64(n)/1000)
+}
@@ -0,0 +1,188 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/axon/pkg/chat"
+	"github.com/axon/pkg/lineedit"
+	"github.com/axon/pkg/llm"
+	"github.com/axon/pkg/policy"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newTestModel creates an interface without a session, sized like a small
+// terminal
+func newTestModel(t *testing.T) *model {
+	m := newModel(nil, Options{Model: "qwen2.5-coder"})
+	m.Update(tea.WindowSizeMsg{Width: 80, Height: 30})
+	return m
+}
+
+func keys(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestTurn(t *testing.T) {
+	m := newTestModel(t)
+	m.busy, m.cancel = true, func() {}
+
+	m.Update(eventMsg{Type: chat.EventToolCall, Tool: "read_file", Args: map[string]interface{}{"path": "main.go"}})
+	m.Update(eventMsg{Type: chat.EventToolResult, Tool: "read_file", Result: json.RawMessage(`{"content":"package main"}`)})
+	m.Update(eventMsg{Type: chat.EventPermission, Text: "Run tests", Decision: policy.Allow, Rule: "run_tests"})
+	m.Update(eventMsg{Type: chat.EventToken, Text: "It is "})
+	m.Update(eventMsg{Type: chat.EventToken, Text: "the main package."})
+	m.Update(eventMsg{Type: chat.EventUsage, Usage: &llm.Usage{PromptTokens: 1200, CompletionTokens: 20, TotalTokens: 1220}})
+
+	view := m.View()
+	for _, want := range []string{"Tool calls (1)", `read_file {"path":"main.go"}`, "allowed by run_tests", "It is the main package.", "1.2k in / 20 out tokens", "thinking"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view lacks %q:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, `"content": "package main"`) {
+		t.Error("collapsed panel shows results")
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	if view := m.View(); !strings.Contains(view, `"content": "package main"`) {
+		t.Errorf("expanded panel lacks the result:\n%s", view)
+	}
+
+	m.Update(turnDoneMsg{answer: "It is the main package."})
+	if m.busy || m.streaming != nil {
+		t.Error("turn not finished")
+	}
+	if view := m.View(); !strings.Contains(view, "ready") {
+		t.Errorf("status not ready:\n%s", view)
+	}
+}
+
+func TestApproval(t *testing.T) {
+	m := newTestModel(t)
+	m.busy, m.cancel = true, func() {}
+	answer := make(chan string, 1)
+	m.Update(confirmMsg{confirmation: chat.Confirmation{
+		ID:          "1",
+		Action:      "Create new file",
+		Description: "File: a.txt",
+		Diff:        "--- a/a.txt\n+++ b/a.txt\n@@ -0,0 +1 @@\n+hello\n",
+		Rules:       []string{"write: a.txt"},
+	}, answer: answer})
+
+	view := m.View()
+	for _, want := range []string{"Create new file", "+hello", "[a]lways", "waiting for approval"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("modal lacks %q:\n%s", want, view)
+		}
+	}
+
+	// Typing does not reach the input while the modal is shown
+	m.Update(keys("x"))
+	if m.approval == nil || m.input.Value() != "" {
+		t.Fatal("key leaked out of the modal")
+	}
+	m.Update(keys("a"))
+	if got := <-answer; got != chat.DecisionAlways {
+		t.Errorf("decision = %q", got)
+	}
+	if m.approval != nil || !strings.Contains(m.View(), "Always allowed: write: a.txt") {
+		t.Errorf("modal not closed:\n%s", m.View())
+	}
+}
+
+func TestApproverCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	asked := make(chan tea.Msg, 1)
+	a := approver{send: func(msg tea.Msg) {
+		asked <- msg
+		cancel()
+	}}
+	if decision, err := a.Approve(ctx, chat.Confirmation{ID: "1"}); err != nil || decision != chat.DecisionNo {
+		t.Errorf("Approve = %q, %v", decision, err)
+	}
+	if _, ok := (<-asked).(confirmMsg); !ok {
+		t.Error("modal not requested")
+	}
+}
+
+func TestHistory(t *testing.T) {
+	m := newTestModel(t)
+	m.history = []string{"first", "second"}
+	m.historyPos = len(m.history)
+	m.input.SetValue("draft")
+
+	up, down := tea.KeyMsg{Type: tea.KeyUp}, tea.KeyMsg{Type: tea.KeyDown}
+	for i, step := range []struct {
+		key  tea.KeyMsg
+		want string
+	}{{up, "second"}, {up, "first"}, {up, "first"}, {down, "second"}, {down, "draft"}} {
+		m.Update(step.key)
+		if got := m.input.Value(); got != step.want {
+			t.Errorf("step %d: input = %q, want %q", i, got, step.want)
+		}
+	}
+}
+
+func TestCommands(t *testing.T) {
+	m := newTestModel(t)
+	m.input.SetValue("/fix go test ./...")
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd == nil || !m.busy {
+		t.Fatal("/fix did not run")
+	}
+	if m.history[0] != "/fix go test ./..." {
+		t.Errorf("history = %q", m.history)
+	}
+
+	// What the command prints and the answers it streams take turns
+	m.Update(eventMsg{Type: chat.EventOutput, Text: "\nRunning: go test ./...\n"})
+	m.Update(eventMsg{Type: chat.EventOutput, Text: "❌ Failed with exit code 1\n"})
+	m.Update(eventMsg{Type: chat.EventToken, Text: "Fixing the test."})
+	m.Update(eventMsg{Type: chat.EventOutput, Text: "✅ The command passes after 1 round(s).\n"})
+	m.Update(commandDoneMsg{})
+	if m.busy || m.output != nil || m.streaming != nil {
+		t.Error("command not finished")
+	}
+	view := m.View()
+	for _, want := range []string{"Running: go test ./...", "Failed with exit code 1", "Fixing", "The command passes"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view lacks %q:\n%s", want, view)
+		}
+	}
+	if n := len(m.entries); n != 5 {
+		t.Errorf("%d entries, want the welcome, the command, two outputs and the answer", n)
+	}
+
+	m.input.SetValue("/rollback abc123")
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m.Update(commandDoneMsg{err: context.Canceled})
+	if view := m.View(); !strings.Contains(view, "Stopped.") {
+		t.Errorf("no stop notice:\n%s", view)
+	}
+
+	m.input.SetValue("/exit")
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd == nil {
+		t.Fatal("/exit did not quit")
+	} else if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("/exit did not quit")
+	}
+}
+
+func TestPersistentHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	lineedit.OpenHistory(path).Add("from the REPL")
+
+	m := newModel(nil, Options{History: lineedit.OpenHistory(path)})
+	m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if got := m.input.Value(); got != "from the REPL" {
+		t.Errorf("input = %q", got)
+	}
+	m.input.SetValue("/help")
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if entries := lineedit.OpenHistory(path).Entries(); len(entries) != 2 || entries[1] != "/help" {
+		t.Errorf("history file = %q", entries)
+	}
+}